@@ -0,0 +1,258 @@
+// Package mirror reverses texts by grapheme clusters, the characters as
+// perceived by the users, so that the emoji sequences, the flags and the
+// combining marks stay intact once reversed, as uniseg.ReverseString does.
+//
+// Unlike uniseg.ReverseString, Reverse stops as soon as its context is
+// canceled and can report its progress, for the servers processing large
+// inputs on behalf of the clients:
+//
+//	mirrored, err := mirror.Reverse(ctx, "Hello, 世界👋🏽")
+//	// mirrored == "👋🏽界世 ,olleH"
+//
+// Segments yields the grapheme clusters of a reader one by one, for the tools
+// processing inputs too large to be loaded whole.
+package mirror
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+	"unsafe"
+
+	"github.com/rivo/uniseg"
+)
+
+// CheckInterval is the default number of grapheme clusters processed between
+// the checks of the context cancellation. See WithCheckInterval.
+const CheckInterval = 4 * 1024
+
+// Chunk is a part of the output completed since the previous progress report.
+// The output is filled from its end, since it is the reversal of the input.
+type Chunk struct {
+	Offset int    // byte offset of the chunk in the output
+	Data   []byte // must not be modified nor retained
+}
+
+// ProgressFunc is notified that done grapheme clusters out of total are
+// processed, with the part of the output completed since the previous call.
+type ProgressFunc func(done, total int, chunk Chunk)
+
+// Option is an option of Reverse.
+type Option func(*options)
+
+// options are the options of Reverse.
+type options struct {
+	progress   ProgressFunc
+	interval   int // grapheme clusters between the progress reports
+	checkEvery int // grapheme clusters between the cancellation checks. 0 is CheckInterval
+	workers    int // max goroutines reversing the parts of the text. 0 or 1 reverses it whole
+}
+
+// WithProgress reports the progress of the reversal to progress every interval
+// grapheme clusters and once done. It takes an extra pass over the text to
+// count the clusters, so give it only if the progress is needed. A nil
+// progress reports nothing, and an interval of zero or less reports only once
+// done.
+func WithProgress(progress ProgressFunc, interval int) Option {
+	return func(opts *options) {
+		opts.progress = progress
+		opts.interval = interval
+	}
+}
+
+// WithCheckInterval checks the cancellation of the context every interval
+// grapheme clusters instead of every CheckInterval ones. A lower interval
+// stops sooner once canceled, at the cost of the checks. An interval of zero or
+// less keeps CheckInterval.
+func WithCheckInterval(interval int) Option {
+	return func(opts *options) {
+		opts.checkEvery = interval
+	}
+}
+
+// Reverse returns the text reversed by grapheme clusters.
+//
+// The text is processed in chunks of CheckInterval clusters, or as given by
+// WithCheckInterval, and it stops as soon as ctx is canceled with an error
+// wrapping ctx.Err(), so that a canceled request on a huge input does not keep
+// the CPU busy.
+//
+// The clusters are written from the end of a single buffer the size of the
+// text, which is returned as is, so that the peak memory is about twice the
+// size of the text.
+func Reverse(ctx context.Context, text string, opts ...Option) (string, error) {
+	if text == "" {
+		return "", reverse(ctx, nil, text, opts)
+	}
+
+	out := make([]byte, len(text))
+
+	err := reverse(ctx, out, text, opts)
+	if err != nil {
+		return "", err
+	}
+
+	// The buffer is not written to once reversed, nor exposed but as the string.
+	return unsafe.String(&out[0], len(out)), nil //nolint:gosec // read-only buffer owned by the string
+}
+
+// AppendReverse appends the text reversed by grapheme clusters to dst and
+// returns the extended buffer, as Reverse does for strings. Giving dst enough
+// capacity, such as that of the text, avoids any other allocation. The text
+// must not be modified until it returns.
+//
+// The chunks reported by WithProgress have their offset in the reversed text,
+// not in dst.
+func AppendReverse(ctx context.Context, dst, text []byte, opts ...Option) ([]byte, error) {
+	dst = slices.Grow(dst, len(text))
+	out := dst[len(dst) : len(dst)+len(text)]
+
+	// The text is only read, and not retained past the call.
+	err := reverse(ctx, out, unsafe.String(unsafe.SliceData(text), len(text)), opts) //nolint:gosec // read-only view
+	if err != nil {
+		return dst, err
+	}
+
+	return dst[:len(dst)+len(text)], nil
+}
+
+// applyOptions returns the options applied in order. It is called only if any,
+// since the options escape to the heap.
+func applyOptions(opts []Option) options {
+	config := new(options)
+
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return *config
+}
+
+// reverse writes the text reversed by grapheme clusters into out, which is the
+// same size as the text, as per the options.
+func reverse(ctx context.Context, out []byte, text string, opts []Option) error {
+	var config options
+
+	if len(opts) > 0 {
+		config = applyOptions(opts)
+	}
+
+	if parts := config.split(text); len(parts) > 1 {
+		return reverseParts(ctx, out, text, parts, config)
+	}
+
+	return reverseInto(ctx, out, text, config)
+}
+
+// reverseInto writes the text reversed by grapheme clusters into out, which is
+// the same size as the text, from its end.
+//
+// The pure ASCII texts, unless the progress is reported, take the fast path of
+// reverseASCII.
+func reverseInto(ctx context.Context, out []byte, text string, config options) error {
+	report := config.progress
+
+	checkEvery := CheckInterval
+	if config.checkEvery > 0 {
+		checkEvery = config.checkEvery
+	}
+
+	if report == nil && isASCII(text) {
+		return reverseASCII(ctx, out, text, checkEvery)
+	}
+
+	total := 0
+	if report != nil {
+		total = uniseg.GraphemeClusterCount(text)
+	}
+
+	end := len(out)
+	reported := end // start of the output reported so far
+	done := 0
+	state := -1
+
+	var cluster string
+
+	for rest := text; rest != ""; {
+		cluster, rest, _, state = uniseg.FirstGraphemeClusterInString(rest, state)
+		end -= copy(out[end-len(cluster):], cluster)
+		done++
+
+		if done%checkEvery == 0 {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("request canceled after %d graphemes: %w", done, ctx.Err())
+			default:
+			}
+		}
+
+		if report != nil && config.interval > 0 && done%config.interval == 0 && done < total {
+			report(done, total, Chunk{Offset: end, Data: out[end:reported]})
+			reported = end
+		}
+	}
+
+	if report != nil {
+		report(total, total, Chunk{Offset: end, Data: out[end:reported]})
+	}
+
+	return nil
+}
+
+// isASCII reports whether the text has no multi-byte sequence.
+func isASCII(text string) bool {
+	for i := range len(text) {
+		if text[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+
+	return true
+}
+
+// reverseASCII is reverseInto for the pure ASCII texts, reversed byte by byte
+// in blocks of checkEvery bytes. The only grapheme cluster of several ASCII
+// characters is CR LF, reversed as LF CR and swapped back.
+func reverseASCII(ctx context.Context, out []byte, text string, checkEvery int) error {
+	done := 0
+
+	for start := 0; start < len(text); {
+		stop := min(start+checkEvery, len(text))
+		if stop < len(text) && text[stop-1] == '\r' && text[stop] == '\n' {
+			stop++ // keep CR LF in one block
+		}
+
+		block := out[len(out)-stop : len(out)-start]
+		for i, j := start, len(block)-1; j >= 0; i, j = i+1, j-1 {
+			block[j] = text[i]
+		}
+
+		done += stop - start
+
+		// LF CR can only come from CR LF once reversed, and never overlap.
+		if strings.IndexByte(text[start:stop], '\r') >= 0 {
+			for j := 0; j+1 < len(block); j++ {
+				if block[j] == '\n' && block[j+1] == '\r' {
+					block[j], block[j+1] = '\r', '\n'
+					done--
+					j++
+				}
+			}
+		}
+
+		full := stop-start >= checkEvery // checked every checkEvery clusters as reverseInto
+		start = stop
+
+		if full {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("request canceled after %d graphemes: %w", done, ctx.Err())
+			default:
+			}
+		}
+	}
+
+	return nil
+}
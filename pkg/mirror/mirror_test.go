@@ -0,0 +1,364 @@
+package mirror_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/KEINOS/mcp-text-mirror/pkg/mirror"
+	"github.com/rivo/uniseg"
+	"github.com/stretchr/testify/require"
+)
+
+// progressInterval is the interval of the progress reports in the tests.
+const progressInterval = 4 * mirror.CheckInterval
+
+// ----------------------------------------------------------------------------
+//  Reverse
+// ----------------------------------------------------------------------------
+
+func TestReverse(t *testing.T) {
+	t.Parallel()
+
+	for index, test := range []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"empty", "", ""},
+		{"ascii", "Hello, World!", "!dlroW ,olleH"},
+		{"cjk", "こんにちは世界", "界世はちにんこ"},
+		{"combining_marks", "café", "éfac"},
+		{"emoji_modifier", "a👍🏽b", "b👍🏽a"},
+		{"zwj_sequence", "x👨‍👩‍👧y", "y👨‍👩‍👧x"},
+		{"flags", "🇯🇵🇺🇸", "🇺🇸🇯🇵"},
+		{"crlf", "a\r\nb", "b\r\na"},
+		{"invalid_utf8", "a\xffb", "b\xffa"},
+	} {
+		t.Run(fmt.Sprintf("Test #%d: %s", index+1, test.name), func(t *testing.T) {
+			t.Parallel()
+
+			got, err := mirror.Reverse(context.Background(), test.input)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, got)
+			require.Equal(t, uniseg.ReverseString(test.input), got)
+
+			// With progress
+			var calls [][2]int
+
+			got, err = mirror.Reverse(context.Background(), test.input,
+				mirror.WithProgress(func(done, total int, _ mirror.Chunk) {
+					calls = append(calls, [2]int{done, total})
+				}, progressInterval))
+			require.NoError(t, err)
+			require.Equal(t, test.expected, got)
+			require.NotEmpty(t, calls, "progress should be reported at least once done")
+
+			total := uniseg.GraphemeClusterCount(test.input)
+			require.Equal(t, [2]int{total, total}, calls[len(calls)-1])
+		})
+	}
+}
+
+func TestReverse_progress_interval(t *testing.T) {
+	t.Parallel()
+
+	input := strings.Repeat("👍🏽", progressInterval*2+1)
+
+	var done []int
+
+	_, err := mirror.Reverse(context.Background(), input, mirror.WithProgress(func(processed, total int, _ mirror.Chunk) {
+		require.Equal(t, progressInterval*2+1, total)
+
+		done = append(done, processed)
+	}, progressInterval))
+	require.NoError(t, err)
+	require.Equal(t, []int{progressInterval, progressInterval * 2, progressInterval*2 + 1}, done)
+
+	// Only once done without interval
+	done = nil
+
+	_, err = mirror.Reverse(context.Background(), input, mirror.WithProgress(func(processed, _ int, _ mirror.Chunk) {
+		done = append(done, processed)
+	}, 0))
+	require.NoError(t, err)
+	require.Equal(t, []int{progressInterval*2 + 1}, done)
+
+	// Nil progress
+	got, err := mirror.Reverse(context.Background(), "abc", mirror.WithProgress(nil, progressInterval))
+	require.NoError(t, err)
+	require.Equal(t, "cba", got)
+}
+
+func TestReverse_canceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	input := strings.Repeat("a", progressInterval*4)
+
+	var done []int
+
+	// Cancel in the middle of the reversal
+	_, err := mirror.Reverse(ctx, input, mirror.WithProgress(func(processed, _ int, _ mirror.Chunk) {
+		done = append(done, processed)
+
+		cancel()
+	}, progressInterval))
+	require.ErrorIs(t, err, context.Canceled)
+	require.ErrorContains(t, err,
+		fmt.Sprintf("request canceled after %d graphemes", progressInterval+mirror.CheckInterval))
+	require.Equal(t, []int{progressInterval}, done, "should stop at the next check once canceled")
+}
+
+func TestReverse_check_interval(t *testing.T) {
+	t.Parallel()
+
+	input := strings.Repeat("👍🏽", 100)
+
+	for index, test := range []struct {
+		name     string
+		interval int
+		wantErr  string
+	}{
+		{"every_cluster", 1, "request canceled after 1 graphemes"},
+		{"every_ten", 10, "request canceled after 10 graphemes"},
+		{"above_the_input", 1000, ""},
+		{"zero_keeps_the_default", 0, ""},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		got, err := mirror.Reverse(ctx, input, mirror.WithCheckInterval(test.interval))
+		if test.wantErr == "" {
+			require.NoError(t, err, name) // no check before the end
+			require.Equal(t, uniseg.ReverseString(input), got, name)
+
+			continue
+		}
+
+		require.ErrorIs(t, err, context.Canceled, name)
+		require.EqualError(t, err, test.wantErr+": context canceled", name)
+	}
+}
+
+func TestReverse_chunks(t *testing.T) {
+	t.Parallel()
+
+	input := strings.Repeat("👍🏽á", progressInterval*3/2) // 3 chunks of graphemes
+	assembled := make([]byte, len(input))
+	covered := 0
+
+	got, err := mirror.Reverse(context.Background(), input, mirror.WithProgress(func(_, _ int, chunk mirror.Chunk) {
+		covered += copy(assembled[chunk.Offset:], chunk.Data)
+	}, progressInterval))
+	require.NoError(t, err)
+	require.Equal(t, len(input), covered, "chunks should cover the whole output once")
+	require.Equal(t, got, string(assembled))
+}
+
+//nolint:paralleltest // AllocsPerRun panics in parallel tests
+func TestReverse_allocations(t *testing.T) {
+	input := strings.Repeat("👍🏽á", progressInterval)
+
+	allocs := testing.AllocsPerRun(10, func() {
+		_, _ = mirror.Reverse(context.Background(), input)
+	})
+	require.Equal(t, 1.0, allocs, "the output buffer should be the only allocation")
+
+	// None with enough capacity
+	text := []byte(input)
+	dst := make([]byte, 0, len(text))
+
+	allocs = testing.AllocsPerRun(10, func() {
+		_, _ = mirror.AppendReverse(context.Background(), dst, text)
+	})
+	require.Zero(t, allocs)
+}
+
+func TestReverse_ascii(t *testing.T) {
+	t.Parallel()
+
+	for index, input := range []string{
+		"a\r\nb",
+		"\r\n\r\n",
+		"\r\r\n",
+		"\r\n\r",
+		"\n\r\n",
+		"\n\r",
+		"\r",
+		"a\tb\x00c\x7f",
+		strings.Repeat("ab\r\n", 1000),
+		strings.Repeat("a\r", 1000) + "\n",
+	} {
+		name := fmt.Sprintf("Test #%d: %q", index+1, input)
+		want := uniseg.ReverseString(input)
+
+		// Every block size, so that CR LF falls at the end of the blocks too.
+		for _, interval := range []int{0, 1, 2, 3, 7} {
+			got, err := mirror.Reverse(context.Background(), input, mirror.WithCheckInterval(interval))
+			require.NoError(t, err, name)
+			require.Equal(t, want, got, name)
+		}
+	}
+}
+
+func FuzzReverse(f *testing.F) {
+	for _, seed := range []string{"", "abc", "a\r\nb", "\r\r\n\n\r", "a👍🏽b", "🇯🇵🇺🇸", "café"} {
+		f.Add(seed, 3)
+	}
+
+	f.Fuzz(func(t *testing.T, input string, interval int) {
+		got, err := mirror.Reverse(context.Background(), input, mirror.WithCheckInterval(interval%16))
+		require.NoError(t, err)
+		require.Equal(t, uniseg.ReverseString(input), got)
+	})
+}
+
+// ----------------------------------------------------------------------------
+//  AppendReverse
+// ----------------------------------------------------------------------------
+
+func TestAppendReverse(t *testing.T) {
+	t.Parallel()
+
+	for index, test := range []struct {
+		name  string
+		dst   []byte
+		input string
+		want  string
+	}{
+		{"nil_dst", nil, "a👍🏽b", "b👍🏽a"},
+		{"prefix", []byte("> "), "x👨‍👩‍👧y", "> y👨‍👩‍👧x"},
+		{"empty_text", []byte("> "), "", "> "},
+		{"empty", nil, "", ""},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		got, err := mirror.AppendReverse(context.Background(), test.dst, []byte(test.input))
+		require.NoError(t, err, name)
+		require.Equal(t, test.want, string(got), name)
+	}
+}
+
+func TestAppendReverse_canceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	got, err := mirror.AppendReverse(ctx, []byte("> "), []byte(strings.Repeat("a", mirror.CheckInterval)))
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, "> ", string(got), "dst should be returned as given on error")
+}
+
+// ----------------------------------------------------------------------------
+//  Examples
+// ----------------------------------------------------------------------------
+
+func ExampleReverse() {
+	mirrored, err := mirror.Reverse(context.Background(), "Hello, 世界👋🏽")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(mirrored)
+	// Output: 👋🏽界世 ,olleH
+}
+
+func ExampleAppendReverse() {
+	text := []byte("Hello, 世界👋🏽")
+
+	mirrored, err := mirror.AppendReverse(context.Background(), make([]byte, 0, len(text)), text)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(string(mirrored))
+	// Output: 👋🏽界世 ,olleH
+}
+
+func ExampleWithCheckInterval() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := mirror.Reverse(ctx, "Hello, 世界", mirror.WithCheckInterval(1))
+	fmt.Println(err)
+	// Output: request canceled after 1 graphemes: context canceled
+}
+
+func ExampleWithProgress() {
+	text := strings.Repeat("abc", 10000)
+
+	_, err := mirror.Reverse(context.Background(), text, mirror.WithProgress(func(done, total int, _ mirror.Chunk) {
+		fmt.Printf("%d/%d\n", done, total)
+	}, 10000))
+	if err != nil {
+		panic(err)
+	}
+	// Output:
+	// 10000/30000
+	// 20000/30000
+	// 30000/30000
+}
+
+// ----------------------------------------------------------------------------
+//  Benchmarks
+// ----------------------------------------------------------------------------
+
+// benchmarkInputs are the inputs of the benchmarks, of about 64 KiB each.
+//
+//nolint:gochecknoglobals // read-only table
+var benchmarkInputs = []struct {
+	name string
+	text string
+}{
+	{"ascii", strings.Repeat("Hello, World! ", 64*1024/14)},
+	{"ascii_crlf", strings.Repeat("Hello, World!\r\n", 64*1024/15)},
+	{"mostly_ascii", strings.Repeat("Hello, World! ", 64*1024/14) + "👋🏽"},
+	{"emoji", strings.Repeat("👍🏽👨‍👩‍👧🇯🇵", 64*1024/37)},
+}
+
+func BenchmarkReverse(b *testing.B) {
+	for _, input := range benchmarkInputs {
+		b.Run(input.name, func(b *testing.B) {
+			b.SetBytes(int64(len(input.text)))
+			b.ReportAllocs()
+
+			for b.Loop() {
+				_, _ = mirror.Reverse(context.Background(), input.text)
+			}
+		})
+	}
+}
+
+func BenchmarkAppendReverse(b *testing.B) {
+	for _, input := range benchmarkInputs {
+		b.Run(input.name, func(b *testing.B) {
+			text := []byte(input.text)
+			dst := make([]byte, 0, len(text))
+
+			b.SetBytes(int64(len(text)))
+			b.ReportAllocs()
+
+			for b.Loop() {
+				_, _ = mirror.AppendReverse(context.Background(), dst, text)
+			}
+		})
+	}
+}
+
+func BenchmarkReverseString_uniseg(b *testing.B) {
+	for _, input := range benchmarkInputs {
+		b.Run(input.name, func(b *testing.B) {
+			b.SetBytes(int64(len(input.text)))
+			b.ReportAllocs()
+
+			for b.Loop() {
+				_ = uniseg.ReverseString(input.text)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package mirror
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// ParallelMinBytes is the min size in bytes of the texts reversed in parts
+// concurrently with WithParallelism. Smaller ones are not worth the goroutines.
+const ParallelMinBytes = 256 * 1024
+
+// partMinBytes is the min size in bytes of the parts, so that the number of
+// goroutines stays bounded whatever the number of workers.
+const partMinBytes = 64 * 1024
+
+// WithParallelism reverses the texts of at least ParallelMinBytes with line
+// breaks in up to workers parts concurrently, such as runtime.GOMAXPROCS(0).
+//
+// The parts end at line breaks, which are grapheme cluster boundaries, so that
+// the parts reversed and reassembled in the reverse order are the same as the
+// whole text reversed. The texts without line breaks are reversed whole.
+//
+// It is ignored along with WithProgress, whose reports follow the order of
+// the clusters.
+func WithParallelism(workers int) Option {
+	return func(opts *options) {
+		opts.workers = workers
+	}
+}
+
+// part is a byte range of the text, ending at a line break or at its end.
+type part struct {
+	start, end int
+}
+
+// split returns the parts of the text to reverse concurrently as per the
+// options, or nil to reverse it whole.
+func (o options) split(text string) []part {
+	if o.workers <= 1 || o.progress != nil || len(text) < ParallelMinBytes {
+		return nil
+	}
+
+	size := max(len(text)/o.workers, partMinBytes)
+	parts := make([]part, 0, len(text)/size+1)
+
+	for start := 0; start < len(text); {
+		end := min(start+size, len(text))
+
+		// Extend the part up to the next line break, included.
+		if lineBreak := strings.IndexByte(text[end-1:], '\n'); lineBreak >= 0 {
+			end += lineBreak
+		} else {
+			end = len(text)
+		}
+
+		parts = append(parts, part{start: start, end: end})
+		start = end
+	}
+
+	return parts
+}
+
+// reverseParts writes the text reversed into out, each part concurrently to
+// its place in the output: the part at [start, end) of the text goes to
+// [len-end, len-start) of the output. It returns the first error of the parts.
+func reverseParts(ctx context.Context, out []byte, text string, parts []part, config options) error {
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+
+	for _, p := range parts {
+		wg.Go(func() {
+			err := reverseInto(ctx, out[len(text)-p.end:len(text)-p.start], text[p.start:p.end], config)
+			if err != nil {
+				once.Do(func() { firstErr = err })
+			}
+		})
+	}
+
+	wg.Wait()
+
+	return firstErr
+}
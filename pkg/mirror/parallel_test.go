@@ -0,0 +1,105 @@
+package mirror_test
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/KEINOS/mcp-text-mirror/pkg/mirror"
+	"github.com/rivo/uniseg"
+	"github.com/stretchr/testify/require"
+)
+
+// multiLine returns the lines repeated up to at least size bytes.
+func multiLine(size int, lines ...string) string {
+	line := strings.Join(lines, "")
+
+	return strings.Repeat(line, size/len(line)+1)
+}
+
+// ----------------------------------------------------------------------------
+//  WithParallelism
+// ----------------------------------------------------------------------------
+
+func TestReverse_parallelism(t *testing.T) {
+	t.Parallel()
+
+	for index, test := range []struct {
+		name    string
+		input   string
+		workers int
+	}{
+		{"lf", multiLine(mirror.ParallelMinBytes, "Hello, 世界\n", "a👍🏽b\n"), 4},
+		{"crlf", multiLine(mirror.ParallelMinBytes, "🇯🇵🇺🇸\r\n", "x👨‍👩‍👧y\r\n"), 4},
+		{"combining_marks_at_line_start", multiLine(mirror.ParallelMinBytes, "cafe\ńb\n"), 3},
+		{"no_trailing_line_break", multiLine(mirror.ParallelMinBytes, "abc\n") + "def", 8},
+		{"single_line", strings.Repeat("👍🏽", mirror.ParallelMinBytes), 4},
+		{"long_lines", multiLine(mirror.ParallelMinBytes*2, strings.Repeat("é", 100*1024), "\n"), 16},
+		{"more_workers_than_lines", multiLine(mirror.ParallelMinBytes, strings.Repeat("a", 1000), "\n"), 1 << 20},
+		{"below_the_min_size", "a\nb\nc", 4},
+		{"one_worker", multiLine(mirror.ParallelMinBytes, "ab\n"), 1},
+	} {
+		t.Run(fmt.Sprintf("Test #%d: %s", index+1, test.name), func(t *testing.T) {
+			t.Parallel()
+
+			want := uniseg.ReverseString(test.input)
+
+			got, err := mirror.Reverse(context.Background(), test.input, mirror.WithParallelism(test.workers))
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+
+			appended, err := mirror.AppendReverse(context.Background(), []byte("> "), []byte(test.input),
+				mirror.WithParallelism(test.workers))
+			require.NoError(t, err)
+			require.Equal(t, "> "+want, string(appended))
+		})
+	}
+}
+
+func TestReverse_parallelism_with_progress(t *testing.T) {
+	t.Parallel()
+
+	input := multiLine(mirror.ParallelMinBytes, "ab\n")
+	covered := 0
+
+	got, err := mirror.Reverse(context.Background(), input, mirror.WithParallelism(4),
+		mirror.WithProgress(func(_, _ int, chunk mirror.Chunk) {
+			covered += len(chunk.Data) // not concurrent since the parallelism is ignored
+		}, progressInterval))
+	require.NoError(t, err)
+	require.Equal(t, uniseg.ReverseString(input), got)
+	require.Equal(t, len(input), covered)
+}
+
+func TestReverse_parallelism_canceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := mirror.Reverse(ctx, multiLine(mirror.ParallelMinBytes, "ab\n"),
+		mirror.WithParallelism(4), mirror.WithCheckInterval(1))
+	require.ErrorIs(t, err, context.Canceled)
+	require.ErrorContains(t, err, "request canceled after 1 graphemes")
+}
+
+// ----------------------------------------------------------------------------
+//  Benchmarks
+// ----------------------------------------------------------------------------
+
+func BenchmarkReverse_parallelism(b *testing.B) {
+	input := multiLine(4*1024*1024, "The quick brown fox jumps over the lazy dog. 👍🏽🇯🇵\n")
+
+	for _, workers := range []int{1, 4, runtime.GOMAXPROCS(0)} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			b.SetBytes(int64(len(input)))
+			b.ReportAllocs()
+
+			for b.Loop() {
+				_, _ = mirror.Reverse(context.Background(), input, mirror.WithParallelism(workers))
+			}
+		})
+	}
+}
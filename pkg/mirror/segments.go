@@ -0,0 +1,118 @@
+package mirror
+
+import (
+	"io"
+	"iter"
+	"slices"
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+)
+
+// segmentsBufferSize is the initial size in bytes of the buffer of Segments,
+// which grows only for the grapheme clusters longer than it.
+const segmentsBufferSize = 32 * 1024
+
+// Cluster is a grapheme cluster yielded by Segments.
+type Cluster struct {
+	Text   string // the bytes of the cluster
+	Offset int64  // byte offset of the cluster in the input
+	Width  int    // monospace display width of the cluster, as uniseg.StringWidth
+	Err    error  // error of the reader ending the sequence, along with an empty Text
+}
+
+// Segments returns the grapheme clusters read from r in the order of the
+// input, without loading the whole input, as the foundation of the streaming
+// tools:
+//
+//	for cluster := range mirror.Segments(os.Stdin) {
+//		if cluster.Err != nil {
+//			return cluster.Err
+//		}
+//		...
+//	}
+//
+// Only the last cluster read so far is held back, since the next bytes may
+// still belong to it, as well as an incomplete UTF-8 sequence at the end of
+// the read. The clusters are the same as uniseg.Step finds in the whole input.
+//
+// A read error other than io.EOF ends the sequence with a Cluster of the error
+// at the offset of the first byte not yielded. The sequence can be iterated
+// once only, as it consumes r.
+func Segments(r io.Reader) iter.Seq[Cluster] {
+	return func(yield func(Cluster) bool) {
+		buf := make([]byte, 0, segmentsBufferSize)
+		state := -1
+		fresh := 0 // bytes read since the last segmentation
+
+		var offset int64
+
+		for {
+			if len(buf) == cap(buf) {
+				buf = slices.Grow(buf, cap(buf)) // a cluster longer than the buffer
+			}
+
+			read, err := r.Read(buf[len(buf):cap(buf)])
+			buf = buf[:len(buf)+read]
+			eof := err == io.EOF //nolint:errorlint // io.Reader returns io.EOF itself per contract
+
+			// Segment again once as many bytes as held back were read, so that
+			// the long clusters read in small parts take linear time.
+			fresh += read
+			if err == nil && fresh < len(buf)-fresh {
+				continue
+			}
+
+			fresh = 0
+
+			complete := len(buf)
+			if !eof {
+				complete -= incompleteSuffix(buf)
+			}
+
+			used := 0
+
+			for used < complete {
+				cluster, next, boundaries, newState := uniseg.Step(buf[used:complete], state)
+				if len(next) == 0 && !eof {
+					break // the next bytes may still belong to the cluster
+				}
+
+				if !yield(Cluster{Text: string(cluster), Offset: offset, Width: boundaries >> uniseg.ShiftWidth}) {
+					return
+				}
+
+				offset += int64(len(cluster))
+				used += len(cluster)
+				state = newState
+			}
+
+			buf = buf[:copy(buf, buf[used:])]
+
+			switch {
+			case eof:
+				return
+			case err != nil:
+				yield(Cluster{Offset: offset, Err: err})
+
+				return
+			}
+		}
+	}
+}
+
+// incompleteSuffix returns the number of bytes at the end of p which start a
+// UTF-8 sequence without completing it, to be read again with the next bytes.
+func incompleteSuffix(p []byte) int {
+	for size := 1; size < utf8.UTFMax && size <= len(p); size++ {
+		if utf8.RuneStart(p[len(p)-size]) {
+			if utf8.FullRune(p[len(p)-size:]) {
+				return 0
+			}
+
+			return size
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,133 @@
+package mirror_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/KEINOS/mcp-text-mirror/pkg/mirror"
+	"github.com/rivo/uniseg"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  Segments
+// ----------------------------------------------------------------------------
+
+// wantClusters returns the clusters uniseg finds in the whole text.
+func wantClusters(text string) []mirror.Cluster {
+	var (
+		clusters []mirror.Cluster
+		offset   int64
+	)
+
+	state := -1
+
+	for text != "" {
+		var (
+			cluster    string
+			boundaries int
+		)
+
+		cluster, text, boundaries, state = uniseg.StepString(text, state)
+		clusters = append(clusters, mirror.Cluster{Text: cluster, Offset: offset, Width: boundaries >> uniseg.ShiftWidth})
+		offset += int64(len(cluster))
+	}
+
+	return clusters
+}
+
+func TestSegments(t *testing.T) {
+	t.Parallel()
+
+	for index, test := range []struct {
+		name  string
+		input string
+	}{
+		{"empty", ""},
+		{"ascii", "Hello, World!"},
+		{"cjk", "こんにちは世界"},
+		{"combining_marks", "café"},
+		{"emoji_modifier", "a👍🏽b"},
+		{"zwj_sequence", "x👨‍👩‍👧y"},
+		{"flags", "🇯🇵🇺🇸🇫🇷"},
+		{"crlf", "a\r\nb\r\n"},
+		{"invalid_utf8", "a\xffb\xe4\xb8"},
+		{"long_cluster", "e" + strings.Repeat("́", 20000)}, // longer than the buffer
+	} {
+		t.Run(fmt.Sprintf("Test #%d: %s", index+1, test.name), func(t *testing.T) {
+			t.Parallel()
+
+			want := wantClusters(test.input)
+
+			// Read at once, and byte by byte to split the clusters and the UTF-8
+			// sequences between the reads
+			for _, reader := range []io.Reader{
+				strings.NewReader(test.input),
+				iotest.OneByteReader(strings.NewReader(test.input)),
+				iotest.DataErrReader(strings.NewReader(test.input)),
+			} {
+				var got []mirror.Cluster
+
+				for cluster := range mirror.Segments(reader) {
+					got = append(got, cluster)
+				}
+
+				require.Equal(t, want, got)
+			}
+		})
+	}
+}
+
+func TestSegments_error(t *testing.T) {
+	t.Parallel()
+
+	errRead := errors.New("read failed")
+	reader := io.MultiReader(strings.NewReader("ab👍🏽"), iotest.ErrReader(errRead))
+
+	var got []mirror.Cluster
+
+	for cluster := range mirror.Segments(reader) {
+		got = append(got, cluster)
+	}
+
+	// The last cluster is held back, as the next bytes could have extended it
+	require.Equal(t, []mirror.Cluster{
+		{Text: "a", Offset: 0, Width: 1},
+		{Text: "b", Offset: 1, Width: 1},
+		{Offset: 2, Err: errRead},
+	}, got)
+}
+
+func TestSegments_break(t *testing.T) {
+	t.Parallel()
+
+	reader := strings.NewReader("abc")
+
+	for cluster := range mirror.Segments(reader) {
+		require.Equal(t, "a", cluster.Text)
+
+		break
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  Examples
+// ----------------------------------------------------------------------------
+
+func ExampleSegments() {
+	for cluster := range mirror.Segments(strings.NewReader("a👍🏽世")) {
+		if cluster.Err != nil {
+			panic(cluster.Err)
+		}
+
+		fmt.Printf("%d %q %d\n", cluster.Offset, cluster.Text, cluster.Width)
+	}
+	// Output:
+	// 0 "a" 1
+	// 1 "👍🏽" 2
+	// 9 "世" 2
+}
@@ -0,0 +1,192 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Admin tool.
+const (
+	envNameAdmin         = envPrefix + "ADMIN"         // env var to enable the admin tool. e.g. true
+	envNameAdminClients  = envPrefix + "ADMIN_CLIENTS" // env var of the comma separated client identities allowed to use the admin tool
+	adminToolName        = "admin"
+	adminToolDescription = "Administers the text-mirror server at runtime: lists the tools and enables or " +
+		"disables them, changes the log level and reports the call statistics"
+
+	adminActionList    = "list"
+	adminActionEnable  = "enable"
+	adminActionDisable = "disable"
+	adminActionLog     = "log"
+	adminActionStats   = "stats"
+
+	logLevelOff = "off" // logging to the log file disabled, errors only
+)
+
+// Predefined errors of the admin tool.
+var (
+	errAdminAction    = errors.New("invalid admin action")
+	errAdminForbidden = errors.New("not allowed to use the admin tool")
+)
+
+// GetAdminEnabled returns whether the admin tool is enabled from
+// 'MCP_TEXT_MIRROR_ADMIN' environment variable. It is disabled by default.
+//
+// Enable it only if all the clients are trusted, since any of them can disable
+// the tools of the other clients.
+func GetAdminEnabled() (bool, error) {
+	return loadedSettings().adminEnabled()
+}
+
+// adminEnabled returns GetAdminEnabled of the settings.
+func (v *settingValues) adminEnabled() (bool, error) {
+	return v.envBool(envNameAdmin)
+}
+
+// GetAdminClients returns the client identities allowed to use the admin tool
+// over HTTP, from 'MCP_TEXT_MIRROR_ADMIN_CLIENTS' environment variable. The
+// identities are the common names of the mTLS client certificates, or
+// "anonymous" for the clients without one.
+//
+// If unset, no client can use the admin tool over HTTP, as the dev profile
+// enables it without the list. The client of the stdio transport, which
+// launched the server, is always allowed.
+func GetAdminClients() []string {
+	return splitList(settingValue(envNameAdminClients))
+}
+
+// AdminInput is the input for the admin tool.
+type AdminInput struct {
+	Action string `json:"action"          jsonschema:"The action to take: list, enable, disable, log or stats."`
+	Tool   string `json:"tool,omitempty"  jsonschema:"The name of the tool to enable or disable."`
+	Level  string `json:"level,omitempty" jsonschema:"The log level to set with the log action: debug, info, warn, error or off."`
+}
+
+// AdminOutput is the output from the admin tool.
+type AdminOutput struct {
+	Tools    []ToolState  `json:"tools"               jsonschema:"The state of the tools after the action."`
+	LogLevel string       `json:"log_level,omitempty" jsonschema:"The log level after the log action."`
+	Stats    *StatsReport `json:"stats,omitempty"     jsonschema:"The call statistics, with the stats action."`
+}
+
+// adminTool is the admin tool, added only if enabled per GetAdminEnabled.
+type adminTool struct{}
+
+// Name returns the name of the tool.
+func (adminTool) Name() string { return adminToolName }
+
+// Description returns the description of the tool.
+func (adminTool) Description() string { return adminToolDescription }
+
+// Schema returns the schema of the input of the tool. The output is inferred
+// from AdminOutput.
+func (adminTool) Schema() (*jsonschema.Schema, *jsonschema.Schema) { return adminInputSchema(), nil }
+
+// Annotations returns the hints of the tool, which changes the server but
+// nothing outside of it.
+func (adminTool) Annotations() *mcp.ToolAnnotations {
+	annotations := new(mcp.ToolAnnotations)
+	annotations.DestructiveHint = new(bool)
+	annotations.OpenWorldHint = new(bool)
+
+	return annotations
+}
+
+// Handler returns adminHandler of the tools and the statistics of the server.
+func (adminTool) Handler(state *serverState) ToolHandler {
+	return TypedHandler(adminHandler(state.tools, state.stats))
+}
+
+// gateEnabled returns GetAdminEnabled.
+func (adminTool) gateEnabled() (bool, error) { return GetAdminEnabled() }
+
+// adminHandler returns the handler of the admin tool administering the tools,
+// the log level and reporting the call statistics. The admin tool itself is not
+// in tools, so that it can't be disabled.
+func adminHandler(tools *toolSet, stats *callStats) mcp.ToolHandlerFor[AdminInput, AdminOutput] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, input AdminInput) (*mcp.CallToolResult, AdminOutput, error) {
+		err := checkAdminClient(req)
+		if err != nil {
+			return nil, AdminOutput{}, err
+		}
+
+		var output AdminOutput
+
+		switch input.Action {
+		case adminActionList:
+		case adminActionEnable, adminActionDisable:
+			if input.Tool == "" {
+				return nil, AdminOutput{}, wrapError(errAdminAction, "%s requires tool", input.Action)
+			}
+
+			err = tools.setEnabled(input.Tool, input.Action == adminActionEnable)
+		case adminActionLog:
+			output.LogLevel, err = setLogLevel(ctx, input.Level)
+		case adminActionStats:
+			output.Stats = stats.report()
+		default:
+			return nil, AdminOutput{}, wrapError(errAdminAction, "unknown action %q", input.Action)
+		}
+
+		if err != nil {
+			return nil, AdminOutput{}, err
+		}
+
+		switch input.Action {
+		case adminActionEnable, adminActionDisable:
+			infoLog(ctx, "admin: tool "+input.Action+"d", callLogAttrs(ctx, req, "target", input.Tool)...)
+		case adminActionLog:
+			infoLog(ctx, "admin: log level set", callLogAttrs(ctx, req, "level", output.LogLevel)...)
+		}
+
+		output.Tools = tools.states()
+
+		return nil, output, nil
+	}
+}
+
+// checkAdminClient returns errAdminForbidden unless the request came over
+// stdio or from a client listed in GetAdminClients. An empty list allows no
+// network client, like checkLogTailClient.
+func checkAdminClient(req *mcp.CallToolRequest) error {
+	clientID := clientIdentity(req)
+
+	if clientID == "" || slices.Contains(GetAdminClients(), clientID) {
+		return nil // stdio, or an allowed client
+	}
+
+	return fmt.Errorf("client %q is %w", clientID, errAdminForbidden)
+}
+
+// setLogLevel sets the log level at runtime and returns it. Logging without a
+// log file configured goes to the default one in the user's log directory. The
+// level off disables the log file, leaving only the errors on standard error.
+// The level overrides the settings as a flag would, until the restart. The
+// logger of ctx, the one of the server, is reopened at the level.
+func setLogLevel(ctx context.Context, level string) (string, error) {
+	switch _, ok := logLevels[level]; {
+	case ok:
+		storeSettings(func(v *settingValues) *settingValues {
+			overrides := map[string]string{envNameLogLevel: level}
+			if v.values[envNameDebug] == "" {
+				overrides[envNameDebug] = logName
+			}
+
+			return v.withFlags(overrides)
+		})
+	case level == logLevelOff:
+		storeSettings(func(v *settingValues) *settingValues {
+			return v.withFlags(map[string]string{envNameLogLevel: "", envNameDebug: ""})
+		})
+	default:
+		return "", wrapError(errAdminAction, "log requires level debug, info, warn, error or off, got %q", level)
+	}
+
+	reopenLog(loggerFrom(ctx))
+
+	return level, nil
+}
@@ -0,0 +1,224 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  GetAdminEnabled
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_newServer_admin_disabled_by_default(t *testing.T) {
+	setEnv(t, envNameAdmin, "")
+
+	session := connectInMemory(t, newServer())
+
+	for tool, err := range session.Tools(context.Background(), nil) {
+		require.NoError(t, err)
+		require.NotEqual(t, adminToolName, tool.Name, "admin tool should be disabled by default")
+	}
+}
+
+//nolint:paralleltest // sets env var
+func Test_run_invalid_admin(t *testing.T) {
+	setEnv(t, envNameAdmin, "maybe")
+
+	err := newApp().serve(context.Background(), nil)
+	require.ErrorIs(t, err, errInvalidBool)
+}
+
+// ----------------------------------------------------------------------------
+//  admin tool
+// ----------------------------------------------------------------------------
+
+// toolStates returns the tool states listed by the admin tool when the given
+// tools are disabled.
+func toolStates(disabled []string) []any {
+	states := make([]any, 0, len(builtinTools))
+	for _, name := range sortedNames(defaultTools()...) {
+		states = append(states, map[string]any{"name": name, "enabled": !slices.Contains(disabled, name)})
+	}
+
+	return states
+}
+
+//nolint:paralleltest // sets env var
+func Test_adminHandler(t *testing.T) {
+	setEnv(t, envNameAdmin, "true")
+
+	session := connectInMemory(t, newServer())
+
+	for index, test := range []struct {
+		name      string
+		args      map[string]any
+		want      []any
+		wantError string
+	}{
+		{
+			"list", map[string]any{"action": adminActionList},
+			toolStates(nil), "",
+		},
+		{
+			"disable", map[string]any{"action": adminActionDisable, "tool": batchToolName},
+			toolStates([]string{batchToolName}), "",
+		},
+		{
+			"enable", map[string]any{"action": adminActionEnable, "tool": batchToolName},
+			toolStates(nil), "",
+		},
+		{"missing_tool", map[string]any{"action": adminActionDisable}, nil, "disable requires tool"},
+		{"unknown_tool", map[string]any{"action": adminActionDisable, "tool": adminToolName}, nil, errUnknownTool.Error()},
+	} {
+		// Not parallel since the cases share the tool states
+		res := callTool(t, session, adminToolName, test.args)
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		if test.wantError != "" {
+			require.True(t, res.IsError, name)
+			require.Contains(t, res.Content[0].(*mcp.TextContent).Text, test.wantError, name) //nolint:forcetypeassert // text content
+
+			continue
+		}
+
+		require.False(t, res.IsError, name)
+		require.Equal(t, map[string]any{"tools": test.want}, res.StructuredContent, name)
+	}
+
+	// Unknown actions are rejected by the schema
+	params := new(mcp.CallToolParams)
+	params.Name = adminToolName
+	params.Arguments = map[string]any{"action": "reboot"}
+
+	_, err := session.CallTool(context.Background(), params)
+	require.Error(t, err)
+
+	// Or by the handler if called directly
+	_, _, err = adminHandler(newToolSet(newServer()), newCallStats())(context.Background(), nil, AdminInput{Action: "reboot"})
+	require.ErrorIs(t, err, errAdminAction)
+}
+
+//nolint:paralleltest // sets env var
+func Test_adminHandler_log(t *testing.T) {
+	unsetEnv(t, envNameDebug, envNameLogLevel, envNameAdminClients)
+	setEnv(t, envNameAdmin, "true")
+
+	dataHome := t.TempDir()
+	setEnv(t, envNameXDGDataHome, dataHome)
+	setEnv(t, envNameLocalAppData, dataHome)
+
+	session := connectInMemory(t, newServer(WithLogger(newLogger(false, ""))))
+
+	// Enable debug logging to the default log file at runtime
+	res := callTool(t, session, adminToolName, map[string]any{"action": adminActionLog, "level": logLevelDebug})
+	require.False(t, res.IsError)
+	require.Equal(t, logLevelDebug, res.StructuredContent.(map[string]any)["log_level"]) //nolint:forcetypeassert // object output
+	require.True(t, IsDebugMode())
+	require.FileExists(t, filepath.Join(dataHome, serviceName, logName))
+
+	// Other levels keep logging to the file
+	res = callTool(t, session, adminToolName, map[string]any{"action": adminActionLog, "level": logLevelWarn})
+	require.False(t, res.IsError)
+
+	level, err := GetLogLevel()
+	require.NoError(t, err)
+	require.Equal(t, slog.LevelWarn, level)
+	require.True(t, IsDebugMode())
+
+	res = callTool(t, session, adminToolName, map[string]any{"action": adminActionLog, "level": logLevelOff})
+	require.False(t, res.IsError)
+	require.False(t, IsDebugMode())
+
+	level, err = GetLogLevel()
+	require.NoError(t, err)
+	require.Equal(t, slog.LevelError, level, "only errors should be logged once off")
+
+	// Level is required
+	res = callTool(t, session, adminToolName, map[string]any{"action": adminActionLog})
+	require.True(t, res.IsError)
+}
+
+//nolint:paralleltest // sets env var
+func Test_adminHandler_stats(t *testing.T) {
+	unsetEnv(t, envNameAdminClients)
+	setEnv(t, envNameAdmin, "true")
+
+	session := connectInMemory(t, newServer())
+
+	callTool(t, session, toolName, map[string]any{"text": "abc"})
+
+	params := new(mcp.CallToolParams)
+	params.Name = toolName
+	params.Arguments = map[string]any{"text": "abc", "render": "gif"}
+
+	_, err := session.CallTool(context.Background(), params)
+	require.Error(t, err, "invalid arguments should fail")
+
+	res := callTool(t, session, adminToolName, map[string]any{"action": adminActionStats})
+	require.False(t, res.IsError)
+
+	stats, ok := res.StructuredContent.(map[string]any)["stats"].(map[string]any)
+	require.True(t, ok, "stats should be reported")
+	require.NotEmpty(t, stats["uptime"])
+	tools, ok := stats["tools"].([]any)
+	require.True(t, ok)
+	require.Len(t, tools, 1)
+
+	mirror, ok := tools[0].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, toolName, mirror["name"])
+	require.InDelta(t, 2, mirror["calls"], 0)
+	require.InDelta(t, 1, mirror["errors"], 0)
+	require.InDelta(t, len(`{"text":"abc"}`)+len(`{"render":"gif","text":"abc"}`), mirror["bytes"], 0)
+	require.Positive(t, mirror["p95_ms"])
+}
+
+//nolint:paralleltest // sets env var
+func Test_checkAdminClient(t *testing.T) {
+	request := func(clientID string) *mcp.CallToolRequest {
+		req := new(mcp.CallToolRequest)
+		if clientID != "" {
+			req.Extra = new(mcp.RequestExtra)
+			req.Extra.Header = http.Header{headerClientID: []string{clientID}}
+		}
+
+		return req
+	}
+
+	for index, test := range []struct {
+		name     string
+		allowed  string
+		clientID string
+		wantErr  bool
+	}{
+		{"no_client_allowed", "", "ops", true},
+		{"stdio_without_list", "", "", false},
+		{"allowed_client", "ops,dev", "dev", false},
+		{"stdio_always_allowed", "ops", "", false},
+		{"other_client", "ops", "dev", true},
+		{"anonymous", "ops", anonymousClient, true},
+		{"anonymous_allowed", "ops,anonymous", anonymousClient, false},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		setEnv(t, envNameAdminClients, test.allowed)
+
+		err := checkAdminClient(request(test.clientID))
+		if test.wantErr {
+			require.ErrorIs(t, err, errAdminForbidden, name)
+
+			continue
+		}
+
+		require.NoError(t, err, name)
+	}
+}
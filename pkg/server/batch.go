@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/KEINOS/mcp-text-mirror/pkg/mirror"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Batch mirror tool.
+const (
+	batchToolName        = "mirror_batch"
+	batchToolDescription = "Reverses each of the given UTF-8 texts in one call. The results are in the same order"
+	batchMaxItems        = 1000 // max texts per call
+)
+
+// MirrorBatchInput is the input for the mirror_batch tool.
+type MirrorBatchInput struct {
+	Texts []string `json:"texts" jsonschema:"The UTF-8 texts to mirror (reverse) by grapheme clusters."`
+}
+
+// MirrorBatchOutput is the output from the mirror_batch tool.
+type MirrorBatchOutput struct {
+	Texts []string `json:"texts" jsonschema:"The mirrored texts, in the same order as the input."`
+}
+
+// batchTool is the mirror_batch tool, the batch variant of the mirror tool.
+type batchTool struct{}
+
+// Name returns the name of the tool.
+func (batchTool) Name() string { return batchToolName }
+
+// Description returns the description of the tool.
+func (batchTool) Description() string { return batchToolDescription }
+
+// Schema returns the schemas of the input and the output of the tool.
+func (batchTool) Schema() (*jsonschema.Schema, *jsonschema.Schema) {
+	return mirrorBatchInputSchema(), mirrorBatchOutputSchema()
+}
+
+// Annotations returns the hints of the tool.
+func (batchTool) Annotations() *mcp.ToolAnnotations { return readOnlyAnnotations() }
+
+// Handler returns handleReverseBatch.
+func (batchTool) Handler(*serverState) ToolHandler { return TypedHandler(handleReverseBatch) }
+
+// cacheable returns true: the mirrored texts depend on the texts only.
+func (batchTool) cacheable(map[string]any) bool { return true }
+
+// handleReverseBatch returns the mirrored texts in the order of the input, which
+// saves the per-call overhead for agents processing lists.
+//
+// Like handleReverse, it stops once the request is canceled. Empty texts are
+// mirrored as is.
+func handleReverseBatch(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input MirrorBatchInput,
+) (*mcp.CallToolResult, MirrorBatchOutput, error) {
+	err := ctx.Err()
+	if err != nil {
+		return nil, MirrorBatchOutput{}, wrapError(err, "request canceled")
+	}
+
+	output := MirrorBatchOutput{Texts: make([]string, len(input.Texts))}
+	start, size := time.Now(), 0
+
+	for index, text := range input.Texts {
+		size += len(text)
+
+		err = checkTextLimit(req, text)
+		if err != nil {
+			return nil, MirrorBatchOutput{}, wrapError(err, "texts[%d]", index)
+		}
+
+		output.Texts[index], err = mirror.Reverse(ctx, text)
+		if err != nil {
+			return nil, MirrorBatchOutput{}, wrapError(err, "failed at texts[%d]", index)
+		}
+	}
+
+	callLog(ctx, "texts mirrored in batch", callLogAttrs(ctx, req, "texts", len(input.Texts), logKeyInputSize, size,
+		logKeyDuration, time.Since(start))...)
+
+	return nil, output, nil
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/KEINOS/mcp-text-mirror/pkg/mirror"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  handleReverseBatch
+// ----------------------------------------------------------------------------
+
+func Test_handleReverseBatch(t *testing.T) {
+	t.Parallel()
+
+	session := connectInMemory(t, newServer())
+
+	texts := make([]any, 0, len(dataToReverse))
+	want := make([]any, 0, len(dataToReverse))
+
+	for _, test := range dataToReverse {
+		texts = append(texts, test.input)
+		want = append(want, test.expected)
+	}
+
+	res := callTool(t, session, batchToolName, map[string]any{"texts": texts})
+	require.False(t, res.IsError, res.Content)
+	require.Equal(t, map[string]any{"texts": want}, res.StructuredContent,
+		"results should be in the order of the input")
+}
+
+func Test_handleReverseBatch_invalid(t *testing.T) {
+	t.Parallel()
+
+	session := connectInMemory(t, newServer())
+
+	for _, args := range []map[string]any{
+		{},                    // missing texts
+		{"texts": nil},        // null
+		{"texts": []any{}},    // empty
+		{"texts": []any{1}},   // not strings
+		{"texts": "not list"}, // not an array
+		{"texts": make([]any, batchMaxItems+1)},
+	} {
+		params := new(mcp.CallToolParams)
+		params.Name = batchToolName
+		params.Arguments = args
+
+		_, err := session.CallTool(context.Background(), params)
+		require.Error(t, err, "arguments %v should be rejected", args)
+	}
+}
+
+func Test_handleReverseBatch_canceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := handleReverseBatch(ctx, nil, MirrorBatchInput{Texts: []string{"abc"}})
+	require.ErrorIs(t, err, context.Canceled)
+
+	// Canceled in the middle of a text
+	_, _, err = handleReverseBatch(&cancelAfter{Context: context.Background(), calls: 1}, nil,
+		MirrorBatchInput{Texts: []string{"abc", strings.Repeat("a", mirror.CheckInterval)}})
+	require.ErrorIs(t, err, context.Canceled)
+	require.ErrorContains(t, err, "failed at texts[1]")
+}
+
+// cancelAfter is a context canceled once Err is called more than calls times.
+type cancelAfter struct {
+	context.Context //nolint:containedctx // wraps the parent context
+
+	calls int
+}
+
+// Err returns context.Canceled once called more than calls times.
+func (c *cancelAfter) Err() error {
+	if c.calls <= 0 {
+		return context.Canceled
+	}
+
+	c.calls--
+
+	return nil
+}
+
+// Done returns a closed channel once Err reports the cancellation.
+func (c *cancelAfter) Done() <-chan struct{} {
+	done := make(chan struct{})
+	if c.calls <= 0 {
+		close(done)
+	}
+
+	return done
+}
@@ -0,0 +1,206 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KEINOS/mcp-text-mirror/pkg/mirror"
+	"github.com/rivo/uniseg"
+)
+
+// Throughput benchmark of the reversal. E.g.: text-mirror bench --size 4MiB --scripts latin,emoji
+const (
+	cmdNameBench = "bench"
+
+	flagNameSize     = "size"     // size of the synthetic input
+	flagNameScripts  = "scripts"  // comma separated scripts of the synthetic input
+	flagNameDuration = "duration" // minimum duration of the measurement
+
+	benchSizeDefault     = 1 << 20 // 1 MiB
+	benchDurationDefault = time.Second
+	bytesPerMiB          = 1 << 20
+)
+
+// errBenchUsage is returned on invalid arguments of the bench subcommand.
+var errBenchUsage = errors.New("usage: text-mirror bench [--size bytes|KiB|MiB] [--scripts latin,...] [--duration 1s]")
+
+// benchScripts are the samples of the scripts the synthetic input is made of,
+// repeated in turn. Each sample ends at a grapheme boundary.
+//
+//nolint:gochecknoglobals // read-only table
+var benchScripts = []struct {
+	name   string
+	sample string
+}{
+	{"latin", "The quick brown fox jumps over the lazy dog. "},
+	{"cjk", "日本語の文章と中文的句子。"},
+	{"hangul", "다람쥐 헌 쳇바퀴에 타고파. "},
+	{"arabic", "نص حكيم له سر قاطع. "},
+	{"combining", "éöñå "},
+	{"emoji", "👨‍👩‍👧‍👦 👍🏽 🇯🇵 🏳️‍🌈 "},
+}
+
+// benchReport is the result of the benchmark.
+type benchReport struct {
+	bytes      int           // size of the input
+	graphemes  int           // grapheme clusters of the input
+	iterations int           // number of reversals
+	elapsed    time.Duration // total duration of the reversals
+	allocs     uint64        // total allocations
+	allocBytes uint64        // total allocated bytes
+}
+
+// runBench is the "bench" subcommand. It reverses a synthetic input of the
+// given size and script mix repeatedly for at least the given duration, and
+// prints the throughput and the allocations per reversal to out.
+func runBench(ctx context.Context, args []string, out io.Writer) error {
+	var (
+		sizeFlag = strconv.Itoa(benchSizeDefault)
+		scripts  string
+		duration time.Duration
+	)
+
+	flags := flag.NewFlagSet(cmdNameBench, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.StringVar(&sizeFlag, flagNameSize, sizeFlag, "size of the input")
+	flags.StringVar(&scripts, flagNameScripts, "", "comma separated scripts of the input")
+	flags.DurationVar(&duration, flagNameDuration, benchDurationDefault, "minimum duration of the measurement")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errBenchUsage, err)
+	}
+
+	if flags.NArg() > 0 {
+		return fmt.Errorf("%w: unexpected arguments %q", errBenchUsage, flags.Args())
+	}
+
+	size, err := parseByteSize(sizeFlag)
+	if err != nil {
+		return fmt.Errorf("%w: --%s: %w", errBenchUsage, flagNameSize, err)
+	}
+
+	switch {
+	case size <= 0:
+		return fmt.Errorf("%w: --%s must be positive, got %q", errBenchUsage, flagNameSize, sizeFlag)
+	case duration <= 0:
+		return fmt.Errorf("%w: --%s must be positive, got %s", errBenchUsage, flagNameDuration, duration)
+	}
+
+	input, names, err := benchInput(size, splitList(scripts))
+	if err != nil {
+		return err
+	}
+
+	report, err := measureReverse(ctx, input, duration)
+	if err != nil {
+		return err
+	}
+
+	report.print(out, names)
+
+	return nil
+}
+
+// benchInput returns the synthetic input of at least size bytes, made of the
+// samples of the named scripts in turn, or of all of them if none, and the
+// names of the scripts used.
+func benchInput(size int, names []string) (string, []string, error) {
+	samples := make(map[string]string, len(benchScripts))
+	known := make([]string, 0, len(benchScripts))
+
+	for _, script := range benchScripts {
+		samples[script.name] = script.sample
+		known = append(known, script.name)
+	}
+
+	if len(names) == 0 {
+		names = known
+	}
+
+	for _, name := range names {
+		if _, ok := samples[name]; !ok {
+			return "", nil, fmt.Errorf("%w: --%s must be of %s", errBenchUsage, flagNameScripts, strings.Join(known, ", "))
+		}
+	}
+
+	var input strings.Builder
+
+	input.Grow(size)
+
+	for index := 0; input.Len() < size; index++ {
+		input.WriteString(samples[names[index%len(names)]])
+	}
+
+	return input.String(), names, nil
+}
+
+// measureReverse reverses the input repeatedly for at least the duration, and
+// returns the measurement.
+func measureReverse(ctx context.Context, input string, duration time.Duration) (benchReport, error) {
+	report := benchReport{bytes: len(input), graphemes: uniseg.GraphemeClusterCount(input)}
+
+	var before, after runtime.MemStats
+
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	start := time.Now()
+
+	for report.elapsed < duration {
+		_, err := mirror.Reverse(ctx, input)
+		if err != nil {
+			return report, err
+		}
+
+		report.iterations++
+		report.elapsed = time.Since(start)
+	}
+
+	runtime.ReadMemStats(&after)
+
+	report.allocs = after.Mallocs - before.Mallocs
+	report.allocBytes = after.TotalAlloc - before.TotalAlloc
+
+	return report, nil
+}
+
+// print prints the report to w.
+func (r benchReport) print(w io.Writer, scripts []string) {
+	seconds := r.elapsed.Seconds()
+	iterations := uint64(r.iterations) //nolint:gosec // positive
+
+	_, _ = fmt.Fprintf(w, "input:      %d bytes, %d graphemes (%s)\n", r.bytes, r.graphemes, strings.Join(scripts, ", "))
+	_, _ = fmt.Fprintf(w, "iterations: %d in %s\n", r.iterations, r.elapsed.Round(time.Millisecond))
+	_, _ = fmt.Fprintf(w, "throughput: %.1f MiB/s, %.2f M graphemes/s\n",
+		float64(r.bytes)*float64(r.iterations)/bytesPerMiB/seconds,
+		float64(r.graphemes)*float64(r.iterations)/1e6/seconds)
+	_, _ = fmt.Fprintf(w, "per op:     %s, %d allocs, %d bytes\n",
+		(r.elapsed / time.Duration(r.iterations)).Round(time.Microsecond), r.allocs/iterations, r.allocBytes/iterations)
+}
+
+// parseByteSize parses the size in bytes, or with KiB or MiB suffix.
+func parseByteSize(value string) (int, error) {
+	multiplier := 1
+
+	switch {
+	case strings.HasSuffix(value, "MiB"):
+		multiplier, value = bytesPerMiB, strings.TrimSuffix(value, "MiB")
+	case strings.HasSuffix(value, "KiB"):
+		multiplier, value = 1<<10, strings.TrimSuffix(value, "KiB")
+	}
+
+	size, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, wrapError(err, "invalid size")
+	}
+
+	return size * multiplier, nil
+}
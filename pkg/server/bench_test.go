@@ -0,0 +1,108 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/rivo/uniseg"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  runBench
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // runs the command, which loads the settings
+func Test_runCommand_bench(t *testing.T) {
+	var out bytes.Buffer
+
+	app := newTestApp(t)
+	app.Stdout = &out
+
+	err := app.Run(context.Background(), []string{cmdNameBench, "--size", "4KiB", "--scripts", "emoji,cjk", "--duration", "10ms"})
+	require.NoError(t, err)
+
+	report := out.String()
+	require.Contains(t, report, "graphemes (emoji, cjk)\n")
+	require.Contains(t, report, "iterations: ")
+	require.Contains(t, report, "MiB/s")
+	require.Contains(t, report, "allocs")
+}
+
+func Test_runBench_invalid(t *testing.T) {
+	t.Parallel()
+
+	for index, args := range [][]string{
+		{"--size", "big"},
+		{"--size", "0"},
+		{"--duration", "-1s"},
+		{"--scripts", "klingon"},
+		{"--iterations", "3"},
+		{"extra"},
+	} {
+		name := fmt.Sprintf("Test #%d: %v", index+1, args)
+
+		err := runBench(context.Background(), args, new(bytes.Buffer))
+		require.ErrorIs(t, err, errBenchUsage, name)
+		require.NotContains(t, err.Error(), "%!", name)
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  benchInput
+// ----------------------------------------------------------------------------
+
+func Test_benchInput(t *testing.T) {
+	t.Parallel()
+
+	input, names, err := benchInput(1000, nil)
+	require.NoError(t, err)
+	require.Len(t, names, len(benchScripts), "all the scripts should be used by default")
+	require.GreaterOrEqual(t, len(input), 1000)
+
+	for _, script := range benchScripts {
+		require.Contains(t, input, script.sample)
+	}
+
+	// Reversing twice gives the input back, i.e. the samples are whole graphemes
+	reversed := uniseg.ReverseString(uniseg.ReverseString(input))
+	require.Equal(t, input, reversed)
+
+	input, _, err = benchInput(10, []string{"latin"})
+	require.NoError(t, err)
+	require.Equal(t, benchScripts[0].sample, input, "samples should not be cut")
+}
+
+// ----------------------------------------------------------------------------
+//  parseByteSize
+// ----------------------------------------------------------------------------
+
+func Test_parseByteSize(t *testing.T) {
+	t.Parallel()
+
+	for index, test := range []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{"1024", 1024, false},
+		{"64KiB", 64 * 1024, false},
+		{"2MiB", 2 * 1024 * 1024, false},
+		{"1GiB", 0, true},
+		{"", 0, true},
+	} {
+		name := fmt.Sprintf("Test #%d: %q", index+1, test.value)
+
+		got, err := parseByteSize(test.value)
+		if test.wantErr {
+			require.Error(t, err, name)
+
+			continue
+		}
+
+		require.NoError(t, err, name)
+		require.Equal(t, test.want, got, name)
+	}
+}
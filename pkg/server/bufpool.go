@@ -0,0 +1,74 @@
+package server
+
+import (
+	"bytes"
+	"sync"
+	"sync/atomic"
+)
+
+// bufferPoolMaxBytes is the max capacity of the buffers put back to the pool.
+// Larger ones, grown by the largest texts, are left to the GC so that a single
+// huge call does not pin its memory for the life of the process.
+const bufferPoolMaxBytes = 4 * 1024 * 1024
+
+// buffers are the scratch buffers reused between the tool calls, such as the
+// ones of the log entries and of the wire-tap frames, which hold the texts of
+// the calls.
+//
+//nolint:gochecknoglobals // shared by the log functions, which are global as well
+var buffers = new(bufferPool)
+
+// bufferPool is a sync.Pool of bytes.Buffer counting its use.
+type bufferPool struct {
+	pool    sync.Pool
+	gets    atomic.Uint64
+	news    atomic.Uint64
+	puts    atomic.Uint64
+	dropped atomic.Uint64
+}
+
+// BufferPoolStats are the counters of the pool of the scratch buffers since the
+// start. Gets minus News is the number of buffers reused.
+type BufferPoolStats struct {
+	Gets    uint64 `json:"gets"`    // buffers taken from the pool
+	News    uint64 `json:"news"`    // buffers allocated since the pool was empty
+	Puts    uint64 `json:"puts"`    // buffers put back to the pool
+	Dropped uint64 `json:"dropped"` // buffers left to the GC for exceeding 4 MiB
+}
+
+// get returns an empty buffer, reused if any.
+func (p *bufferPool) get() *bytes.Buffer {
+	p.gets.Add(1)
+
+	if buf, ok := p.pool.Get().(*bytes.Buffer); ok {
+		return buf
+	}
+
+	p.news.Add(1)
+
+	return new(bytes.Buffer)
+}
+
+// put resets the buffer and puts it back to the pool, unless larger than
+// bufferPoolMaxBytes. The buffer must not be used afterwards.
+func (p *bufferPool) put(buf *bytes.Buffer) {
+	if buf.Cap() > bufferPoolMaxBytes {
+		p.dropped.Add(1)
+
+		return
+	}
+
+	buf.Reset()
+	p.pool.Put(buf)
+	p.puts.Add(1)
+}
+
+// stats returns the counters of the pool.
+func (p *bufferPool) stats() BufferPoolStats {
+	return BufferPoolStats{
+		Gets:    p.gets.Load(),
+		News:    p.news.Load(),
+		Puts:    p.puts.Load(),
+		Dropped: p.dropped.Load(),
+	}
+}
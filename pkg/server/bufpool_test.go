@@ -0,0 +1,47 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  bufferPool
+// ----------------------------------------------------------------------------
+
+func Test_bufferPool(t *testing.T) {
+	t.Parallel()
+
+	pool := new(bufferPool)
+
+	buf := pool.get()
+	buf.WriteString("text of a call")
+	pool.put(buf)
+
+	// Empty once reused. sync.Pool may drop it though, such as on GC.
+	reused := pool.get()
+	require.Zero(t, reused.Len(), "buffers should be reset before reuse")
+	pool.put(reused)
+
+	// Too large to be kept
+	large := pool.get()
+	large.Grow(bufferPoolMaxBytes + 1)
+	pool.put(large)
+
+	stats := pool.stats()
+	require.Equal(t, uint64(3), stats.Gets)
+	require.GreaterOrEqual(t, stats.News, uint64(1))
+	require.LessOrEqual(t, stats.News, stats.Gets)
+	require.Equal(t, uint64(2), stats.Puts)
+	require.Equal(t, uint64(1), stats.Dropped)
+}
+
+func Test_logEntry_pooled_buffer(t *testing.T) {
+	t.Parallel()
+
+	before := buffers.stats()
+
+	require.Equal(t, "text mirrored text=abc", logEntry("text mirrored", logKeyText, "abc"))
+	require.Greater(t, buffers.stats().Puts, before.Puts, "the buffer of the entry should be put back")
+}
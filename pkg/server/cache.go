@@ -0,0 +1,316 @@
+package server
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"maps"
+	"strconv"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Result cache of the tool calls. E.g.: MCP_TEXT_MIRROR_CACHE_SIZE=1000
+const (
+	envNameCacheSize  = envPrefix + "CACHE_SIZE"  // env var of the max results cached. 0 disables the cache (default)
+	envNameCacheBytes = envPrefix + "CACHE_BYTES" // env var of the max total bytes of the results cached. 0 for no bound
+	cacheBytesDefault = 64 * 1024 * 1024          // default max total bytes of the results cached
+	metaKeyCached     = "text-mirror/cached"      // _meta key of the results served from the cache
+)
+
+// GetCacheSize returns the max number of tool results kept in the cache from
+// 'MCP_TEXT_MIRROR_CACHE_SIZE' environment variable. Zero, the default,
+// disables the cache.
+func GetCacheSize() (int, error) {
+	return loadedSettings().cacheSize()
+}
+
+// cacheSize returns GetCacheSize of the settings.
+func (v *settingValues) cacheSize() (int, error) {
+	return v.envInt(envNameCacheSize, 0)
+}
+
+// GetCacheBytes returns the max total size in bytes of the tool results kept in
+// the cache from 'MCP_TEXT_MIRROR_CACHE_BYTES' environment variable, 64 MiB by
+// default. The size of a result is the one of its JSON encoding. Zero bounds
+// the cache by the number of results only.
+//
+// The cache holds the results beyond the calls, so it is not counted by the
+// memory budget of the calls in progress (see GetMemoryBudget). Both add up.
+func GetCacheBytes() (int, error) {
+	return loadedSettings().cacheBytes()
+}
+
+// cacheBytes returns GetCacheBytes of the settings.
+func (v *settingValues) cacheBytes() (int, error) {
+	return v.envInt(envNameCacheBytes, cacheBytesDefault)
+}
+
+// cacheableTool is a Tool whose results depend on its arguments only, so that
+// the results of the repeated calls can be served from the resultCache.
+type cacheableTool interface {
+	Tool
+	// cacheable returns whether the result of the call with the arguments can
+	// be cached, e.g. not if it reads a file.
+	cacheable(args map[string]any) bool
+}
+
+// CacheStats is the usage of the result cache since the server started, or
+// since the last reload.
+type CacheStats struct {
+	Size     int     `json:"size"      jsonschema:"The max number of results kept."`
+	Entries  int     `json:"entries"   jsonschema:"The number of results kept."`
+	MaxBytes int     `json:"max_bytes" jsonschema:"The max total size of the results kept in bytes. 0 if unbounded."`
+	Bytes    int     `json:"bytes"     jsonschema:"The total size of the results kept in bytes, as JSON."`
+	Hits     int64   `json:"hits"      jsonschema:"The number of calls served from the cache."`
+	Misses   int64   `json:"misses"    jsonschema:"The number of cacheable calls not in the cache."`
+	HitRate  float64 `json:"hit_rate"  jsonschema:"The ratio of the hits to the cacheable calls, from 0 to 1."`
+}
+
+// cacheKey identifies a tool call by the hash of the name of the tool and its
+// arguments.
+type cacheKey [sha256.Size]byte
+
+// cacheEntry is a result kept in the cache.
+type cacheEntry struct {
+	key    cacheKey
+	result *mcp.CallToolResult
+	bytes  int // size of the result in JSON
+}
+
+// resultCache keeps the results of the latest tool calls, up to its size and
+// its max bytes, and evicts the least recently used ones. Only the successful
+// calls of the cacheableTool are cached.
+type resultCache struct {
+	tools    map[string]cacheableTool // cacheable tools by name, including the versioned names
+	size     int
+	maxBytes int                        // 0 if unbounded
+	bytes    int                        // total size of the results kept
+	entries  map[cacheKey]*list.Element // of order
+	order    *list.List                 // of *cacheEntry, the most recently used first
+	hits     int64
+	misses   int64
+	statsd   *statsdClient // pushes the hits and the misses to StatsD too if not nil
+	mu       sync.Mutex
+}
+
+// newResultCache returns the cache of the tools of toolRegistry, disabled
+// until loaded.
+func newResultCache() *resultCache {
+	cache := new(resultCache)
+	cache.tools = make(map[string]cacheableTool)
+	cache.entries = make(map[cacheKey]*list.Element)
+	cache.order = list.New()
+
+	for _, tool := range withVersions(toolRegistry) {
+		inner := tool
+		if alias, ok := tool.(versionAlias); ok {
+			inner = alias.versionedTool
+		}
+
+		if cacheable, ok := inner.(cacheableTool); ok {
+			cache.tools[tool.Name()] = cacheable
+		}
+	}
+
+	return cache
+}
+
+// load empties the cache and resizes it as configured, since the results may
+// depend on the other settings, such as the locale. The counters start over.
+func (c *resultCache) load() {
+	// Invalid values are reported by loadSettings.
+	size, _ := GetCacheSize()
+	maxBytes, _ := GetCacheBytes()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.size, c.maxBytes, c.bytes = size, maxBytes, 0
+	c.entries = make(map[cacheKey]*list.Element)
+	c.order.Init()
+	c.hits, c.misses = 0, 0
+}
+
+// get returns the result cached for the key, if any, and counts the hit or the
+// miss.
+func (c *resultCache) get(name string, key cacheKey) (*mcp.CallToolResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		c.statsd.recordCache(name, false)
+
+		return nil, false
+	}
+
+	c.hits++
+	c.statsd.recordCache(name, true)
+	c.order.MoveToFront(element)
+
+	entry, _ := element.Value.(*cacheEntry)
+
+	return entry.result, true
+}
+
+// put caches the result for the key, evicting the least recently used results
+// until both the number of results and their total size fit. Results larger
+// than the max bytes alone are not cached.
+func (c *resultCache) put(key cacheKey, result *mcp.CallToolResult) {
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+
+	size := len(encoded)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.size <= 0 || (c.maxBytes > 0 && size > c.maxBytes) {
+		return // disabled by a reload meanwhile, or too large
+	}
+
+	if element, ok := c.entries[key]; ok {
+		c.order.MoveToFront(element)
+
+		return
+	}
+
+	for c.order.Len() >= c.size || (c.maxBytes > 0 && c.bytes+size > c.maxBytes) {
+		oldest := c.order.Back()
+		entry, _ := oldest.Value.(*cacheEntry)
+
+		c.order.Remove(oldest)
+		delete(c.entries, entry.key)
+		c.bytes -= entry.bytes
+	}
+
+	entry := new(cacheEntry)
+	entry.key = key
+	entry.result = result
+	entry.bytes = size
+	c.entries[key] = c.order.PushFront(entry)
+	c.bytes += size
+}
+
+// enabled returns whether the cache is enabled.
+func (c *resultCache) enabled() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.size > 0
+}
+
+// snapshot returns the usage of the cache, or nil if disabled.
+func (c *resultCache) snapshot() *CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.size <= 0 {
+		return nil
+	}
+
+	stats := new(CacheStats)
+	stats.Size = c.size
+	stats.Entries = c.order.Len()
+	stats.MaxBytes = c.maxBytes
+	stats.Bytes = c.bytes
+	stats.Hits = c.hits
+	stats.Misses = c.misses
+
+	if calls := c.hits + c.misses; calls > 0 {
+		stats.HitRate = float64(c.hits) / float64(calls)
+	}
+
+	return stats
+}
+
+// callKey returns the key of the call of the named tool with the arguments by
+// a client with the text limit, if limited, so that the clients with a lower
+// limit are not served the results they would be refused. The arguments are
+// hashed as re-encoded, so that the order of their keys doesn't matter.
+func callKey(name string, args map[string]any, limit int, limited bool) (cacheKey, error) {
+	canonical, err := json.Marshal(args) // sorts the keys of the maps
+	if err != nil {
+		return cacheKey{}, wrapError(err, "failed to encode the arguments")
+	}
+
+	if !limited {
+		limit = -1
+	}
+
+	hash := sha256.New()
+	_, _ = hash.Write([]byte(name))
+	_, _ = hash.Write([]byte{0})
+	_, _ = hash.Write(strconv.AppendInt(nil, int64(limit), 10))
+	_, _ = hash.Write([]byte{0})
+	_, _ = hash.Write(canonical)
+
+	var key cacheKey
+
+	hash.Sum(key[:0])
+
+	return key, nil
+}
+
+// copyResult returns a copy of the result whose _meta can be changed without
+// changing the one of the result. The contents are shared, as the middlewares
+// change the _meta only.
+func copyResult(result *mcp.CallToolResult) *mcp.CallToolResult {
+	copied := *result
+	copied.Meta = maps.Clone(result.Meta)
+
+	return &copied
+}
+
+// middleware serves the calls of the cacheable tools from the cache if their
+// arguments were seen, with `"text-mirror/cached": true` in the _meta of the
+// result, and caches the successful results of the others.
+func (c *resultCache) middleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		params, ok := req.GetParams().(*mcp.CallToolParamsRaw)
+		if method != methodCallTool || !ok || params == nil || !c.enabled() {
+			return next(ctx, method, req)
+		}
+
+		tool, ok := c.tools[params.Name]
+
+		var args map[string]any
+
+		if !ok || json.Unmarshal(params.Arguments, &args) != nil || !tool.cacheable(args) {
+			return next(ctx, method, req)
+		}
+
+		call, _ := req.(*mcp.CallToolRequest)
+		limit, limited := clientTextLimit(call)
+
+		key, err := callKey(params.Name, args, limit, limited)
+		if err != nil {
+			return next(ctx, method, req)
+		}
+
+		if cached, hit := c.get(params.Name, key); hit {
+			result := copyResult(cached)
+			if result.Meta == nil {
+				result.Meta = make(mcp.Meta)
+			}
+
+			result.Meta[metaKeyCached] = true
+
+			return result, nil
+		}
+
+		res, err := next(ctx, method, req)
+
+		if result, ok := res.(*mcp.CallToolResult); ok && err == nil && result != nil && !result.IsError {
+			c.put(key, copyResult(result))
+		}
+
+		return res, err
+	}
+}
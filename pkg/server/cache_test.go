@@ -0,0 +1,256 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  GetCacheSize
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_GetCacheSize(t *testing.T) {
+	setEnv(t, envNameCacheSize, "")
+
+	size, err := GetCacheSize()
+	require.NoError(t, err)
+	require.Zero(t, size, "the cache should be disabled by default")
+
+	setEnv(t, envNameCacheSize, "-1")
+
+	_, err = GetCacheSize()
+	require.ErrorIs(t, err, errInvalidNumber)
+}
+
+// ----------------------------------------------------------------------------
+//  resultCache
+// ----------------------------------------------------------------------------
+
+// ----------------------------------------------------------------------------
+//  GetCacheBytes
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_GetCacheBytes(t *testing.T) {
+	setEnv(t, envNameCacheBytes, "")
+
+	size, err := GetCacheBytes()
+	require.NoError(t, err)
+	require.Equal(t, cacheBytesDefault, size)
+
+	setEnv(t, envNameCacheBytes, "-1")
+
+	_, err = GetCacheBytes()
+	require.ErrorIs(t, err, errInvalidNumber)
+}
+
+// resultBytes returns the size of the result as counted by the cache.
+func resultBytes(t *testing.T, result *mcp.CallToolResult) int {
+	t.Helper()
+
+	encoded, err := json.Marshal(result)
+	require.NoError(t, err)
+
+	return len(encoded)
+}
+
+//nolint:paralleltest // sets env var
+func Test_resultCache_lru(t *testing.T) {
+	setEnv(t, envNameCacheSize, "2")
+	setEnv(t, envNameCacheBytes, "")
+
+	cache := newResultCache()
+	require.Nil(t, cache.snapshot(), "the cache should be disabled until loaded")
+
+	cache.load()
+
+	keys := make([]cacheKey, 3)
+	for index := range keys {
+		key, err := callKey(toolName, map[string]any{"text": fmt.Sprint(index)}, 0, false)
+		require.NoError(t, err)
+
+		keys[index] = key
+	}
+
+	first := new(mcp.CallToolResult)
+	cache.put(keys[0], first)
+	cache.put(keys[1], new(mcp.CallToolResult))
+
+	got, ok := cache.get(toolName, keys[0])
+	require.True(t, ok)
+	require.Same(t, first, got)
+
+	cache.put(keys[2], new(mcp.CallToolResult)) // evicts keys[1], the least recently used
+
+	_, ok = cache.get(toolName, keys[1])
+	require.False(t, ok, "the least recently used result should be evicted")
+
+	_, ok = cache.get(toolName, keys[0])
+	require.True(t, ok)
+
+	empty := resultBytes(t, first)
+	require.Equal(t, &CacheStats{
+		Size: 2, Entries: 2, MaxBytes: cacheBytesDefault, Bytes: 2 * empty, Hits: 2, Misses: 1, HitRate: 2.0 / 3,
+	}, cache.snapshot())
+
+	setEnv(t, envNameCacheSize, "0")
+	cache.load()
+
+	cache.put(keys[0], first)
+	require.Nil(t, cache.snapshot(), "a reload should disable the cache")
+}
+
+//nolint:paralleltest // sets env var
+func Test_resultCache_bytes(t *testing.T) {
+	text := func(size int) *mcp.CallToolResult {
+		result := new(mcp.CallToolResult)
+		result.Content = []mcp.Content{&mcp.TextContent{Text: strings.Repeat("a", size)}}
+
+		return result
+	}
+
+	small, large := text(100), text(1000)
+	smallBytes := resultBytes(t, small)
+
+	setEnv(t, envNameCacheSize, "10")
+	setEnv(t, envNameCacheBytes, strconv.Itoa(3*smallBytes))
+
+	cache := newResultCache()
+	cache.load()
+
+	keys := make([]cacheKey, 4)
+	for index := range keys {
+		key, err := callKey(toolName, map[string]any{"text": fmt.Sprint(index)}, 0, false)
+		require.NoError(t, err)
+
+		keys[index] = key
+	}
+
+	for _, key := range keys {
+		cache.put(key, small) // the fourth evicts the first, over the max bytes
+	}
+
+	_, ok := cache.get(toolName, keys[0])
+	require.False(t, ok, "the least recently used result should be evicted to fit the max bytes")
+
+	stats := cache.snapshot()
+	require.Equal(t, 3, stats.Entries, "the number of results should be bounded by the max bytes")
+	require.Equal(t, 3*smallBytes, stats.Bytes)
+
+	cache.put(keys[0], large)
+
+	_, ok = cache.get(toolName, keys[0])
+	require.False(t, ok, "a result larger than the max bytes should not be cached")
+	require.Equal(t, 3*smallBytes, cache.snapshot().Bytes, "the cache should be unchanged")
+
+	// Unbounded
+	setEnv(t, envNameCacheBytes, "0")
+	cache.load()
+
+	cache.put(keys[0], large)
+
+	_, ok = cache.get(toolName, keys[0])
+	require.True(t, ok, "0 should bound the cache by the number of results only")
+}
+
+func Test_callKey(t *testing.T) {
+	t.Parallel()
+
+	key := func(name string, args map[string]any, limit int, limited bool) cacheKey {
+		t.Helper()
+
+		key, err := callKey(name, args, limit, limited)
+		require.NoError(t, err)
+
+		return key
+	}
+
+	args := map[string]any{"text": "abc", "expr": "mirror | upper"}
+	base := key(pipelineToolName, args, 0, false)
+
+	require.Equal(t, base, key(pipelineToolName, map[string]any{"expr": "mirror | upper", "text": "abc"}, 0, false),
+		"the order of the arguments should not matter")
+	require.NotEqual(t, base, key(transformToolName, args, 0, false), "the tool should matter")
+	require.NotEqual(t, base, key(pipelineToolName, map[string]any{"text": "abd", "expr": "mirror | upper"}, 0, false))
+	require.NotEqual(t, base, key(pipelineToolName, args, 0, true), "the text limit of the client should matter")
+	require.NotEqual(t, key(pipelineToolName, args, 10, true), key(pipelineToolName, args, 20, true))
+}
+
+//nolint:paralleltest // sets env var
+func Test_resultCache_middleware(t *testing.T) {
+	unsetEnv(t, envNameAdmin, envNameToolsEnabled, envNameToolsDisabled, envNameToolsPreset, envNameVerify,
+		envNameClientLimits, envNameRateLimit)
+	setEnv(t, envNameCacheSize, "10")
+	setEnv(t, envNameCacheBytes, "")
+
+	state := newServerState()
+	session := connectInMemory(t, state.server)
+
+	cached := func(res *mcp.CallToolResult) bool {
+		t.Helper()
+
+		require.False(t, res.IsError, "the call should succeed")
+
+		hit, _ := res.Meta[metaKeyCached].(bool)
+
+		return hit
+	}
+
+	args := map[string]any{"text": "abc", "op": "upper"}
+
+	first := callTool(t, session, transformToolName, args)
+	require.False(t, cached(first), "the first call should not be served from the cache")
+
+	second := callTool(t, session, transformToolName, map[string]any{"op": "upper", "text": "abc"})
+	require.True(t, cached(second), "the repeated call should be served from the cache")
+	require.Equal(t, first.StructuredContent, second.StructuredContent)
+	require.Equal(t, first.Content, second.Content)
+
+	require.True(t, cached(callTool(t, session, transformToolName, args)))
+	require.NotContains(t, first.Meta, metaKeyCached, "the results returned should not be changed by the hits")
+
+	// Not cacheable
+	require.False(t, cached(callTool(t, session, statsToolName, map[string]any{})))
+	require.False(t, cached(callTool(t, session, statsToolName, map[string]any{})))
+
+	res := callTool(t, session, toolName, map[string]any{"text": "", "path": "missing.txt"})
+	require.True(t, res.IsError)
+	require.Nil(t, res.Meta[metaKeyCached], "calls reading files should not be cached")
+
+	report := state.stats.report()
+	require.Positive(t, report.Cache.Bytes, "the statistics should report the size of the results kept")
+
+	report.Cache.Bytes = 0
+	require.Equal(t, &CacheStats{Size: 10, Entries: 1, MaxBytes: cacheBytesDefault, Hits: 2, Misses: 1, HitRate: 2.0 / 3},
+		report.Cache, "the statistics should report the hit rate")
+
+	// The results may depend on the settings, so a reload empties the cache
+	setEnv(t, envNameLocale, "tr")
+	state.apply()
+
+	res = callTool(t, session, transformToolName, map[string]any{"text": "i", "op": "upper"})
+	require.False(t, cached(res))
+	require.Equal(t, "İ", res.StructuredContent.(map[string]any)["text"]) //nolint:forcetypeassert // structured output
+}
+
+//nolint:paralleltest // sets env var
+func Test_resultCache_middleware_disabled(t *testing.T) {
+	unsetEnv(t, envNameAdmin, envNameToolsEnabled, envNameToolsDisabled, envNameToolsPreset, envNameCacheSize)
+
+	state := newServerState()
+	session := connectInMemory(t, state.server)
+
+	for range 2 {
+		res := callTool(t, session, toolName, map[string]any{"text": "abc"})
+		require.Nil(t, res.Meta[metaKeyCached], "the cache should be disabled by default")
+	}
+
+	require.Nil(t, state.stats.report().Cache)
+}
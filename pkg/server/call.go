@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// One-shot tool call. E.g.: text-mirror call mirror --json '{"text":"abc"}'
+const (
+	cmdNameCall = "call"
+
+	flagNameJSON = "json" // arguments of the tool call in JSON
+	flagNameURL  = "url"  // endpoint of a remote server to call instead of the in-process one
+)
+
+// Predefined errors of the call subcommand.
+var (
+	errCallUsage  = errors.New("usage: text-mirror call <tool> [--json '{...}'] [--url http://host:port/mcp]")
+	errToolFailed = errors.New("tool call failed")
+)
+
+// runCall is the "call" subcommand. It calls the tool once, against an
+// in-process server as configured or the remote server at --url, and prints the
+// result as indented JSON to out. It returns errToolFailed if the result is a
+// tool execution error, so that the command exits nonzero in scripts.
+func runCall(ctx context.Context, args []string, out io.Writer) error {
+	var tool, rawArgs, url string
+
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		tool, args = args[0], args[1:]
+	}
+
+	flags := flag.NewFlagSet(cmdNameCall, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.StringVar(&rawArgs, flagNameJSON, "", "arguments of the tool in JSON")
+	flags.StringVar(&url, flagNameURL, "", "endpoint of the remote server")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errCallUsage, err)
+	}
+
+	if tool == "" && flags.NArg() > 0 {
+		tool = flags.Arg(0)
+
+		err = flags.Parse(flags.Args()[1:]) // flags after the tool name
+		if err != nil {
+			return fmt.Errorf("%w: %w", errCallUsage, err)
+		}
+	}
+
+	switch {
+	case tool == "":
+		return fmt.Errorf("%w: no tool given", errCallUsage)
+	case flags.NArg() > 0:
+		return fmt.Errorf("%w: unexpected arguments %q", errCallUsage, flags.Args())
+	}
+
+	session, closeSession, err := connectCallTarget(ctx, url)
+	if err != nil {
+		return err
+	}
+
+	defer closeSession()
+
+	res, err := callToolJSON(ctx, session, tool, rawArgs)
+	if err != nil {
+		return err
+	}
+
+	err = printJSON(out, res)
+	if err != nil {
+		return wrapError(err, "failed to write the result")
+	}
+
+	if res.IsError {
+		return fmt.Errorf("%w: %s", errToolFailed, tool)
+	}
+
+	return nil
+}
+
+// connectCallTarget returns the session of a client connected to the remote
+// server at url over streamable HTTP, or to an in-process server as configured
+// if url is empty, and the function to close it.
+func connectCallTarget(ctx context.Context, url string) (*mcp.ClientSession, func(), error) {
+	if url == "" {
+		err := loadSettings()
+		if err != nil {
+			return nil, nil, wrapError(err, "invalid configuration")
+		}
+
+		return connectInProcess(ctx)
+	}
+
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return nil, nil, fmt.Errorf("%w: --url must be an http(s) URL", errCallUsage)
+	}
+
+	transport := new(mcp.StreamableClientTransport)
+	transport.Endpoint = url
+
+	client := mcp.NewClient(&mcp.Implementation{Name: serviceName, Version: GetServiceVersion()}, nil) //nolint:exhaustruct // name and version only
+
+	session, err := client.Connect(ctx, transport, nil)
+	if err != nil {
+		return nil, nil, wrapError(err, "failed to connect to %s", url)
+	}
+
+	return session, func() { _ = session.Close() }, nil
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  runCall
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_runCommand_call(t *testing.T) {
+	unsetEnv(t, envNameToolsEnabled, envNameToolsDisabled, envNameProfile)
+
+	for index, test := range []struct {
+		name     string
+		args     []string
+		wantText string
+		wantErr  error
+	}{
+		{"tool_first", []string{"mirror", "--json", `{"text":"abc"}`}, "cba", nil},
+		{"flags_first", []string{"--json", `{"text":"a👍🏽b"}`, "mirror"}, "b👍🏽a", nil},
+		{"tool_error", []string{"mirror", "--json", `{"text":"a","path":"b"}`}, errTextAndPath.Error(), errToolFailed},
+		{"no_tool", []string{"--json", `{}`}, "", errCallUsage},
+		{"extra_argument", []string{"mirror", "extra"}, "", errCallUsage},
+		{"unknown_flag", []string{"mirror", "--jsn", `{}`}, "", errCallUsage},
+		{"unknown_flag_after_tool", []string{"--json", `{"text":"abc"}`, "mirror", "--bogus"}, "", errCallUsage},
+		{"extra_argument_after_tool", []string{"--json", `{"text":"abc"}`, "mirror", "extra"}, "", errCallUsage},
+		{"invalid_json", []string{"mirror", "--json", `["abc"]`}, "", errJSONArguments},
+		{"invalid_url", []string{"mirror", "--url", "ftp://example.com"}, "", errCallUsage},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		var out bytes.Buffer
+
+		app := newTestApp(t)
+		app.Stdout = &out
+
+		err := app.Run(context.Background(), append([]string{cmdNameCall}, test.args...))
+		if test.wantErr != nil {
+			require.ErrorIs(t, err, test.wantErr, name)
+		} else {
+			require.NoError(t, err, name)
+		}
+
+		if test.wantText == "" {
+			continue
+		}
+
+		var res struct {
+			Content []struct {
+				Text string `json:"text"`
+			} `json:"content"`
+		}
+
+		require.NoError(t, json.Unmarshal(out.Bytes(), &res), name)
+		require.Equal(t, test.wantText, res.Content[0].Text, name)
+	}
+}
+
+func Test_runCall_remote(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(newHTTPHandler(context.Background(), newServer(), new(atomic.Bool)))
+	defer server.Close()
+
+	var out bytes.Buffer
+
+	err := runCall(context.Background(), []string{toolName, "--json", `{"text":"abc"}`, "--url", server.URL + httpPathMCP}, &out)
+	require.NoError(t, err)
+	require.Contains(t, out.String(), `"text": "cba"`)
+
+	// Unreachable server
+	err = runCall(context.Background(), []string{toolName, "--url", server.URL + "/nowhere"}, &out)
+	require.ErrorContains(t, err, "failed to connect")
+}
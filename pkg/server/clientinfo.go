@@ -0,0 +1,159 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Per client configuration.
+const (
+	envNameClientLimits = envPrefix + "CLIENT_LIMITS" // env var of the max text bytes per client name. e.g. "vscode=16777216;*=65536"
+	anyClient           = "*"                         // client name matching the clients not listed
+	clientLimitSep      = ";"
+	clientLimitNameSep  = "="
+)
+
+// Predefined errors of the per client configuration.
+var (
+	errClientLimitFormat = errors.New("client limit must be in 'name=bytes' format")
+	errTextTooLarge      = errors.New("text too large for this client")
+)
+
+// GetClientLimits returns the max size in bytes of the texts to mirror per
+// client name, from 'MCP_TEXT_MIRROR_CLIENT_LIMITS' environment variable.
+//
+// The value is a ";" separated list of "name=bytes" pairs, where name is the
+// one the client sent in its clientInfo at initialize time. The "*" name applies
+// to the clients not listed, which allows stricter limits for unknown clients.
+// Clients without a limit are limited only by the input schema.
+//
+// NOTE: The client name is declared by the client itself. Use mTLS to
+// authenticate the clients.
+func GetClientLimits() (map[string]int, error) {
+	return loadedSettings().clientLimits()
+}
+
+// clientLimits returns GetClientLimits of the settings.
+func (v *settingValues) clientLimits() (map[string]int, error) {
+	limits := make(map[string]int)
+
+	for entry := range strings.SplitSeq(v.value(envNameClientLimits), clientLimitSep) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, value, found := strings.Cut(entry, clientLimitNameSep)
+		name = strings.TrimSpace(name)
+
+		limit, err := strconv.Atoi(strings.TrimSpace(value))
+		if !found || name == "" || err != nil || limit < 0 {
+			return nil, wrapError(errClientLimitFormat, "invalid client limit %q", entry)
+		}
+
+		limits[name] = limit
+	}
+
+	return limits, nil
+}
+
+// clientInfo returns the implementation info the client of the request sent at
+// initialize time. It returns nil for direct calls.
+func clientInfo(req *mcp.CallToolRequest) *mcp.Implementation {
+	if req == nil || req.Session == nil {
+		return nil
+	}
+
+	params := req.Session.InitializeParams()
+	if params == nil {
+		return nil
+	}
+
+	return params.ClientInfo
+}
+
+// checkTextLimit returns errTextTooLarge if the text exceeds the limit of the
+// client of the request. See GetClientLimits.
+func checkTextLimit(req *mcp.CallToolRequest, text string) error {
+	limit, ok := clientTextLimit(req)
+	if ok && len(text) > limit {
+		return fmt.Errorf("%w: %d bytes given, %d bytes allowed", errTextTooLarge, len(text), limit)
+	}
+
+	return nil
+}
+
+// clientTextLimit returns the max text bytes of the client of the request,
+// and false if it is not limited. See GetClientLimits.
+func clientTextLimit(req *mcp.CallToolRequest) (int, bool) {
+	// Invalid configurations are reported by loadSettings beforehand.
+	limits, _ := GetClientLimits()
+	if len(limits) == 0 {
+		return 0, false
+	}
+
+	name := ""
+	if info := clientInfo(req); info != nil {
+		name = info.Name
+	}
+
+	limit, ok := limits[name]
+	if !ok {
+		limit, ok = limits[anyClient]
+	}
+
+	return limit, ok
+}
+
+// callLogAttrs returns the attributes of the debug logs of the tool call as
+// key-value pairs for debugLog: the request ID of ctx, the tool name, the
+// client implementation and identity if known, and the propagated _meta
+// entries of the request such as the trace IDs, followed by args.
+func callLogAttrs(ctx context.Context, req *mcp.CallToolRequest, args ...any) []any {
+	var attrs []any
+
+	if id := requestID(ctx); id != "" {
+		attrs = append(attrs, logKeyRequestID, id)
+	}
+
+	if req != nil && req.Params != nil {
+		attrs = append(attrs, logKeyTool, req.Params.Name)
+	}
+
+	if req != nil && req.Session != nil {
+		attrs = append(attrs, logKeySession, sessionLog(req))
+	}
+
+	if info := clientInfo(req); info != nil {
+		attrs = append(attrs, logKeyApp, info.Name+" "+info.Version)
+	}
+
+	if clientID := clientIdentity(req); clientID != "" {
+		attrs = append(attrs, logKeyClient, clientID)
+	}
+
+	if req != nil && req.Params != nil {
+		if meta := metaLogAttrs(req.Params); len(meta) > 0 {
+			attrs = append(attrs, slog.Group(logKeyMeta, meta...))
+		}
+	}
+
+	return append(attrs, args...)
+}
+
+// handleInitialized is the mcp.ServerOptions.InitializedHandler. It logs the
+// client implementation and registers the session to receive the log messages.
+func handleInitialized(ctx context.Context, req *mcp.InitializedRequest) {
+	if params := req.Session.InitializeParams(); params != nil && params.ClientInfo != nil {
+		infoLog(ctx, "client initialized", logKeyApp, params.ClientInfo.Name+" "+params.ClientInfo.Version,
+			logKeyProtocol, params.ProtocolVersion, logKeySession, logSession{session: req.Session})
+	}
+
+	clientLog.initializedHandler(ctx, req)
+}
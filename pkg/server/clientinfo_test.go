@@ -0,0 +1,147 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// connectAs connects a client with the given name to the server. It returns
+// the client session.
+func connectAs(t *testing.T, server *mcp.Server, name string) *mcp.ClientSession {
+	t.Helper()
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+
+	client := mcp.NewClient(&mcp.Implementation{Name: name, Version: "v1.2.3"}, nil) //nolint:exhaustruct // minimal client
+
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = session.Close()
+		_ = serverSession.Wait()
+	})
+
+	return session
+}
+
+// ----------------------------------------------------------------------------
+//  GetClientLimits
+// ----------------------------------------------------------------------------
+
+func Test_GetClientLimits(t *testing.T) {
+	for index, test := range []struct {
+		name    string
+		value   string
+		want    map[string]int
+		wantErr bool
+	}{
+		{"empty", "", map[string]int{}, false},
+		{"limits", " vscode = 100 ; *=10;", map[string]int{"vscode": 100, anyClient: 10}, false},
+		{"missing_separator", "vscode", nil, true},
+		{"missing_name", "=10", nil, true},
+		{"invalid_bytes", "vscode=many", nil, true},
+		{"negative_bytes", "vscode=-1", nil, true},
+	} {
+		t.Run(fmt.Sprintf("Test #%d: %s", index+1, test.name), func(t *testing.T) {
+			setEnv(t, envNameClientLimits, test.value)
+
+			got, err := GetClientLimits()
+			if test.wantErr {
+				require.ErrorIs(t, err, errClientLimitFormat)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.want, got)
+		})
+	}
+}
+
+//nolint:paralleltest // sets env var
+func Test_run_invalid_client_limits(t *testing.T) {
+	setEnv(t, envNameClientLimits, "vscode")
+
+	err := newApp().serve(context.Background(), nil)
+	require.ErrorIs(t, err, errClientLimitFormat)
+}
+
+// ----------------------------------------------------------------------------
+//  Per client limits
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_handleReverse_client_limits(t *testing.T) {
+	setEnv(t, envNameClientLimits, "trusted=10;*=3")
+
+	for index, test := range []struct {
+		client  string
+		text    string
+		wantErr bool
+	}{
+		{"trusted", "0123456789", false},
+		{"trusted", "0123456789a", true},
+		{"unknown", "abc", false},
+		{"unknown", "abcd", true},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.client)
+		session := connectAs(t, newServer(), test.client)
+
+		res := callTool(t, session, toolName, map[string]any{"text": test.text})
+		require.Equal(t, test.wantErr, res.IsError, name)
+
+		res = callTool(t, session, batchToolName, map[string]any{"texts": []string{"a", test.text}})
+		require.Equal(t, test.wantErr, res.IsError, name)
+
+		if test.wantErr {
+			require.Contains(t, res.Content[0].(*mcp.TextContent).Text, errTextTooLarge.Error(), name) //nolint:forcetypeassert // text content
+		}
+	}
+
+	// Direct calls without client info fall back to "*"
+	_, _, err := handleReverse(context.Background(), nil, MirrorInput{Text: "abcd"})
+	require.ErrorIs(t, err, errTextTooLarge)
+}
+
+// ----------------------------------------------------------------------------
+//  clientInfo logging
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_clientInfo_logging(t *testing.T) {
+	setEnv(t, envNameDebug, "test.log")
+
+	var (
+		mu     sync.Mutex
+		logged []string
+	)
+
+	server := newServer(WithLogger(mockLogger(func(entry string) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		logged = append(logged, entry)
+	})))
+
+	session := connectAs(t, server, "logging-client")
+	callTool(t, session, toolName, map[string]any{"text": "abc"})
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	all := strings.Join(logged, "\n")
+	require.Contains(t, all, `client initialized app="logging-client v1.2.3" protocol=`)
+	require.Regexp(t, `text mirrored request_id=\w{26} tool=mirror app="logging-client v1.2.3" input_size=3 duration=`, all)
+	require.Contains(t, all, "text=abc mirrored=cba")
+}
@@ -0,0 +1,190 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"runtime/debug"
+	"slices"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Protocol compatibility report.
+const (
+	compatURI  = "text-mirror://compat"
+	compatMIME = "application/json"
+	sdkModule  = "github.com/modelcontextprotocol/go-sdk"
+)
+
+// protocolVersions are the MCP protocol versions supported by this build, the
+// latest first. They are the ones of the SDK in use, which answers with the
+// latest one if the client asks for an unsupported version.
+//
+//nolint:gochecknoglobals // constant list
+var protocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// clientFeatures are the optional client capabilities used by this server, if
+// the client declares them.
+//
+//nolint:gochecknoglobals // constant list
+var clientFeatures = []string{"roots", "sampling", "elicitation"}
+
+// CompatReport is the content of the compat resource.
+type CompatReport struct {
+	Server                *mcp.Implementation `json:"server"`
+	SDKVersion            string              `json:"sdkVersion"`
+	ProtocolVersions      []string            `json:"protocolVersions"`
+	LatestProtocolVersion string              `json:"latestProtocolVersion"`
+	ClientFeatures        []string            `json:"clientFeatures"`
+	Session               *SessionCompat      `json:"session,omitempty"`
+}
+
+// SessionCompat is the outcome of the handshake with the client reading the
+// compat resource.
+type SessionCompat struct {
+	RequestedProtocolVersion string                  `json:"requestedProtocolVersion"`
+	ProtocolVersion          string                  `json:"protocolVersion"`
+	ClientInfo               *mcp.Implementation     `json:"clientInfo,omitempty"`
+	ClientCapabilities       *mcp.ClientCapabilities `json:"clientCapabilities,omitempty"`
+	ServerCapabilities       *mcp.ServerCapabilities `json:"serverCapabilities,omitempty"`
+}
+
+// handshakes keeps the initialize results sent to the connected sessions, to
+// report the negotiated protocol version and capabilities.
+type handshakes struct {
+	results map[*mcp.ServerSession]*mcp.InitializeResult
+	mu      sync.Mutex
+}
+
+// newHandshakes returns an empty handshakes.
+func newHandshakes() *handshakes {
+	h := new(handshakes)
+	h.results = make(map[*mcp.ServerSession]*mcp.InitializeResult)
+
+	return h
+}
+
+// get returns the initialize result sent to the session, or nil if unknown.
+func (h *handshakes) get(session *mcp.ServerSession) *mcp.InitializeResult {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.results[session]
+}
+
+// middleware is a middleware which logs the protocol version negotiated on
+// initialize and keeps the result until the session ends.
+func (h *handshakes) middleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		res, err := next(ctx, method, req)
+		if method != methodInitialize {
+			return res, err
+		}
+
+		requested := ""
+		if params, ok := req.GetParams().(*mcp.InitializeParams); ok && params != nil {
+			requested = params.ProtocolVersion
+		}
+
+		initResult, ok := res.(*mcp.InitializeResult)
+		if err != nil || !ok || initResult == nil {
+			warnLog(ctx, "handshake failed", "requested", requested, logKeySession, sessionLog(req), logKeyError, err)
+
+			return res, err
+		}
+
+		debugLog(ctx, "protocol negotiated", logKeyProtocol, initResult.ProtocolVersion, "requested", requested,
+			"supported", slices.Contains(protocolVersions, requested), // answered with the latest if not
+			logKeySession, sessionLog(req))
+
+		session, ok := req.GetSession().(*mcp.ServerSession)
+		if ok && session != nil {
+			h.mu.Lock()
+			h.results[session] = initResult
+			h.mu.Unlock()
+
+			go func() {
+				_ = session.Wait()
+
+				h.mu.Lock()
+				delete(h.results, session)
+				h.mu.Unlock()
+			}()
+		}
+
+		return res, nil
+	}
+}
+
+// sdkVersion returns the version of the MCP SDK in the build info read by
+// readBuildInfo, or "unknown".
+func sdkVersion(readBuildInfo func() (*debug.BuildInfo, bool)) string {
+	info, ok := readBuildInfo()
+	if ok {
+		for _, dep := range info.Deps {
+			if dep.Path == sdkModule {
+				return dep.Version
+			}
+		}
+	}
+
+	return "unknown"
+}
+
+// addCompatResource adds the resource reporting the supported protocol
+// versions and capabilities, so that operators can diagnose handshake failures
+// with older clients.
+func addCompatResource(server *mcp.Server, h *handshakes) {
+	resource := new(mcp.Resource)
+	resource.URI = compatURI
+	resource.Name = "compat"
+	resource.Title = "Protocol compatibility"
+	resource.Description = "MCP protocol versions and capabilities supported by this server, " +
+		"and the ones negotiated with the reading client"
+	resource.MIMEType = compatMIME
+
+	server.AddResource(resource, func(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		report := new(CompatReport)
+		report.Server = &mcp.Implementation{Name: serviceName, Title: serviceTitle, Version: GetServiceVersion()}
+		report.SDKVersion = sdkVersion(debug.ReadBuildInfo)
+		report.ProtocolVersions = protocolVersions
+		report.LatestProtocolVersion = protocolVersions[0]
+		report.ClientFeatures = clientFeatures
+
+		if req.Session != nil {
+			report.Session = sessionCompat(req.Session, h.get(req.Session))
+		}
+
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return nil, wrapError(err, "failed to marshal the compat report")
+		}
+
+		contents := new(mcp.ResourceContents)
+		contents.URI = req.Params.URI
+		contents.MIMEType = compatMIME
+		contents.Text = string(data)
+
+		return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{contents}}, nil
+	})
+}
+
+// sessionCompat returns the handshake outcome of the session. initResult may
+// be nil if the session was initialized before the tracking.
+func sessionCompat(session *mcp.ServerSession, initResult *mcp.InitializeResult) *SessionCompat {
+	compat := new(SessionCompat)
+
+	if params := session.InitializeParams(); params != nil {
+		compat.RequestedProtocolVersion = params.ProtocolVersion
+		compat.ClientInfo = params.ClientInfo
+		compat.ClientCapabilities = params.Capabilities
+	}
+
+	if initResult != nil {
+		compat.ProtocolVersion = initResult.ProtocolVersion
+		compat.ServerCapabilities = initResult.Capabilities
+	}
+
+	return compat
+}
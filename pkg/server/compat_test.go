@@ -0,0 +1,161 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime/debug"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// initializeRaw sends a raw initialize request asking for the protocol version
+// to the HTTP handler, like an older client would, and returns the result.
+func initializeRaw(t *testing.T, handler http.Handler, version string) *mcp.InitializeResult {
+	t.Helper()
+
+	body := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":`+
+		`{"protocolVersion":%q,"capabilities":{},"clientInfo":{"name":"old-client","version":"0.1"}}}`, version)
+
+	req := httptest.NewRequest(http.MethodPost, httpPathMCP, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	// The response is a server-sent event
+	var response struct {
+		Result *mcp.InitializeResult `json:"result"`
+	}
+
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+			require.NoError(t, json.Unmarshal([]byte(data), &response))
+		}
+	}
+
+	require.NotNil(t, response.Result, rec.Body.String())
+
+	return response.Result
+}
+
+// ----------------------------------------------------------------------------
+//  Protocol version negotiation
+// ----------------------------------------------------------------------------
+
+func Test_protocolVersions(t *testing.T) {
+	t.Parallel()
+
+	handler := newHTTPHandler(context.Background(), newServer(), new(atomic.Bool))
+
+	for index, test := range []struct {
+		requested string
+		want      string
+	}{
+		{protocolVersions[0], protocolVersions[0]},
+		{protocolVersions[1], protocolVersions[1]},
+		{protocolVersions[2], protocolVersions[2]},
+		{"2024-01-01", protocolVersions[0]}, // unsupported
+	} {
+		res := initializeRaw(t, handler, test.requested)
+		require.Equal(t, test.want, res.ProtocolVersion,
+			fmt.Sprintf("Test #%d: %s should be answered by the SDK as listed", index+1, test.requested))
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  handshakes
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_handshakes_logging(t *testing.T) {
+	setEnv(t, envNameDebug, "test.log")
+
+	logged := make(chan string, 16)
+	server := newServer(WithLogger(mockLogger(func(entry string) {
+		select {
+		case logged <- entry:
+		default:
+		}
+	})))
+
+	handler := newHTTPHandler(context.Background(), server, new(atomic.Bool))
+	initializeRaw(t, handler, "2024-01-01")
+
+	all := ""
+	for len(logged) > 0 {
+		all += <-logged + "\n"
+	}
+
+	require.Contains(t, all, "protocol negotiated protocol="+protocolVersions[0]+" requested=2024-01-01 supported=false")
+}
+
+func Test_handshakes_failure(t *testing.T) {
+	t.Parallel()
+
+	h := newHandshakes()
+	handler := h.middleware(func(context.Context, string, mcp.Request) (mcp.Result, error) {
+		return nil, errTest
+	})
+
+	_, err := handler(context.Background(), methodInitialize, new(mcp.InitializeRequest))
+	require.ErrorIs(t, err, errTest)
+	require.Empty(t, h.results)
+}
+
+// ----------------------------------------------------------------------------
+//  compat resource
+// ----------------------------------------------------------------------------
+
+func Test_addCompatResource(t *testing.T) {
+	t.Parallel()
+
+	session := connectInMemory(t, newServer())
+
+	params := new(mcp.ReadResourceParams)
+	params.URI = compatURI
+
+	res, err := session.ReadResource(context.Background(), params)
+	require.NoError(t, err)
+	require.Len(t, res.Contents, 1)
+	require.Equal(t, compatMIME, res.Contents[0].MIMEType)
+
+	report := new(CompatReport)
+	require.NoError(t, json.Unmarshal([]byte(res.Contents[0].Text), report))
+
+	require.Equal(t, serviceName, report.Server.Name)
+	require.NotEmpty(t, report.SDKVersion)
+	require.Equal(t, protocolVersions, report.ProtocolVersions)
+	require.Equal(t, protocolVersions[0], report.LatestProtocolVersion)
+	require.Equal(t, clientFeatures, report.ClientFeatures)
+
+	require.NotNil(t, report.Session)
+	require.Equal(t, protocolVersions[0], report.Session.RequestedProtocolVersion)
+	require.Equal(t, protocolVersions[0], report.Session.ProtocolVersion)
+	require.Equal(t, "test-client", report.Session.ClientInfo.Name)
+	require.NotNil(t, report.Session.ServerCapabilities.Tools)
+	require.Contains(t, report.Session.ServerCapabilities.Experimental, experimentalBatch)
+}
+
+func Test_sdkVersion(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "v1.2.3", sdkVersion(func() (*debug.BuildInfo, bool) {
+		bldInfo := new(debug.BuildInfo) // avoid exhaustruct lint error
+		bldInfo.Deps = []*debug.Module{{Path: sdkModule, Version: "v1.2.3"}}
+
+		return bldInfo, true
+	}))
+
+	require.Equal(t, "unknown", sdkVersion(func() (*debug.BuildInfo, bool) { return nil, false }))
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Argument completion.
+const (
+	refPrompt     = "ref/prompt"   // completion reference type of prompt arguments
+	refResource   = "ref/resource" // completion reference type of URI template variables
+	completionMax = 100            // max values per response, per the MCP spec
+)
+
+// completionKey identifies an argument to complete.
+type completionKey struct {
+	refType  string // refPrompt or refResource
+	ref      string // name of the prompt or URI template of the resource
+	argument string
+}
+
+// completionValues are the suggested values of the enum-style arguments.
+//
+//nolint:gochecknoglobals // read-only table
+var completionValues = map[completionKey][]string{
+	{refResource, logTailTemplate, "lines"}: {"10", "50", "100", "500", "1000"},
+}
+
+// handleComplete is the completion/complete handler. It suggests the values of
+// the argument starting with the value typed so far, case-insensitively.
+//
+// Unknown arguments get no suggestion rather than an error, so that clients can
+// ask for any argument.
+func handleComplete(_ context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+	result := new(mcp.CompleteResult)
+	result.Completion.Values = []string{}
+
+	if req.Params == nil || req.Params.Ref == nil {
+		return result, nil
+	}
+
+	key := completionKey{refType: req.Params.Ref.Type, argument: req.Params.Argument.Name}
+
+	switch key.refType {
+	case refPrompt:
+		key.ref = req.Params.Ref.Name
+	case refResource:
+		key.ref = req.Params.Ref.URI
+	}
+
+	typed := strings.ToLower(req.Params.Argument.Value)
+
+	for _, value := range completionValues[key] {
+		if strings.HasPrefix(strings.ToLower(value), typed) {
+			result.Completion.Values = append(result.Completion.Values, value)
+		}
+	}
+
+	if total := len(result.Completion.Values); total > completionMax {
+		result.Completion.Values = result.Completion.Values[:completionMax]
+		result.Completion.Total = total
+		result.Completion.HasMore = true
+	}
+
+	return result, nil
+}
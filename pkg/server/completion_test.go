@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  handleComplete
+// ----------------------------------------------------------------------------
+
+func Test_handleComplete(t *testing.T) {
+	t.Parallel()
+
+	session := connectInMemory(t, newServer())
+
+	for index, test := range []struct {
+		name     string
+		ref      *mcp.CompleteReference
+		argument string
+		value    string
+		want     []string
+	}{
+		{"all", &mcp.CompleteReference{Type: refResource, URI: logTailTemplate}, "lines", "", []string{"10", "50", "100", "500", "1000"}},
+		{"prefix", &mcp.CompleteReference{Type: refResource, URI: logTailTemplate}, "lines", "1", []string{"10", "100", "1000"}},
+		{"no_match", &mcp.CompleteReference{Type: refResource, URI: logTailTemplate}, "lines", "2", []string{}},
+		{"unknown_argument", &mcp.CompleteReference{Type: refResource, URI: logTailTemplate}, "size", "", []string{}},
+		{"unknown_prompt", &mcp.CompleteReference{Type: refPrompt, Name: "unknown"}, "lines", "", []string{}},
+	} {
+		t.Run(fmt.Sprintf("Test #%d: %s", index+1, test.name), func(t *testing.T) {
+			t.Parallel()
+
+			params := new(mcp.CompleteParams)
+			params.Ref = test.ref
+			params.Argument.Name = test.argument
+			params.Argument.Value = test.value
+
+			res, err := session.Complete(context.Background(), params)
+			require.NoError(t, err)
+			require.Equal(t, test.want, res.Completion.Values)
+			require.False(t, res.Completion.HasMore)
+		})
+	}
+}
+
+func Test_handleComplete_nil_ref(t *testing.T) {
+	t.Parallel()
+
+	req := new(mcp.CompleteRequest)
+	req.Params = new(mcp.CompleteParams)
+
+	res, err := handleComplete(context.Background(), req)
+	require.NoError(t, err)
+	require.Empty(t, res.Completion.Values)
+}
+
+//nolint:paralleltest // modifies completionValues
+func Test_handleComplete_max(t *testing.T) {
+	key := completionKey{refType: refPrompt, ref: "test", argument: "number"}
+
+	for i := range completionMax + 10 {
+		completionValues[key] = append(completionValues[key], strconv.Itoa(i))
+	}
+
+	defer delete(completionValues, key)
+
+	req := new(mcp.CompleteRequest)
+	req.Params = new(mcp.CompleteParams)
+	req.Params.Ref = &mcp.CompleteReference{Type: refPrompt, Name: "test"}
+	req.Params.Argument.Name = "number"
+
+	res, err := handleComplete(context.Background(), req)
+	require.NoError(t, err)
+	require.Len(t, res.Completion.Values, completionMax)
+	require.Equal(t, completionMax+10, res.Completion.Total)
+	require.True(t, res.Completion.HasMore)
+}
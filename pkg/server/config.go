@@ -0,0 +1,310 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Config file.
+//
+// E.g.: text-mirror --config ./text-mirror.yaml
+const (
+	configFileName = "config.yaml" // default config file name in the user config directory
+	configExtTOML  = ".toml"       // config files with this extension are TOML, otherwise YAML
+)
+
+// errConfigUnknownKey is returned on unknown keys in the config file, which are
+// most likely typos.
+var errConfigUnknownKey = errors.New("unknown config key")
+
+// Config is the content of the config file. Each setting is an alternative to
+// the environment variable of the same name, which takes precedence over it.
+type Config struct {
+	Server    ServerConfig    `toml:"server"    yaml:"server"`
+	Transport TransportConfig `toml:"transport" yaml:"transport"`
+	Logging   LoggingConfig   `toml:"logging"   yaml:"logging"`
+	Limits    LimitsConfig    `toml:"limits"    yaml:"limits"`
+	Tools     ToolsConfig     `toml:"tools"     yaml:"tools"`
+}
+
+// ServerConfig is the server section of the config file.
+type ServerConfig struct {
+	Profile   string           `toml:"profile"   yaml:"profile"`   // MCP_TEXT_MIRROR_PROFILE
+	Instances []InstanceConfig `toml:"instances" yaml:"instances"` // MCP_TEXT_MIRROR_INSTANCES
+}
+
+// TransportConfig is the transport section of the config file.
+type TransportConfig struct {
+	HTTPAddr       string   `toml:"http_addr"       yaml:"http_addr"`       // MCP_TEXT_MIRROR_HTTP_ADDR
+	TLSCert        string   `toml:"tls_cert"        yaml:"tls_cert"`        // MCP_TEXT_MIRROR_TLS_CERT
+	TLSKey         string   `toml:"tls_key"         yaml:"tls_key"`         // MCP_TEXT_MIRROR_TLS_KEY
+	TLSClientCA    string   `toml:"tls_client_ca"   yaml:"tls_client_ca"`   // MCP_TEXT_MIRROR_TLS_CLIENT_CA
+	AllowedOrigins []string `toml:"allowed_origins" yaml:"allowed_origins"` // MCP_TEXT_MIRROR_ALLOWED_ORIGINS
+	CORSHeaders    []string `toml:"cors_headers"    yaml:"cors_headers"`    // MCP_TEXT_MIRROR_CORS_HEADERS
+	KeepAlive      string   `toml:"keepalive"       yaml:"keepalive"`       // MCP_TEXT_MIRROR_KEEPALIVE
+	IdleTimeout    string   `toml:"idle_timeout"    yaml:"idle_timeout"`    // MCP_TEXT_MIRROR_IDLE_TIMEOUT
+	MaxBody        *int     `toml:"max_body"        yaml:"max_body"`        // MCP_TEXT_MIRROR_MAX_BODY
+	Debug          *bool    `toml:"debug"           yaml:"debug"`           // MCP_TEXT_MIRROR_DEBUG
+}
+
+// LoggingConfig is the logging section of the config file.
+type LoggingConfig struct {
+	DebugLog      string   `toml:"debug_log"       yaml:"debug_log"`       // MCP_TEXT_MIRROR_DEBUG_LOG
+	WireTap       string   `toml:"wire_tap"        yaml:"wire_tap"`        // MCP_TEXT_MIRROR_WIRE_TAP
+	LogFormat     string   `toml:"log_format"      yaml:"log_format"`      // MCP_TEXT_MIRROR_LOG_FORMAT
+	LogLevel      string   `toml:"log_level"       yaml:"log_level"`       // MCP_TEXT_MIRROR_LOG_LEVEL
+	LogMaxSize    *int     `toml:"log_max_size"    yaml:"log_max_size"`    // MCP_TEXT_MIRROR_LOG_MAX_SIZE
+	LogMaxBackups *int     `toml:"log_max_backups" yaml:"log_max_backups"` // MCP_TEXT_MIRROR_LOG_MAX_BACKUPS
+	LogMaxAge     string   `toml:"log_max_age"     yaml:"log_max_age"`     // MCP_TEXT_MIRROR_LOG_MAX_AGE
+	LogCompress   *bool    `toml:"log_compress"    yaml:"log_compress"`    // MCP_TEXT_MIRROR_LOG_COMPRESS
+	LogSample     *int     `toml:"log_sample"      yaml:"log_sample"`      // MCP_TEXT_MIRROR_LOG_SAMPLE
+	Syslog        string   `toml:"syslog"          yaml:"syslog"`          // MCP_TEXT_MIRROR_SYSLOG
+	EventLog      *bool    `toml:"event_log"       yaml:"event_log"`       // MCP_TEXT_MIRROR_EVENT_LOG
+	ErrorWebhook  string   `toml:"error_webhook"   yaml:"error_webhook"`   // MCP_TEXT_MIRROR_ERROR_WEBHOOK
+	Statsd        string   `toml:"statsd"          yaml:"statsd"`          // MCP_TEXT_MIRROR_STATSD
+	LogRedact     string   `toml:"log_redact"      yaml:"log_redact"`      // MCP_TEXT_MIRROR_LOG_REDACT
+	SlowCall      string   `toml:"slow_call"       yaml:"slow_call"`       // MCP_TEXT_MIRROR_SLOW_CALL
+	MetaKeys      []string `toml:"meta_keys"       yaml:"meta_keys"`       // MCP_TEXT_MIRROR_META_KEYS
+}
+
+// LimitsConfig is the limits section of the config file.
+type LimitsConfig struct {
+	RateLimit    *float64       `toml:"rate_limit"    yaml:"rate_limit"`    // MCP_TEXT_MIRROR_RATE_LIMIT
+	RateBurst    *int           `toml:"rate_burst"    yaml:"rate_burst"`    // MCP_TEXT_MIRROR_RATE_BURST
+	Workers      *int           `toml:"workers"       yaml:"workers"`       // MCP_TEXT_MIRROR_WORKERS
+	QueueDepth   *int           `toml:"queue_depth"   yaml:"queue_depth"`   // MCP_TEXT_MIRROR_QUEUE_DEPTH
+	CallTimeout  string         `toml:"call_timeout"  yaml:"call_timeout"`  // MCP_TEXT_MIRROR_CALL_TIMEOUT
+	MemoryBudget *int           `toml:"memory_budget" yaml:"memory_budget"` // MCP_TEXT_MIRROR_MEMORY_BUDGET
+	MemoryWait   string         `toml:"memory_wait"   yaml:"memory_wait"`   // MCP_TEXT_MIRROR_MEMORY_WAIT
+	PageSize     *int           `toml:"page_size"     yaml:"page_size"`     // MCP_TEXT_MIRROR_PAGE_SIZE
+	ClientLimits map[string]int `toml:"client_limits" yaml:"client_limits"` // MCP_TEXT_MIRROR_CLIENT_LIMITS
+}
+
+// ToolsConfig is the tools section of the config file.
+type ToolsConfig struct {
+	Admin        *bool                        `toml:"admin"       yaml:"admin"`           // MCP_TEXT_MIRROR_ADMIN
+	AdminClients []string                     `toml:"admin_clients" yaml:"admin_clients"` // MCP_TEXT_MIRROR_ADMIN_CLIENTS
+	Verify       *bool                        `toml:"verify"      yaml:"verify"`          // MCP_TEXT_MIRROR_VERIFY
+	RenderFont   string                       `toml:"render_font" yaml:"render_font"`     // MCP_TEXT_MIRROR_RENDER_FONT
+	Enabled      []string                     `toml:"enabled"     yaml:"enabled"`         // MCP_TEXT_MIRROR_TOOLS_ENABLED
+	Disabled     []string                     `toml:"disabled"    yaml:"disabled"`        // MCP_TEXT_MIRROR_TOOLS_DISABLED
+	Preset       string                       `toml:"preset"      yaml:"preset"`          // MCP_TEXT_MIRROR_TOOLS_PRESET
+	CacheSize    *int                         `toml:"cache_size"  yaml:"cache_size"`      // MCP_TEXT_MIRROR_CACHE_SIZE
+	CacheBytes   *int                         `toml:"cache_bytes" yaml:"cache_bytes"`     // MCP_TEXT_MIRROR_CACHE_BYTES
+	Locale       string                       `toml:"locale"      yaml:"locale"`          // MCP_TEXT_MIRROR_LOCALE
+	Defaults     map[string]map[string]string `toml:"defaults"    yaml:"defaults"`        // MCP_TEXT_MIRROR_TOOLS_DEFAULTS
+	Upstreams    map[string]string            `toml:"upstreams"   yaml:"upstreams"`       // MCP_TEXT_MIRROR_UPSTREAMS
+	Plugins      string                       `toml:"plugins"     yaml:"plugins"`         // MCP_TEXT_MIRROR_PLUGINS
+}
+
+// defaultConfigPath returns the path of the config file read if no --config
+// flag is given: "$XDG_CONFIG_HOME/text-mirror/config.yaml" on Unix-like
+// systems, the same under %AppData% on Windows. It returns an empty string if
+// the user config directory is unknown.
+func defaultConfigPath() string {
+	dir, err := userConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, serviceName, configFileName)
+}
+
+// loadConfig reads the config file at path. If path is empty, the file at the
+// default path is read if it exists.
+//
+// It returns nil if no path is given and the default file doesn't exist.
+func loadConfig(path string) (*Config, error) {
+	explicit := path != ""
+	if !explicit {
+		path = defaultConfigPath()
+		if path == "" {
+			return nil, nil //nolint:nilnil // no config file is not an error
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			return nil, nil //nolint:nilnil // no config file is not an error
+		}
+
+		return nil, wrapError(err, "failed to read config file")
+	}
+
+	config, err := parseConfig(data, strings.EqualFold(filepath.Ext(path), configExtTOML))
+	if err != nil {
+		return nil, wrapError(err, "invalid config file %s", path)
+	}
+
+	return config, nil
+}
+
+// parseConfig parses the content of a config file in YAML or TOML. Unknown keys
+// are rejected.
+func parseConfig(data []byte, isTOML bool) (*Config, error) {
+	config := new(Config)
+
+	if isTOML {
+		meta, err := toml.Decode(string(data), config)
+		if err != nil {
+			return nil, wrapError(err, "failed to parse TOML")
+		}
+
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return nil, wrapError(errConfigUnknownKey, "%s", undecoded[0].String())
+		}
+
+		return config, nil
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	err := decoder.Decode(config)
+	if err != nil && !errors.Is(err, io.EOF) { // empty file
+		return nil, wrapError(err, "failed to parse YAML")
+	}
+
+	return config, nil
+}
+
+// env returns the config as the values of the equivalent environment variables.
+// Settings not in the config file are omitted, and all of them without config
+// file.
+func (c *Config) env() map[string]string {
+	if c == nil {
+		return nil
+	}
+
+	env := make(map[string]string)
+
+	setString := func(name, value string) {
+		if value != "" {
+			env[name] = value
+		}
+	}
+
+	setList := func(name string, values []string) {
+		setString(name, strings.Join(values, listSep))
+	}
+
+	setInt := func(name string, value *int) {
+		if value != nil {
+			env[name] = strconv.Itoa(*value)
+		}
+	}
+
+	setString(envNameProfile, c.Server.Profile)
+	setString(envNameInstances, instancesEnv(c.Server.Instances))
+	setString(envNameHTTPAddr, c.Transport.HTTPAddr)
+	setString(envNameTLSCert, c.Transport.TLSCert)
+	setString(envNameTLSKey, c.Transport.TLSKey)
+	setString(envNameTLSClientCA, c.Transport.TLSClientCA)
+	setList(envNameAllowedOrigins, c.Transport.AllowedOrigins)
+	setList(envNameCORSHeaders, c.Transport.CORSHeaders)
+	setString(envNameKeepAlive, c.Transport.KeepAlive)
+	setString(envNameIdleTimeout, c.Transport.IdleTimeout)
+	setInt(envNameMaxBody, c.Transport.MaxBody)
+
+	if c.Transport.Debug != nil {
+		env[envNameDebugHTTP] = strconv.FormatBool(*c.Transport.Debug)
+	}
+
+	setString(envNameDebug, c.Logging.DebugLog)
+	setString(envNameWireTap, c.Logging.WireTap)
+	setString(envNameLogFormat, c.Logging.LogFormat)
+	setString(envNameLogLevel, c.Logging.LogLevel)
+	setInt(envNameLogMaxSize, c.Logging.LogMaxSize)
+	setInt(envNameLogMaxBackups, c.Logging.LogMaxBackups)
+	setString(envNameLogMaxAge, c.Logging.LogMaxAge)
+
+	if c.Logging.LogCompress != nil {
+		env[envNameLogCompress] = strconv.FormatBool(*c.Logging.LogCompress)
+	}
+
+	setInt(envNameLogSample, c.Logging.LogSample)
+	setString(envNameSyslog, c.Logging.Syslog)
+
+	if c.Logging.EventLog != nil {
+		env[envNameEventLog] = strconv.FormatBool(*c.Logging.EventLog)
+	}
+
+	setString(envNameErrorWebhook, c.Logging.ErrorWebhook)
+	setString(envNameStatsd, c.Logging.Statsd)
+	setString(envNameLogRedact, c.Logging.LogRedact)
+	setString(envNameSlowCall, c.Logging.SlowCall)
+	setList(envNameMetaKeys, c.Logging.MetaKeys)
+
+	if c.Limits.RateLimit != nil {
+		env[envNameRateLimit] = strconv.FormatFloat(*c.Limits.RateLimit, 'g', -1, 64)
+	}
+
+	setInt(envNameRateBurst, c.Limits.RateBurst)
+	setInt(envNameWorkers, c.Limits.Workers)
+	setInt(envNameQueueDepth, c.Limits.QueueDepth)
+	setString(envNameCallTimeout, c.Limits.CallTimeout)
+	setInt(envNameMemoryBudget, c.Limits.MemoryBudget)
+	setString(envNameMemoryWait, c.Limits.MemoryWait)
+	setInt(envNamePageSize, c.Limits.PageSize)
+
+	limits := make(map[string]string, len(c.Limits.ClientLimits))
+	for name, limit := range c.Limits.ClientLimits {
+		limits[name] = strconv.Itoa(limit)
+	}
+
+	setString(envNameClientLimits, joinPairs(limits, clientLimitSep, clientLimitNameSep))
+
+	if c.Tools.Admin != nil {
+		env[envNameAdmin] = strconv.FormatBool(*c.Tools.Admin)
+	}
+
+	setList(envNameAdminClients, c.Tools.AdminClients)
+
+	if c.Tools.Verify != nil {
+		env[envNameVerify] = strconv.FormatBool(*c.Tools.Verify)
+	}
+
+	setString(envNameRenderFont, c.Tools.RenderFont)
+	setList(envNameToolsEnabled, c.Tools.Enabled)
+	setList(envNameToolsDisabled, c.Tools.Disabled)
+	setString(envNameToolsPreset, c.Tools.Preset)
+	setInt(envNameCacheSize, c.Tools.CacheSize)
+	setInt(envNameCacheBytes, c.Tools.CacheBytes)
+	setString(envNameLocale, c.Tools.Locale)
+
+	defaults := make(map[string]string)
+	for tool, args := range c.Tools.Defaults {
+		for arg, value := range args {
+			defaults[tool+toolDefaultArgSep+arg] = value
+		}
+	}
+
+	setString(envNameToolDefaults, joinPairs(defaults, toolDefaultSep, toolDefaultNameSep))
+	setString(envNameUpstreams, joinPairs(c.Tools.Upstreams, upstreamSep, upstreamNameSep))
+	setString(envNamePlugins, c.Tools.Plugins)
+
+	return env
+}
+
+// joinPairs joins the map into "key=value;key=value" form in key order, with
+// the given separators.
+func joinPairs(pairs map[string]string, sep, nameSep string) string {
+	entries := make([]string, 0, len(pairs))
+	for key, value := range pairs {
+		entries = append(entries, key+nameSep+value)
+	}
+
+	slices.Sort(entries)
+
+	return strings.Join(entries, sep)
+}
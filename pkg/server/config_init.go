@@ -0,0 +1,110 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"gopkg.in/yaml.v3"
+)
+
+// Generation of the default config file.
+//
+// E.g.: text-mirror config init > ~/.config/text-mirror/config.yaml
+const (
+	cmdNameConfigInit = "init"
+
+	configIndent = 2 // indentation of the generated YAML
+	configHeader = `# Config file of text-mirror, generated by 'text-mirror config init'.
+#
+# The values are the defaults. Empty and null values leave the setting unset.
+# Environment variables override this file, and command line flags override
+# both. Check it with 'text-mirror config validate'.
+`
+)
+
+// defaultConfig returns the config with the in-code defaults. The settings
+// without a static default, such as the number of workers defaulting to
+// GOMAXPROCS, are left unset.
+func defaultConfig() *Config {
+	falseValue := false
+	queueDepth := queueDepthDefault
+	pageSize := mcp.DefaultPageSize
+	logMaxSize := logMaxSizeDefault
+	logMaxBackups := logMaxBackupsDefault
+	logSample := logSampleDefault
+
+	config := new(Config)
+	config.Logging.LogMaxSize = &logMaxSize
+	config.Logging.LogMaxBackups = &logMaxBackups
+	config.Logging.LogCompress = &falseValue
+	config.Logging.LogSample = &logSample
+	config.Logging.MetaKeys = splitList(metaKeysDefault)
+	config.Limits.QueueDepth = &queueDepth
+	config.Limits.PageSize = &pageSize
+	config.Tools.Admin = &falseValue
+	config.Tools.Verify = &falseValue
+
+	return config
+}
+
+// writeDefaultConfig writes the default config file in YAML to w, with every
+// setting commented with its description, environment variable and flag.
+func writeDefaultConfig(w io.Writer) error {
+	var root yaml.Node
+
+	err := root.Encode(defaultConfig())
+	if err != nil {
+		return wrapError(err, "failed to encode the default config")
+	}
+
+	usages := make(map[string]setting, len(settings))
+	for _, s := range settings {
+		usages[s.key] = s
+	}
+
+	sections := root.Content
+	for i := 0; i+1 < len(sections); i += 2 {
+		keys := sections[i+1].Content
+		for j := 0; j+1 < len(keys); j += 2 {
+			s := usages[sections[i].Value+settingKeySep+keys[j].Value]
+			keys[j].HeadComment = strings.ReplaceAll(s.usage, "`", "") +
+				"\nenv " + s.envName() + ", flag --" + s.flagName()
+
+			if keys[j+1].Kind == yaml.SequenceNode {
+				keys[j+1].Style = yaml.FlowStyle // [a, b]
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(configIndent)
+
+	err = encoder.Encode(&root)
+	if err != nil {
+		return wrapError(err, "failed to encode the default config")
+	}
+
+	_ = encoder.Close()
+
+	// Separate the sections by a blank line
+	var out strings.Builder
+
+	out.WriteString(configHeader)
+
+	for line := range strings.Lines(buf.String()) {
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "#") {
+			out.WriteString("\n")
+		}
+
+		out.WriteString(line)
+	}
+
+	_, err = fmt.Fprint(w, out.String())
+
+	return err
+}
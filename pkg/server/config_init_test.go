@@ -0,0 +1,53 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  config init subcommand
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // runs the command, which loads the settings
+func Test_runCommand_config_init(t *testing.T) {
+	var out bytes.Buffer
+
+	app := newTestApp(t)
+	app.Stdout = &out
+
+	require.NoError(t, app.Run(context.Background(), []string{"config", "init"}))
+
+	generated := out.String()
+	require.True(t, strings.HasPrefix(generated, configHeader))
+	require.Contains(t, generated, "\n\nlimits:\n")
+	require.Contains(t, generated, "  meta_keys: [traceparent, tracestate]\n")
+
+	// Every setting is documented
+	for _, s := range settings {
+		_, name, _ := strings.Cut(s.key, settingKeySep)
+		require.Contains(t, generated, "  # env "+s.envName()+", flag --"+s.flagName()+"\n  "+name+":", s.key)
+	}
+
+	// The generated file is valid and equivalent to the defaults
+	require.Empty(t, validateConfig(out.Bytes(), false))
+
+	config, err := parseConfig(out.Bytes(), false)
+	require.NoError(t, err)
+	require.Equal(t, defaultConfig().env(), config.env())
+	require.Equal(t, map[string]string{
+		envNameLogMaxSize:    "100",
+		envNameLogMaxBackups: "5",
+		envNameLogCompress:   "false",
+		envNameLogSample:     "1",
+		envNameMetaKeys:      metaKeysDefault,
+		envNameQueueDepth:    "64",
+		envNamePageSize:      "1000",
+		envNameAdmin:         "false",
+		envNameVerify:        "false",
+	}, config.env())
+}
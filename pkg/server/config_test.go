@@ -0,0 +1,421 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// Config file covering all the settings, in YAML and TOML.
+const (
+	testConfigYAML = `
+server:
+  profile: prod
+  instances:
+    - name: team-a
+      tools: [mirror, pipeline]
+      clients: [alice]
+      workers: 2
+transport:
+  http_addr: 127.0.0.1:8080
+  tls_cert: server.crt
+  tls_key: server.key
+  tls_client_ca: ca.crt
+  allowed_origins: [https://a.example.com, https://b.example.com]
+  cors_headers: [X-Trace-Id]
+  keepalive: 30s
+  idle_timeout: 10m
+  max_body: 1048576
+  debug: true
+logging:
+  debug_log: /tmp/text-mirror.log
+  wire_tap: wire.log
+  log_format: json
+  log_level: info
+  log_max_size: 10
+  log_max_backups: 3
+  log_max_age: 168h
+  log_compress: true
+  log_sample: 100
+  syslog: udp://127.0.0.1:514?facility=local0
+  event_log: true
+  error_webhook: https://errors.example.com/hooks/text-mirror
+  statsd: dogstatsd://127.0.0.1:8125
+  log_redact: truncate:16
+  slow_call: 500ms
+  meta_keys: [traceparent, x-request-id]
+limits:
+  rate_limit: 0.5
+  rate_burst: 2
+  workers: 4
+  queue_depth: 16
+  call_timeout: 30s
+  memory_budget: 1048576
+  memory_wait: 5s
+  page_size: 50
+  client_limits:
+    vscode: 1024
+    "*": 64
+tools:
+  admin: true
+  admin_clients: [ops, anonymous]
+  verify: false
+  render_font: font.ttf
+  enabled: [mirror, mirror_batch]
+  disabled: [admin]
+  preset: full
+  cache_size: 100
+  cache_bytes: 1048576
+  locale: tr
+  defaults:
+    mirror:
+      render: png
+  upstreams:
+    fs: mcp-fs --ro
+    web: http://127.0.0.1:9000/mcp
+  plugins: plugins
+`
+	testConfigTOML = `
+[server]
+profile = "prod"
+
+[[server.instances]]
+name = "team-a"
+tools = ["mirror", "pipeline"]
+clients = ["alice"]
+workers = 2
+
+[transport]
+http_addr = "127.0.0.1:8080"
+tls_cert = "server.crt"
+tls_key = "server.key"
+tls_client_ca = "ca.crt"
+allowed_origins = ["https://a.example.com", "https://b.example.com"]
+cors_headers = ["X-Trace-Id"]
+keepalive = "30s"
+idle_timeout = "10m"
+max_body = 1048576
+debug = true
+
+[logging]
+debug_log = "/tmp/text-mirror.log"
+wire_tap = "wire.log"
+log_format = "json"
+log_level = "info"
+log_max_size = 10
+log_max_backups = 3
+log_max_age = "168h"
+log_compress = true
+log_sample = 100
+syslog = "udp://127.0.0.1:514?facility=local0"
+event_log = true
+error_webhook = "https://errors.example.com/hooks/text-mirror"
+statsd = "dogstatsd://127.0.0.1:8125"
+log_redact = "truncate:16"
+slow_call = "500ms"
+meta_keys = ["traceparent", "x-request-id"]
+
+[limits]
+rate_limit = 0.5
+rate_burst = 2
+workers = 4
+queue_depth = 16
+call_timeout = "30s"
+memory_budget = 1048576
+memory_wait = "5s"
+page_size = 50
+client_limits = { vscode = 1024, "*" = 64 }
+
+[tools]
+admin = true
+admin_clients = ["ops", "anonymous"]
+verify = false
+render_font = "font.ttf"
+enabled = ["mirror", "mirror_batch"]
+disabled = ["admin"]
+preset = "full"
+cache_size = 100
+cache_bytes = 1048576
+locale = "tr"
+defaults = { mirror = { render = "png" } }
+upstreams = { fs = "mcp-fs --ro", web = "http://127.0.0.1:9000/mcp" }
+plugins = "plugins"
+`
+)
+
+// unsetEnv unsets the environment variables for the test and reloads the
+// settings from the environment. Both are restored on cleanup.
+func unsetEnv(t *testing.T, names ...string) {
+	t.Helper()
+	keepSettings(t)
+
+	for _, name := range names {
+		t.Setenv(name, "") // restores the original value on cleanup
+		require.NoError(t, os.Unsetenv(name))
+	}
+
+	reloadEnv()
+}
+
+// setEnv sets the environment variable as t.Setenv does and reloads the
+// settings from the environment. Both are restored on cleanup.
+func setEnv(t *testing.T, name, value string) {
+	t.Helper()
+	keepSettings(t)
+
+	t.Setenv(name, value)
+	reloadEnv()
+}
+
+// keepSettings restores the loaded settings on cleanup, for the tests storing
+// their own, such as by running the command. It runs after the cleanups
+// registered later, which restore the environment variables.
+func keepSettings(t *testing.T) {
+	t.Helper()
+
+	previous := loadedSettings()
+
+	t.Cleanup(func() { currentSettings.Store(previous) })
+}
+
+// reloadEnv reloads the environment variables into the loaded settings,
+// keeping the values of the config file and the flags.
+func reloadEnv() {
+	storeSettings(func(v *settingValues) *settingValues {
+		return newSettingValues(v.config, v.flags)
+	})
+}
+
+// ----------------------------------------------------------------------------
+//  parseConfig / Config.env
+// ----------------------------------------------------------------------------
+
+func Test_parseConfig(t *testing.T) {
+	t.Parallel()
+
+	want := map[string]string{
+		envNameProfile:        "prod",
+		envNameInstances:      `[{"name":"team-a","tools":["mirror","pipeline"],"clients":["alice"],"workers":2}]`,
+		envNameHTTPAddr:       "127.0.0.1:8080",
+		envNameTLSCert:        "server.crt",
+		envNameTLSKey:         "server.key",
+		envNameTLSClientCA:    "ca.crt",
+		envNameAllowedOrigins: "https://a.example.com,https://b.example.com",
+		envNameCORSHeaders:    "X-Trace-Id",
+		envNameKeepAlive:      "30s",
+		envNameIdleTimeout:    "10m",
+		envNameMaxBody:        "1048576",
+		envNameDebugHTTP:      "true",
+		envNameDebug:          "/tmp/text-mirror.log",
+		envNameWireTap:        "wire.log",
+		envNameLogFormat:      "json",
+		envNameLogLevel:       "info",
+		envNameLogMaxSize:     "10",
+		envNameLogMaxBackups:  "3",
+		envNameLogMaxAge:      "168h",
+		envNameLogCompress:    "true",
+		envNameLogSample:      "100",
+		envNameSyslog:         "udp://127.0.0.1:514?facility=local0",
+		envNameEventLog:       "true",
+		envNameErrorWebhook:   "https://errors.example.com/hooks/text-mirror",
+		envNameStatsd:         "dogstatsd://127.0.0.1:8125",
+		envNameLogRedact:      "truncate:16",
+		envNameSlowCall:       "500ms",
+		envNameMetaKeys:       "traceparent,x-request-id",
+		envNameRateLimit:      "0.5",
+		envNameRateBurst:      "2",
+		envNameWorkers:        "4",
+		envNameQueueDepth:     "16",
+		envNameCallTimeout:    "30s",
+		envNameMemoryBudget:   "1048576",
+		envNameMemoryWait:     "5s",
+		envNamePageSize:       "50",
+		envNameClientLimits:   "*=64;vscode=1024",
+		envNameAdmin:          "true",
+		envNameAdminClients:   "ops,anonymous",
+		envNameVerify:         "false",
+		envNameRenderFont:     "font.ttf",
+		envNameToolsEnabled:   "mirror,mirror_batch",
+		envNameToolsDisabled:  "admin",
+		envNameToolsPreset:    "full",
+		envNameCacheSize:      "100",
+		envNameCacheBytes:     "1048576",
+		envNameLocale:         "tr",
+		envNameToolDefaults:   "mirror.render=png",
+		envNameUpstreams:      "fs=mcp-fs --ro;web=http://127.0.0.1:9000/mcp",
+		envNamePlugins:        "plugins",
+	}
+
+	for _, test := range []struct {
+		name   string
+		data   string
+		isTOML bool
+	}{
+		{"yaml", testConfigYAML, false},
+		{"toml", testConfigTOML, true},
+	} {
+		config, err := parseConfig([]byte(test.data), test.isTOML)
+		require.NoError(t, err, test.name)
+		require.Equal(t, want, config.env(), test.name)
+	}
+
+	// Parsed values must be valid for the env var parsers
+	upstreams, err := parseUpstreams(want[envNameUpstreams])
+	require.NoError(t, err)
+	require.Len(t, upstreams, 2)
+}
+
+func Test_parseConfig_empty(t *testing.T) {
+	t.Parallel()
+
+	for _, isTOML := range []bool{false, true} {
+		config, err := parseConfig(nil, isTOML)
+		require.NoError(t, err)
+		require.Empty(t, config.env(), "empty config should set nothing")
+	}
+}
+
+func Test_parseConfig_invalid(t *testing.T) {
+	t.Parallel()
+
+	for index, test := range []struct {
+		name   string
+		data   string
+		isTOML bool
+	}{
+		{"yaml_unknown_key", "limits:\n  worker: 4\n", false},
+		{"yaml_wrong_type", "limits:\n  workers: many\n", false},
+		{"yaml_syntax", "limits: [", false},
+		{"toml_unknown_key", "[limits]\nworker = 4\n", true},
+		{"toml_wrong_type", "[limits]\nworkers = \"many\"\n", true},
+		{"toml_syntax", "[limits", true},
+	} {
+		_, err := parseConfig([]byte(test.data), test.isTOML)
+		require.Error(t, err, fmt.Sprintf("Test #%d: %s", index+1, test.name))
+	}
+
+	_, err := parseConfig([]byte("[tools]\nadmn = true\n"), true)
+	require.ErrorIs(t, err, errConfigUnknownKey)
+	require.ErrorContains(t, err, "tools.admn")
+}
+
+// ----------------------------------------------------------------------------
+//  loadConfig
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_loadConfig(t *testing.T) {
+	dir := t.TempDir()
+	setEnv(t, "XDG_CONFIG_HOME", dir)
+
+	// No config file at the default path is fine
+	config, err := loadConfig("")
+	require.NoError(t, err)
+	require.Nil(t, config)
+
+	// Missing config file given explicitly is not
+	_, err = loadConfig(filepath.Join(dir, "missing.yaml"))
+	require.ErrorContains(t, err, "failed to read config file")
+
+	// By extension
+	tomlPath := filepath.Join(dir, "config.TOML")
+	require.NoError(t, os.WriteFile(tomlPath, []byte(testConfigTOML), 0o600))
+
+	config, err = loadConfig(tomlPath)
+	require.NoError(t, err)
+	require.Equal(t, "127.0.0.1:8080", config.Transport.HTTPAddr)
+
+	invalidPath := filepath.Join(dir, "invalid.yml")
+	require.NoError(t, os.WriteFile(invalidPath, []byte("unknown: 1\n"), 0o600))
+
+	_, err = loadConfig(invalidPath)
+	require.ErrorContains(t, err, "invalid config file "+invalidPath)
+
+	// Default path
+	defaultPath := defaultConfigPath()
+	if !strings.HasPrefix(defaultPath, dir) {
+		t.Skip("user config directory doesn't follow XDG_CONFIG_HOME on this OS")
+	}
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(defaultPath), 0o700))
+	require.NoError(t, os.WriteFile(defaultPath, []byte("limits:\n  workers: 3\n"), 0o600))
+
+	config, err = loadConfig("")
+	require.NoError(t, err)
+	require.Equal(t, 3, *config.Limits.Workers)
+}
+
+// ----------------------------------------------------------------------------
+//  newSettingValues
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_newSettingValues(t *testing.T) {
+	unsetEnv(t, envNameProfile, envNameWorkers, envNameQueueDepth, envNamePageSize, envNameRateLimit)
+	setEnv(t, envNameQueueDepth, "8")
+	setEnv(t, envNameRateLimit, "")
+
+	config, err := parseConfig([]byte("limits:\n  workers: 4\n  queue_depth: 16\n  rate_limit: 2\n"), false)
+	require.NoError(t, err)
+	require.Nil(t, (*Config)(nil).env(), "no config file should set nothing")
+
+	values := newSettingValues(config.env(), map[string]string{envNameWorkers: "2"})
+	require.Equal(t, map[string]string{envNameWorkers: "2", envNameQueueDepth: "8"}, values.values,
+		"env vars should override the config file, even if empty, and the flags both")
+	require.True(t, values.overridden(envNameWorkers))
+	require.False(t, values.overridden(envNameQueueDepth))
+
+	_, ok := values.values[envNamePageSize]
+	require.False(t, ok, "settings not in the config file should stay unset")
+
+	// Runtime overrides, such as of the admin tool
+	values = values.withFlags(map[string]string{envNameQueueDepth: "", envNamePageSize: "10"})
+	require.Equal(t, map[string]string{envNameWorkers: "2", envNamePageSize: "10"}, values.values,
+		"empty values should unset the settings")
+
+	values = values.withConfig(nil)
+	require.Equal(t, map[string]string{envNameWorkers: "2", envNamePageSize: "10"}, values.values,
+		"the flags should outlive the config file")
+}
+
+// ----------------------------------------------------------------------------
+//  runCommand with config file
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_runCommand_config(t *testing.T) {
+	unsetEnv(t, envNameWorkers, envNameQueueDepth)
+
+	var workers string
+
+	app := newTestApp(t)
+	app.RunServer = func(_ context.Context, _ *mcp.Server) error {
+		workers = settingValue(envNameWorkers)
+
+		return nil
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("limits:\n  workers: 3\n"), 0o600))
+
+	require.NoError(t, app.Run(context.Background(), []string{"--config", path}))
+	require.Equal(t, "3", workers, "config file should be applied before running the server")
+
+	// Invalid values in the config file are reported like the env vars
+	require.NoError(t, os.WriteFile(path, []byte("limits:\n  queue_depth: -1\n"), 0o600))
+
+	err := newTestApp(t).Run(context.Background(), []string{"--config", path})
+	require.ErrorIs(t, err, errInvalidNumber)
+
+	err = newTestApp(t).Run(context.Background(), []string{"--config", filepath.Join(t.TempDir(), "missing.yaml")})
+	require.ErrorContains(t, err, "failed to read config file")
+
+	err = newTestApp(t).Run(context.Background(), []string{"--config"})
+	require.ErrorContains(t, err, "flag needs an argument")
+}
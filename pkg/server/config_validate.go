@@ -0,0 +1,271 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Config subcommands. E.g.: text-mirror config validate ./config.yaml
+const (
+	cmdNameConfig         = "config"
+	cmdNameConfigValidate = "validate"
+)
+
+// Predefined errors of the config subcommands.
+var (
+	errConfigUsage   = errors.New("usage: text-mirror config <validate [file]|init>")
+	errConfigInvalid = errors.New("invalid config file")
+)
+
+// Patterns to find the line of the problems in the config files.
+//
+//nolint:gochecknoglobals // compiled once
+var (
+	reErrorLine   = regexp.MustCompile(`line (\d+)`)                                    // in the messages of the parsers
+	reTOMLSection = regexp.MustCompile(`^\s*\[\s*([A-Za-z0-9_-]+)\s*\]`)                // [section]
+	reTOMLKey     = regexp.MustCompile(`^\s*"?([A-Za-z0-9_-]+)"?\s*=`)                  // key = value
+	reParserName  = regexp.MustCompile(`^((yaml|toml): )?(line \d+(?: \([^)]*\))?: )?`) // prefix of the parser errors
+	reYAMLUnknown = regexp.MustCompile(`^field (\S+) not found in type`)                // unknown key error of the YAML parser
+)
+
+// configProblem is a problem found in the config file.
+type configProblem struct {
+	line    int    // line in the file starting at 1. 0 if unknown
+	key     string // config key such as "limits.workers". empty if unknown
+	message string // description of the problem
+}
+
+// runConfig is the "config" subcommand writing its report to w. configPath is
+// the --config flag.
+func runConfig(args []string, configPath string, w io.Writer) error {
+	if len(args) == 0 {
+		return errConfigUsage
+	}
+
+	switch args[0] {
+	case cmdNameConfigValidate:
+		if len(args) > 1 {
+			configPath = args[1]
+		}
+
+		return validateConfigFile(w, configPath)
+	case cmdNameConfigInit:
+		return writeDefaultConfig(w)
+	default:
+		return errConfigUsage
+	}
+}
+
+// validateConfigFile checks the config file at path, or the default one if
+// empty, and prints all the problems found with the lines of the file to w.
+// It returns errConfigInvalid if any, so that the command exits nonzero.
+func validateConfigFile(w io.Writer, path string) error {
+	if path == "" {
+		path = defaultConfigPath()
+	}
+
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return wrapError(err, "failed to read config file")
+	}
+
+	problems := validateConfig(data, strings.EqualFold(filepath.Ext(path), configExtTOML))
+	if len(problems) == 0 {
+		_, err = fmt.Fprintln(w, path+": OK")
+
+		return err
+	}
+
+	lines := strings.Split(string(data), "\n")
+
+	for _, problem := range problems {
+		message := problem.message
+		if problem.key != "" {
+			message = problem.key + ": " + message
+		}
+
+		if problem.line < 1 || problem.line > len(lines) {
+			_, _ = fmt.Fprintf(w, "%s: %s\n", path, message)
+
+			continue
+		}
+
+		_, _ = fmt.Fprintf(w, "%s:%d: %s\n", path, problem.line, message)
+		_, _ = fmt.Fprintf(w, "%6d | %s\n", problem.line, strings.TrimRight(lines[problem.line-1], "\r"))
+	}
+
+	return fmt.Errorf("%w: %d problem(s) in %s", errConfigInvalid, len(problems), path)
+}
+
+// validateConfig parses the content of a config file in YAML or TOML and checks
+// the values of the settings in it. It returns all the problems found, in the
+// order of the lines.
+//
+// The values are checked as if they were the only settings, i.e. regardless of
+// the environment variables.
+func validateConfig(data []byte, isTOML bool) []configProblem {
+	config, problems, ok := decodeConfig(data, isTOML)
+	lines := keyLines(data, isTOML)
+
+	// Name the keys of the parser errors by their lines
+	keys := make(map[int]string, len(lines))
+	for key, line := range lines {
+		if strings.Contains(key, settingKeySep) {
+			keys[line] = key
+		}
+	}
+
+	for index, problem := range problems {
+		if problem.key == "" {
+			problems[index].key = keys[problem.line]
+		}
+	}
+
+	if !ok {
+		return problems // nothing to check further
+	}
+
+	env := config.env()
+	values := mergeSettings(env, nil, nil) // of the config file only
+	reported := make(map[string]bool)
+
+	for _, s := range settings {
+		if _, ok := env[s.envName()]; !ok || s.check == nil {
+			continue
+		}
+
+		err := s.check(values)
+		if err == nil || reported[err.Error()] { // e.g. the TLS files are checked together
+			continue
+		}
+
+		reported[err.Error()] = true
+
+		problems = append(problems, configProblem{line: lines[s.key], key: s.key, message: err.Error()})
+	}
+
+	slices.SortStableFunc(problems, func(a, b configProblem) int { return a.line - b.line })
+
+	return problems
+}
+
+// decodeConfig decodes the config file, collecting the unknown keys and the
+// values of wrong types as problems. ok is false if the file could not be
+// decoded, such as on syntax errors.
+func decodeConfig(data []byte, isTOML bool) (*Config, []configProblem, bool) {
+	config := new(Config)
+
+	if isTOML {
+		meta, err := toml.Decode(string(data), config)
+		if err != nil {
+			var parseErr toml.ParseError
+			if errors.As(err, &parseErr) {
+				return nil, []configProblem{{line: parseErr.Position.Line, key: "", message: parseErr.Message}}, false
+			}
+
+			return nil, []configProblem{parserProblem(err.Error())}, false
+		}
+
+		lines := keyLines(data, true)
+
+		var problems []configProblem
+
+		for _, key := range meta.Undecoded() {
+			problems = append(problems, configProblem{
+				line:    lines[key.String()],
+				key:     key.String(),
+				message: errConfigUnknownKey.Error(),
+			})
+		}
+
+		return config, problems, true
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	err := decoder.Decode(config)
+
+	var typeErr *yaml.TypeError
+
+	switch {
+	case err == nil, errors.Is(err, io.EOF): // empty file
+		return config, nil, true
+	case errors.As(err, &typeErr):
+		problems := make([]configProblem, 0, len(typeErr.Errors))
+		for _, message := range typeErr.Errors {
+			problems = append(problems, parserProblem(message))
+		}
+
+		return config, problems, true
+	default:
+		return nil, []configProblem{parserProblem(err.Error())}, false
+	}
+}
+
+// parserProblem returns the problem of the error message of the YAML or TOML
+// parser, such as "yaml: line 3: mapping values are not allowed".
+func parserProblem(message string) configProblem {
+	problem := configProblem{line: 0, key: "", message: reParserName.ReplaceAllString(message, "")}
+
+	if match := reErrorLine.FindStringSubmatch(message); match != nil {
+		problem.line, _ = strconv.Atoi(match[1])
+	}
+
+	if reYAMLUnknown.MatchString(problem.message) {
+		problem.message = errConfigUnknownKey.Error()
+	}
+
+	return problem
+}
+
+// keyLines returns the lines of the settings in the config file by key, such
+// as "limits.workers".
+func keyLines(data []byte, isTOML bool) map[string]int {
+	lines := make(map[string]int)
+
+	if isTOML {
+		section := ""
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+
+		for line := 1; scanner.Scan(); line++ {
+			if match := reTOMLSection.FindStringSubmatch(scanner.Text()); match != nil {
+				section = match[1]
+			} else if match := reTOMLKey.FindStringSubmatch(scanner.Text()); match != nil {
+				lines[section+settingKeySep+match[1]] = line
+			}
+		}
+
+		return lines
+	}
+
+	var root yaml.Node
+
+	if yaml.Unmarshal(data, &root) != nil || len(root.Content) == 0 {
+		return lines
+	}
+
+	sections := root.Content[0].Content
+	for i := 0; i+1 < len(sections); i += 2 {
+		lines[sections[i].Value] = sections[i].Line
+
+		keys := sections[i+1].Content
+		for j := 0; sections[i+1].Kind == yaml.MappingNode && j+1 < len(keys); j += 2 {
+			lines[sections[i].Value+settingKeySep+keys[j].Value] = keys[j].Line
+		}
+	}
+
+	return lines
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  validateConfig
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_validateConfig(t *testing.T) {
+	// Environment variables should not affect the validation of the file
+	setEnv(t, envNameRateBurst, "-1")
+	setEnv(t, envNameWorkers, "many")
+
+	for index, test := range []struct {
+		name   string
+		data   string
+		isTOML bool
+		want   []configProblem
+	}{
+		{"empty", "", false, nil},
+		{"valid_yaml", "limits:\n  workers: 2\n  rate_limit: 0.5\n", false, nil},
+		{"valid_toml", "[limits]\nworkers = 2\n[tools]\nenabled = [\"mirror\"]\n", true, nil},
+		{
+			"yaml_problems",
+			"transport:\n  http_addr: localhost\nlimits:\n  workers: -1\n  queue_depth: many\n  page_sise: 10\n",
+			false,
+			[]configProblem{
+				{2, "transport.http_addr", `invalid MCP_TEXT_MIRROR_HTTP_ADDR "localhost": ` + errInvalidAddr.Error()},
+				{4, "limits.workers", `invalid MCP_TEXT_MIRROR_WORKERS "-1": ` + errInvalidNumber.Error()},
+				{5, "limits.queue_depth", "cannot unmarshal !!str `many` into int"},
+				{6, "limits.page_sise", errConfigUnknownKey.Error()},
+			},
+		},
+		{
+			"toml_problems",
+			"[limits]\nworkers = -1\n\n[tools]\ndisabled = [\"mirorr\"]\nverfy = true\n",
+			true,
+			[]configProblem{
+				{2, "limits.workers", `invalid MCP_TEXT_MIRROR_WORKERS "-1": ` + errInvalidNumber.Error()},
+				{5, "tools.disabled", `unknown tool: "mirorr". must be one of ` + strings.Join(builtinTools, ", ") + `, or an upstream or plugin tool with its prefix`},
+				{6, "tools.verfy", errConfigUnknownKey.Error()},
+			},
+		},
+		{
+			"toml_type_error",
+			"[tools]\nadmin = \"yes\"\n",
+			true,
+			[]configProblem{{2, "tools.admin", "incompatible types: TOML value has type string; destination has type boolean"}},
+		},
+		{
+			"yaml_syntax_error",
+			"limits:\n  workers: 1\n   x: [\n",
+			false,
+			[]configProblem{{3, "", "mapping values are not allowed in this context"}},
+		},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		require.Equal(t, test.want, validateConfig([]byte(test.data), test.isTOML), name)
+	}
+
+	require.Equal(t, "-1", settingValue(envNameRateBurst), "loaded settings should be restored")
+	require.Equal(t, "many", settingValue(envNameWorkers), "loaded settings should be restored")
+	require.Empty(t, settingValue(envNameHTTPAddr), "settings of the file should not be left loaded")
+}
+
+func Test_validateConfig_toml_syntax_error(t *testing.T) {
+	t.Parallel()
+
+	problems := validateConfig([]byte("[limits]\nworkers = \n"), true)
+	require.Len(t, problems, 1)
+	require.Equal(t, 2, problems[0].line)
+	require.NotEmpty(t, problems[0].message)
+}
+
+// ----------------------------------------------------------------------------
+//  config validate subcommand
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // runs the command, which loads the settings
+func Test_runCommand_config_validate(t *testing.T) {
+	dir := t.TempDir()
+	validPath := filepath.Join(dir, "valid.yaml")
+	invalidPath := filepath.Join(dir, "invalid.toml")
+
+	require.NoError(t, os.WriteFile(validPath, []byte("limits:\n  workers: 2\n"), 0o600))
+	require.NoError(t, os.WriteFile(invalidPath, []byte("[limits]\nworkers = -1\n"), 0o600))
+
+	var out bytes.Buffer
+
+	app := newTestApp(t)
+	app.Stdout = &out
+
+	// Valid file given as argument
+	require.NoError(t, app.Run(context.Background(), []string{"config", "validate", validPath}))
+	require.Equal(t, validPath+": OK\n", out.String())
+
+	// Invalid file given by --config, not loaded before validation
+	out.Reset()
+
+	err := app.Run(context.Background(), []string{"--config", invalidPath, "config", "validate"})
+	require.ErrorIs(t, err, errConfigInvalid)
+	require.ErrorContains(t, err, "1 problem(s)")
+	require.Equal(t, invalidPath+":2: limits.workers: invalid MCP_TEXT_MIRROR_WORKERS \"-1\": "+
+		errInvalidNumber.Error()+"\n     2 | workers = -1\n", out.String())
+
+	// Missing file and usage errors
+	err = app.Run(context.Background(), []string{"config", "validate", filepath.Join(dir, "missing.yaml")})
+	require.ErrorIs(t, err, os.ErrNotExist)
+	require.ErrorIs(t, app.Run(context.Background(), []string{"config"}), errConfigUsage)
+	require.ErrorIs(t, app.Run(context.Background(), []string{"config", "check"}), errConfigUsage)
+}
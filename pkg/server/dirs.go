@@ -0,0 +1,83 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Default directories of the config and log files. The XDG Base Directory
+// Specification is followed on Unix-like systems, including macOS if the XDG
+// variables are set.
+const (
+	envNameXDGConfigHome = "XDG_CONFIG_HOME"
+	envNameXDGDataHome   = "XDG_DATA_HOME"
+	envNameLocalAppData  = "LocalAppData" // Windows
+	logDirPerm           = os.FileMode(0o750)
+)
+
+// errNoLogDir is returned if the default log directory can't be determined.
+var errNoLogDir = errors.New("no default log directory")
+
+// userConfigDir returns the directory of the user's config files:
+// $XDG_CONFIG_HOME if set to an absolute path, otherwise "~/.config" on
+// Unix-like systems, "~/Library/Application Support" on macOS and %AppData% on
+// Windows.
+func userConfigDir() (string, error) {
+	if dir := os.Getenv(envNameXDGConfigHome); runtime.GOOS != "windows" && filepath.IsAbs(dir) {
+		return dir, nil
+	}
+
+	return os.UserConfigDir()
+}
+
+// userLogDir returns the directory of the user's log files on goos:
+// $XDG_DATA_HOME if set to an absolute path, otherwise "~/.local/share" on
+// Unix-like systems, "~/Library/Logs" on macOS and %LocalAppData% on Windows.
+func userLogDir(goos string) (string, error) {
+	if goos == "windows" {
+		dir := os.Getenv(envNameLocalAppData)
+		if dir == "" {
+			return "", fmt.Errorf("%w: %%%s%% is not set", errNoLogDir, envNameLocalAppData)
+		}
+
+		return dir, nil
+	}
+
+	if dir := os.Getenv(envNameXDGDataHome); filepath.IsAbs(dir) {
+		return dir, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errNoLogDir, err)
+	}
+
+	if goos == "darwin" || goos == "ios" {
+		return filepath.Join(home, "Library", "Logs"), nil
+	}
+
+	return filepath.Join(home, ".local", "share"), nil
+}
+
+// defaultLogDir returns the directory of the log file if not given as an
+// absolute path, i.e. "text-mirror" under the user's log directory. It falls
+// back to the current directory if the user's log directory is unknown.
+func defaultLogDir() string {
+	dir, err := userLogDir(runtime.GOOS)
+	if err != nil {
+		return logDir
+	}
+
+	return filepath.Join(dir, serviceName)
+}
+
+// isInLogDir returns true if path is in the default log directory.
+func isInLogDir(path string) bool {
+	rel, err := filepath.Rel(defaultLogDir(), path)
+
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  userConfigDir
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_userConfigDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("XDG_CONFIG_HOME is ignored on Windows")
+	}
+
+	configHome := t.TempDir()
+	setEnv(t, envNameXDGConfigHome, configHome)
+
+	got, err := userConfigDir()
+	require.NoError(t, err)
+	require.Equal(t, configHome, got)
+	require.Equal(t, filepath.Join(configHome, serviceName, configFileName), defaultConfigPath())
+}
+
+// ----------------------------------------------------------------------------
+//  userLogDir
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_userLogDir(t *testing.T) {
+	home := t.TempDir()
+	setEnv(t, "HOME", home)
+	setEnv(t, "USERPROFILE", home) // Windows
+
+	for index, test := range []struct {
+		name     string
+		goos     string
+		env      map[string]string
+		want     string
+		wantFail bool
+	}{
+		{"linux", "linux", nil, filepath.Join(home, ".local", "share"), false},
+		{"linux_xdg", "linux", map[string]string{envNameXDGDataHome: "/var/data"}, "/var/data", false},
+		{"linux_relative_xdg", "linux", map[string]string{envNameXDGDataHome: "data"}, filepath.Join(home, ".local", "share"), false},
+		{"macos", "darwin", nil, filepath.Join(home, "Library", "Logs"), false},
+		{"macos_xdg", "darwin", map[string]string{envNameXDGDataHome: "/var/data"}, "/var/data", false},
+		{"windows", "windows", map[string]string{envNameLocalAppData: `C:\Users\me\AppData\Local`}, `C:\Users\me\AppData\Local`, false},
+		{"windows_unset", "windows", nil, "", true},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		unsetEnv(t, envNameXDGDataHome, envNameLocalAppData)
+
+		for key, value := range test.env {
+			setEnv(t, key, value)
+		}
+
+		got, err := userLogDir(test.goos)
+		if test.wantFail {
+			require.ErrorIs(t, err, errNoLogDir, name)
+
+			continue
+		}
+
+		require.NoError(t, err, name)
+		require.Equal(t, test.want, got, name)
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  logOutput
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_logOutput_creates_directory(t *testing.T) {
+	dataHome := t.TempDir()
+	setEnv(t, envNameXDGDataHome, dataHome)
+	setEnv(t, envNameLocalAppData, dataHome)
+
+	path := filepath.Join(dataHome, serviceName, logName)
+
+	file := logOutput(true, path)
+	require.NotEqual(t, os.Stderr, file, "log file should be opened")
+	require.NoError(t, file.Close())
+	require.FileExists(t, path)
+
+	// Directories outside the user's log directory are not created
+	path = filepath.Join(t.TempDir(), "missing", logName)
+
+	require.Equal(t, os.Stderr, logOutput(true, path))
+	require.NoDirExists(t, filepath.Dir(path))
+}
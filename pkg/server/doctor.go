@@ -0,0 +1,223 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Self-test. E.g.: text-mirror --config ./config.yaml doctor
+const (
+	cmdNameDoctor = "doctor"
+
+	doctorPass = "PASS"
+	doctorFail = "FAIL"
+	doctorSkip = "SKIP"
+)
+
+// errDoctorFailed is returned if any check of the doctor subcommand failed, so
+// that the command exits nonzero.
+var errDoctorFailed = errors.New("doctor found problems")
+
+// errDoctorSkipped is returned by the checks which don't apply to the settings.
+var errDoctorSkipped = errors.New("skipped")
+
+// errMirrorMismatch is returned if the mirror tool returned an unexpected text.
+var errMirrorMismatch = errors.New("unexpected mirrored text")
+
+// doctorMirrorCases are the round-trip checks of the mirror tool, covering the
+// grapheme clusters which naive reversal breaks.
+//
+//nolint:gochecknoglobals // read-only table
+var doctorMirrorCases = []struct {
+	name string
+	text string
+	want string
+}{
+	{"emoji ZWJ sequence", "a👨‍👩‍👧‍👦b", "b👨‍👩‍👧‍👦a"},
+	{"emoji skin tone modifier", "👍🏽!", "!👍🏽"},
+	{"regional indicator flags", "🇯🇵🇺🇸", "🇺🇸🇯🇵"},
+	{"combining marks", "e\u0301o\u0308", "o\u0308e\u0301"},
+	{"CRLF line break", "a\r\nb", "b\r\na"},
+}
+
+// doctorCheck is a check of the doctor subcommand. run returns the detail of
+// the result, or errDoctorSkipped if the check doesn't apply.
+type doctorCheck struct {
+	name string
+	run  func(ctx context.Context) (string, error)
+}
+
+// runDoctor is the "doctor" subcommand. It checks the settings, mirrors texts
+// with tricky graphemes through an in-process client and server pair, and
+// checks the log file and the transport as configured, without serving. The
+// report is printed to w.
+func runDoctor(ctx context.Context, w io.Writer) error {
+	checks := []doctorCheck{{"settings", checkDoctorSettings}}
+
+	session, closeSession, err := connectInProcess(ctx)
+	if err != nil {
+		checks = append(checks, doctorCheck{"in-process session", func(context.Context) (string, error) {
+			return "", err
+		}})
+	} else {
+		defer closeSession()
+
+		for _, test := range doctorMirrorCases {
+			checks = append(checks, doctorCheck{"mirror: " + test.name, func(ctx context.Context) (string, error) {
+				return checkDoctorMirror(ctx, session, test.text, test.want)
+			}})
+		}
+	}
+
+	checks = append(checks, doctorCheck{"log file", checkDoctorLogFile}, doctorCheck{"transport", checkDoctorTransport})
+
+	counts := make(map[string]int)
+
+	for _, check := range checks {
+		detail, err := check.run(ctx)
+
+		status := doctorPass
+
+		switch {
+		case errors.Is(err, errDoctorSkipped):
+			status = doctorSkip
+			detail = strings.TrimPrefix(err.Error(), errDoctorSkipped.Error()+": ")
+		case err != nil:
+			status = doctorFail
+			detail = err.Error()
+		}
+
+		counts[status]++
+
+		if detail != "" {
+			detail = ": " + detail
+		}
+
+		_, _ = fmt.Fprintf(w, "%s  %s%s\n", status, check.name, detail)
+	}
+
+	_, _ = fmt.Fprintf(w, "\n%d passed, %d failed, %d skipped\n", counts[doctorPass], counts[doctorFail], counts[doctorSkip])
+
+	if counts[doctorFail] > 0 {
+		return fmt.Errorf("%w: %d check(s) failed", errDoctorFailed, counts[doctorFail])
+	}
+
+	return nil
+}
+
+// checkDoctorSettings checks the settings as loadSettings does at startup.
+func checkDoctorSettings(context.Context) (string, error) {
+	return "", loadSettings()
+}
+
+// checkDoctorMirror mirrors the text with the mirror tool and checks the
+// result, then mirrors it back to check the round trip.
+func checkDoctorMirror(ctx context.Context, session *mcp.ClientSession, text, want string) (string, error) {
+	if allowed, err := GetToolFilter(); err == nil && !allowed(toolName) {
+		return "", fmt.Errorf("%w: %s tool is disabled", errDoctorSkipped, toolName)
+	}
+
+	mirror := func(text string) (string, error) {
+		params := new(mcp.CallToolParams)
+		params.Name = toolName
+		params.Arguments = map[string]any{"text": text}
+
+		res, err := session.CallTool(ctx, params)
+		if err != nil {
+			return "", wrapError(err, "failed to call %s", toolName)
+		}
+
+		if len(res.Content) == 0 {
+			return "", fmt.Errorf("%w: no content of %q", errMirrorMismatch, text)
+		}
+
+		content, ok := res.Content[0].(*mcp.TextContent)
+		if res.IsError || !ok {
+			return "", fmt.Errorf("%w: %v", errMirrorMismatch, res.Content)
+		}
+
+		return content.Text, nil
+	}
+
+	got, err := mirror(text)
+	if err != nil {
+		return "", err
+	}
+
+	if got != want {
+		return "", fmt.Errorf("%w: %q => %q, want %q", errMirrorMismatch, text, got, want)
+	}
+
+	back, err := mirror(got)
+	if err != nil {
+		return "", err
+	}
+
+	if back != text {
+		return "", fmt.Errorf("%w: round trip of %q => %q", errMirrorMismatch, text, back)
+	}
+
+	return fmt.Sprintf("%q => %q", text, got), nil
+}
+
+// checkDoctorLogFile checks that the debug log file can be written, if debug
+// logging is enabled.
+func checkDoctorLogFile(context.Context) (string, error) {
+	if !IsDebugMode() {
+		return "", fmt.Errorf("%w: debug logging is disabled", errDoctorSkipped)
+	}
+
+	path := GetLogPath()
+	if isInLogDir(path) {
+		_ = os.MkdirAll(filepath.Dir(path), logDirPerm)
+	}
+
+	file, err := os.OpenFile(filepath.Clean(path), logFlag, logPerm)
+	if err != nil {
+		return "", wrapError(err, "log file is not writable")
+	}
+
+	return path + " is writable", wrapError(file.Close(), "failed to close the log file")
+}
+
+// checkDoctorTransport checks that the HTTP listen address can be bound, with
+// the TLS certificates if configured. The address is released right away.
+func checkDoctorTransport(ctx context.Context) (string, error) {
+	addr := GetHTTPAddr()
+	if addr == "" {
+		return "stdio", nil
+	}
+
+	tlsConfig, err := GetTLSConfig()
+	if err != nil {
+		return "", err
+	}
+
+	listener, err := new(net.ListenConfig).Listen(ctx, "tcp", addr)
+	if err != nil {
+		return "", wrapError(err, "failed to listen on %s", addr)
+	}
+
+	err = checkPprofListener(listener.Addr())
+
+	_ = listener.Close()
+
+	if err != nil {
+		return "", err
+	}
+
+	scheme := "http"
+	if tlsConfig != nil {
+		scheme = "https"
+	}
+
+	return fmt.Sprintf("%s://%s can be listened on", scheme, addr), nil
+}
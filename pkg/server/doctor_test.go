@@ -0,0 +1,112 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  runDoctor
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_runCommand_doctor(t *testing.T) {
+	unsetEnv(t, envNameHTTPAddr, envNameToolsEnabled, envNameToolsDisabled, envNameProfile)
+	setEnv(t, envNameDebug, filepath.Join(t.TempDir(), "doctor.log"))
+
+	var out bytes.Buffer
+
+	app := newTestApp(t)
+	app.Stdout = &out
+
+	err := app.Run(context.Background(), []string{cmdNameDoctor})
+	require.NoError(t, err, out.String())
+
+	report := out.String()
+	require.Contains(t, report, "PASS  settings\n")
+	require.Contains(t, report, "PASS  mirror: emoji ZWJ sequence: ")
+	require.Contains(t, report, "PASS  mirror: regional indicator flags: ")
+	require.Contains(t, report, "PASS  mirror: combining marks: ")
+	require.Contains(t, report, "PASS  log file: ")
+	require.Contains(t, report, "PASS  transport: stdio\n")
+	require.Contains(t, report, "\n8 passed, 0 failed, 0 skipped\n")
+}
+
+//nolint:paralleltest // sets env var
+func Test_runDoctor_failures(t *testing.T) {
+	unsetEnv(t, envNameDebug, envNameToolsDisabled, envNameProfile)
+
+	// Occupied address
+	listener, err := new(net.ListenConfig).Listen(context.Background(), "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer func() { _ = listener.Close() }()
+
+	setEnv(t, envNameHTTPAddr, listener.Addr().String())
+	setEnv(t, envNameToolsEnabled, batchToolName)
+	setEnv(t, envNameWorkers, "-1")
+
+	var out bytes.Buffer
+
+	err = runDoctor(context.Background(), &out)
+	require.ErrorIs(t, err, errDoctorFailed)
+
+	report := out.String()
+	require.Contains(t, report, "FAIL  settings: ")
+	require.Contains(t, report, "SKIP  mirror: emoji ZWJ sequence: mirror tool is disabled\n")
+	require.Contains(t, report, "SKIP  log file: debug logging is disabled\n")
+	require.Contains(t, report, "FAIL  transport: failed to listen on ")
+	require.Contains(t, report, "\n0 passed, 2 failed, 6 skipped\n")
+}
+
+//nolint:paralleltest // sets env var
+func Test_checkDoctorMirror_mismatch(t *testing.T) {
+	unsetEnv(t, envNameToolsEnabled, envNameToolsDisabled)
+
+	session, closeSession, err := connectInProcess(context.Background())
+	require.NoError(t, err)
+
+	defer closeSession()
+
+	_, err = checkDoctorMirror(context.Background(), session, "abc", "abc")
+	require.ErrorIs(t, err, errMirrorMismatch)
+}
+
+//nolint:paralleltest // reads env var
+func Test_checkDoctorMirror_no_content(t *testing.T) {
+	ctx := context.Background()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "v0.0.0"}, nil)  //nolint:exhaustruct // minimal server
+	server.AddTool(&mcp.Tool{Name: toolName, InputSchema: &jsonschema.Schema{Type: "object"}}, //nolint:exhaustruct // minimal tool
+		func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return new(mcp.CallToolResult), nil
+		})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.0"}, nil) //nolint:exhaustruct // minimal client
+
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+
+	defer func() {
+		_ = session.Close()
+		_ = serverSession.Wait()
+	}()
+
+	require.NotPanics(t, func() {
+		_, err = checkDoctorMirror(ctx, session, "abc", "cba")
+	})
+	require.ErrorIs(t, err, errMirrorMismatch)
+	require.ErrorContains(t, err, "no content")
+}
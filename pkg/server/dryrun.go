@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// dryRunReport is the line of the report of --dry-run, e.g. "tools:      mirror".
+const dryRunReport = "%-11s %s\n"
+
+// dryRun validates the startup as configured without serving, for --dry-run.
+// It loads the settings, registers the tools, connects to the upstream servers,
+// describes the plugins and binds the HTTP listener, then releases them all and
+// prints what would run to w. configPath is the --config flag.
+//
+// It returns the first error the startup would fail with, so that deployments
+// can be validated before swapping the binaries.
+func dryRun(ctx context.Context, w io.Writer, configPath string) error {
+	err := loadSettings()
+	if err != nil {
+		return wrapError(err, "invalid configuration")
+	}
+
+	state := newServerState(WithLogger(loggerFrom(ctx)))
+
+	upstreams, _ := GetUpstreams() // checked by loadSettings
+	if len(upstreams) > 0 {
+		closeUpstreams, err := addUpstreams(ctx, state.tools, upstreams)
+		if err != nil {
+			return wrapError(err, "MCP server would fail to start")
+		}
+		defer closeUpstreams()
+	}
+
+	plugins, _ := GetPlugins() // checked by loadSettings
+	if plugins != "" {
+		closePlugins, err := addPlugins(ctx, state.tools, plugins)
+		if err != nil {
+			return wrapError(err, "MCP server would fail to start")
+		}
+		defer closePlugins()
+	}
+
+	session, closeSession, err := connectServer(ctx, state.server)
+	if err != nil {
+		return err
+	}
+
+	defer closeSession()
+
+	var tools []string
+
+	for tool, err := range session.Tools(ctx, nil) {
+		if err != nil {
+			return wrapError(err, "failed to list the tools")
+		}
+
+		tools = append(tools, tool.Name)
+	}
+
+	report := newStartupReport(state, configPath)
+
+	transport, err := checkDoctorTransport(ctx)
+	if err != nil {
+		return wrapError(err, "MCP server would fail to start")
+	}
+
+	_, _ = fmt.Fprintf(w, dryRunReport, "version:", report.Version)
+	_, _ = fmt.Fprintf(w, dryRunReport, "config:", report.Config)
+	_, _ = fmt.Fprintf(w, dryRunReport, "profile:", report.Profile)
+	_, _ = fmt.Fprintf(w, dryRunReport, "log:", report.Log)
+	_, _ = fmt.Fprintf(w, dryRunReport, "transport:", transport)
+	_, _ = fmt.Fprintf(w, dryRunReport, "tools:", strings.Join(tools, ", "))
+
+	for _, upstream := range report.Upstreams {
+		_, _ = fmt.Fprintf(w, dryRunReport, "upstream:", upstream)
+	}
+
+	for _, instance := range report.Instances {
+		_, _ = fmt.Fprintf(w, dryRunReport, "instance:", instance)
+	}
+
+	_, err = fmt.Fprintln(w, "\nOK: the server would start. exiting without serving.")
+
+	return err
+}
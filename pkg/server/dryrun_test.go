@@ -0,0 +1,73 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  dryRun
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_runCommand_dry_run(t *testing.T) {
+	unsetEnv(t, envNameHTTPAddr, envNameToolsDisabled, envNameProfile, envNameDebug, envNameLogLevel, envNameWorkers)
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("tools:\n  disabled: ["+strings.Join(defaultTools(toolName), ", ")+"]\n"), 0o600))
+
+	var out bytes.Buffer
+
+	app := newTestApp(t)
+	app.Stdout = &out
+	app.RunServer = func(context.Context, *mcp.Server) error {
+		require.Fail(t, "dry run should not serve")
+
+		return nil
+	}
+
+	err := app.Run(context.Background(), []string{
+		"--config", configPath, "--http-addr", "127.0.0.1:0", "--dry-run",
+	})
+	require.NoError(t, err)
+	require.Contains(t, out.String(), "config:     "+configPath+"\n")
+	require.Contains(t, out.String(), "profile:    none\n")
+	require.Contains(t, out.String(), "log:        stderr (error)\n")
+	require.Contains(t, out.String(), "transport:  http://127.0.0.1:0 can be listened on\n")
+	require.Contains(t, out.String(), "tools:      "+toolName+"\n", "disabled tools should not be reported")
+	require.Contains(t, out.String(), "OK: the server would start")
+}
+
+//nolint:paralleltest // sets env var
+func Test_dryRun_failures(t *testing.T) {
+	unsetEnv(t, envNameHTTPAddr, envNameWorkers)
+
+	var out bytes.Buffer
+
+	// Invalid settings
+	setEnv(t, envNameWorkers, "-1")
+
+	err := dryRun(context.Background(), &out, "")
+	require.ErrorIs(t, err, errInvalidNumber)
+
+	// Address in use
+	listener, err := new(net.ListenConfig).Listen(context.Background(), "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer listener.Close()
+
+	unsetEnv(t, envNameWorkers)
+	setEnv(t, envNameHTTPAddr, listener.Addr().String())
+
+	err = dryRun(context.Background(), &out, "")
+	require.ErrorContains(t, err, "would fail to start")
+	require.Empty(t, out.String(), "nothing should be reported as running")
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/KEINOS/mcp-text-mirror/pkg/mirror"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rivo/uniseg"
+)
+
+// Mirror each line tool.
+const (
+	eachLineToolName        = "mirror_each_line"
+	eachLineToolDescription = "Mirrors (reverses) each line of the UTF-8 text by grapheme clusters, keeping the " +
+		"order of the lines, e.g. to mirror ASCII art horizontally. Set pad to mirror the block as a rectangle"
+)
+
+// MirrorEachLineInput is the input for the mirror_each_line tool.
+type MirrorEachLineInput struct {
+	Text string `json:"text"          jsonschema:"The UTF-8 text whose lines to mirror."`
+	Pad  bool   `json:"pad,omitempty" jsonschema:"Pad the lines with spaces to the display width of the widest one before mirroring, so that the shorter lines stay aligned as in a mirrored block of ASCII art."`
+}
+
+// MirrorEachLineOutput is the output from the mirror_each_line tool.
+type MirrorEachLineOutput struct {
+	Text  string `json:"text"  jsonschema:"The text with each line mirrored, in the original order. The line breaks stay in place."`
+	Lines int    `json:"lines" jsonschema:"The number of lines, not counting the empty one after a trailing line break."`
+}
+
+// eachLineTool is the mirror_each_line tool, mirroring the lines of a block
+// of text one by one.
+type eachLineTool struct{}
+
+// Name returns the name of the tool.
+func (eachLineTool) Name() string { return eachLineToolName }
+
+// Description returns the description of the tool.
+func (eachLineTool) Description() string { return eachLineToolDescription }
+
+// Schema returns the schemas of the input and the output of the tool.
+func (eachLineTool) Schema() (*jsonschema.Schema, *jsonschema.Schema) {
+	return mirrorEachLineInputSchema(), nil
+}
+
+// Annotations returns the hints of the tool.
+func (eachLineTool) Annotations() *mcp.ToolAnnotations { return readOnlyAnnotations() }
+
+// Handler returns handleMirrorEachLine.
+func (eachLineTool) Handler(*serverState) ToolHandler { return TypedHandler(handleMirrorEachLine) }
+
+// cacheable returns true, as the result depends on the text and the padding
+// only.
+func (eachLineTool) cacheable(map[string]any) bool { return true }
+
+// mirrorEachLineInputSchema returns the JSON schema of MirrorEachLineInput.
+func mirrorEachLineInputSchema() *jsonschema.Schema {
+	schema := mustInferSchema[MirrorEachLineInput]()
+	schema.Title = "Mirror each line input"
+	schema.Required = []string{"text"}
+
+	text := schema.Properties["text"]
+	text.Title = "Text"
+	text.MaxLength = jsonschema.Ptr(textMaxLength)
+	text.Examples = []any{" /\\_/\\\n( o.o )>\n > ^ <"}
+
+	pad := schema.Properties["pad"]
+	pad.Title = "Pad"
+
+	return schema
+}
+
+// handleMirrorEachLine returns the text with each line mirrored in place.
+func handleMirrorEachLine(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input MirrorEachLineInput,
+) (*mcp.CallToolResult, MirrorEachLineOutput, error) {
+	err := ctx.Err()
+	if err != nil {
+		return nil, MirrorEachLineOutput{}, wrapError(err, "request canceled")
+	}
+
+	err = checkTextLimit(req, input.Text)
+	if err != nil {
+		return nil, MirrorEachLineOutput{}, err
+	}
+
+	start := time.Now()
+
+	mirrored, lines, err := mirrorEachLine(ctx, input.Text, input.Pad)
+	if err != nil {
+		return nil, MirrorEachLineOutput{}, err
+	}
+
+	callLog(ctx, "lines mirrored", callLogAttrs(ctx, req, logKeyInputSize, len(input.Text), logKeyDuration, time.Since(start),
+		logKeyText, userText(input.Text), logKeyMirrored, userText(mirrored))...)
+
+	return nil, MirrorEachLineOutput{Text: mirrored, Lines: lines}, nil
+}
+
+// mirrorEachLine returns the text with each line reversed by grapheme clusters
+// and the number of lines. The line breaks, LF or CR LF, stay in place. If pad
+// is true, the lines are first padded with spaces to the monospace display
+// width of the widest one, so that the mirrored lines are right-aligned as the
+// original ones were left-aligned. It stops once ctx is canceled.
+func mirrorEachLine(ctx context.Context, text string, pad bool) (string, int, error) {
+	if text == "" {
+		return "", 0, nil
+	}
+
+	lines, breaks := splitLines(text)
+
+	// The empty line after a trailing line break is not a line.
+	count := len(lines)
+	if len(breaks) > 0 && lines[count-1] == "" {
+		count--
+	}
+
+	width := 0
+
+	if pad {
+		for _, line := range lines[:count] {
+			width = max(width, uniseg.StringWidth(line))
+		}
+	}
+
+	var builder strings.Builder
+
+	builder.Grow(len(text))
+
+	for index, line := range lines[:count] {
+		if ctx.Err() != nil {
+			return "", 0, wrapError(ctx.Err(), "request canceled after %d lines", index)
+		}
+
+		if pad {
+			line += strings.Repeat(" ", width-uniseg.StringWidth(line))
+		}
+
+		mirrored, err := mirror.Reverse(ctx, line)
+		if err != nil {
+			return "", 0, err
+		}
+
+		builder.WriteString(mirrored)
+
+		if index < len(breaks) {
+			builder.WriteString(breaks[index])
+		}
+	}
+
+	return builder.String(), count, nil
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  handleMirrorEachLine
+// ----------------------------------------------------------------------------
+
+func Test_handleMirrorEachLine(t *testing.T) {
+	t.Parallel()
+
+	session := connectInMemory(t, newServer())
+
+	for index, test := range []struct {
+		name      string
+		args      map[string]any
+		wantText  string
+		wantLines float64
+	}{
+		{"lf", map[string]any{"text": "abc\nde"}, "cba\ned", 2},
+		{"trailing_crlf", map[string]any{"text": "ab\r\ncd\r\n"}, "ba\r\ndc\r\n", 2},
+		{"graphemes", map[string]any{"text": "a👍🏽\ncafé"}, "👍🏽a\néfac", 2},
+		{"ascii_art", map[string]any{"text": "/|\n/_|", "pad": true}, " |/\n|_/", 2},
+		{"pad_wide_chars", map[string]any{"text": "世界\nab\n", "pad": true}, "界世\n  ba\n", 2},
+		{"pad_empty_line", map[string]any{"text": "ab\n\nc", "pad": true}, "ba\n  \n c", 3},
+		{"empty", map[string]any{"text": ""}, "", 0},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		res := callTool(t, session, eachLineToolName, test.args)
+		require.False(t, res.IsError, name)
+		require.Equal(t, map[string]any{"text": test.wantText, "lines": test.wantLines}, res.StructuredContent, name)
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  mirrorEachLine
+// ----------------------------------------------------------------------------
+
+func Test_mirrorEachLine_canceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := mirrorEachLine(ctx, "ab\ncd", false)
+	require.ErrorIs(t, err, context.Canceled)
+}
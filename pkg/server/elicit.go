@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Elicitation of the missing input text.
+const (
+	elicitMessage = "The text to mirror is empty. Enter the text to mirror, " +
+		"or submit it empty if you intended to mirror an empty string."
+	elicitAccept  = "accept"  // user submitted the form
+	elicitDecline = "decline" // user explicitly declined to give the text
+)
+
+// errElicitCanceled is returned if the user dismissed the elicitation.
+var errElicitCanceled = errors.New("canceled by the user")
+
+// elicitText asks the user for the text to mirror via MCP elicitation, since an
+// empty text is more likely a mistake than intended.
+//
+// It returns an empty string as is if the client does not support elicitation
+// or if the user declined to give the text. It returns errElicitCanceled if the
+// user dismissed the request.
+func elicitText(ctx context.Context, req *mcp.CallToolRequest) (string, error) {
+	if req == nil || req.Session == nil {
+		return "", nil
+	}
+
+	initParams := req.Session.InitializeParams()
+	if initParams == nil || initParams.Capabilities == nil || initParams.Capabilities.Elicitation == nil {
+		return "", nil
+	}
+
+	params := new(mcp.ElicitParams)
+	params.Message = elicitMessage
+	params.RequestedSchema = map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"text": map[string]any{
+				"type":        "string",
+				"title":       "Text",
+				"description": "UTF-8 text to be mirrored. Leave empty to mirror an empty string",
+			},
+		},
+	}
+
+	result, err := req.Session.Elicit(ctx, params)
+	if err != nil {
+		return "", wrapError(err, "failed to ask the user for the text")
+	}
+
+	switch result.Action {
+	case elicitAccept:
+		text, _ := result.Content["text"].(string)
+
+		return text, nil
+	case elicitDecline:
+		return "", nil
+	default:
+		return "", errElicitCanceled
+	}
+}
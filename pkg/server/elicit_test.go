@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// connectElicit connects a client answering elicitations with the given result
+// or error to a new server. It returns the client session.
+func connectElicit(t *testing.T, result *mcp.ElicitResult, err error) *mcp.ClientSession {
+	t.Helper()
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+
+	serverSession, errConnect := newServer().Connect(ctx, serverTransport, nil)
+	require.NoError(t, errConnect)
+
+	options := new(mcp.ClientOptions)
+	options.ElicitationHandler = func(_ context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+		require.Equal(t, elicitMessage, req.Params.Message)
+
+		return result, err
+	}
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.0"}, options) //nolint:exhaustruct // minimal client
+
+	session, errConnect := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, errConnect)
+
+	t.Cleanup(func() {
+		_ = session.Close()
+		_ = serverSession.Wait()
+	})
+
+	return session
+}
+
+// ----------------------------------------------------------------------------
+//  elicitText
+// ----------------------------------------------------------------------------
+
+func Test_handleReverse_elicit(t *testing.T) {
+	t.Parallel()
+
+	for index, test := range []struct {
+		name      string
+		result    *mcp.ElicitResult
+		wantText  string
+		wantError string
+	}{
+		{"accept_text", &mcp.ElicitResult{Action: elicitAccept, Content: map[string]any{"text": "abc"}}, "cba", ""},
+		{"accept_empty", &mcp.ElicitResult{Action: elicitAccept, Content: map[string]any{"text": ""}}, "", ""},
+		{"accept_no_content", &mcp.ElicitResult{Action: elicitAccept}, "", ""},
+		{"decline", &mcp.ElicitResult{Action: elicitDecline}, "", ""},
+		{"cancel", &mcp.ElicitResult{Action: "cancel"}, "", errElicitCanceled.Error()},
+	} {
+		t.Run(fmt.Sprintf("Test #%d: %s", index+1, test.name), func(t *testing.T) {
+			t.Parallel()
+
+			session := connectElicit(t, test.result, nil)
+			res := callTool(t, session, toolName, map[string]any{"text": ""})
+
+			if test.wantError != "" {
+				require.True(t, res.IsError)
+				require.Contains(t, res.Content[0].(*mcp.TextContent).Text, test.wantError) //nolint:forcetypeassert // text content
+
+				return
+			}
+
+			require.False(t, res.IsError)
+			require.Equal(t, map[string]any{"text": test.wantText}, res.StructuredContent)
+		})
+	}
+}
+
+func Test_handleReverse_elicit_failure(t *testing.T) {
+	t.Parallel()
+
+	session := connectElicit(t, nil, errTest)
+	res := callTool(t, session, toolName, map[string]any{"text": ""})
+
+	require.True(t, res.IsError)
+	require.Contains(t, res.Content[0].(*mcp.TextContent).Text, "failed to ask the user for the text") //nolint:forcetypeassert // text content
+}
+
+func Test_handleReverse_elicit_unsupported(t *testing.T) {
+	t.Parallel()
+
+	// Client without elicitation capability
+	session := connectInMemory(t, newServer())
+	res := callTool(t, session, toolName, map[string]any{"text": ""})
+
+	require.False(t, res.IsError)
+	require.Equal(t, map[string]any{"text": ""}, res.StructuredContent)
+}
+
+func Test_handleReverse_elicit_not_empty(t *testing.T) {
+	t.Parallel()
+
+	// Non-empty text is never elicited
+	session := connectElicit(t, nil, errTest)
+	res := callTool(t, session, toolName, map[string]any{"text": "abc"})
+
+	require.False(t, res.IsError)
+	require.Equal(t, map[string]any{"text": "cba"}, res.StructuredContent)
+}
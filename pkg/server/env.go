@@ -0,0 +1,80 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Predefined errors of the environment variables.
+var (
+	errInvalidNumber   = errors.New("must be a non-negative number")
+	errInvalidDuration = errors.New("must be a non-negative duration such as 30s or 5m")
+	errInvalidBool     = errors.New("must be a boolean such as true or false")
+)
+
+// envFloat returns the non-negative number of the setting of the environment
+// variable. It returns zero if not set.
+func (v *settingValues) envFloat(name string) (float64, error) {
+	value := v.value(name)
+	if value == "" {
+		return 0, nil
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number < 0 || math.IsInf(number, 0) || math.IsNaN(number) {
+		return 0, fmt.Errorf("invalid %s %q: %w", name, value, errInvalidNumber)
+	}
+
+	return number, nil
+}
+
+// envInt returns the non-negative integer of the setting of the environment
+// variable. It returns defaultValue if not set.
+func (v *settingValues) envInt(name string, defaultValue int) (int, error) {
+	value := v.value(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("invalid %s %q: %w", name, value, errInvalidNumber)
+	}
+
+	return number, nil
+}
+
+// envDuration returns the non-negative duration set in the environment variable
+// such as "30s" or "5m". It returns zero if the variable is not set.
+func (v *settingValues) envDuration(name string) (time.Duration, error) {
+	value := v.value(name)
+	if value == "" {
+		return 0, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("invalid %s %q: %w", name, value, errInvalidDuration)
+	}
+
+	return duration, nil
+}
+
+// envBool returns the boolean set in the environment variable such as "true",
+// "false", "1" or "0". It returns false if the variable is not set.
+func (v *settingValues) envBool(name string) (bool, error) {
+	value := v.value(name)
+	if value == "" {
+		return false, nil
+	}
+
+	flag, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q: %w", name, value, errInvalidBool)
+	}
+
+	return flag, nil
+}
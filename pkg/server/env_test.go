@@ -0,0 +1,106 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  envFloat / envInt
+// ----------------------------------------------------------------------------
+
+func Test_envFloat(t *testing.T) {
+	const name = "MCP_TEXT_MIRROR_TEST_FLOAT"
+
+	setEnv(t, name, "")
+
+	value, err := loadedSettings().envFloat(name)
+	require.NoError(t, err)
+	require.Zero(t, value, "unset variable should be zero")
+
+	setEnv(t, name, "1.5")
+
+	value, err = loadedSettings().envFloat(name)
+	require.NoError(t, err)
+	require.InDelta(t, 1.5, value, 0)
+
+	for _, invalid := range []string{"abc", "-1", "NaN", "Inf"} {
+		setEnv(t, name, invalid)
+
+		_, err = loadedSettings().envFloat(name)
+		require.ErrorIs(t, err, errInvalidNumber, "value %q should be invalid", invalid)
+	}
+}
+
+func Test_envInt(t *testing.T) {
+	const name = "MCP_TEXT_MIRROR_TEST_INT"
+
+	setEnv(t, name, "")
+
+	value, err := loadedSettings().envInt(name, 42)
+	require.NoError(t, err)
+	require.Equal(t, 42, value, "unset variable should be the default value")
+
+	setEnv(t, name, "7")
+
+	value, err = loadedSettings().envInt(name, 42)
+	require.NoError(t, err)
+	require.Equal(t, 7, value)
+
+	for _, invalid := range []string{"abc", "-1", "1.5"} {
+		setEnv(t, name, invalid)
+
+		_, err = loadedSettings().envInt(name, 42)
+		require.ErrorIs(t, err, errInvalidNumber, "value %q should be invalid", invalid)
+	}
+}
+
+func Test_envDuration(t *testing.T) {
+	const name = "MCP_TEXT_MIRROR_TEST_DURATION"
+
+	setEnv(t, name, "")
+
+	value, err := loadedSettings().envDuration(name)
+	require.NoError(t, err)
+	require.Zero(t, value, "unset variable should be zero")
+
+	setEnv(t, name, "1m30s")
+
+	value, err = loadedSettings().envDuration(name)
+	require.NoError(t, err)
+	require.Equal(t, 90*time.Second, value)
+
+	for _, invalid := range []string{"abc", "-1s", "30"} {
+		setEnv(t, name, invalid)
+
+		_, err = loadedSettings().envDuration(name)
+		require.ErrorIs(t, err, errInvalidDuration, "value %q should be invalid", invalid)
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  envBool
+// ----------------------------------------------------------------------------
+
+func Test_envBool(t *testing.T) {
+	const name = "MCP_TEXT_MIRROR_TEST_BOOL"
+
+	setEnv(t, name, "")
+
+	flag, err := loadedSettings().envBool(name)
+	require.NoError(t, err)
+	require.False(t, flag, "unset variable should be false")
+
+	setEnv(t, name, "true")
+
+	flag, err = loadedSettings().envBool(name)
+	require.NoError(t, err)
+	require.True(t, flag)
+
+	setEnv(t, name, "yes")
+
+	_, err = loadedSettings().envBool(name)
+	require.ErrorIs(t, err, errInvalidBool)
+}
@@ -0,0 +1,182 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime/debug"
+	"time"
+)
+
+// Reporting of the panics and the fatal errors to an external service.
+//
+// E.g.: MCP_TEXT_MIRROR_ERROR_WEBHOOK=https://errors.example.com/hooks/text-mirror
+const (
+	envNameErrorWebhook = envPrefix + "ERROR_WEBHOOK" // env var of the URL to POST the error reports to
+
+	errorKindPanic = "panic" // the server panicked
+	errorKindFatal = "fatal" // the server exits on the error
+
+	errorReportTimeout = 5 * time.Second // timeout to report, as the process exits afterwards
+	errorReportMIME    = "application/json"
+)
+
+// Predefined errors of the error reporting.
+var (
+	errInvalidWebhook = errors.New("must be an http:// or https:// URL")
+	errWebhookStatus  = errors.New("unexpected status of the webhook")
+)
+
+// errorReporter reports the panics and the fatal errors. If nil, they are
+// reported to the webhook of MCP_TEXT_MIRROR_ERROR_WEBHOOK if set. Builds which
+// report elsewhere, such as to an SDK of an error tracking service, or tests
+// can replace it, before serving: it is read by the sessions without
+// synchronization.
+//
+//nolint:gochecknoglobals // dependency injection point
+var errorReporter ErrorReporter
+
+// ErrorReporter reports the panics and the fatal errors of the server, so that
+// the crashes in production are noticed.
+type ErrorReporter interface {
+	// Report reports the error. It is called once the server failed, so it
+	// should give up at the deadline of ctx.
+	Report(ctx context.Context, report *ErrorReport) error
+}
+
+// ErrorReport is a panic or a fatal error of the server.
+type ErrorReport struct {
+	Kind    string    `json:"kind"`            // "panic" or "fatal"
+	Message string    `json:"message"`         // panic value or error message
+	Stack   string    `json:"stack,omitempty"` // stack trace of the panic
+	Version string    `json:"version"`         // version of the server
+	Host    string    `json:"host,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// GetErrorWebhook returns the URL to POST the error reports to as JSON, from
+// 'MCP_TEXT_MIRROR_ERROR_WEBHOOK' environment variable, or empty if not set.
+func GetErrorWebhook() (string, error) {
+	return loadedSettings().errorWebhook()
+}
+
+// errorWebhook returns GetErrorWebhook of the settings.
+func (v *settingValues) errorWebhook() (string, error) {
+	value := v.value(envNameErrorWebhook)
+	if value == "" {
+		return "", nil
+	}
+
+	parsed, err := url.Parse(value)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", fmt.Errorf("invalid %s %q: %w", envNameErrorWebhook, value, errInvalidWebhook)
+	}
+
+	return value, nil
+}
+
+// webhookReporter is the built-in ErrorReporter posting the reports as JSON to
+// a webhook, such as the ones of the error tracking and chat services.
+type webhookReporter struct {
+	url    string
+	client *http.Client
+}
+
+// newWebhookReporter returns the reporter posting to the URL.
+func newWebhookReporter(url string) *webhookReporter {
+	reporter := new(webhookReporter)
+	reporter.url = url
+	reporter.client = new(http.Client)
+
+	return reporter
+}
+
+// Report posts the report to the webhook. Statuses other than 2xx are errors.
+// It is an implementation of ErrorReporter.
+func (r *webhookReporter) Report(ctx context.Context, report *ErrorReport) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return wrapError(err, "failed to marshal the error report")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, bytes.NewReader(body))
+	if err != nil {
+		return wrapError(err, "failed to create the webhook request")
+	}
+
+	req.Header.Set("Content-Type", errorReportMIME)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return wrapError(err, "failed to post to the webhook")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%w: %s", errWebhookStatus, resp.Status)
+	}
+
+	return nil
+}
+
+// currentErrorReporter returns errorReporter, or the webhook reporter if
+// configured, or nil if none.
+func currentErrorReporter() ErrorReporter {
+	if errorReporter != nil {
+		return errorReporter
+	}
+
+	webhook, _ := GetErrorWebhook() // invalid values are reported by loadSettings
+	if webhook == "" {
+		return nil
+	}
+
+	return newWebhookReporter(webhook)
+}
+
+// reportError reports the panic or the fatal error, if a reporter is set. It
+// waits for the reporter up to errorReportTimeout. Failures are logged to the
+// logger of ctx.
+func reportError(ctx context.Context, kind, message string, stack []byte) {
+	reporter := currentErrorReporter()
+	if reporter == nil {
+		return
+	}
+
+	report := new(ErrorReport)
+	report.Kind = kind
+	report.Message = message
+	report.Stack = string(stack)
+	report.Version = GetServiceVersion()
+	report.Time = time.Now().UTC()
+
+	if hostname, err := os.Hostname(); err == nil {
+		report.Host = hostname
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, errorReportTimeout)
+	defer cancel()
+
+	if err := reporter.Report(ctx, report); err != nil {
+		warnLog(ctx, "failed to report the error", "kind", kind, logKeyError, err)
+	}
+}
+
+// reportPanics reports the panic in progress, if any, and panics again so that
+// the process crashes as usual. Defer it at the top of the goroutines, with
+// the context of their logger.
+func reportPanics(ctx context.Context) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+
+	reportError(ctx, errorKindPanic, fmt.Sprint(recovered), debug.Stack())
+
+	panic(recovered)
+}
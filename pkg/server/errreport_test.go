@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// mockReporter is an ErrorReporter recording the reports.
+type mockReporter struct {
+	reports []*ErrorReport
+}
+
+func (m *mockReporter) Report(_ context.Context, report *ErrorReport) error {
+	m.reports = append(m.reports, report)
+
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+//  GetErrorWebhook
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_GetErrorWebhook(t *testing.T) {
+	for index, test := range []struct {
+		name    string
+		value   string
+		want    string
+		wantErr error
+	}{
+		{"unset", "", "", nil},
+		{"https", "https://errors.example.com/hooks/1", "https://errors.example.com/hooks/1", nil},
+		{"http", "http://127.0.0.1:8080", "http://127.0.0.1:8080", nil},
+		{"no_scheme", "errors.example.com/hooks/1", "", errInvalidWebhook},
+		{"other_scheme", "udp://127.0.0.1:514", "", errInvalidWebhook},
+		{"no_host", "https:///hooks/1", "", errInvalidWebhook},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		setEnv(t, envNameErrorWebhook, test.value)
+
+		got, err := GetErrorWebhook()
+		require.ErrorIs(t, err, test.wantErr, name)
+		require.Equal(t, test.want, got, name)
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  webhookReporter
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_reportError_webhook(t *testing.T) {
+	received := make(chan *ErrorReport, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := new(ErrorReport)
+		if json.NewDecoder(r.Body).Decode(report) != nil || r.Header.Get("Content-Type") != errorReportMIME {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		received <- report
+	}))
+	defer server.Close()
+
+	setEnv(t, envNameErrorWebhook, server.URL)
+
+	reportError(context.Background(), errorKindFatal, "failed to listen", nil)
+
+	var report *ErrorReport
+
+	select {
+	case report = <-received:
+	default:
+		require.FailNow(t, "the report should be posted before reportError returns")
+	}
+
+	require.Equal(t, errorKindFatal, report.Kind)
+	require.Equal(t, "failed to listen", report.Message)
+	require.Empty(t, report.Stack)
+	require.Equal(t, GetServiceVersion(), report.Version)
+	require.False(t, report.Time.IsZero())
+}
+
+func Test_webhookReporter_status(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	err := newWebhookReporter(server.URL).Report(context.Background(), new(ErrorReport))
+	require.ErrorIs(t, err, errWebhookStatus)
+	require.ErrorContains(t, err, "500 Internal Server Error")
+}
+
+// ----------------------------------------------------------------------------
+//  reportPanics and exitOnError
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // replaces the global error reporter
+func Test_reportPanics(t *testing.T) {
+	reporter := new(mockReporter)
+	errorReporter = reporter
+
+	defer func() { errorReporter = nil }()
+
+	require.PanicsWithValue(t, "boom", func() {
+		defer reportPanics(context.Background())
+
+		panic("boom")
+	}, "the panic should go on once reported")
+
+	require.Len(t, reporter.reports, 1)
+	require.Equal(t, errorKindPanic, reporter.reports[0].Kind)
+	require.Equal(t, "boom", reporter.reports[0].Message)
+	require.Contains(t, reporter.reports[0].Stack, "Test_reportPanics", "the stack should be of the panic")
+
+	require.NotPanics(t, func() {
+		defer reportPanics(context.Background())
+	})
+	require.Len(t, reporter.reports, 1, "nothing to report without panic")
+}
+
+//nolint:paralleltest // replaces errorReporter
+func Test_exitOnError_report(t *testing.T) {
+	defer func() { errorReporter = nil }()
+
+	reporter := new(mockReporter)
+	errorReporter = reporter
+
+	app := newTestApp(t)
+	app.Logger = mockLogger(func(string) {})
+	app.Exit = func(code int) { panic(code) }
+
+	require.PanicsWithValue(t, 1, func() { app.exitOnError(errTest) })
+	require.Len(t, reporter.reports, 1)
+	require.Equal(t, errorKindFatal, reporter.reports[0].Kind)
+	require.Equal(t, errTest.Error(), reporter.reports[0].Message)
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+)
+
+// Windows Event Log output of the log entries.
+//
+// E.g.: MCP_TEXT_MIRROR_EVENT_LOG=true
+const envNameEventLog = envPrefix + "EVENT_LOG" // env var to write the warnings and errors to the event log
+
+// errNoEventLog is the error of enabling the event log on other platforms.
+var errNoEventLog = errors.New("the event log is only available on Windows")
+
+// GetEventLog returns whether the warnings and errors are written to the
+// Windows Event Log too, under the event source registered by "service
+// install", from 'MCP_TEXT_MIRROR_EVENT_LOG' environment variable.
+//
+// It takes effect only when running as a Windows service. The entries are the
+// ones at or above MCP_TEXT_MIRROR_LOG_LEVEL. Leave MCP_TEXT_MIRROR_DEBUG_LOG
+// unset to write them to the event log only, as the standard error of a
+// service goes nowhere.
+func GetEventLog() (bool, error) {
+	return loadedSettings().eventLog()
+}
+
+// eventLog returns GetEventLog of the settings.
+func (v *settingValues) eventLog() (bool, error) {
+	enabled, err := v.envBool(envNameEventLog)
+	if err != nil {
+		return false, err
+	}
+
+	if enabled && runtime.GOOS != "windows" {
+		return false, fmt.Errorf("invalid %s %q: %w", envNameEventLog, v.value(envNameEventLog), errNoEventLog)
+	}
+
+	return enabled, nil
+}
@@ -0,0 +1,22 @@
+//go:build !windows
+
+package server
+
+import "log/slog"
+
+// eventLogWriter writes the log entries to the Windows Event Log. There is no
+// event log on other platforms.
+type eventLogWriter struct{}
+
+// newEventLogWriter always returns nil as there is no event log to write to.
+func newEventLogWriter(bool) *eventLogWriter {
+	return nil
+}
+
+// send does nothing.
+func (*eventLogWriter) send(slog.Record) error {
+	return nil
+}
+
+// close does nothing.
+func (*eventLogWriter) close() {}
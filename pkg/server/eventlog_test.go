@@ -0,0 +1,59 @@
+package server
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  GetEventLog
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_GetEventLog(t *testing.T) {
+	onWindows := runtime.GOOS == "windows"
+
+	var errEnabled error
+	if !onWindows {
+		errEnabled = errNoEventLog
+	}
+
+	for index, test := range []struct {
+		name    string
+		value   string
+		want    bool
+		wantErr error
+	}{
+		{"unset", "", false, nil},
+		{"disabled", "false", false, nil},
+		{"enabled", "true", onWindows, errEnabled},
+		{"invalid", "yes please", false, errInvalidBool},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		setEnv(t, envNameEventLog, test.value)
+
+		got, err := GetEventLog()
+		if test.wantErr != nil {
+			require.ErrorIs(t, err, test.wantErr, name)
+		} else {
+			require.NoError(t, err, name)
+		}
+
+		require.Equal(t, test.want, got, name)
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  eventLogWriter
+// ----------------------------------------------------------------------------
+
+func Test_newEventLogWriter(t *testing.T) {
+	t.Parallel()
+
+	require.Nil(t, newEventLogWriter(false))
+	require.Nil(t, newEventLogWriter(true), "the tests don't run as a service")
+}
@@ -0,0 +1,73 @@
+//go:build windows
+
+package server
+
+import (
+	"log/slog"
+	"sync"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+// eventLogWriter writes the warnings and errors of the logger to the Windows
+// Event Log, under the event source of the service.
+type eventLogWriter struct {
+	elog *eventlog.Log // nil once closed
+	mu   sync.Mutex
+}
+
+// newEventLogWriter returns the writer to the event log if enabled and running
+// as a Windows service, or nil otherwise. It is also nil if the event log
+// can't be opened, as the failure can't be logged there.
+func newEventLogWriter(enabled bool) *eventLogWriter {
+	if !enabled {
+		return nil
+	}
+
+	if isService, err := svc.IsWindowsService(); err != nil || !isService {
+		return nil
+	}
+
+	elog, err := eventlog.Open(serviceName)
+	if err != nil {
+		return nil
+	}
+
+	writer := new(eventLogWriter)
+	writer.elog = elog
+
+	return writer
+}
+
+// send writes the record to the event log as an error or a warning. Entries
+// below the warn level are not written, so as not to flood the event log.
+func (w *eventLogWriter) send(record slog.Record) error {
+	if record.Level < slog.LevelWarn {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.elog == nil {
+		return nil // closed
+	}
+
+	if record.Level >= slog.LevelError {
+		return wrapError(w.elog.Error(eventIDLogged, recordEntry(record)), "failed to write to the event log")
+	}
+
+	return wrapError(w.elog.Warning(eventIDLogged, recordEntry(record)), "failed to write to the event log")
+}
+
+// close closes the event log.
+func (w *eventLogWriter) close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.elog != nil {
+		_ = w.elog.Close()
+		w.elog = nil
+	}
+}
@@ -0,0 +1,49 @@
+package server_test
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/KEINOS/mcp-text-mirror/pkg/server"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ----------------------------------------------------------------------------
+//  Examples
+// ----------------------------------------------------------------------------
+
+func ExampleNew() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+
+	srv := server.New(
+		server.WithLogger(slog.New(slog.DiscardHandler)), // the logger of this server only
+		server.WithTools("mirror"),
+		server.WithTransport(serverTransport),
+	)
+
+	go func() { _ = srv.Run(ctx) }()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "example", Version: "v1.0.0"}, nil) //nolint:exhaustruct // minimal client
+
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		panic(err)
+	}
+	defer session.Close()
+
+	params := new(mcp.CallToolParams)
+	params.Name = "mirror"
+	params.Arguments = map[string]any{"text": "Hello, 世界👋🏽"}
+
+	res, err := session.CallTool(ctx, params)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(res.StructuredContent.(map[string]any)["text"]) //nolint:forcetypeassert // object output
+	// Output: 👋🏽界世 ,olleH
+}
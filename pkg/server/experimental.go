@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Keys of the opt-in features in the experimental capabilities of the server.
+// They share the "text-mirror/" prefix with the _meta keys.
+const (
+	experimentalStreaming    = "text-mirror/streaming"    // partial results in the progress notifications
+	experimentalBatch        = "text-mirror/batch"        // mirror_batch tool
+	experimentalRender       = "text-mirror/render"       // render argument of the mirror tool
+	experimentalVerification = "text-mirror/verification" // verdict of the client's LLM in the _meta of the results
+)
+
+// StreamingCapability describes how to opt in to the streamed partial results.
+type StreamingCapability struct {
+	MinBytes       int    `json:"minBytes"`       // min input size in bytes to stream
+	RequestMetaKey string `json:"requestMetaKey"` // _meta key of the request to set to true
+	PartialMetaKey string `json:"partialMetaKey"` // _meta key of the partial results in the notifications
+}
+
+// BatchCapability describes the batch tool.
+type BatchCapability struct {
+	Tool     string `json:"tool"`     // name of the tool
+	MaxItems int    `json:"maxItems"` // max number of texts per call
+}
+
+// RenderCapability describes the image formats of the render argument.
+type RenderCapability struct {
+	Formats []string `json:"formats"`
+}
+
+// VerificationCapability describes where to find the verdict of the client's
+// LLM on the tricky texts.
+type VerificationCapability struct {
+	MetaKey string `json:"metaKey"` // _meta key of the tool result
+}
+
+// experimentalCapabilities returns the opt-in features currently available, by
+// key, so that clients can feature-detect them instead of probing with failing
+// calls. Features of disabled tools are omitted.
+func experimentalCapabilities(tools *toolSet) map[string]any {
+	capabilities := make(map[string]any)
+
+	if tools.enabled(toolName) {
+		capabilities[experimentalStreaming] = StreamingCapability{
+			MinBytes:       streamMinBytes,
+			RequestMetaKey: streamMetaKey,
+			PartialMetaKey: partialMetaKey,
+		}
+		capabilities[experimentalRender] = RenderCapability{Formats: []string{renderPNG}}
+
+		if enabled, err := GetVerifyEnabled(); err == nil && enabled {
+			capabilities[experimentalVerification] = VerificationCapability{MetaKey: verifyMetaKey}
+		}
+	}
+
+	if tools.enabled(batchToolName) {
+		capabilities[experimentalBatch] = BatchCapability{Tool: batchToolName, MaxItems: batchMaxItems}
+	}
+
+	return capabilities
+}
+
+// experimentalMiddleware returns a middleware which advertises the experimental
+// capabilities in the initialize result, since the SDK has no option for them.
+func experimentalMiddleware(tools *toolSet) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			res, err := next(ctx, method, req)
+			if method != methodInitialize || err != nil {
+				return res, err
+			}
+
+			if initResult, ok := res.(*mcp.InitializeResult); ok && initResult.Capabilities != nil {
+				initResult.Capabilities.Experimental = experimentalCapabilities(tools)
+			}
+
+			return res, nil
+		}
+	}
+}
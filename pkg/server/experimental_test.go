@@ -0,0 +1,62 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  experimentalCapabilities
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_newServer_experimental_capabilities(t *testing.T) {
+	setEnv(t, envNameVerify, "")
+
+	session := connectInMemory(t, newServer())
+
+	// Round trip to the JSON form seen by the clients
+	data, err := json.Marshal(session.InitializeResult().Capabilities.Experimental)
+	require.NoError(t, err)
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(data, &got))
+
+	require.Equal(t, map[string]any{
+		experimentalStreaming: map[string]any{
+			"minBytes":       float64(streamMinBytes),
+			"requestMetaKey": streamMetaKey,
+			"partialMetaKey": partialMetaKey,
+		},
+		experimentalBatch:  map[string]any{"tool": batchToolName, "maxItems": float64(batchMaxItems)},
+		experimentalRender: map[string]any{"formats": []any{renderPNG}},
+	}, got)
+}
+
+//nolint:paralleltest // sets env var
+func Test_experimentalCapabilities(t *testing.T) {
+	setEnv(t, envNameVerify, "true")
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v0.0.0"}, nil) //nolint:exhaustruct // minimal server
+	tools := newToolSet(server)
+	require.Empty(t, experimentalCapabilities(tools), "features of unregistered tools should be omitted")
+
+	mirrorTool := new(mcp.Tool)
+	mirrorTool.Name = toolName
+	addTool(tools, mirrorTool, handleReverse)
+
+	batchTool := new(mcp.Tool)
+	batchTool.Name = batchToolName
+	addTool(tools, batchTool, handleReverseBatch)
+
+	got := experimentalCapabilities(tools)
+	require.Contains(t, got, experimentalVerification)
+	require.Contains(t, got, experimentalBatch)
+
+	require.NoError(t, tools.setEnabled(batchToolName, false))
+	require.NoError(t, tools.setEnabled(toolName, false))
+	require.Empty(t, experimentalCapabilities(tools), "features of disabled tools should be omitted")
+}
@@ -0,0 +1,186 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// Transform expressions. E.g.: nfc | mirror | upper(locale=tr)
+const (
+	exprStepSeparator = "|"      // separates the steps
+	exprArgLocale     = "locale" // argument of the steps depending on the locale
+	exprMaxLength     = 1024     // max length of an expression in characters
+)
+
+// Predefined errors of the transform expressions.
+var (
+	errInvalidExpr = errors.New("invalid transform expression")
+	errExprSyntax  = errors.New("syntax error")
+)
+
+// TransformStep is a step of a transform plan: the operation and its
+// arguments, as parsed from a transform expression or given as the steps of
+// the pipeline tool.
+type TransformStep struct {
+	Op     string `json:"op"               jsonschema:"The name of the operation."`
+	Locale string `json:"locale,omitempty" jsonschema:"The locale of the step, overriding the one of the call."`
+}
+
+// parseTransformExpr parses the transform expression into the plan to apply
+// with applyPlan.
+//
+// An expression is the operations separated by "|", applied from left to
+// right. Operations depending on the locale take it as an argument, such as
+// "upper(locale=tr)", and the normalizations can be written nfc, nfd, nfkc and
+// nfkd. Spaces around the names, arguments and separators are ignored.
+func parseTransformExpr(expr string) ([]TransformStep, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, fmt.Errorf("%w: no operation", errInvalidExpr)
+	}
+
+	parts := strings.Split(expr, exprStepSeparator)
+	if len(parts) > pipelineMaxSteps {
+		return nil, fmt.Errorf("%w: %d operations, up to %d", errInvalidExpr, len(parts), pipelineMaxSteps)
+	}
+
+	plan := make([]TransformStep, len(parts))
+
+	for index, part := range parts {
+		step, err := parseTransformStep(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("%w: step %d %q: %w", errInvalidExpr, index+1, strings.TrimSpace(part), err)
+		}
+
+		plan[index] = step
+	}
+
+	return plan, nil
+}
+
+// parseTransformStep parses a step of a transform expression, i.e. the name of
+// the operation followed by its arguments in parentheses, if any.
+func parseTransformStep(text string) (TransformStep, error) {
+	name, args, hasArgs := strings.Cut(text, "(")
+	name = strings.TrimSpace(name)
+
+	if alias, ok := transformAliases[name]; ok {
+		name = alias
+	}
+
+	op, ok := findTransform(name)
+	if !ok {
+		return TransformStep{}, fmt.Errorf("%w %q", errUnknownStep, name)
+	}
+
+	step := TransformStep{Op: op.name}
+	if !hasArgs {
+		return step, nil
+	}
+
+	args, ok = strings.CutSuffix(args, ")")
+	if !ok {
+		return TransformStep{}, fmt.Errorf("%w: missing )", errExprSyntax)
+	}
+
+	if strings.TrimSpace(args) == "" {
+		return step, nil
+	}
+
+	for arg := range strings.SplitSeq(args, ",") {
+		key, value, ok := strings.Cut(arg, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		switch {
+		case !ok || key == "" || value == "":
+			return TransformStep{}, fmt.Errorf("%w: argument %q is not key=value", errExprSyntax, strings.TrimSpace(arg))
+		case key != exprArgLocale:
+			return TransformStep{}, fmt.Errorf("%w %q of %s", errUnknownArgument, key, op.name)
+		case step.Locale != "":
+			return TransformStep{}, fmt.Errorf("%w: argument %q given twice", errExprSyntax, key)
+		}
+
+		var err error
+
+		step, err = newTransformStep(op.name, value)
+		if err != nil {
+			return TransformStep{}, err
+		}
+	}
+
+	return step, nil
+}
+
+// newTransformStep returns the step of the named operation with the locale
+// argument, if not empty. The locale is rejected unless the operation depends
+// on it.
+func newTransformStep(name, locale string) (TransformStep, error) {
+	op, ok := findTransform(name)
+	if !ok {
+		return TransformStep{}, fmt.Errorf("%w %q", errUnknownStep, name)
+	}
+
+	if locale == "" {
+		return TransformStep{Op: op.name}, nil
+	}
+
+	if !op.localized {
+		return TransformStep{}, fmt.Errorf("%w %q of %s", errUnknownArgument, exprArgLocale, op.name)
+	}
+
+	_, err := parseLocale(exprArgLocale, locale)
+	if err != nil {
+		return TransformStep{}, err
+	}
+
+	return TransformStep{Op: op.name, Locale: locale}, nil
+}
+
+// planOfSteps returns the plan of the names of the operations, as given in the
+// steps of the pipeline tool.
+func planOfSteps(names []string) ([]TransformStep, error) {
+	plan := make([]TransformStep, len(names))
+
+	for index, name := range names {
+		op, ok := findTransform(name)
+		if !ok {
+			return nil, fmt.Errorf("steps[%d]: %w %q", index, errUnknownStep, name)
+		}
+
+		plan[index] = TransformStep{Op: op.name}
+	}
+
+	return plan, nil
+}
+
+// applyPlan applies the steps of the plan to the text in order and returns the
+// last result. The steps without locale use the given one, see resolveLocale.
+// It stops at the first failing step, or once ctx is canceled.
+func applyPlan(ctx context.Context, text string, plan []TransformStep, locale language.Tag) (string, error) {
+	for index, step := range plan {
+		op, ok := findTransform(step.Op)
+		if !ok {
+			return "", fmt.Errorf("steps[%d]: %w %q", index, errUnknownStep, step.Op)
+		}
+
+		stepLocale := locale
+
+		err := ctx.Err()
+		if err == nil && step.Locale != "" {
+			stepLocale, err = parseLocale(exprArgLocale, step.Locale)
+		}
+
+		if err == nil {
+			text, err = op.apply(ctx, text, stepLocale)
+		}
+
+		if err != nil {
+			return "", wrapError(err, "failed at steps[%d] (%s)", index, op.name)
+		}
+	}
+
+	return text, nil
+}
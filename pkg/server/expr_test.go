@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+// ----------------------------------------------------------------------------
+//  parseTransformExpr
+// ----------------------------------------------------------------------------
+
+func Test_parseTransformExpr(t *testing.T) {
+	t.Parallel()
+
+	for index, test := range []struct {
+		name string
+		expr string
+		want []TransformStep
+	}{
+		{"single", "mirror", []TransformStep{{Op: "mirror"}}},
+		{
+			"chain", "nfc | mirror | upper(locale=tr)",
+			[]TransformStep{{Op: "normalize"}, {Op: "mirror"}, {Op: "upper", Locale: "tr"}},
+		},
+		{"no_spaces", "nfkd|strip_accents|lower", []TransformStep{{Op: "normalize_nfkd"}, {Op: "strip_accents"}, {Op: "lower"}}},
+		{"spaces_in_args", " title ( locale = zh-Hant ) ", []TransformStep{{Op: "title", Locale: "zh-Hant"}}},
+		{"empty_args", "upper()", []TransformStep{{Op: "upper"}}},
+		{"full_names", "normalize | normalize_nfd", []TransformStep{{Op: "normalize"}, {Op: "normalize_nfd"}}},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		got, err := parseTransformExpr(test.expr)
+		require.NoError(t, err, name)
+		require.Equal(t, test.want, got, name)
+	}
+}
+
+func Test_parseTransformExpr_invalid(t *testing.T) {
+	t.Parallel()
+
+	for index, test := range []struct {
+		name    string
+		expr    string
+		wantErr error
+		wantMsg string
+	}{
+		{"empty", " ", errInvalidExpr, "no operation"},
+		{"unknown_operation", "nfc | reverse", errUnknownStep, `step 2 "reverse": unknown operation "reverse"`},
+		{"empty_step", "mirror || upper", errUnknownStep, `step 2 "": unknown operation ""`},
+		{"missing_paren", "upper(locale=tr", errExprSyntax, "missing )"},
+		{"trailing_text", "upper(locale=tr)x", errExprSyntax, "missing )"},
+		{"not_key_value", "upper(tr)", errExprSyntax, `argument "tr" is not key=value`},
+		{"unknown_argument", "upper(lang=tr)", errUnknownArgument, `unknown argument "lang" of upper`},
+		{"not_localized", "mirror(locale=tr)", errUnknownArgument, `unknown argument "locale" of mirror`},
+		{"twice", "upper(locale=tr, locale=az)", errExprSyntax, `argument "locale" given twice`},
+		{"invalid_locale", "upper(locale=!!)", errInvalidLocale, `invalid locale "!!"`},
+		{"too_many", strings.Repeat("trim|", pipelineMaxSteps) + "trim", errInvalidExpr, "33 operations, up to 32"},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		_, err := parseTransformExpr(test.expr)
+		require.ErrorIs(t, err, errInvalidExpr, name)
+		require.ErrorIs(t, err, test.wantErr, name)
+		require.ErrorContains(t, err, test.wantMsg, name)
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  applyPlan
+// ----------------------------------------------------------------------------
+
+func Test_applyPlan(t *testing.T) {
+	t.Parallel()
+
+	plan, err := parseTransformExpr("lower(locale=tr) | mirror")
+	require.NoError(t, err)
+
+	got, err := applyPlan(context.Background(), "ISTANBUL", plan, language.English)
+	require.NoError(t, err)
+	require.Equal(t, "lubnatsı", got, "the locale of the step should override the given one")
+
+	got, err = applyPlan(context.Background(), "ISTANBUL", []TransformStep{{Op: "lower"}}, language.Turkish)
+	require.NoError(t, err)
+	require.Equal(t, "ıstanbul", got, "steps without locale should use the given one")
+}
+
+func Test_applyPlan_failures(t *testing.T) {
+	t.Parallel()
+
+	_, err := applyPlan(context.Background(), "abc", []TransformStep{{Op: "mirror"}, {Op: "reverse"}}, language.Und)
+	require.ErrorIs(t, err, errUnknownStep)
+	require.ErrorContains(t, err, "steps[1]")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = applyPlan(ctx, "abc", []TransformStep{{Op: "trim"}}, language.Und)
+	require.ErrorIs(t, err, context.Canceled)
+	require.ErrorContains(t, err, "failed at steps[0] (trim)")
+}
@@ -0,0 +1,206 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// Batch file processing. E.g.: text-mirror files --out ./mirrored 'docs/*.txt'
+const (
+	cmdNameFiles = "files"
+
+	flagNameOut     = "out"      // output directory
+	flagNameInPlace = "in-place" // overwrites the files, keeping backups
+	flagNameBackup  = "backup"   // suffix of the backups of the files overwritten
+
+	backupSuffixDefault = ".bak"
+	outDirPerm          = os.FileMode(0o750)
+)
+
+// Predefined errors of the files subcommand.
+var (
+	errFilesUsage  = errors.New("usage: text-mirror files <--out dir|--in-place [--backup .bak]> <glob>...")
+	errFilesFailed = errors.New("some files failed")
+	errNoMatch     = errors.New("no file matches")
+	errFileTarget  = errors.New("output would overwrite")
+)
+
+// filesOptions are the options of the files subcommand.
+type filesOptions struct {
+	outDir  string // output directory. empty if in place
+	inPlace bool   // overwrite the files
+	backup  string // suffix of the backups if in place
+}
+
+// runFiles is the "files" subcommand. It mirrors the content of each file
+// matching the globs into the output directory, or in place keeping a backup,
+// and prints the result of each file and a summary to out. It returns
+// errFilesFailed if any file failed, after processing all the others.
+func runFiles(ctx context.Context, args []string, out io.Writer) error {
+	opts := new(filesOptions)
+
+	flags := flag.NewFlagSet(cmdNameFiles, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.StringVar(&opts.outDir, flagNameOut, "", "output directory")
+	flags.BoolVar(&opts.inPlace, flagNameInPlace, false, "overwrite the files")
+	flags.StringVar(&opts.backup, flagNameBackup, backupSuffixDefault, "suffix of the backups")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errFilesUsage, err)
+	}
+
+	if flags.NArg() == 0 || (opts.outDir == "") == !opts.inPlace || (opts.inPlace && opts.backup == "") {
+		return errFilesUsage
+	}
+
+	var paths []string
+
+	for _, pattern := range flags.Args() {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return fmt.Errorf("%w: %w", errFilesUsage, err)
+		}
+
+		if len(matches) == 0 {
+			return fmt.Errorf("%w: %s", errNoMatch, pattern)
+		}
+
+		paths = append(paths, matches...)
+	}
+
+	failed := 0
+
+	for _, path := range paths {
+		target, err := mirrorFile(ctx, path, opts)
+		if err != nil {
+			failed++
+
+			_, _ = fmt.Fprintf(out, "FAIL  %s: %v\n", path, err)
+
+			continue
+		}
+
+		_, _ = fmt.Fprintf(out, "OK    %s -> %s\n", path, target)
+	}
+
+	_, _ = fmt.Fprintf(out, "\n%d mirrored, %d failed\n", len(paths)-failed, failed)
+
+	if failed > 0 {
+		return fmt.Errorf("%w: %d of %d", errFilesFailed, failed, len(paths))
+	}
+
+	return nil
+}
+
+// mirrorFile mirrors the content of the file as given by opts, and returns the
+// path written. The file is read whole, since the last grapheme cluster comes
+// first in the output, and is rejected unless valid UTF-8. The output is written to a temporary file renamed once
+// complete, so that no partial output is left on failure.
+func mirrorFile(ctx context.Context, path string, opts *filesOptions) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("%w: not a regular file", errFileNotText)
+	}
+
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return "", err
+	}
+
+	if !utf8.Valid(data) {
+		return "", errFileNotText
+	}
+
+	// The output is written then dropped, so its buffer is reused between the
+	// files.
+	buf := buffers.get()
+	defer buffers.put(buf)
+
+	buf.Grow(len(data))
+
+	mirrored, err := appendMirrored(ctx, buf.AvailableBuffer(), data) // as in pipe mode
+	if err != nil {
+		return "", err
+	}
+
+	target := path
+
+	if opts.inPlace {
+		err = os.WriteFile(path+opts.backup, data, info.Mode().Perm())
+		if err != nil {
+			return "", wrapError(err, "failed to back up")
+		}
+	} else {
+		target, err = outputPath(path, info, opts.outDir)
+		if err != nil {
+			return "", err
+		}
+
+		err = os.MkdirAll(filepath.Dir(target), outDirPerm)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return target, writeFileAtomic(target, mirrored, info.Mode().Perm())
+}
+
+// outputPath returns the path of the output of the file at path, whose info is
+// given, in outDir. The relative directories of path are kept. It returns
+// errFileTarget if the output would be outside outDir, such as for the paths
+// with "..", or would be the file itself.
+func outputPath(path string, info os.FileInfo, outDir string) (string, error) {
+	target := filepath.Join(outDir, filepath.Base(path))
+	if !filepath.IsAbs(path) {
+		target = filepath.Join(outDir, path) // keep the relative directories
+	}
+
+	rel, err := filepath.Rel(filepath.Clean(outDir), target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %s is outside %s", errFileTarget, target, outDir)
+	}
+
+	if targetInfo, err := os.Stat(target); err == nil && os.SameFile(info, targetInfo) {
+		return "", fmt.Errorf("%w: %s is the input file. use --%s to overwrite it", errFileTarget, target, flagNameInPlace)
+	}
+
+	return target, nil
+}
+
+// writeFileAtomic writes the data to a temporary file in the directory of path
+// and renames it to path.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = os.Remove(temp.Name()) }() // no-op once renamed
+
+	_, err = temp.Write(data)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Chmod(temp.Name(), perm)
+	}
+
+	if err == nil {
+		err = os.Rename(temp.Name(), path)
+	}
+
+	return err
+}
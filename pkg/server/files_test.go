@@ -0,0 +1,143 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  runFiles
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // changes the working directory
+func Test_runFiles_out(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	require.NoError(t, os.MkdirAll("docs", 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join("docs", "a.txt"), []byte("Hello, 世界\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join("docs", "b.txt"), []byte("👨‍👩‍👧 🇯🇵\r\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join("docs", "c.txt"), []byte("\xff\xfe"), 0o600))
+
+	var out bytes.Buffer
+
+	err := runFiles(context.Background(), []string{"--out", "mirrored", "docs/*.txt"}, &out)
+	require.ErrorIs(t, err, errFilesFailed)
+	require.Contains(t, out.String(), "OK    "+filepath.Join("docs", "a.txt")+" -> "+filepath.Join("mirrored", "docs", "a.txt"))
+	require.Contains(t, out.String(), "FAIL  "+filepath.Join("docs", "c.txt")+": "+errFileNotText.Error())
+	require.Contains(t, out.String(), "2 mirrored, 1 failed")
+
+	for path, want := range map[string]string{
+		"a.txt": "界世 ,olleH\n",
+		"b.txt": "🇯🇵 👨‍👩‍👧\r\n",
+	} {
+		data, err := os.ReadFile(filepath.Join("mirrored", "docs", path))
+		require.NoError(t, err, path)
+		require.Equal(t, want, string(data), path)
+	}
+
+	_, err = os.Stat(filepath.Join("mirrored", "docs", "c.txt"))
+	require.ErrorIs(t, err, os.ErrNotExist, "failed files should not be written")
+
+	data, err := os.ReadFile(filepath.Join("docs", "a.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "Hello, 世界\n", string(data), "inputs should be left as is")
+}
+
+//nolint:paralleltest // changes the working directory
+func Test_runFiles_out_target(t *testing.T) {
+	root := t.TempDir()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "sub"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a.txt"), []byte("hello"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "sub", "b.txt"), []byte("hello"), 0o600))
+
+	for index, test := range []struct {
+		name string
+		dir  string
+		args []string
+		want string
+	}{
+		{"same_file", ".", []string{"--out", ".", "a.txt"}, "is the input file"},
+		{"same_file_via_dir", ".", []string{"--out", "sub/..", "a.txt"}, "is the input file"},
+		{"outside", "sub", []string{"--out", "out", "../sub/b.txt"}, "is outside out"},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		t.Chdir(filepath.Join(root, test.dir))
+
+		var out bytes.Buffer
+
+		err := runFiles(context.Background(), test.args, &out)
+		require.ErrorIs(t, err, errFilesFailed, name)
+		require.Contains(t, out.String(), errFileTarget.Error(), name)
+		require.Contains(t, out.String(), test.want, name)
+	}
+
+	for _, path := range []string{"a.txt", filepath.Join("sub", "b.txt")} {
+		data, err := os.ReadFile(filepath.Join(root, path))
+		require.NoError(t, err, path)
+		require.Equal(t, "hello", string(data), "the inputs should be left as is")
+	}
+
+	_, err := os.Stat(filepath.Join(root, "sub", "sub"))
+	require.ErrorIs(t, err, os.ErrNotExist, "nothing should be written outside the output directory")
+}
+
+func Test_runFiles_in_place(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "a.txt")
+	require.NoError(t, os.WriteFile(path, []byte("abc"), 0o600))
+
+	var out bytes.Buffer
+
+	err := runFiles(context.Background(), []string{"--in-place", "--backup", ".orig", path}, &out)
+	require.NoError(t, err)
+	require.Contains(t, out.String(), "1 mirrored, 0 failed")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "cba", string(data))
+
+	data, err = os.ReadFile(path + ".orig")
+	require.NoError(t, err)
+	require.Equal(t, "abc", string(data), "original should be backed up")
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	require.Len(t, entries, 2, "temporary files should be removed")
+}
+
+func Test_runFiles_usage(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	for index, test := range []struct {
+		name    string
+		args    []string
+		wantErr error
+	}{
+		{"no_glob", []string{"--out", dir}, errFilesUsage},
+		{"no_destination", []string{"*.txt"}, errFilesUsage},
+		{"both_destinations", []string{"--out", dir, "--in-place", "*.txt"}, errFilesUsage},
+		{"empty_backup", []string{"--in-place", "--backup", "", "*.txt"}, errFilesUsage},
+		{"unknown_flag", []string{"--unknown", "*.txt"}, errFilesUsage},
+		{"bad_pattern", []string{"--out", dir, "["}, errFilesUsage},
+		{"no_match", []string{"--out", dir, filepath.Join(dir, "*.none")}, errNoMatch},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		var out bytes.Buffer
+
+		err := runFiles(context.Background(), test.args, &out)
+		require.ErrorIs(t, err, test.wantErr, name)
+		require.Empty(t, out.String(), name)
+	}
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Command line flags other than the settings. See settings for the others.
+const (
+	flagNameConfig    = "config"     // path of the config file
+	flagNameVersion   = "version"    // prints the version and exits
+	flagNameHelp      = "help"       // prints the usage and exits
+	flagNameListTools = "list-tools" // prints the tools with their schemas and exits
+	flagNameDryRun    = "dry-run"    // validates the startup without serving and exits
+
+	usageHeader = `Usage: text-mirror [flags] [service <install [addr]|uninstall|run [addr]>]
+       text-mirror [--config file] config <validate [file]|init>
+       text-mirror mirror [--expr expression] [text...] < input
+       text-mirror files <--out dir|--in-place [--backup .bak]> <glob>...
+       text-mirror bench [--size bytes|KiB|MiB] [--scripts latin,...] [--duration 1s]
+       text-mirror fuzz [--seed n] [--iterations n] [--case n]
+       text-mirror [flags] <doctor|repl>
+       text-mirror [flags] install <vscode|claude|json> [--write [--yes] [--file path]]
+       text-mirror [flags] call <tool> [--json '{...}'] [--url http://host:port/mcp]
+
+MCP server mirroring (reversing) UTF-8 text while preserving grapheme clusters.
+It serves MCP over stdio by default, or over HTTP with --http-addr.
+
+Flags override the environment variables, which override the config file,
+which overrides the defaults of the profile (--profile dev|prod).
+
+Flags:
+`
+)
+
+// cliOptions are the parsed command line flags.
+type cliOptions struct {
+	configPath string
+	version    bool
+	help       bool
+	listTools  bool
+	dryRun     bool
+	env        map[string]string // values of the setting flags given, by environment variable
+	args       []string          // remaining arguments, i.e. the subcommand
+}
+
+// newFlagSet returns the flag set of the command line, which parses into opts.
+func newFlagSet(opts *cliOptions) *flag.FlagSet {
+	flags := flag.NewFlagSet(serviceName, flag.ContinueOnError)
+	flags.SetOutput(io.Discard) // errors are returned and usage is printed by the caller
+
+	flags.StringVar(&opts.configPath, flagNameConfig, "",
+		"config `file` (YAML, or TOML if .toml) (default \"$XDG_CONFIG_HOME/text-mirror/config.yaml\" if exists)")
+	flags.BoolVar(&opts.version, flagNameVersion, false, "print the version and exit")
+	flags.BoolVar(&opts.help, flagNameHelp, false, "print this help and exit")
+	flags.BoolVar(&opts.listTools, flagNameListTools, false, "print the tools with their JSON schemas as JSON and exit")
+	flags.BoolVar(&opts.dryRun, flagNameDryRun, false,
+		"load the settings, register the tools and bind the listener, then report what would run and exit")
+
+	for _, setting := range settings {
+		usage := setting.usage + " (env " + setting.envName() + ")"
+		set := func(value string) error {
+			opts.env[setting.envName()] = value
+
+			return nil
+		}
+
+		if setting.isBool {
+			flags.BoolFunc(setting.flagName(), usage, func(value string) error {
+				if _, err := strconv.ParseBool(value); err != nil {
+					return errInvalidBool
+				}
+
+				return set(value)
+			})
+
+			continue
+		}
+
+		flags.Func(setting.flagName(), usage, set)
+	}
+
+	return flags
+}
+
+// parseFlags parses the command line flags before the subcommand in args.
+func parseFlags(args []string) (*cliOptions, error) {
+	opts := new(cliOptions)
+	opts.env = make(map[string]string)
+
+	flags := newFlagSet(opts)
+
+	err := flags.Parse(args)
+	if errors.Is(err, flag.ErrHelp) { // -h
+		opts.help = true
+	} else if err != nil {
+		return nil, fmt.Errorf("%w. run '%s --help' for usage", err, serviceName)
+	}
+
+	opts.args = flags.Args()
+
+	return opts, nil
+}
+
+// printUsage prints the usage of the command line to w.
+func printUsage(w io.Writer) {
+	flags := newFlagSet(new(cliOptions))
+	flags.SetOutput(w)
+
+	_, _ = io.WriteString(w, usageHeader)
+
+	flags.PrintDefaults()
+}
@@ -0,0 +1,176 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  parseFlags
+// ----------------------------------------------------------------------------
+
+func Test_parseFlags(t *testing.T) {
+	t.Parallel()
+
+	for index, test := range []struct {
+		name    string
+		args    []string
+		want    cliOptions
+		wantErr string
+	}{
+		{"no_args", nil, cliOptions{env: map[string]string{}}, ""},
+		{
+			"subcommand_only", []string{"service", "run"},
+			cliOptions{env: map[string]string{}, args: []string{"service", "run"}}, "",
+		},
+		{
+			"config", []string{"--config", "a.yaml", "service", "run"},
+			cliOptions{configPath: "a.yaml", env: map[string]string{}, args: []string{"service", "run"}}, "",
+		},
+		{"joined_value", []string{"-config=a.toml"}, cliOptions{configPath: "a.toml", env: map[string]string{}}, ""},
+		{"version", []string{"--version"}, cliOptions{version: true, env: map[string]string{}}, ""},
+		{"help", []string{"--help"}, cliOptions{help: true, env: map[string]string{}}, ""},
+		{"help_short", []string{"-h"}, cliOptions{help: true, env: map[string]string{}}, ""},
+		{
+			"settings", []string{"--http-addr", ":8080", "--workers=2", "--admin", "--verify=false"},
+			cliOptions{env: map[string]string{
+				envNameHTTPAddr: ":8080",
+				envNameWorkers:  "2",
+				envNameAdmin:    "true",
+				envNameVerify:   "false",
+			}}, "",
+		},
+		{"missing_value", []string{"--config"}, cliOptions{}, "flag needs an argument: -config"},
+		{"unknown_flag", []string{"--unknown"}, cliOptions{}, "flag provided but not defined: -unknown"},
+		{"invalid_bool", []string{"--admin=maybe"}, cliOptions{}, errInvalidBool.Error()},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		opts, err := parseFlags(test.args)
+		if test.wantErr != "" {
+			require.ErrorContains(t, err, test.wantErr, name)
+			require.ErrorContains(t, err, "run 'text-mirror --help' for usage", name)
+
+			continue
+		}
+
+		require.NoError(t, err, name)
+
+		if len(opts.args) == 0 {
+			opts.args = nil // flag.Args returns an empty slice
+		}
+
+		require.Equal(t, test.want, *opts, name)
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  runCommand with flags
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // runs the command, which loads the settings
+func Test_runCommand_help_version(t *testing.T) {
+	for _, test := range []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"--help"}, []string{"Usage: text-mirror [flags]", "-http-addr address", "-config file", "-version"}},
+		{[]string{"-h", "service"}, []string{"Usage: text-mirror [flags]"}},
+		{[]string{"--version"}, []string{serviceName + " " + GetServiceVersion() + "\n"}},
+	} {
+		var out bytes.Buffer
+
+		app := newTestApp(t)
+		app.Stdout = &out
+
+		require.NoError(t, app.Run(context.Background(), test.args))
+
+		for _, want := range test.want {
+			require.Contains(t, out.String(), want, test.args)
+		}
+	}
+
+	// Version from the build info of the App
+	var out bytes.Buffer
+
+	app := newTestApp(t)
+	app.Stdout = &out
+	app.ReadBuildInfo = func() (*debug.BuildInfo, bool) {
+		info := new(debug.BuildInfo) // avoid exhaustruct lint error
+		info.Main.Version = "v1.2.3"
+
+		return info, true
+	}
+
+	require.NoError(t, app.Run(context.Background(), []string{"--version"}))
+	require.Equal(t, serviceName+" v1.2.3 (unknown)\n", out.String())
+}
+
+//nolint:paralleltest // sets env var
+func Test_runCommand_flags_precedence(t *testing.T) {
+	unsetEnv(t, envNameWorkers, envNameQueueDepth, envNamePageSize)
+
+	got := make(map[string]string)
+
+	app := newTestApp(t)
+	app.RunServer = func(_ context.Context, _ *mcp.Server) error {
+		for _, name := range []string{envNameWorkers, envNameQueueDepth, envNamePageSize} {
+			got[name] = settingValue(name)
+		}
+
+		return nil
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("limits:\n  workers: 1\n  queue_depth: 1\n  page_size: 1\n"), 0o600))
+
+	setEnv(t, envNameQueueDepth, "2")
+	setEnv(t, envNamePageSize, "2")
+
+	require.NoError(t, app.Run(context.Background(), []string{"--config", path, "--page-size", "3"}))
+	require.Equal(t, map[string]string{
+		envNameWorkers:    "1", // config file
+		envNameQueueDepth: "2", // env var over config file
+		envNamePageSize:   "3", // flag over env var
+	}, got)
+}
+
+//nolint:paralleltest // sets env var
+func Test_runCommand_invalid_flag_value(t *testing.T) {
+	unsetEnv(t, envNameWorkers)
+
+	err := newTestApp(t).Run(context.Background(), []string{"--workers", "-1"})
+	require.ErrorIs(t, err, errInvalidNumber, "flag values should be validated like the env vars")
+}
+
+//nolint:paralleltest // runs the command, which loads the settings
+func Test_runCommand_unknown_subcommand(t *testing.T) {
+	app := newTestApp(t)
+	app.RunServer = func(_ context.Context, _ *mcp.Server) error {
+		require.Fail(t, "server should not start with an unknown subcommand")
+
+		return nil
+	}
+
+	err := app.Run(context.Background(), []string{"doctr"})
+	require.ErrorIs(t, err, errUnknownCommand)
+	require.ErrorContains(t, err, `"doctr"`)
+}
+
+func Test_subcommands(t *testing.T) {
+	t.Parallel()
+
+	require.Len(t, subcommands, 10)
+
+	for name := range subcommands {
+		require.Regexp(t, `[ <|[]`+name+`\b`, usageHeader, "subcommand should be in the usage")
+	}
+}
@@ -0,0 +1,229 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/KEINOS/mcp-text-mirror/pkg/mirror"
+	"github.com/rivo/uniseg"
+)
+
+// Invariant checks of the reversal. E.g.: text-mirror fuzz --iterations 100000
+const (
+	cmdNameFuzz = "fuzz"
+
+	flagNameSeed       = "seed"       // seed of the random inputs
+	flagNameIterations = "iterations" // number of the inputs
+	flagNameCase       = "case"       // runs only the case of the seed, to reproduce a failure
+
+	fuzzIterationsDefault = 10000
+	fuzzMaxClusters       = 64 // max grapheme clusters of a generated input
+	fuzzMaxMutations      = 4  // max mutations of a mutated input
+	fuzzMaxReported       = 10 // failures reported before giving up
+)
+
+// Predefined errors of the fuzz subcommand.
+var (
+	errFuzzUsage  = errors.New("usage: text-mirror fuzz [--seed n] [--iterations n] [--case n]")
+	errFuzzFailed = errors.New("invariants violated")
+	errInvariant  = errors.New("invariant violated")
+)
+
+// fuzzClusters are the grapheme clusters the valid inputs are made of. None of
+// them joins the adjacent ones, whatever the order, so that the clusters of
+// the input are the same once reversed.
+//
+//nolint:gochecknoglobals // read-only table
+var fuzzClusters = []string{
+	"a", "Z", "0", " ", ".", "\t", "\r\n",
+	"é", "é", "ȫ", "नि", "日", "한", "ا",
+	"👍", "👍🏽", "👨‍👩‍👧‍👦", "🏳️‍🌈", "❤️", "🇯🇵", "🇺🇸",
+}
+
+// fuzzMutations are the bytes and runes inserted by the mutations: broken
+// UTF-8 sequences and the runes which join the adjacent ones into clusters.
+//
+//nolint:gochecknoglobals // read-only table
+var fuzzMutations = []string{
+	"\x80", "\xff", "\xc3", "\xe2\x80", "\xf0\x9f\x91",
+	"‍", "́", "️", "\U0001F3FD", "\U0001F1E6", "\r", "\n",
+	"ᄀ", "ᅡ", "ᆨ", "؀", "ำ",
+}
+
+// fuzzCase is a generated input. mutated inputs may be invalid UTF-8 and have
+// clusters which join once reversed.
+type fuzzCase struct {
+	input   string
+	mutated bool
+}
+
+// runFuzz is the "fuzz" subcommand. It reverses random and mutated inputs and
+// checks the invariants of the reversal, reporting the failing inputs with the
+// seed and the case number to reproduce them with --case.
+func runFuzz(ctx context.Context, args []string, out io.Writer) error {
+	seed := uint64(time.Now().UnixNano()) //nolint:gosec // any seed
+
+	var iterations, caseNumber int
+
+	flags := flag.NewFlagSet(cmdNameFuzz, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.Uint64Var(&seed, flagNameSeed, seed, "seed of the random inputs")
+	flags.IntVar(&iterations, flagNameIterations, fuzzIterationsDefault, "number of the inputs")
+	flags.IntVar(&caseNumber, flagNameCase, 0, "case of the seed to run")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errFuzzUsage, err)
+	}
+
+	switch {
+	case flags.NArg() > 0:
+		return fmt.Errorf("%w: unexpected arguments %q", errFuzzUsage, flags.Args())
+	case iterations <= 0:
+		return fmt.Errorf("%w: --%s must be positive, got %d", errFuzzUsage, flagNameIterations, iterations)
+	case caseNumber < 0:
+		return fmt.Errorf("%w: --%s must not be negative, got %d", errFuzzUsage, flagNameCase, caseNumber)
+	}
+
+	first, last := 1, iterations
+	if caseNumber > 0 {
+		first, last = caseNumber, caseNumber
+	}
+
+	failed := 0
+
+	for number := first; number <= last && failed < fuzzMaxReported; number++ {
+		test := newFuzzCase(seed, number)
+
+		err := checkReverse(ctx, test)
+		if err == nil {
+			continue
+		}
+
+		failed++
+
+		_, _ = fmt.Fprintf(out, "FAIL case %d: %v\n  input: %q\n  reproduce: text-mirror fuzz --seed %d --case %d\n",
+			number, err, test.input, seed, number)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%w: %d failure(s) with seed %d", errFuzzFailed, failed, seed)
+	}
+
+	_, _ = fmt.Fprintf(out, "ok: %d case(s) with seed %d\n", last-first+1, seed)
+
+	return nil
+}
+
+// newFuzzCase returns the input of the case number of the seed. The same seed
+// and number always give the same input.
+func newFuzzCase(seed uint64, number int) fuzzCase {
+	random := rand.New(rand.NewPCG(seed, uint64(number))) //nolint:gosec // reproducible, not secret
+
+	var input strings.Builder
+
+	for range random.IntN(fuzzMaxClusters + 1) {
+		input.WriteString(fuzzClusters[random.IntN(len(fuzzClusters))])
+	}
+
+	test := fuzzCase{input: input.String(), mutated: random.IntN(2) == 0}
+	if !test.mutated {
+		return test
+	}
+
+	data := []byte(test.input)
+
+	for range 1 + random.IntN(fuzzMaxMutations) {
+		at := 0
+		if len(data) > 0 {
+			at = random.IntN(len(data))
+		}
+
+		switch random.IntN(3) {
+		case 0: // insert
+			data = append(data[:at], append([]byte(fuzzMutations[random.IntN(len(fuzzMutations))]), data[at:]...)...)
+		case 1: // delete
+			if len(data) > 0 {
+				data = append(data[:at], data[at+1:]...)
+			}
+		default: // replace
+			if len(data) > 0 {
+				data[at] = byte(random.IntN(256)) //nolint:gosec // 0-255
+			}
+		}
+	}
+
+	test.input = string(data)
+
+	return test
+}
+
+// checkReverse reverses the input, without panicking, and checks that:
+//
+//   - the output is the grapheme clusters of the input in reverse order,
+//     therefore of the same length, and valid UTF-8 if the input is.
+//   - for valid inputs, whose clusters don't join once reversed, reversing
+//     twice gives the input back and the number of clusters is preserved.
+func checkReverse(ctx context.Context, test fuzzCase) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("%w: panic: %v", errInvariant, recovered)
+		}
+	}()
+
+	output, err := mirror.Reverse(ctx, test.input)
+	if err != nil {
+		return err
+	}
+
+	var want strings.Builder
+
+	for _, cluster := range slices.Backward(graphemeClusters(test.input)) {
+		want.WriteString(cluster)
+	}
+
+	switch {
+	case output != want.String():
+		return fmt.Errorf("%w: output %q is not the clusters in reverse order", errInvariant, output)
+	case utf8.ValidString(test.input) && !utf8.ValidString(output):
+		return fmt.Errorf("%w: output %q is invalid UTF-8", errInvariant, output)
+	case test.mutated:
+		return nil
+	}
+
+	back, err := mirror.Reverse(ctx, output)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case back != test.input:
+		return fmt.Errorf("%w: reversed twice %q", errInvariant, back)
+	case uniseg.GraphemeClusterCount(output) != uniseg.GraphemeClusterCount(test.input):
+		return fmt.Errorf("%w: grapheme count changed in %q", errInvariant, output)
+	}
+
+	return nil
+}
+
+// graphemeClusters returns the grapheme clusters of the text.
+func graphemeClusters(text string) []string {
+	var clusters []string
+
+	state := -1
+
+	for cluster := ""; text != ""; {
+		cluster, text, _, state = uniseg.FirstGraphemeClusterInString(text, state)
+		clusters = append(clusters, cluster)
+	}
+
+	return clusters
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  runFuzz
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // runs the command, which loads the settings
+func Test_runCommand_fuzz(t *testing.T) {
+	var out bytes.Buffer
+
+	app := newTestApp(t)
+	app.Stdout = &out
+
+	err := app.Run(context.Background(), []string{cmdNameFuzz, "--seed", "42", "--iterations", "2000"})
+	require.NoError(t, err)
+	require.Equal(t, "ok: 2000 case(s) with seed 42\n", out.String())
+}
+
+//nolint:paralleltest // replaces fuzzClusters
+func Test_runFuzz_failure(t *testing.T) {
+	orig := fuzzClusters
+
+	defer func() { fuzzClusters = orig }()
+
+	// "\n\r" becomes "\r\n", a single cluster, once reversed
+	fuzzClusters = []string{"\n", "\r"}
+
+	var out bytes.Buffer
+
+	err := runFuzz(context.Background(), []string{"--seed", "1", "--iterations", "100"}, &out)
+	require.ErrorIs(t, err, errFuzzFailed)
+	require.Equal(t, fuzzMaxReported, strings.Count(out.String(), "FAIL case "), "reports should be capped")
+	require.Contains(t, out.String(), "reproduce: text-mirror fuzz --seed 1 --case ")
+
+	// The reported case reproduces the failure alone
+	var number int
+
+	_, err = fmt.Sscanf(out.String(), "FAIL case %d:", &number)
+	require.NoError(t, err)
+
+	var again bytes.Buffer
+
+	err = runFuzz(context.Background(), []string{"--seed", "1", "--case", fmt.Sprint(number)}, &again)
+	require.ErrorIs(t, err, errFuzzFailed)
+	require.Equal(t, strings.SplitAfterN(out.String(), "\n", 4)[:3], strings.SplitAfterN(again.String(), "\n", 4)[:3])
+}
+
+func Test_runFuzz_invalid(t *testing.T) {
+	t.Parallel()
+
+	for index, args := range [][]string{
+		{"--iterations", "0"},
+		{"--case", "-1"},
+		{"--seed", "abc"},
+		{"extra"},
+	} {
+		name := fmt.Sprintf("Test #%d: %v", index+1, args)
+
+		err := runFuzz(context.Background(), args, new(bytes.Buffer))
+		require.ErrorIs(t, err, errFuzzUsage, name)
+		require.NotContains(t, err.Error(), "%!", name)
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  newFuzzCase
+// ----------------------------------------------------------------------------
+
+func Test_newFuzzCase_reproducible(t *testing.T) {
+	t.Parallel()
+
+	mutated := 0
+
+	for number := 1; number <= 100; number++ {
+		test := newFuzzCase(7, number)
+		require.Equal(t, test, newFuzzCase(7, number), "same seed and case should give the same input")
+
+		if test.mutated {
+			mutated++
+		}
+	}
+
+	require.NotZero(t, mutated, "some inputs should be mutated")
+	require.NotEqual(t, 100, mutated, "some inputs should be valid")
+}
+
+// ----------------------------------------------------------------------------
+//  checkReverse
+// ----------------------------------------------------------------------------
+
+func Test_checkReverse(t *testing.T) {
+	t.Parallel()
+
+	for index, test := range []struct {
+		name    string
+		test    fuzzCase
+		wantErr bool
+	}{
+		{"valid", fuzzCase{input: "a👨‍👩‍👧‍👦🇯🇵é", mutated: false}, false},
+		{"invalid_utf8", fuzzCase{input: "a\xffb\xe2\x80", mutated: true}, false},
+		{"joining_once_reversed", fuzzCase{input: "\n\r", mutated: true}, false},
+		{"not_reversible", fuzzCase{input: "\n\r", mutated: false}, true},
+		{"odd_regional_indicators", fuzzCase{input: "🇯🇵🇺", mutated: false}, true},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		err := checkReverse(context.Background(), test.test)
+		if test.wantErr {
+			require.ErrorIs(t, err, errInvariant, name)
+
+			continue
+		}
+
+		require.NoError(t, err, name)
+	}
+}
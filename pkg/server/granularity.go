@@ -0,0 +1,226 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/KEINOS/mcp-text-mirror/pkg/mirror"
+	"github.com/rivo/uniseg"
+)
+
+// Values of the granularity argument of the mirror tool, i.e. the unit of the
+// reversal.
+const (
+	granularityGrapheme = "grapheme" // grapheme clusters, the characters as perceived by the users (default)
+	granularityRune     = "rune"     // Unicode code points
+	granularityByte     = "byte"     // bytes of the UTF-8 encoding
+	granularityWord     = "word"     // word segments per UAX #29: the words, the runs of spaces and the punctuation
+	granularityLine     = "line"     // lines, without their line breaks
+)
+
+// Predefined errors of the granularity.
+var (
+	// errInvalidGranularity is returned for an unknown granularity, which the
+	// schema normally rejects beforehand.
+	errInvalidGranularity = errors.New("granularity must be grapheme, rune, byte, word or line")
+	// errNonASCIIBytes is returned for the byte granularity of a text which is
+	// not ASCII, whose reversed bytes would not be valid UTF-8.
+	errNonASCIIBytes = errors.New("byte granularity needs an ASCII text. use rune or grapheme")
+)
+
+// granularities returns the values of the granularity argument, as the enum of
+// the schema.
+func granularities() []any {
+	return []any{granularityGrapheme, granularityRune, granularityByte, granularityWord, granularityLine}
+}
+
+// reverseBy returns the text reversed by the unit of the granularity. The
+// grapheme clusters are reversed as the mirror tool does by default, reporting
+// the progress to progress if not nil. It stops once ctx is canceled, whatever
+// the granularity.
+func reverseBy(ctx context.Context, text, granularity string, progress mirror.ProgressFunc) (string, error) {
+	switch granularity {
+	case "", granularityGrapheme:
+		return mirror.Reverse(ctx, text, mirror.WithParallelism(runtime.GOMAXPROCS(0)),
+			mirror.WithProgress(progress, progressInterval))
+	case granularityRune:
+		return reverseRunes(ctx, text)
+	case granularityByte:
+		return reverseBytes(ctx, text)
+	case granularityWord:
+		reversed, _, err := reverseWords(ctx, text, false)
+
+		return reversed, err
+	case granularityLine:
+		reversed, _, err := reverseLines(ctx, text)
+
+		return reversed, err
+	default:
+		return "", fmt.Errorf("%w: %q given", errInvalidGranularity, granularity)
+	}
+}
+
+// reverseRunes returns the text reversed by code points. The invalid UTF-8
+// bytes are kept as they are, one by one. It stops once ctx is canceled,
+// checking every mirror.CheckInterval code points.
+func reverseRunes(ctx context.Context, text string) (string, error) {
+	var builder strings.Builder
+
+	builder.Grow(len(text))
+
+	for count, end := 0, len(text); end > 0; count++ {
+		if count%mirror.CheckInterval == 0 && ctx.Err() != nil {
+			return "", wrapError(ctx.Err(), "request canceled after %d runes", count)
+		}
+
+		_, size := utf8.DecodeLastRuneInString(text[:end])
+		builder.WriteString(text[end-size : end])
+
+		end -= size
+	}
+
+	return builder.String(), nil
+}
+
+// reverseBytes returns the ASCII text reversed byte by byte, or
+// errNonASCIIBytes at the first byte out of ASCII. It stops once ctx is
+// canceled, checking every mirror.CheckInterval bytes.
+func reverseBytes(ctx context.Context, text string) (string, error) {
+	reversed := make([]byte, len(text))
+
+	for index := range len(text) {
+		if index%mirror.CheckInterval == 0 && ctx.Err() != nil {
+			return "", wrapError(ctx.Err(), "request canceled after %d bytes", index)
+		}
+
+		if text[index] >= utf8.RuneSelf {
+			return "", fmt.Errorf("%w: byte %#x at %d", errNonASCIIBytes, text[index], index)
+		}
+
+		reversed[len(text)-1-index] = text[index]
+	}
+
+	return string(reversed), nil
+}
+
+// reverseWords returns the text with its word segments in the reverse order,
+// each one kept as is, and the number of words. The text is split at the word
+// boundaries of UAX #29, and the segments with a letter, a digit or a symbol
+// such as an emoji are the words, the others being the whitespace and the
+// punctuation.
+//
+// If keepPunct is true, only the words are reversed, the whitespace and the
+// punctuation staying in place, as the reverse_words tool does. Otherwise all
+// the segments are, as the word granularity of the mirror tool does. It stops
+// once ctx is canceled, checking every mirror.CheckInterval segments.
+func reverseWords(ctx context.Context, text string, keepPunct bool) (string, int, error) {
+	var (
+		segments []string
+		words    []int // indexes of the words in segments
+	)
+
+	state := -1
+
+	for rest := text; rest != ""; {
+		if len(segments)%mirror.CheckInterval == 0 && ctx.Err() != nil {
+			return "", 0, wrapError(ctx.Err(), "request canceled after %d words", len(words))
+		}
+
+		var segment string
+
+		segment, rest, state = uniseg.FirstWordInString(rest, state)
+		if isWord(segment) {
+			words = append(words, len(segments))
+		}
+
+		segments = append(segments, segment)
+	}
+
+	if !keepPunct {
+		slices.Reverse(segments)
+
+		return strings.Join(segments, ""), len(words), nil
+	}
+
+	for left, right := 0, len(words)-1; left < right; left, right = left+1, right-1 {
+		segments[words[left]], segments[words[right]] = segments[words[right]], segments[words[left]]
+	}
+
+	return strings.Join(segments, ""), len(words), nil
+}
+
+// isWord returns whether the word segment is a word rather than whitespace or
+// punctuation.
+func isWord(segment string) bool {
+	return strings.ContainsFunc(segment, func(r rune) bool {
+		return !unicode.IsSpace(r) && !unicode.IsPunct(r)
+	})
+}
+
+// splitLines returns the lines of the text without their line breaks, and the
+// line breaks, LF or CR LF, after each line but the last. A text ending with a
+// line break has an empty last line.
+func splitLines(text string) ([]string, []string) {
+	var lines, breaks []string
+
+	for {
+		index := strings.IndexByte(text, '\n')
+		if index < 0 {
+			return append(lines, text), breaks
+		}
+
+		line, lineBreak := text[:index], "\n"
+		if strings.HasSuffix(line, "\r") {
+			line, lineBreak = line[:len(line)-1], "\r\n"
+		}
+
+		lines = append(lines, line)
+		breaks = append(breaks, lineBreak)
+		text = text[index+1:]
+	}
+}
+
+// reverseLines returns the text with its lines in the reverse order, each one
+// kept as is, and the number of lines. The line breaks stay where they are, so
+// that a trailing line break stays trailing and the mixed LF and CR LF keep
+// their positions. The empty text has no line. It stops once ctx is canceled,
+// checking every mirror.CheckInterval lines.
+func reverseLines(ctx context.Context, text string) (string, int, error) {
+	if text == "" {
+		return "", 0, nil
+	}
+
+	lines, breaks := splitLines(text)
+
+	// The empty line after a trailing line break stays last.
+	last := len(lines)
+	if len(breaks) > 0 && lines[last-1] == "" {
+		last--
+	}
+
+	slices.Reverse(lines[:last])
+
+	var builder strings.Builder
+
+	builder.Grow(len(text))
+
+	for index, line := range lines {
+		if index%mirror.CheckInterval == 0 && ctx.Err() != nil {
+			return "", 0, wrapError(ctx.Err(), "request canceled after %d lines", index)
+		}
+
+		builder.WriteString(line)
+
+		if index < len(breaks) {
+			builder.WriteString(breaks[index])
+		}
+	}
+
+	return builder.String(), last, nil
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/KEINOS/mcp-text-mirror/pkg/mirror"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  reverseBy
+// ----------------------------------------------------------------------------
+
+func Test_reverseBy(t *testing.T) {
+	t.Parallel()
+
+	for index, test := range []struct {
+		name        string
+		granularity string
+		input       string
+		expected    string
+	}{
+		{"default", "", "a👍🏽é", "é👍🏽a"},
+		{"grapheme", granularityGrapheme, "a👍🏽é", "é👍🏽a"},
+		{"rune", granularityRune, "ab́", "́ba"},
+		{"rune_invalid_utf8", granularityRune, "a\xffé", "é\xffa"},
+		{"byte", granularityByte, "abc", "cba"},
+		{"word", granularityWord, "Hello, big world!", "!world big ,Hello"},
+		{"word_spaces", granularityWord, " one  two ", " two  one "},
+		{"word_emoji", granularityWord, "I 👍🏽 café", "café 👍🏽 I"},
+		{"line", granularityLine, "a\nb\nc", "c\nb\na"},
+		{"line_trailing_break", granularityLine, "a\nb\n", "b\na\n"},
+		{"line_crlf", granularityLine, "a\r\nb\nc", "c\r\nb\na"},
+		{"line_empty_lines", granularityLine, "a\n\nb", "b\n\na"},
+		{"line_single", granularityLine, "abc", "abc"},
+		{"empty", granularityWord, "", ""},
+	} {
+		t.Run(fmt.Sprintf("Test #%d: %s", index+1, test.name), func(t *testing.T) {
+			t.Parallel()
+
+			got, err := reverseBy(context.Background(), test.input, test.granularity, nil)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, got)
+		})
+	}
+}
+
+func Test_reverseBy_errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := reverseBy(context.Background(), "abc", "sentence", nil)
+	require.ErrorIs(t, err, errInvalidGranularity)
+
+	_, err = reverseBy(context.Background(), "abé", granularityByte, nil)
+	require.ErrorIs(t, err, errNonASCIIBytes)
+	require.ErrorContains(t, err, "byte 0xc3 at 2")
+
+	_, err = reverseBy(context.Background(), "a\xff", granularityByte, nil)
+	require.ErrorIs(t, err, errNonASCIIBytes, "invalid UTF-8 should be rejected too")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	large := strings.Repeat("a b\n", 2*mirror.CheckInterval)
+
+	for _, granularity := range []string{granularityGrapheme, granularityRune, granularityByte, granularityWord, granularityLine} {
+		_, err = reverseBy(ctx, large, granularity, nil)
+		require.ErrorIs(t, err, context.Canceled, "large input should be canceled: %s", granularity)
+	}
+}
+
+func Test_handleReverse_granularity(t *testing.T) {
+	t.Parallel()
+
+	session := connectInMemory(t, newServer())
+
+	res := callTool(t, session, toolName, map[string]any{"text": "one two\nthree", "granularity": granularityLine})
+	require.False(t, res.IsError)
+	require.Equal(t, "three\none two", res.StructuredContent.(map[string]any)["text"]) //nolint:forcetypeassert // structured output
+
+	params := new(mcp.CallToolParams)
+	params.Name = toolName
+	params.Arguments = map[string]any{"text": "abc", "granularity": "sentence"}
+
+	_, err := session.CallTool(context.Background(), params)
+	require.ErrorContains(t, err, "granularity", "unknown granularity should be rejected by the schema")
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"math"
+	"time"
+)
+
+// Buckets of the latency histograms. The bounds grow exponentially, so that
+// the percentiles are within histogramGrowth/2 of the actual latency from
+// microseconds to hours with a fixed memory per tool.
+const (
+	histogramMin     = time.Microsecond // upper bound of the first bucket
+	histogramMax     = time.Hour        // latencies above fall in the last bucket
+	histogramGrowth  = 1.05             // ratio of the bounds of the consecutive buckets
+	histogramBuckets = 452              // ceil(log(histogramMax/histogramMin) / log(histogramGrowth)) + 1
+)
+
+// latencyHistogram is a streaming histogram of latencies, in the manner of the
+// HDR histograms. It counts all the latencies recorded without keeping them.
+type latencyHistogram struct {
+	counts [histogramBuckets]uint64
+	total  uint64
+	min    time.Duration
+	max    time.Duration
+}
+
+// record adds the latency to the histogram.
+func (h *latencyHistogram) record(latency time.Duration) {
+	h.counts[histogramBucket(latency)]++
+
+	if h.total == 0 || latency < h.min {
+		h.min = latency
+	}
+
+	if latency > h.max {
+		h.max = latency
+	}
+
+	h.total++
+}
+
+// quantile returns the nearest-rank quantile of the latencies, such as 0.95
+// for the 95th percentile, or zero if none. It is the middle of the bucket of
+// the rank, bounded by the smallest and the largest latencies recorded.
+func (h *latencyHistogram) quantile(q float64) time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+
+	rank := max(uint64(math.Ceil(q*float64(h.total))), 1)
+
+	var seen uint64
+
+	for index, count := range h.counts {
+		seen += count
+		if seen >= rank {
+			return min(max(histogramMiddle(index), h.min), h.max)
+		}
+	}
+
+	return h.max
+}
+
+// histogramBucket returns the index of the bucket of the latency.
+func histogramBucket(latency time.Duration) int {
+	if latency <= histogramMin {
+		return 0
+	}
+
+	index := int(math.Ceil(math.Log(float64(latency)/float64(histogramMin)) / math.Log(histogramGrowth)))
+
+	return min(index, histogramBuckets-1)
+}
+
+// histogramMiddle returns the geometric middle of the bucket of the index.
+func histogramMiddle(index int) time.Duration {
+	if index == 0 {
+		return histogramMin
+	}
+
+	return time.Duration(float64(histogramMin) * math.Pow(histogramGrowth, float64(index)-0.5))
+}
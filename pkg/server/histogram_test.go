@@ -0,0 +1,71 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  latencyHistogram
+// ----------------------------------------------------------------------------
+
+func Test_histogramBuckets(t *testing.T) {
+	t.Parallel()
+
+	want := int(math.Ceil(math.Log(float64(histogramMax/histogramMin))/math.Log(histogramGrowth))) + 1
+	require.Equal(t, want, histogramBuckets, "histogramBuckets should cover up to histogramMax")
+	require.Equal(t, histogramBuckets-1, histogramBucket(histogramMax))
+	require.Equal(t, histogramBuckets-2, histogramBucket(histogramMax*95/100))
+	require.Equal(t, histogramBuckets-1, histogramBucket(24*time.Hour), "longer latencies should fall in the last bucket")
+	require.Equal(t, 0, histogramBucket(0))
+}
+
+func Test_latencyHistogram_quantile(t *testing.T) {
+	t.Parallel()
+
+	uniform := new(latencyHistogram)
+	for i := range 1000 {
+		uniform.record(time.Duration(i+1) * time.Millisecond)
+	}
+
+	single := new(latencyHistogram)
+	single.record(1500 * time.Microsecond)
+
+	for index, test := range []struct {
+		name      string
+		histogram *latencyHistogram
+		quantile  float64
+		want      time.Duration
+	}{
+		{"empty", new(latencyHistogram), 0.5, 0},
+		{"single", single, 0.99, 1500 * time.Microsecond},
+		{"p0", uniform, 0, time.Millisecond},
+		{"p50", uniform, 0.5, 500 * time.Millisecond},
+		{"p95", uniform, 0.95, 950 * time.Millisecond},
+		{"p99", uniform, 0.99, 990 * time.Millisecond},
+		{"p100", uniform, 1, time.Second},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		got := test.histogram.quantile(test.quantile)
+		if test.want == 0 {
+			require.Zero(t, got, name)
+
+			continue
+		}
+
+		require.InEpsilon(t, float64(test.want), float64(got), (histogramGrowth-1)/2, name)
+	}
+}
+
+func Benchmark_latencyHistogram_record(b *testing.B) {
+	histogram := new(latencyHistogram)
+
+	for i := 0; b.Loop(); i++ {
+		histogram.record(time.Duration(i%1000) * time.Microsecond)
+	}
+}
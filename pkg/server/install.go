@@ -0,0 +1,261 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Client configuration snippets. E.g.: text-mirror --admin install vscode --write
+const (
+	cmdNameInstall = "install"
+
+	clientVSCode  = "vscode" // VS Code (Copilot) mcp.json
+	clientClaude  = "claude" // Claude Desktop claude_desktop_config.json
+	clientGeneric = "json"   // server entry only, for other clients
+
+	flagNameWrite = "write" // writes the snippet into the config file of the client
+	flagNameYes   = "yes"   // writes without confirmation
+	flagNameFile  = "file"  // config file of the client instead of the default one
+
+	installServerName = "text-mirror" // name of the server entry
+	installFilePerm   = 0o600         // permission of the config files created
+)
+
+// Predefined errors of the install subcommand.
+var (
+	errInstallUsage    = errors.New("usage: text-mirror [flags] install <vscode|claude|json> [--write [--yes] [--file path]]")
+	errInstallCanceled = errors.New("canceled")
+	errClientConfig    = errors.New("invalid client config file")
+)
+
+// installClients are the root keys of the server entries in the config files
+// of the clients, and the paths of the files relative to the user's config
+// directory.
+//
+//nolint:gochecknoglobals // read-only table
+var installClients = map[string]struct{ serversKey, path string }{
+	clientVSCode: {"servers", filepath.Join("Code", "User", "mcp.json")},
+	clientClaude: {"mcpServers", filepath.Join("Claude", "claude_desktop_config.json")},
+}
+
+// installEntry is the server entry of the client config files.
+type installEntry struct {
+	Type    string   `json:"type,omitempty"`
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+}
+
+// runInstall is the "install" subcommand. It prints the configuration of the
+// client to start the running executable with the setting flags given before
+// the subcommand, such as --admin, and the --config file. With --write, it
+// adds the server entry to the config file of the client after confirmation
+// read from in, keeping the other entries.
+func runInstall(args []string, opts *cliOptions, in io.Reader, out io.Writer) error {
+	var write, yes bool
+
+	var path, client string
+
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		client, args = args[0], args[1:]
+	}
+
+	flags := flag.NewFlagSet(cmdNameInstall, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.BoolVar(&write, flagNameWrite, false, "write into the config file of the client")
+	flags.BoolVar(&yes, flagNameYes, false, "write without confirmation")
+	flags.StringVar(&path, flagNameFile, "", "config file of the client")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errInstallUsage, err)
+	}
+
+	_, known := installClients[client]
+	if flags.NArg() > 0 || (!known && client != clientGeneric) || (write && client == clientGeneric) {
+		return errInstallUsage
+	}
+
+	entry, err := newInstallEntry(opts)
+	if err != nil {
+		return err
+	}
+
+	if !write {
+		return wrapError(printJSON(out, installSnippet(client, entry)), "failed to write the snippet")
+	}
+
+	if path == "" {
+		path, err = clientConfigPath(client)
+		if err != nil {
+			return err
+		}
+	}
+
+	data, err := mergeClientConfig(path, client, entry)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(out, "%s\n", data)
+
+	if !yes {
+		_, _ = fmt.Fprintf(out, "Write the above to %s? [y/N] ", path)
+
+		answer, _ := bufio.NewReader(in).ReadString('\n')
+		if !slices.Contains([]string{"y", "yes"}, strings.ToLower(strings.TrimSpace(answer))) {
+			return errInstallCanceled
+		}
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), outDirPerm)
+	if err == nil {
+		err = writeFileAtomic(path, data, installFilePerm)
+	}
+
+	if err != nil {
+		return wrapError(err, "failed to write %s", path)
+	}
+
+	_, err = fmt.Fprintf(out, "Wrote %s. Restart the client to load %s.\n", path, installServerName)
+
+	return err
+}
+
+// newInstallEntry returns the server entry starting the running executable with
+// the setting flags given and the --config file, both checked beforehand.
+func newInstallEntry(opts *cliOptions) (*installEntry, error) {
+	command, err := os.Executable()
+	if err != nil {
+		return nil, wrapError(err, "failed to get executable path")
+	}
+
+	if resolved, err := filepath.EvalSymlinks(command); err == nil {
+		command = resolved
+	}
+
+	entry := new(installEntry)
+	entry.Command = command
+
+	if opts.configPath != "" {
+		configPath, err := filepath.Abs(opts.configPath) // clients start the server from anywhere
+		if err != nil {
+			return nil, wrapError(err, "failed to resolve the config file path")
+		}
+
+		entry.Args = append(entry.Args, "--"+flagNameConfig+"="+configPath)
+	}
+
+	var errs []error
+
+	values := mergeSettings(opts.env, nil, nil) // of the flags only
+
+	for _, s := range settings { // in the order of the usage
+		value, ok := opts.env[s.envName()]
+		if !ok {
+			continue
+		}
+
+		if s.check != nil {
+			if err := s.check(values); err != nil {
+				errs = append(errs, fmt.Errorf("--%s: %w", s.flagName(), err))
+			}
+		}
+
+		entry.Args = append(entry.Args, "--"+s.flagName()+"="+value)
+	}
+
+	if len(errs) > 0 {
+		return nil, wrapError(errors.Join(errs...), "invalid flags")
+	}
+
+	return entry, nil
+}
+
+// installSnippet returns the configuration of the client with the server entry.
+// The generic one is the entry alone.
+func installSnippet(client string, entry *installEntry) any {
+	if client == clientGeneric {
+		return entry
+	}
+
+	return map[string]any{installClients[client].serversKey: map[string]any{installServerName: clientEntry(client, entry)}}
+}
+
+// clientEntry returns the server entry as the client expects it. VS Code gets
+// the transport type, which it otherwise guesses.
+func clientEntry(client string, entry *installEntry) *installEntry {
+	if client != clientVSCode {
+		return entry
+	}
+
+	vscodeEntry := *entry
+	vscodeEntry.Type = "stdio"
+
+	return &vscodeEntry
+}
+
+// clientConfigPath returns the path of the user's config file of the client.
+func clientConfigPath(client string) (string, error) {
+	dir, err := os.UserConfigDir() // where the clients look, regardless of XDG_CONFIG_HOME on macOS
+	if err != nil {
+		return "", wrapError(err, "failed to get the user's config directory")
+	}
+
+	return filepath.Join(dir, installClients[client].path), nil
+}
+
+// mergeClientConfig returns the content of the config file of the client at
+// path with the server entry added, replacing the previous one if any. The
+// other settings are kept, in the sorted order of the keys.
+func mergeClientConfig(path, client string, entry *installEntry) ([]byte, error) {
+	var config map[string]any
+
+	serversKey := installClients[client].serversKey
+
+	data, err := os.ReadFile(filepath.Clean(path))
+
+	switch {
+	case errors.Is(err, os.ErrNotExist): // created
+	case err != nil:
+		return nil, wrapError(err, "failed to read %s", path)
+	case len(bytes.TrimSpace(data)) > 0:
+		err = json.Unmarshal(data, &config) // comments of JSONC are rejected rather than lost
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: expected a JSON object without comments: %w", errClientConfig, path, err)
+		}
+	}
+
+	if config == nil { // new, empty or null
+		config = make(map[string]any)
+	}
+
+	servers, ok := config[serversKey].(map[string]any)
+	if config[serversKey] != nil && !ok {
+		return nil, fmt.Errorf("%w: %s: %q is not an object", errClientConfig, path, serversKey)
+	}
+
+	if servers == nil {
+		servers = make(map[string]any)
+	}
+
+	servers[installServerName] = clientEntry(client, entry)
+	config[serversKey] = servers
+
+	var buf bytes.Buffer
+
+	err = printJSON(&buf, config)
+	if err != nil {
+		return nil, wrapError(err, "failed to encode %s", path)
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
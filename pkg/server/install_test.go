@@ -0,0 +1,136 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  runInstall
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_runInstall_snippets(t *testing.T) {
+	opts, err := parseFlags([]string{"--config", "config.yaml", "--admin", "--rate-limit", "5"})
+	require.NoError(t, err)
+
+	command, err := os.Executable()
+	require.NoError(t, err)
+
+	command, err = filepath.EvalSymlinks(command)
+	require.NoError(t, err)
+
+	configPath, err := filepath.Abs("config.yaml")
+	require.NoError(t, err)
+
+	args := []any{"--config=" + configPath, "--rate-limit=5", "--admin=true"}
+
+	for index, test := range []struct {
+		name   string
+		client string
+		want   any
+	}{
+		{"vscode", clientVSCode, map[string]any{"servers": map[string]any{
+			"text-mirror": map[string]any{"type": "stdio", "command": command, "args": args},
+		}}},
+		{"claude", clientClaude, map[string]any{"mcpServers": map[string]any{
+			"text-mirror": map[string]any{"command": command, "args": args},
+		}}},
+		{"generic", clientGeneric, map[string]any{"command": command, "args": args}},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		var out bytes.Buffer
+
+		err := runInstall([]string{test.client}, opts, strings.NewReader(""), &out)
+		require.NoError(t, err, name)
+
+		var got any
+
+		require.NoError(t, json.Unmarshal(out.Bytes(), &got), name)
+		require.Equal(t, test.want, got, name)
+	}
+}
+
+//nolint:paralleltest // sets env var
+func Test_runInstall_write(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Code", "User", "mcp.json")
+	opts := &cliOptions{env: map[string]string{envNameAdmin: "true"}}
+
+	// Declined
+	var out bytes.Buffer
+
+	err := runInstall([]string{clientVSCode, "--write", "--file", path}, opts, strings.NewReader("n\n"), &out)
+	require.ErrorIs(t, err, errInstallCanceled)
+	require.Contains(t, out.String(), "Write the above to "+path+"? [y/N]")
+	require.NoFileExists(t, path)
+
+	// Confirmed, creating the file and its directory
+	err = runInstall([]string{clientVSCode, "--write", "--file", path}, opts, strings.NewReader("y\n"), &out)
+	require.NoError(t, err)
+
+	// Merged into the existing config, replacing the previous entry
+	existing := `{"inputs": [], "servers": {"other": {"command": "other"}, "text-mirror": {"command": "old"}}}`
+	require.NoError(t, os.WriteFile(path, []byte(existing), 0o600))
+
+	opts.env = map[string]string{}
+	err = runInstall([]string{clientVSCode, "--file", path, "--write", "--yes"}, opts, strings.NewReader(""), &out)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var got map[string]any
+
+	require.NoError(t, json.Unmarshal(data, &got))
+	require.Equal(t, []any{}, got["inputs"], "other settings should be kept")
+
+	servers, ok := got["servers"].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, map[string]any{"command": "other"}, servers["other"])
+
+	entry, ok := servers["text-mirror"].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, "stdio", entry["type"])
+	require.NotEqual(t, "old", entry["command"])
+	require.NotContains(t, entry, "args", "no flags were given")
+}
+
+//nolint:paralleltest // sets env var
+func Test_runInstall_errors(t *testing.T) {
+	dir := t.TempDir()
+
+	for name, data := range map[string]string{"jsonc.json": "{\n  // comment\n}", "servers.json": `{"mcpServers": []}`} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600))
+	}
+
+	for index, test := range []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		wantErr error
+	}{
+		{"no_client", nil, nil, errInstallUsage},
+		{"unknown_client", []string{"vim"}, nil, errInstallUsage},
+		{"write_generic", []string{clientGeneric, "--write"}, nil, errInstallUsage},
+		{"extra_args", []string{clientClaude, "extra"}, nil, errInstallUsage},
+		{"unknown_flag", []string{clientClaude, "--unknown"}, nil, errInstallUsage},
+		{"invalid_setting", []string{clientClaude}, map[string]string{envNameWorkers: "-1"}, errInvalidNumber},
+		{"jsonc", []string{clientClaude, "--write", "--yes", "--file", filepath.Join(dir, "jsonc.json")}, nil, errClientConfig},
+		{"servers_not_object", []string{clientClaude, "--write", "--yes", "--file", filepath.Join(dir, "servers.json")}, nil, errClientConfig},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		var out bytes.Buffer
+
+		err := runInstall(test.args, &cliOptions{env: test.env}, strings.NewReader(""), &out)
+		require.ErrorIs(t, err, test.wantErr, name)
+	}
+}
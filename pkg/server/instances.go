@@ -0,0 +1,239 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Server instances. E.g.: MCP_TEXT_MIRROR_INSTANCES='[{"name": "team-a", "tools": ["mirror"]}]'
+const (
+	envNameInstances = envPrefix + "INSTANCES" // env var of the extra server instances, as a JSON array
+)
+
+// Predefined errors of the server instances.
+var (
+	errInstanceName      = errors.New("must be letters, digits, - or _, and unique")
+	errInstancePath      = errors.New("must start with / and be unique, apart from /, /mcp, /healthz, /readyz and /debug/")
+	errInstanceForbidden = errors.New("client is not allowed to use the instance")
+)
+
+// InstanceConfig is an extra server instance served over HTTP at its own path,
+// alongside the main one at /mcp, with its own tools, limits and clients. It
+// is an item of the instances of the server section of the config file.
+type InstanceConfig struct {
+	Name        string   `json:"name"                   toml:"name"         yaml:"name"`         // e.g. team-a
+	Path        string   `json:"path,omitempty"         toml:"path"         yaml:"path"`         // endpoint path. default /<name>/mcp
+	Tools       []string `json:"tools,omitempty"        toml:"tools"        yaml:"tools"`        // built-in tools served. default as configured
+	Clients     []string `json:"clients,omitempty"      toml:"clients"      yaml:"clients"`      // client identities allowed. default any
+	RateLimit   *float64 `json:"rate_limit,omitempty"   toml:"rate_limit"   yaml:"rate_limit"`   // default as configured
+	RateBurst   *int     `json:"rate_burst,omitempty"   toml:"rate_burst"   yaml:"rate_burst"`   // default as configured
+	Workers     *int     `json:"workers,omitempty"      toml:"workers"      yaml:"workers"`      // default as configured
+	QueueDepth  *int     `json:"queue_depth,omitempty"  toml:"queue_depth"  yaml:"queue_depth"`  // default as configured
+	CallTimeout string   `json:"call_timeout,omitempty" toml:"call_timeout" yaml:"call_timeout"` // default as configured
+}
+
+// serverInstance is a server instance as served.
+type serverInstance struct {
+	config InstanceConfig
+	state  *serverState
+}
+
+// GetInstances returns the extra server instances from 'MCP_TEXT_MIRROR_INSTANCES'
+// environment variable, a JSON array of InstanceConfig, with their paths
+// defaulted. It returns nil if the variable is not set.
+//
+// The instances share the process, the log and the settings not given per
+// instance, but each has its own tools, limits, statistics and sessions, so
+// that one host can serve several projects in isolation. They are served over
+// HTTP only.
+func GetInstances() ([]InstanceConfig, error) {
+	return loadedSettings().instances()
+}
+
+// instances returns GetInstances of the settings.
+func (v *settingValues) instances() ([]InstanceConfig, error) {
+	value := v.value(envNameInstances)
+	if value == "" {
+		return nil, nil
+	}
+
+	var instances []InstanceConfig
+
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(&instances)
+	if err != nil {
+		return nil, wrapError(err, "invalid %s", envNameInstances)
+	}
+
+	names := make(map[string]bool, len(instances))
+	paths := make(map[string]bool, len(instances))
+
+	for index := range instances {
+		instance := &instances[index]
+		if instance.Path == "" {
+			instance.Path = "/" + instance.Name + httpPathMCP
+		}
+
+		err = checkInstance(*instance, names, paths)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s instance %q: %w", envNameInstances, instance.Name, err)
+		}
+
+		names[instance.Name] = true
+		paths[instance.Path] = true
+	}
+
+	return instances, nil
+}
+
+// checkInstance checks the instance, whose name and path must not be in the
+// ones of the previous instances.
+func checkInstance(instance InstanceConfig, names, paths map[string]bool) error {
+	validName := func(r rune) bool {
+		return r == '-' || r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9')
+	}
+
+	switch {
+	case instance.Name == "" || strings.IndexFunc(instance.Name, func(r rune) bool { return !validName(r) }) >= 0 ||
+		names[instance.Name]:
+		return fmt.Errorf("name %w", errInstanceName)
+	case !strings.HasPrefix(instance.Path, "/") || paths[instance.Path] ||
+		slices.Contains([]string{"/", httpPathMCP, httpPathHealthz, httpPathReadyz}, instance.Path) ||
+		strings.HasPrefix(instance.Path, httpPathDebug):
+		return fmt.Errorf("path %q %w", instance.Path, errInstancePath)
+	}
+
+	for _, tool := range instance.Tools {
+		if !slices.Contains(builtinTools, tool) {
+			return fmt.Errorf("%w: %q. must be one of %s", errUnknownTool, tool, strings.Join(builtinTools, ", "))
+		}
+	}
+
+	negative := func(value *int) bool { return value != nil && *value < 0 }
+
+	if (instance.RateLimit != nil && *instance.RateLimit < 0) || negative(instance.RateBurst) ||
+		negative(instance.Workers) || negative(instance.QueueDepth) {
+		return fmt.Errorf("limits %w", errInvalidNumber)
+	}
+
+	if instance.CallTimeout != "" {
+		_, err := time.ParseDuration(instance.CallTimeout)
+		if err != nil {
+			return wrapError(err, "invalid call_timeout")
+		}
+	}
+
+	return nil
+}
+
+// options returns the options of New serving the instance: its tools and its
+// limits, each defaulting to the configured one.
+func (c InstanceConfig) options() []Option {
+	var opts []Option
+
+	if len(c.Tools) > 0 {
+		opts = append(opts, WithTools(c.Tools...))
+	}
+
+	if c.RateLimit == nil && c.RateBurst == nil && c.Workers == nil && c.QueueDepth == nil && c.CallTimeout == "" {
+		return opts
+	}
+
+	limits := configuredLimits()
+
+	if c.RateLimit != nil {
+		limits.RateLimit = *c.RateLimit
+	}
+
+	if c.RateBurst != nil {
+		limits.RateBurst = *c.RateBurst
+	}
+
+	if c.Workers != nil {
+		limits.Workers = *c.Workers
+	}
+
+	if c.QueueDepth != nil {
+		limits.QueueDepth = *c.QueueDepth
+	}
+
+	if c.CallTimeout != "" {
+		limits.CallTimeout, _ = time.ParseDuration(c.CallTimeout) // checked by GetInstances
+	}
+
+	return append(opts, WithLimits(*limits))
+}
+
+// newInstances returns the configured server instances, each with its own
+// server logging to the logger of ctx. Their states are added to the one set by
+// withParentState if any, so that they follow the settings on reload along
+// with it. Invalid configurations are reported by loadSettings beforehand.
+func newInstances(ctx context.Context) []serverInstance {
+	configs, _ := GetInstances()
+	instances := make([]serverInstance, 0, len(configs))
+	parent, _ := ctx.Value(parentStateKey{}).(*serverState)
+
+	for _, config := range configs {
+		state := newServerState(append(config.options(), WithLogger(loggerFrom(ctx)))...)
+		instances = append(instances, serverInstance{config: config, state: state})
+
+		if parent != nil {
+			parent.addInstance(state)
+		}
+	}
+
+	return instances
+}
+
+// parentStateKey is the context key of the state of the main server.
+type parentStateKey struct{}
+
+// withParentState returns the context whose server instances, made by
+// newInstances, are applied along with state: App.serve sets the state of the
+// server it reloads.
+func withParentState(ctx context.Context, state *serverState) context.Context {
+	return context.WithValue(ctx, parentStateKey{}, state)
+}
+
+// withInstanceClients is an HTTP middleware rejecting the clients whose
+// identity, resolved by withClientIdentity, is not allowed by the instance.
+// Any client is allowed if the instance lists none.
+func withInstanceClients(instance InstanceConfig, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientID := r.Header.Get(headerClientID)
+
+		if len(instance.Clients) > 0 && !slices.Contains(instance.Clients, clientID) {
+			warnLog(r.Context(), "instance forbidden", logKeyClient, clientID, "instance", instance.Name)
+			http.Error(w, errInstanceForbidden.Error(), http.StatusForbidden)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// instancesEnv returns the value of 'MCP_TEXT_MIRROR_INSTANCES' of the
+// instances of the config file, or an empty string if none.
+func instancesEnv(instances []InstanceConfig) string {
+	if len(instances) == 0 {
+		return ""
+	}
+
+	var buf bytes.Buffer
+
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(instances) // plain values only
+
+	return strings.TrimSpace(buf.String())
+}
@@ -0,0 +1,205 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  GetInstances
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_GetInstances(t *testing.T) {
+	setEnv(t, envNameInstances, `[
+		{"name": "team-a", "tools": ["mirror", "pipeline"], "clients": ["alice"], "workers": 2},
+		{"name": "team_b", "path": "/b"}
+	]`)
+
+	workers := 2
+
+	got, err := GetInstances()
+	require.NoError(t, err)
+	require.Equal(t, []InstanceConfig{
+		{Name: "team-a", Path: "/team-a/mcp", Tools: []string{toolName, pipelineToolName}, Clients: []string{"alice"}, Workers: &workers},
+		{Name: "team_b", Path: "/b"},
+	}, got, "paths should default to /<name>/mcp")
+
+	setEnv(t, envNameInstances, "")
+
+	got, err = GetInstances()
+	require.NoError(t, err)
+	require.Nil(t, got)
+}
+
+//nolint:paralleltest // sets env var
+func Test_GetInstances_invalid(t *testing.T) {
+	for index, test := range []struct {
+		name    string
+		value   string
+		wantErr error
+	}{
+		{"not_json", `team-a`, nil},
+		{"unknown_field", `[{"name": "a", "tool": ["mirror"]}]`, nil},
+		{"no_name", `[{"path": "/a"}]`, errInstanceName},
+		{"invalid_name", `[{"name": "team a"}]`, errInstanceName},
+		{"duplicate_name", `[{"name": "a"}, {"name": "a", "path": "/other"}]`, errInstanceName},
+		{"relative_path", `[{"name": "a", "path": "a/mcp"}]`, errInstancePath},
+		{"main_path", `[{"name": "a", "path": "/mcp"}]`, errInstancePath},
+		{"health_path", `[{"name": "a", "path": "/healthz"}]`, errInstancePath},
+		{"pprof_path", `[{"name": "a", "path": "/debug/pprof/a"}]`, errInstancePath},
+		{"debug_path", `[{"name": "a", "path": "/debug/vars"}]`, errInstancePath},
+		{"root_path", `[{"name": "a", "path": "/"}]`, errInstancePath},
+		{"duplicate_path", `[{"name": "a", "path": "/x"}, {"name": "b", "path": "/x"}]`, errInstancePath},
+		{"unknown_tool", `[{"name": "a", "tools": ["mirorr"]}]`, errUnknownTool},
+		{"negative_workers", `[{"name": "a", "workers": -1}]`, errInvalidNumber},
+		{"negative_rate_limit", `[{"name": "a", "rate_limit": -0.5}]`, errInvalidNumber},
+		{"invalid_call_timeout", `[{"name": "a", "call_timeout": "30"}]`, nil},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		setEnv(t, envNameInstances, test.value)
+
+		_, err := GetInstances()
+		require.Error(t, err, name)
+		require.ErrorContains(t, err, envNameInstances, name)
+
+		if test.wantErr != nil {
+			require.ErrorIs(t, err, test.wantErr, name)
+		}
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  InstanceConfig.options
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_InstanceConfig_options(t *testing.T) {
+	unsetEnv(t, envNameRateBurst, envNameQueueDepth, envNameCallTimeout)
+	setEnv(t, envNameRateLimit, "5")
+	setEnv(t, envNameWorkers, "8")
+
+	require.Empty(t, InstanceConfig{Name: "a"}.options(), "nothing given should follow the configuration")
+
+	workers := 2
+	opts := newServerOptions(InstanceConfig{Name: "a", Tools: []string{toolName}, Workers: &workers, CallTimeout: "1s"}.options())
+
+	require.Equal(t, []string{toolName}, opts.tools)
+	require.NotNil(t, opts.limits)
+	require.Equal(t, Limits{RateLimit: 5, RateBurst: 5, Workers: 2, QueueDepth: queueDepthDefault, CallTimeout: time.Second},
+		*opts.limits, "the limits not given should be the configured ones")
+}
+
+// ----------------------------------------------------------------------------
+//  newInstances
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_newInstances_apply(t *testing.T) {
+	unsetEnv(t, envNameToolsEnabled, envNameToolsDisabled, envNameAdmin)
+	setEnv(t, envNameInstances, `[{"name": "team-a"}, {"name": "team-b", "tools": ["mirror_batch"]}]`)
+
+	parent := newServerState()
+	instances := newInstances(withParentState(context.Background(), parent))
+
+	require.Len(t, instances, 2)
+	require.Len(t, parent.instances, 2, "the instances should be added to the parent")
+	require.True(t, instances[0].state.tools.enabled(batchToolName))
+
+	setEnv(t, envNameToolsDisabled, batchToolName)
+	parent.apply()
+
+	require.False(t, parent.tools.enabled(batchToolName))
+	require.False(t, instances[0].state.tools.enabled(batchToolName), "the instances should follow the settings")
+	require.True(t, instances[1].state.tools.enabled(batchToolName), "the tools of the instance should be kept")
+
+	require.Len(t, newInstances(context.Background()), 2, "the instances should be made without parent too")
+}
+
+// ----------------------------------------------------------------------------
+//  newHTTPHandler with instances
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_newHTTPHandler_instances(t *testing.T) {
+	unsetEnv(t, envNameToolsEnabled, envNameToolsDisabled, envNameAdmin, envNameMaxBody)
+	setEnv(t, envNameInstances, `[
+		{"name": "team-a", "tools": ["pipeline"]},
+		{"name": "team-b", "clients": ["alice"]}
+	]`)
+
+	server := httptest.NewServer(newHTTPHandler(context.Background(), newServer(), new(atomic.Bool)))
+	t.Cleanup(server.Close)
+
+	listTools := func(path string) []string {
+		t.Helper()
+
+		transport := new(mcp.StreamableClientTransport)
+		transport.Endpoint = server.URL + path
+
+		client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.0"}, nil) //nolint:exhaustruct // minimal client
+
+		session, err := client.Connect(context.Background(), transport, nil)
+		require.NoError(t, err)
+
+		defer session.Close()
+
+		var names []string
+
+		for tool, err := range session.Tools(context.Background(), nil) {
+			require.NoError(t, err)
+
+			names = append(names, tool.Name)
+		}
+
+		return names
+	}
+
+	require.Equal(t, []string{pipelineToolName}, listTools("/team-a/mcp"), "the instance should serve its tools only")
+	require.Contains(t, listTools(httpPathMCP), batchToolName, "the main server should serve the configured tools")
+
+	// The clients not allowed are rejected before reaching the MCP server
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, server.URL+"/team-b/mcp",
+		strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`))
+	require.NoError(t, err)
+	req.Header.Set(headerClientID, "alice") // never trusted
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	require.Equal(t, http.StatusForbidden, res.StatusCode)
+}
+
+func Test_withInstanceClients(t *testing.T) {
+	t.Parallel()
+
+	handler := withInstanceClients(InstanceConfig{Name: "a", Clients: []string{"alice"}},
+		http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+	for index, test := range []struct {
+		clientID string
+		want     int
+	}{
+		{"alice", http.StatusOK},
+		{"bob", http.StatusForbidden},
+		{anonymousClient, http.StatusForbidden},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/a/mcp", nil)
+		req.Header.Set(headerClientID, test.clientID)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		require.Equal(t, test.want, rec.Code, "Test #%d: %s", index+1, test.clientID)
+	}
+}
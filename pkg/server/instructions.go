@@ -0,0 +1,51 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+// serverInstructions returns the usage guidance sent to the clients in the
+// initialize result, so that LLM clients use the tools correctly without trial
+// and error.
+//
+// It is a terse, line-oriented list of "key: value" rules, which is easy for
+// both LLMs and programs to read.
+func serverInstructions() string {
+	lines := []string{
+		"# text-mirror server instructions",
+		"purpose: reverse (mirror) UTF-8 text exactly, character by character as seen by humans",
+		"use " + toolName + " when: the user asks to reverse, mirror or flip text, or for palindrome checks",
+		"do not use " + toolName + " for: translation, or right-to-left rendering",
+		"granularity: set granularity to word or line to reverse the order of the words or the lines instead," +
+			" or to rune or byte to reverse the code points or the bytes, which breaks the emoji and accented letters",
+		"semantics: text is reversed by grapheme clusters (UAX #29), so emoji, flags, ZWJ sequences" +
+			" and combining marks are kept intact. e.g. \"👍🏽é\" -> \"é👍🏽\"",
+		"semantics: reversing twice may not give back the original if it starts with combining marks",
+		"input: text is required. set it to \"\" with path to mirror a UTF-8 text file within the client roots, over stdio only",
+		"input: an empty text without path asks the user for the text if the client supports elicitation",
+		fmt.Sprintf("limits: text up to %d characters, files up to %d bytes, paths up to %d characters",
+			textMaxLength, fileMaxBytes, pathMaxLength),
+		fmt.Sprintf("batch: use %s to mirror up to %d texts in one call. results are in the input order",
+			batchToolName, batchMaxItems),
+		fmt.Sprintf("pipeline: use %s to apply up to %d operations such as normalize, strip_accents, mirror"+
+			" and upper in order in one call, instead of a call per operation. e.g. expr \"nfc | mirror | upper(locale=tr)\"",
+			pipelineToolName, pipelineMaxSteps),
+		"transform: use " + transformToolName + " to apply a single operation given by op, with its params",
+		"words: use " + wordsToolName + " to reverse the order of the words, keeping the letters of each word," +
+			" the whitespace and the punctuation in place. e.g. \"Hello, big world!\" -> \"world, big Hello!\"",
+		"lines: use " + linesToolName + " to reverse the order of the lines, keeping the contents of each line",
+		"lines: use " + eachLineToolName + " to mirror each line in place, such as ASCII art. set pad to keep the block aligned",
+		"length: use " + textStatsToolName + " to count the bytes, code points, graphemes, words and lines of a text" +
+			" and its display width, instead of estimating them",
+		"normalize: use " + normalizeToolName + " to convert a text to NFC, NFD, NFKC or NFKD and to check whether it" +
+			" already is, e.g. before comparing texts",
+		fmt.Sprintf("progress: send a progressToken for inputs over %d bytes to get progress notifications",
+			progressMinBytes),
+		"versions: " + toolName + " follows the latest behavior. call " + toolName + toolVersionSep +
+			"1 to pin it, and avoid tools whose description starts with Deprecated",
+		"errors: tool errors such as rate limits or busy server are retryable later; schema errors are not",
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,25 @@
+package server
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  serverInstructions
+// ----------------------------------------------------------------------------
+
+func Test_newServer_instructions(t *testing.T) {
+	t.Parallel()
+
+	session := connectInMemory(t, newServer())
+	instructions := session.InitializeResult().Instructions
+
+	require.Equal(t, serverInstructions(), instructions)
+
+	for _, want := range []string{toolName, batchToolName, pipelineToolName, transformToolName, "grapheme", strconv.Itoa(textMaxLength)} {
+		require.Contains(t, instructions, want)
+	}
+}
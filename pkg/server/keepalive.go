@@ -0,0 +1,38 @@
+package server
+
+import "time"
+
+// Session lifetime configuration.
+const (
+	envNameKeepAlive   = envPrefix + "KEEPALIVE"    // env var of the interval to ping clients. e.g. 30s. unset disables pings
+	envNameIdleTimeout = envPrefix + "IDLE_TIMEOUT" // env var of the duration to close idle HTTP sessions. e.g. 10m. unset disables it
+)
+
+// GetKeepAlive returns the interval of the keepalive pings sent to the clients
+// from 'MCP_TEXT_MIRROR_KEEPALIVE' environment variable. Sessions whose client
+// fails to respond to a ping are closed.
+//
+// A zero interval means keepalive pings are disabled.
+func GetKeepAlive() (time.Duration, error) {
+	return loadedSettings().keepAlive()
+}
+
+// keepAlive returns GetKeepAlive of the settings.
+func (v *settingValues) keepAlive() (time.Duration, error) {
+	return v.envDuration(envNameKeepAlive)
+}
+
+// GetIdleTimeout returns the duration after which HTTP sessions without any
+// request from the client are closed, from 'MCP_TEXT_MIRROR_IDLE_TIMEOUT'
+// environment variable. It does not apply to stdio, where the client owns the
+// process.
+//
+// A zero duration means idle sessions are never closed.
+func GetIdleTimeout() (time.Duration, error) {
+	return loadedSettings().idleTimeout()
+}
+
+// idleTimeout returns GetIdleTimeout of the settings.
+func (v *settingValues) idleTimeout() (time.Duration, error) {
+	return v.envDuration(envNameIdleTimeout)
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  GetKeepAlive / GetIdleTimeout
+// ----------------------------------------------------------------------------
+
+func Test_GetKeepAlive(t *testing.T) {
+	setEnv(t, envNameKeepAlive, "")
+
+	interval, err := GetKeepAlive()
+	require.NoError(t, err)
+	require.Zero(t, interval, "keepalive should be disabled by default")
+
+	setEnv(t, envNameKeepAlive, "30s")
+
+	interval, err = GetKeepAlive()
+	require.NoError(t, err)
+	require.Equal(t, 30*time.Second, interval)
+}
+
+func Test_GetIdleTimeout(t *testing.T) {
+	setEnv(t, envNameIdleTimeout, "")
+
+	timeout, err := GetIdleTimeout()
+	require.NoError(t, err)
+	require.Zero(t, timeout, "idle timeout should be disabled by default")
+
+	setEnv(t, envNameIdleTimeout, "10m")
+
+	timeout, err = GetIdleTimeout()
+	require.NoError(t, err)
+	require.Equal(t, 10*time.Minute, timeout)
+}
+
+//nolint:paralleltest // sets env var
+func Test_run_invalid_session_lifetime(t *testing.T) {
+	for _, name := range []string{envNameKeepAlive, envNameIdleTimeout} {
+		t.Run(name, func(t *testing.T) {
+			setEnv(t, name, "forever")
+
+			err := newApp().serve(context.Background(), nil)
+			require.ErrorIs(t, err, errInvalidDuration)
+		})
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  Keepalive pings
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_newServer_keepalive(t *testing.T) {
+	setEnv(t, envNameKeepAlive, "50ms")
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+
+	serverSession, err := newServer().Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+
+	// Client which does not answer pings, like a stale one.
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.0"}, nil) //nolint:exhaustruct // minimal client
+	client.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method == "ping" {
+				return nil, errTest
+			}
+
+			return next(ctx, method, req)
+		}
+	})
+
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+
+	defer clientSession.Close()
+
+	closed := make(chan struct{})
+
+	go func() {
+		_ = serverSession.Wait()
+
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(timeoutEventually):
+		require.Fail(t, "session should be closed once the client fails to answer a ping")
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  Idle session timeout
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_newHTTPHandler_idle_timeout(t *testing.T) {
+	setEnv(t, envNameIdleTimeout, "100ms")
+
+	listener, err := new(net.ListenConfig).Listen(context.Background(), "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errServe := make(chan error, 1)
+
+	server := newServer()
+
+	go func() {
+		errServe <- serveHTTPListener(ctx, server, listener)
+	}()
+
+	transport := new(mcp.StreamableClientTransport)
+	transport.Endpoint = "http://" + listener.Addr().String() + httpPathMCP
+	transport.MaxRetries = -1
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.0"}, nil) //nolint:exhaustruct // minimal client
+
+	session, err := client.Connect(ctx, transport, nil)
+	require.NoError(t, err)
+
+	defer session.Close()
+
+	require.Eventually(t, func() bool {
+		for range server.Sessions() {
+			return false
+		}
+
+		return true
+	}, timeoutEventually, tickEventually, "idle session should be closed by the server")
+
+	cancel()
+	require.ErrorIs(t, <-errServe, context.Canceled)
+}
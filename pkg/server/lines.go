@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Reverse lines tool.
+const (
+	linesToolName        = "reverse_lines"
+	linesToolDescription = "Reverses the order of the lines of the UTF-8 text, keeping the contents of each line " +
+		"as is. LF and CR LF line breaks are supported, and a trailing line break stays trailing"
+)
+
+// ReverseLinesInput is the input for the reverse_lines tool.
+type ReverseLinesInput struct {
+	Text string `json:"text" jsonschema:"The UTF-8 text whose lines to reverse the order of."`
+}
+
+// ReverseLinesOutput is the output from the reverse_lines tool.
+type ReverseLinesOutput struct {
+	Text  string `json:"text"  jsonschema:"The text with its lines in the reverse order. The line breaks stay in place."`
+	Lines int    `json:"lines" jsonschema:"The number of lines, not counting the empty one after a trailing line break."`
+}
+
+// linesTool is the reverse_lines tool, reversing the order of the lines
+// instead of the characters.
+type linesTool struct{}
+
+// Name returns the name of the tool.
+func (linesTool) Name() string { return linesToolName }
+
+// Description returns the description of the tool.
+func (linesTool) Description() string { return linesToolDescription }
+
+// Schema returns the schemas of the input and the output of the tool.
+func (linesTool) Schema() (*jsonschema.Schema, *jsonschema.Schema) {
+	return reverseLinesInputSchema(), nil
+}
+
+// Annotations returns the hints of the tool.
+func (linesTool) Annotations() *mcp.ToolAnnotations { return readOnlyAnnotations() }
+
+// Handler returns handleReverseLines.
+func (linesTool) Handler(*serverState) ToolHandler { return TypedHandler(handleReverseLines) }
+
+// cacheable returns true, as the result depends on the text only.
+func (linesTool) cacheable(map[string]any) bool { return true }
+
+// reverseLinesInputSchema returns the JSON schema of ReverseLinesInput.
+func reverseLinesInputSchema() *jsonschema.Schema {
+	schema := mustInferSchema[ReverseLinesInput]()
+	schema.Title = "Reverse lines input"
+	schema.Required = []string{"text"}
+
+	text := schema.Properties["text"]
+	text.Title = "Text"
+	text.MaxLength = jsonschema.Ptr(textMaxLength)
+	text.Examples = []any{"first\nsecond\nthird\n"}
+
+	return schema
+}
+
+// handleReverseLines returns the text with its lines in the reverse order, as
+// mirror does with the line granularity.
+func handleReverseLines(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input ReverseLinesInput,
+) (*mcp.CallToolResult, ReverseLinesOutput, error) {
+	err := ctx.Err()
+	if err != nil {
+		return nil, ReverseLinesOutput{}, wrapError(err, "request canceled")
+	}
+
+	err = checkTextLimit(req, input.Text)
+	if err != nil {
+		return nil, ReverseLinesOutput{}, err
+	}
+
+	start := time.Now()
+
+	reversed, lines, err := reverseLines(ctx, input.Text)
+	if err != nil {
+		return nil, ReverseLinesOutput{}, err
+	}
+
+	callLog(ctx, "lines reversed", callLogAttrs(ctx, req, logKeyInputSize, len(input.Text), logKeyDuration, time.Since(start),
+		logKeyText, userText(input.Text), logKeyMirrored, userText(reversed))...)
+
+	return nil, ReverseLinesOutput{Text: reversed, Lines: lines}, nil
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  handleReverseLines
+// ----------------------------------------------------------------------------
+
+func Test_handleReverseLines(t *testing.T) {
+	t.Parallel()
+
+	session := connectInMemory(t, newServer())
+
+	for index, test := range []struct {
+		name      string
+		input     string
+		wantText  string
+		wantLines float64
+	}{
+		{"lf", "first\nsecond\nthird", "third\nsecond\nfirst", 3},
+		{"trailing_lf", "first\nsecond\n", "second\nfirst\n", 2},
+		{"crlf", "first\r\nsecond\r\n", "second\r\nfirst\r\n", 2},
+		{"mixed_breaks", "a\r\nb\nc", "c\r\nb\na", 3},
+		{"empty_lines", "a\n\n\nb\n", "b\n\n\na\n", 4},
+		{"lone_cr_kept", "a\rb\nc", "c\na\rb", 2},
+		{"contents_kept", "👍🏽 café\nabc", "abc\n👍🏽 café", 2},
+		{"single_line", "abc", "abc", 1},
+		{"only_break", "\n", "\n", 1},
+		{"empty", "", "", 0},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		res := callTool(t, session, linesToolName, map[string]any{"text": test.input})
+		require.False(t, res.IsError, name)
+		require.Equal(t, map[string]any{"text": test.wantText, "lines": test.wantLines}, res.StructuredContent, name)
+	}
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// listToolsIndent is the indentation of the JSON printed by --list-tools and
+// the other subcommands.
+const listToolsIndent = "  "
+
+// listTools prints the tools the server offers as configured, with their input
+// and output JSON schemas, as the JSON of the tools/list result to w. The tools
+// are listed from an in-process session, so no transport is started and no
+// upstream server of the aggregator mode is connected.
+func listTools(ctx context.Context, w io.Writer) error {
+	err := loadSettings()
+	if err != nil {
+		return wrapError(err, "invalid configuration")
+	}
+
+	session, closeSession, err := connectInProcess(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer closeSession()
+
+	result := new(mcp.ListToolsResult)
+
+	for tool, err := range session.Tools(ctx, nil) {
+		if err != nil {
+			return wrapError(err, "failed to list the tools")
+		}
+
+		result.Tools = append(result.Tools, tool)
+	}
+
+	return wrapError(printJSON(w, result), "failed to write the tools")
+}
+
+// printJSON prints v as indented JSON to w, such as the results of the tools.
+func printJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", listToolsIndent)
+
+	return encoder.Encode(v)
+}
+
+// connectInProcess returns the session of a client connected to a new server
+// as configured over in-memory transports, and the function to close both.
+func connectInProcess(ctx context.Context) (*mcp.ClientSession, func(), error) {
+	return connectServer(ctx, newServer(WithLogger(loggerFrom(ctx))))
+}
+
+// connectServer returns the session of a client connected to the server over
+// in-memory transports, and the function to close both.
+func connectServer(ctx context.Context, server *mcp.Server) (*mcp.ClientSession, func(), error) {
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		return nil, nil, wrapError(err, "failed to connect to the server")
+	}
+
+	client := mcp.NewClient(&mcp.Implementation{Name: serviceName, Version: GetServiceVersion()}, nil) //nolint:exhaustruct // name and version only
+
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		_ = serverSession.Close()
+
+		return nil, nil, wrapError(err, "failed to connect to the server")
+	}
+
+	return session, func() {
+		_ = session.Close()
+		_ = serverSession.Close()
+	}, nil
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  listTools
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_runCommand_list_tools(t *testing.T) {
+	unsetEnv(t, envNameToolsEnabled, envNameToolsDisabled, envNameAdmin, envNameProfile)
+
+	var out bytes.Buffer
+
+	app := newTestApp(t)
+	app.Stdout = &out
+
+	err := app.Run(context.Background(), []string{"--list-tools", "--admin", "--tools-disabled", strings.Join(defaultTools(toolName), ",")})
+	require.NoError(t, err)
+
+	var result struct {
+		Tools []struct {
+			Name         string         `json:"name"`
+			InputSchema  map[string]any `json:"inputSchema"`
+			OutputSchema map[string]any `json:"outputSchema"`
+		} `json:"tools"`
+	}
+
+	require.NoError(t, json.Unmarshal(out.Bytes(), &result), "output should be JSON")
+	require.Len(t, result.Tools, 2, "configured tools should be listed")
+	require.Equal(t, adminToolName, result.Tools[0].Name)
+	require.Equal(t, toolName, result.Tools[1].Name)
+	require.Equal(t, "object", result.Tools[1].InputSchema["type"])
+	require.NotEmpty(t, result.Tools[1].OutputSchema, "output schema should be included")
+}
+
+//nolint:paralleltest // sets env var
+func Test_listTools_invalid_configuration(t *testing.T) {
+	setEnv(t, envNameToolsDisabled, "mirorr")
+
+	var out bytes.Buffer
+
+	err := listTools(context.Background(), &out)
+	require.ErrorIs(t, err, errUnknownTool)
+	require.Empty(t, out.String())
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/text/language"
+)
+
+// Locale configuration.
+const (
+	envNameLocale = envPrefix + "LOCALE" // env var of the default BCP 47 locale of the tools. e.g. tr
+)
+
+// errInvalidLocale is returned if the locale is not a well-formed BCP 47 tag.
+var errInvalidLocale = errors.New("must be a BCP 47 language tag such as en, tr or zh-Hant")
+
+// GetLocale returns the server-wide locale of the tools depending on the
+// language, from 'MCP_TEXT_MIRROR_LOCALE' environment variable.
+//
+// The locale drives the case mapping, such as the dotted and dotless I of
+// Turkish and Azerbaijani. The word segmentation of reverse_words, text_stats
+// and the word granularity follows the language neutral rules of UAX #29
+// regardless. It defaults to the undetermined language (language.Und), i.e.
+// the language neutral rules of Unicode.
+func GetLocale() (language.Tag, error) {
+	return loadedSettings().locale()
+}
+
+// locale returns GetLocale of the settings.
+func (v *settingValues) locale() (language.Tag, error) {
+	return parseLocale(envNameLocale, v.value(envNameLocale))
+}
+
+// resolveLocale returns the locale of a tool call: the one given in the locale
+// argument of the call if any, otherwise the server-wide one.
+func resolveLocale(override string) (language.Tag, error) {
+	if override != "" {
+		return parseLocale("locale", override)
+	}
+
+	return GetLocale()
+}
+
+// parseLocale parses the BCP 47 tag set in the named variable or argument. An
+// empty value is the undetermined language.
+func parseLocale(name, value string) (language.Tag, error) {
+	if value == "" {
+		return language.Und, nil
+	}
+
+	tag, err := language.Parse(value)
+	if err != nil {
+		return language.Und, fmt.Errorf("invalid %s %q: %w", name, value, errInvalidLocale)
+	}
+
+	return tag, nil
+}
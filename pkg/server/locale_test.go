@@ -0,0 +1,66 @@
+package server
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+// ----------------------------------------------------------------------------
+//  GetLocale
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func TestGetLocale(t *testing.T) {
+	for index, test := range []struct {
+		name    string
+		value   string
+		want    language.Tag
+		wantErr bool
+	}{
+		{"unset", "", language.Und, false},
+		{"turkish", "tr", language.Turkish, false},
+		{"azerbaijani", "az", language.Azerbaijani, false},
+		{"traditional_chinese", "zh-Hant", language.TraditionalChinese, false},
+		{"underscore", "el_GR", language.MustParse("el-GR"), false},
+		{"malformed", "not a locale", language.Und, true},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		setEnv(t, envNameLocale, test.value)
+
+		got, err := GetLocale()
+		if test.wantErr {
+			require.ErrorIs(t, err, errInvalidLocale, name)
+			require.ErrorContains(t, err, envNameLocale, name)
+
+			continue
+		}
+
+		require.NoError(t, err, name)
+		require.Equal(t, test.want, got, name)
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  resolveLocale
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_resolveLocale(t *testing.T) {
+	setEnv(t, envNameLocale, "tr")
+
+	got, err := resolveLocale("")
+	require.NoError(t, err)
+	require.Equal(t, language.Turkish, got, "server-wide locale should apply if not overridden")
+
+	got, err = resolveLocale("el")
+	require.NoError(t, err)
+	require.Equal(t, language.Greek, got, "argument should override the server-wide locale")
+
+	_, err = resolveLocale("!")
+	require.ErrorIs(t, err, errInvalidLocale)
+	require.ErrorContains(t, err, `invalid locale "!"`)
+}
@@ -0,0 +1,283 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Log levels. E.g.: MCP_TEXT_MIRROR_LOG_LEVEL=info
+const (
+	envNameLogLevel = envPrefix + "LOG_LEVEL" // env var of the minimum level of the log entries written
+
+	logLevelDebug = "debug" // tool calls and other details
+	logLevelInfo  = "info"  // lifecycle events such as the start of the transport, reloads and client sessions
+	logLevelWarn  = "warn"  // rejected requests and degraded operations
+	logLevelError = "error" // failures of the server
+)
+
+// Log formats. E.g.: MCP_TEXT_MIRROR_LOG_FORMAT=json
+const (
+	envNameLogFormat = envPrefix + "LOG_FORMAT" // env var of the format of the log entries written
+
+	logFormatText = "text" // "key=value" pairs, the default
+	logFormatJSON = "json" // one JSON object per line, for the log collectors
+)
+
+// Predefined errors of the log settings.
+var (
+	errInvalidLogLevel  = errors.New("must be debug, info, warn or error")
+	errInvalidLogFormat = errors.New("must be text or json")
+)
+
+// logLevels are the slog levels by name.
+//
+//nolint:gochecknoglobals // read-only table
+var logLevels = map[string]slog.Level{
+	logLevelDebug: slog.LevelDebug,
+	logLevelInfo:  slog.LevelInfo,
+	logLevelWarn:  slog.LevelWarn,
+	logLevelError: slog.LevelError,
+}
+
+// Attribute keys of the structured log entries shared by the call sites.
+//
+// E.g.: time=2025-01-02T03:04:05.678Z level=DEBUG msg="text mirrored" tool=mirror input_size=3
+const (
+	logKeyRequestID = "request_id" // ID of the tool call, also in the _meta of the result
+	logKeyTool      = "tool"       // name of the tool called
+	logKeyApp       = "app"        // client implementation, e.g. "Visual Studio Code 1.102.0"
+	logKeyClient    = "client"     // client identity of mTLS
+	logKeyMeta      = "meta"       // group of the propagated _meta entries of the request
+	logKeyDuration  = "duration"   // time taken by the tool call
+	logKeyInputSize = "input_size" // size of the input text in bytes
+	logKeyText      = "text"       // input text, as userText
+	logKeyMirrored  = "mirrored"   // output text, as userText
+	logKeyError     = "error"
+	logKeySession   = "session"  // MCP session ID
+	logKeyProtocol  = "protocol" // MCP protocol version
+)
+
+// GetLogLevel returns the minimum level of the log entries written, from
+// 'MCP_TEXT_MIRROR_LOG_LEVEL' environment variable: debug, info, warn or error.
+//
+// If not set, it is debug if MCP_TEXT_MIRROR_DEBUG_LOG is set and error
+// otherwise, as debug logging used to be turned on and off by the log file. The
+// default is also returned along with the error if the value is invalid.
+func GetLogLevel() (slog.Level, error) {
+	v := loadedSettings()
+
+	return v.logLevel, v.logLevelErr
+}
+
+// parseLogLevel returns the log level of the values of the settings, as
+// GetLogLevel does.
+func parseLogLevel(values map[string]string) (slog.Level, error) {
+	level := slog.LevelError
+	if values[envNameDebug] != "" || fileLogDefault {
+		level = slog.LevelDebug
+	}
+
+	name := values[envNameLogLevel]
+	if name == "" {
+		return level, nil
+	}
+
+	parsed, ok := logLevels[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return level, fmt.Errorf("invalid %s %q: %w", envNameLogLevel, name, errInvalidLogLevel)
+	}
+
+	return parsed, nil
+}
+
+// GetLogFormat returns the format of the log entries written to the log file
+// or the standard error, from 'MCP_TEXT_MIRROR_LOG_FORMAT' environment
+// variable: text (default) or json. The entries sent to the syslog, the clients
+// and the debug log resource keep their own format.
+//
+// The text format is also returned along with the error if the value is
+// invalid.
+func GetLogFormat() (string, error) {
+	return loadedSettings().logFormat()
+}
+
+// logFormat returns GetLogFormat of the settings.
+func (v *settingValues) logFormat() (string, error) {
+	format := strings.ToLower(strings.TrimSpace(v.value(envNameLogFormat)))
+
+	switch format {
+	case "", logFormatText:
+		return logFormatText, nil
+	case logFormatJSON:
+		return logFormatJSON, nil
+	default:
+		return logFormatText, fmt.Errorf("invalid %s %q: %w", envNameLogFormat, format, errInvalidLogFormat)
+	}
+}
+
+// logWriter is the output of the logger, either the debug log file or the
+// standard error, which reopenLog redirects without replacing the logger. The
+// log file is rotated per GetLogRotation.
+type logWriter struct {
+	file     *os.File
+	size     int64 // size of the log file
+	rotation logRotation
+	now      func() time.Time // time of the rotation
+	pruning  sync.WaitGroup   // removal and compression of the rotated files
+	pruneMu  sync.Mutex       // serializes the pruning
+	mu       sync.Mutex
+}
+
+// Write writes the log entry to the current output. The log file is rotated
+// beforehand if the entry would exceed the max size, unless it is the first
+// entry of the file.
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	maxSize := w.rotation.maxSize
+	if w.file != os.Stderr && maxSize > 0 && w.size > 0 && w.size+int64(len(p)) > maxSize {
+		w.rotate()
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+
+	return n, err
+}
+
+// swap redirects the output to file and returns the previous one. The rotation
+// policy is reloaded.
+func (w *logWriter) swap(file *os.File) *os.File {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	previous := w.file
+	w.file, w.size = file, 0
+	w.rotation, _ = GetLogRotation() // invalid values are reported by loadSettings
+
+	if info, err := file.Stat(); err == nil && info.Mode().IsRegular() {
+		w.size = info.Size() // appended
+	}
+
+	return previous
+}
+
+// logHandler is the slog handler of the logger. It writes the entries in the
+// "key=value" text form or in JSON per GetLogFormat, with the times in UTC, and
+// sends them to the syslog and the Windows Event Log if configured.
+type logHandler struct {
+	slog.Handler
+
+	out      *logWriter
+	syslog   atomic.Pointer[syslogWriter]
+	eventLog atomic.Pointer[eventLogWriter]
+}
+
+// Handle writes the record to the output, to the syslog and to the event log.
+// Failures of the syslog and the event log are ignored, as they can't be
+// logged.
+func (h *logHandler) Handle(ctx context.Context, record slog.Record) error {
+	err := h.Handler.Handle(ctx, record)
+
+	if syslog := h.syslog.Load(); syslog != nil {
+		_ = syslog.send(record)
+	}
+
+	if eventLog := h.eventLog.Load(); eventLog != nil {
+		_ = eventLog.send(record)
+	}
+
+	return err
+}
+
+// swapSyslog sends the entries to the syslog writer from now on, or stops
+// sending them if nil. The previous writer is closed.
+func (h *logHandler) swapSyslog(syslog *syslogWriter) {
+	if previous := h.syslog.Swap(syslog); previous != nil {
+		previous.close()
+	}
+}
+
+// swapEventLog writes the entries to the event log writer from now on, or stops
+// writing them if nil. The previous writer is closed.
+func (h *logHandler) swapEventLog(eventLog *eventLogWriter) {
+	if previous := h.eventLog.Swap(eventLog); previous != nil {
+		previous.close()
+	}
+}
+
+// newLogHandler returns a logHandler writing to file.
+func newLogHandler(file *os.File) *logHandler {
+	options := new(slog.HandlerOptions)
+	options.Level = slog.LevelDebug // debugLog decides whether to log
+	options.ReplaceAttr = func(groups []string, attr slog.Attr) slog.Attr {
+		if attr.Key == slog.TimeKey && len(groups) == 0 {
+			attr.Value = slog.TimeValue(attr.Value.Time().UTC())
+		}
+
+		return attr
+	}
+
+	handler := new(logHandler)
+	handler.out = new(logWriter)
+	handler.out.now = time.Now
+	handler.out.swap(file)
+	handler.Handler = slog.NewTextHandler(handler.out, options)
+
+	if format, _ := GetLogFormat(); format == logFormatJSON { // invalid values are reported by loadSettings
+		handler.Handler = slog.NewJSONHandler(handler.out, options)
+	}
+
+	target, _ := GetSyslog() // invalid values are reported by loadSettings
+	handler.swapSyslog(newSyslogWriter(target))
+
+	eventLog, _ := GetEventLog() // invalid values are reported by loadSettings
+	handler.swapEventLog(newEventLogWriter(eventLog))
+
+	return handler
+}
+
+// logEntry returns the log entry of the message and the attributes given as
+// key-value pairs, as slog takes them, in the "msg key=value" form without the
+// time and the level. E.g. `text mirrored tool=mirror input_size=3`.
+func logEntry(msg string, args ...any) string {
+	record := slog.NewRecord(time.Time{}, slog.LevelDebug, msg, 0)
+	record.Add(args...)
+
+	return recordEntry(record)
+}
+
+// recordEntry returns the log entry of the record in the "msg key=value" form
+// without the time and the level, as logEntry.
+func recordEntry(record slog.Record) string {
+	if record.NumAttrs() == 0 {
+		return record.Message
+	}
+
+	options := new(slog.HandlerOptions)
+	options.ReplaceAttr = func(groups []string, attr slog.Attr) slog.Attr {
+		if len(groups) == 0 && (attr.Key == slog.TimeKey || attr.Key == slog.LevelKey || attr.Key == slog.MessageKey) {
+			return slog.Attr{} // dropped
+		}
+
+		return attr
+	}
+
+	buf := buffers.get()
+	defer buffers.put(buf)
+
+	buf.WriteString(record.Message + " ")
+
+	_ = slog.NewTextHandler(buf, options).Handle(context.Background(), record)
+
+	return string(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+}
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  GetLogLevel
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_GetLogLevel(t *testing.T) {
+	for index, test := range []struct {
+		name     string
+		debugLog string
+		level    string
+		want     slog.Level
+		wantErr  error
+	}{
+		{"default", "", "", slog.LevelError, nil},
+		{"default_with_log_file", "test.log", "", slog.LevelDebug, nil},
+		{"info", "", "info", slog.LevelInfo, nil},
+		{"warn_with_log_file", "test.log", "warn", slog.LevelWarn, nil},
+		{"case_insensitive", "", " DEBUG ", slog.LevelDebug, nil},
+		{"invalid", "test.log", "verbose", slog.LevelDebug, errInvalidLogLevel},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		setEnv(t, envNameDebug, test.debugLog)
+		setEnv(t, envNameLogLevel, test.level)
+
+		got, err := GetLogLevel()
+		require.ErrorIs(t, err, test.wantErr, name)
+		require.Equal(t, test.want, got, name)
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  GetLogFormat
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_GetLogFormat(t *testing.T) {
+	for index, test := range []struct {
+		name    string
+		format  string
+		want    string
+		wantErr error
+	}{
+		{"default", "", logFormatText, nil},
+		{"text", "text", logFormatText, nil},
+		{"json", " JSON ", logFormatJSON, nil},
+		{"invalid", "logfmt", logFormatText, errInvalidLogFormat},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		setEnv(t, envNameLogFormat, test.format)
+
+		got, err := GetLogFormat()
+		require.ErrorIs(t, err, test.wantErr, name)
+		require.Equal(t, test.want, got, name)
+	}
+}
+
+//nolint:paralleltest // sets env var
+func Test_newLogger_json(t *testing.T) {
+	setEnv(t, envNameLogFormat, logFormatJSON)
+
+	path := filepath.Join(t.TempDir(), "test.log")
+	newLogger(true, path).Info("test log entry", logKeyTool, toolName)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var entry map[string]any
+
+	require.NoError(t, json.Unmarshal(content, &entry), "entry should be a JSON object")
+	require.Equal(t, "test log entry", entry["msg"])
+	require.Equal(t, toolName, entry[logKeyTool])
+	require.Regexp(t, `Z$`, entry["time"], "time should be in UTC")
+}
+
+// ----------------------------------------------------------------------------
+//  logAt
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_logAt(t *testing.T) {
+	unsetEnv(t, envNameDebug)
+	setEnv(t, envNameLogLevel, logLevelWarn)
+
+	var logged []string
+
+	ctx := withLogger(context.Background(), mockLogger(func(entry string) { logged = append(logged, entry) }))
+
+	debugLog(ctx, "Test_logAt debug")
+	infoLog(ctx, "Test_logAt info")
+	warnLog(ctx, "Test_logAt warn", "reason", "test")
+	errorLog(ctx, "Test_logAt error")
+
+	require.Equal(t, []string{"Test_logAt warn reason=test", "Test_logAt error"}, logged,
+		"entries below the log level should be dropped")
+
+	tail := strings.Join(debugTail.last(logTailMax), "\n")
+	require.Contains(t, tail, "Test_logAt warn")
+	require.NotContains(t, tail, "Test_logAt info", "only the entries written should be kept")
+}
+
+// ----------------------------------------------------------------------------
+//  logEntry
+// ----------------------------------------------------------------------------
+
+func Test_logEntry(t *testing.T) {
+	t.Parallel()
+
+	for index, test := range []struct {
+		name string
+		msg  string
+		args []any
+		want string
+	}{
+		{"message_only", "config reloaded", nil, "config reloaded"},
+		{"fields", "text mirrored", []any{logKeyTool, toolName, logKeyInputSize, 3}, "text mirrored tool=mirror input_size=3"},
+		{"quoted", "client initialized", []any{logKeyApp, "Visual Studio Code 1.102.0"}, `client initialized app="Visual Studio Code 1.102.0"`},
+		{"duration", "text mirrored", []any{logKeyDuration, 1500 * time.Microsecond}, "text mirrored duration=1.5ms"},
+		{"error", "tool call rejected", []any{logKeyError, errors.New("queue full")}, `tool call rejected error="queue full"`},
+		{"group", "text mirrored", []any{slog.Group(logKeyMeta, "traceparent", "00-ab-01")}, "text mirrored meta.traceparent=00-ab-01"},
+	} {
+		require.Equal(t, test.want, logEntry(test.msg, test.args...), fmt.Sprintf("Test #%d: %s", index+1, test.name))
+	}
+}
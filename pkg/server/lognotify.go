@@ -0,0 +1,224 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// logNotifyLogger is the logger name of the log messages sent to the clients.
+const logNotifyLogger = serviceName
+
+// notifyLevel returns the MCP log level of the slog level.
+func notifyLevel(level slog.Level) mcp.LoggingLevel {
+	switch {
+	case level >= slog.LevelError:
+		return "error"
+	case level >= slog.LevelWarn:
+		return "warning"
+	case level >= slog.LevelInfo:
+		return "info"
+	default:
+		return "debug"
+	}
+}
+
+// clientLog sends the log entries to the connected clients as MCP log
+// messages.
+//
+//nolint:gochecknoglobals // debugLog is global as well
+var clientLog = newLogBroadcaster()
+
+// notifyLevels are the MCP log levels, from the lowest to the highest.
+//
+//nolint:gochecknoglobals // read-only table
+var notifyLevels = []mcp.LoggingLevel{"debug", "info", "notice", "warning", "error", "critical", "alert", "emergency"}
+
+// logNotifyQueue is the number of log messages queued per session. Messages
+// are dropped while the queue of a slow client is full.
+const logNotifyQueue = 256
+
+// logSession is the value of the logKeySession attribute, logged as the ID of
+// the session. It tells logAt which session the entry is about.
+type logSession struct {
+	session *mcp.ServerSession
+}
+
+// LogValue returns the session ID. It is an empty group, omitted from the
+// entry, for the sessions without an ID such as over stdio.
+func (s logSession) LogValue() slog.Value {
+	if s.session == nil || s.session.ID() == "" {
+		return slog.GroupValue()
+	}
+
+	return slog.StringValue(s.session.ID())
+}
+
+// sessionLog returns the logKeySession attribute value of the session of the
+// request.
+func sessionLog(req mcp.Request) logSession {
+	if req == nil {
+		return logSession{}
+	}
+
+	session, _ := req.GetSession().(*mcp.ServerSession)
+
+	return logSession{session: session}
+}
+
+// entrySession returns the session the log entry with the attributes is about,
+// as given by a logSession value. It returns nil for the server-wide entries.
+func entrySession(args []any) *mcp.ServerSession {
+	for _, arg := range args {
+		if attr, ok := arg.(slog.Attr); ok {
+			arg = attr.Value.Any()
+		}
+
+		if value, ok := arg.(logSession); ok && value.session != nil {
+			return value.session
+		}
+	}
+
+	return nil
+}
+
+// logSubscriber is a session registered to the logBroadcaster.
+type logSubscriber struct {
+	queue chan *mcp.LoggingMessageParams // sent from another goroutine
+	level mcp.LoggingLevel               // set by the client. empty until set
+}
+
+// logBroadcaster sends log messages to the initialized sessions. Each session
+// receives them only once the client sets the log level via logging/setLevel,
+// and only the ones at or above that level.
+//
+// The entries of the tool calls hold the texts of the client, so the entries
+// about a session are sent to that session only. The server-wide entries are
+// sent to the sessions without an ID, such as over stdio, since the sessions
+// over HTTP may be of anyone.
+type logBroadcaster struct {
+	sessions map[*mcp.ServerSession]*logSubscriber
+	mu       sync.Mutex
+}
+
+// newLogBroadcaster returns a logBroadcaster without sessions.
+func newLogBroadcaster() *logBroadcaster {
+	broadcaster := new(logBroadcaster)
+	broadcaster.sessions = make(map[*mcp.ServerSession]*logSubscriber)
+
+	return broadcaster
+}
+
+// add registers the session until it is closed. The messages to the session
+// are sent from another goroutine, so that a slow client never blocks the
+// logging nor the other clients.
+func (b *logBroadcaster) add(session *mcp.ServerSession) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.sessions[session]; ok {
+		return
+	}
+
+	subscriber := new(logSubscriber)
+	subscriber.queue = make(chan *mcp.LoggingMessageParams, logNotifyQueue)
+	b.sessions[session] = subscriber
+
+	go func() {
+		for params := range subscriber.queue {
+			// Best effort. Logging must not fail the caller.
+			_ = session.Log(context.Background(), params)
+		}
+	}()
+
+	go func() {
+		_ = session.Wait()
+
+		b.mu.Lock()
+		delete(b.sessions, session)
+		close(subscriber.queue)
+		b.mu.Unlock()
+	}()
+}
+
+// initializedHandler is the mcp.ServerOptions.InitializedHandler registering
+// the sessions.
+func (b *logBroadcaster) initializedHandler(_ context.Context, req *mcp.InitializedRequest) {
+	b.add(req.Session)
+}
+
+// middleware records the log levels set by the clients of the registered
+// sessions, so that the log functions do not build the messages none of them
+// would receive.
+func (b *logBroadcaster) middleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		result, err := next(ctx, method, req)
+
+		params, ok := req.GetParams().(*mcp.SetLoggingLevelParams)
+		session, isServer := req.GetSession().(*mcp.ServerSession)
+
+		if err == nil && ok && isServer && params != nil {
+			b.mu.Lock()
+			if subscriber, registered := b.sessions[session]; registered {
+				subscriber.level = params.Level
+			}
+			b.mu.Unlock()
+		}
+
+		return result, err
+	}
+}
+
+// wants reports whether any registered session receives the messages of the
+// level about the session, or the server-wide ones if session is nil.
+func (b *logBroadcaster) wants(level mcp.LoggingLevel, session *mcp.ServerSession) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for target, subscriber := range b.sessions {
+		if receives(target, session) && subscriber.level != "" &&
+			slices.Index(notifyLevels, level) >= slices.Index(notifyLevels, subscriber.level) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// send queues the message about the session, or the server-wide one if
+// session is nil, to the registered sessions receiving it. The level filtering
+// is done by the sessions. Messages to the sessions whose queue is full are
+// dropped.
+func (b *logBroadcaster) send(level mcp.LoggingLevel, message string, session *mcp.ServerSession) {
+	params := new(mcp.LoggingMessageParams)
+	params.Level = level
+	params.Logger = logNotifyLogger
+	params.Data = message
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for target, subscriber := range b.sessions {
+		if !receives(target, session) {
+			continue
+		}
+
+		select {
+		case subscriber.queue <- params:
+		default:
+		}
+	}
+}
+
+// receives reports whether the target session receives the log entries about
+// the session, or the server-wide ones if session is nil.
+func receives(target, session *mcp.ServerSession) bool {
+	if session == nil {
+		return target.ID() == ""
+	}
+
+	return target == session
+}
@@ -0,0 +1,316 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  logBroadcaster
+// ----------------------------------------------------------------------------
+
+func Test_logBroadcaster_session_closed(t *testing.T) {
+	t.Parallel()
+
+	broadcaster := newLogBroadcaster()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+
+	serverSession, err := newServer().Connect(context.Background(), serverTransport, nil)
+	require.NoError(t, err)
+
+	broadcaster.add(serverSession)
+	require.Len(t, broadcaster.sessions, 1)
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.0"}, nil) //nolint:exhaustruct // minimal client
+
+	session, err := client.Connect(context.Background(), clientTransport, nil)
+	require.NoError(t, err)
+	require.NoError(t, session.Close())
+
+	require.Eventually(t, func() bool {
+		broadcaster.mu.Lock()
+		defer broadcaster.mu.Unlock()
+
+		return len(broadcaster.sessions) == 0
+	}, timeoutEventually, tickEventually, "closed session should be removed")
+}
+
+func Test_logBroadcaster_wants(t *testing.T) {
+	t.Parallel()
+
+	broadcaster := newLogBroadcaster()
+	server := newServer()
+	server.AddReceivingMiddleware(broadcaster.middleware)
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+
+	serverSession, err := server.Connect(context.Background(), serverTransport, nil)
+	require.NoError(t, err)
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.0"}, nil) //nolint:exhaustruct // minimal client
+
+	session, err := client.Connect(context.Background(), clientTransport, nil)
+	require.NoError(t, err)
+
+	defer func() {
+		_ = session.Close()
+		_ = serverSession.Wait()
+	}()
+
+	level := new(mcp.SetLoggingLevelParams)
+	level.Level = "warning"
+
+	// Not registered: the level is not recorded
+	require.NoError(t, session.SetLoggingLevel(context.Background(), level))
+	require.False(t, broadcaster.wants("error", nil))
+
+	// Registered, but no level set yet
+	broadcaster.add(serverSession)
+	require.False(t, broadcaster.wants("error", nil), "no message is sent until the client sets the level")
+
+	require.NoError(t, session.SetLoggingLevel(context.Background(), level))
+	require.True(t, broadcaster.wants("error", nil))
+	require.True(t, broadcaster.wants("warning", serverSession))
+	require.False(t, broadcaster.wants("info", nil))
+
+	// Entries about other sessions are not sent
+	require.False(t, broadcaster.wants("error", new(mcp.ServerSession)))
+}
+
+func Test_entrySession(t *testing.T) {
+	t.Parallel()
+
+	session := new(mcp.ServerSession)
+
+	for index, test := range []struct {
+		name string
+		args []any
+		want *mcp.ServerSession
+	}{
+		{"none", []any{logKeyTool, toolName}, nil},
+		{"pair", []any{logKeyTool, toolName, logKeySession, logSession{session: session}}, session},
+		{"attr", []any{slog.Any(logKeySession, logSession{session: session})}, session},
+		{"no_session", []any{logKeySession, logSession{}}, nil},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		require.Same(t, test.want, entrySession(test.args), name)
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  notifications/message
+// ----------------------------------------------------------------------------
+
+func Test_debugLog_notifications(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+
+	serverSession, err := newServer().Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+
+	messages := make(chan *mcp.LoggingMessageParams, 16)
+	options := new(mcp.ClientOptions)
+	options.LoggingMessageHandler = func(_ context.Context, req *mcp.LoggingMessageRequest) {
+		// Other tests may log concurrently.
+		if text, ok := req.Params.Data.(string); ok && strings.Contains(text, "Test_debugLog_notifications") {
+			messages <- req.Params
+		}
+	}
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.0"}, options) //nolint:exhaustruct // minimal client
+
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+
+	defer func() {
+		_ = session.Close()
+		_ = serverSession.Wait()
+	}()
+
+	level := new(mcp.SetLoggingLevelParams)
+	level.Level = "debug"
+
+	// Registered once initialized
+	require.Eventually(t, func() bool {
+		clientLog.mu.Lock()
+		defer clientLog.mu.Unlock()
+
+		_, ok := clientLog.sessions[serverSession]
+
+		return ok
+	}, timeoutEventually, tickEventually)
+
+	require.NoError(t, session.SetLoggingLevel(ctx, level))
+
+	res := callTool(t, session, toolName, map[string]any{"text": "Test_debugLog_notifications"})
+	require.False(t, res.IsError)
+
+	select {
+	case msg := <-messages:
+		require.Equal(t, mcp.LoggingLevel("debug"), msg.Level)
+		require.Equal(t, logNotifyLogger, msg.Logger)
+		require.Contains(t, msg.Data, "text=Test_debugLog_notifications")
+	case <-time.After(timeoutEventually):
+		require.Fail(t, "debug log should be sent to the client")
+	}
+
+	// Not sent below the level set by the client
+	level.Level = "info"
+	require.NoError(t, session.SetLoggingLevel(ctx, level))
+
+	debugLog(ctx, "Test_debugLog_notifications not sent")
+	warnLog(ctx, "Test_debugLog_notifications warning", "reason", "test")
+
+	select {
+	case msg := <-messages:
+		require.Equal(t, mcp.LoggingLevel("warning"), msg.Level)
+		require.Equal(t, "Test_debugLog_notifications warning reason=test", msg.Data)
+	case <-time.After(timeoutEventually):
+		require.Fail(t, "warnings should be sent to the client")
+	}
+
+	select {
+	case msg := <-messages:
+		require.Fail(t, "unexpected message", msg.Data)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func Test_notifyLevel(t *testing.T) {
+	t.Parallel()
+
+	for level, want := range map[slog.Level]mcp.LoggingLevel{
+		slog.LevelDebug:     "debug",
+		slog.LevelInfo:      "info",
+		slog.LevelWarn:      "warning",
+		slog.LevelError:     "error",
+		slog.LevelError + 4: "error",
+	} {
+		require.Equal(t, want, notifyLevel(level), level.String())
+	}
+}
+
+func Test_debugLog_notifications_http(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(newHTTPHandler(context.Background(), newServer(), new(atomic.Bool)))
+	defer server.Close()
+
+	ctx := context.Background()
+
+	connect := func(name string) (*mcp.ClientSession, chan string) {
+		messages := make(chan string, 16)
+		options := new(mcp.ClientOptions)
+		options.LoggingMessageHandler = func(_ context.Context, req *mcp.LoggingMessageRequest) {
+			// Other tests may log concurrently.
+			if text, ok := req.Params.Data.(string); ok && strings.Contains(text, "Test_debugLog_notifications_http") {
+				messages <- text
+			}
+		}
+
+		transport := new(mcp.StreamableClientTransport)
+		transport.Endpoint = server.URL + httpPathMCP
+
+		client := mcp.NewClient(&mcp.Implementation{Name: name, Version: "v0.0.0"}, options) //nolint:exhaustruct // minimal client
+
+		session, err := client.Connect(ctx, transport, nil)
+		require.NoError(t, err)
+
+		level := new(mcp.SetLoggingLevelParams)
+		level.Level = "debug"
+		require.NoError(t, session.SetLoggingLevel(ctx, level))
+
+		return session, messages
+	}
+
+	alice, aliceMessages := connect("alice")
+	defer alice.Close()
+
+	bob, bobMessages := connect("bob")
+	defer bob.Close()
+
+	res := callTool(t, alice, toolName, map[string]any{"text": "Test_debugLog_notifications_http secret"})
+	require.False(t, res.IsError)
+
+	select {
+	case text := <-aliceMessages:
+		require.Contains(t, text, "Test_debugLog_notifications_http secret")
+		require.Contains(t, text, logKeySession+"="+alice.ID(), "entry should hold the session ID")
+	case <-time.After(timeoutEventually):
+		require.Fail(t, "entries of the calls should be sent to the calling client")
+	}
+
+	warnLog(ctx, "Test_debugLog_notifications_http server-wide")
+
+	select {
+	case text := <-bobMessages:
+		require.Fail(t, "other clients should not receive the entries", text)
+	case text := <-aliceMessages:
+		require.Fail(t, "clients over HTTP should not receive the server-wide entries", text)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func Test_logBroadcaster_send_slow_client(t *testing.T) {
+	t.Parallel()
+
+	broadcaster := newLogBroadcaster()
+	server := newServer()
+	server.AddReceivingMiddleware(broadcaster.middleware)
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+
+	serverSession, err := server.Connect(context.Background(), serverTransport, nil)
+	require.NoError(t, err)
+
+	release := make(chan struct{})
+	options := new(mcp.ClientOptions)
+	options.LoggingMessageHandler = func(context.Context, *mcp.LoggingMessageRequest) {
+		<-release // client never reading the messages
+	}
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.0"}, options) //nolint:exhaustruct // minimal client
+
+	session, err := client.Connect(context.Background(), clientTransport, nil)
+	require.NoError(t, err)
+
+	defer func() {
+		close(release)
+		_ = session.Close()
+		_ = serverSession.Wait()
+	}()
+
+	broadcaster.add(serverSession)
+
+	level := new(mcp.SetLoggingLevelParams)
+	level.Level = "debug"
+	require.NoError(t, session.SetLoggingLevel(context.Background(), level))
+
+	done := make(chan struct{})
+
+	go func() {
+		for range logNotifyQueue * 4 {
+			broadcaster.send("info", "Test_logBroadcaster_send_slow_client", nil)
+		}
+
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeoutEventually):
+		require.Fail(t, "sending should not block on a slow client")
+	}
+}
@@ -0,0 +1,206 @@
+package server
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Rotation of the debug log file.
+//
+// E.g.: MCP_TEXT_MIRROR_LOG_MAX_SIZE=10 MCP_TEXT_MIRROR_LOG_MAX_AGE=168h
+const (
+	envNameLogMaxSize    = envPrefix + "LOG_MAX_SIZE"    // env var of the size in megabytes to rotate the log file at. 0 disables rotation
+	envNameLogMaxBackups = envPrefix + "LOG_MAX_BACKUPS" // env var of the number of rotated log files kept. 0 keeps all
+	envNameLogMaxAge     = envPrefix + "LOG_MAX_AGE"     // env var of the duration to keep the rotated log files. e.g. 168h
+	envNameLogCompress   = envPrefix + "LOG_COMPRESS"    // env var to gzip the rotated log files
+
+	logMaxSizeDefault    = 100     // default size in megabytes to rotate the log file at
+	logMaxBackupsDefault = 5       // default number of rotated log files kept
+	megabyte             = 1 << 20 // unit of the max size
+	logBackupSep         = "-"     // separator between the name of the log file and the time of the rotation
+	logBackupTimeFmt     = "2006-01-02T15-04-05.000"
+	logCompressExt       = ".gz"
+)
+
+// logRotation is the rotation policy of the log file.
+type logRotation struct {
+	maxSize    int64         // size in bytes to rotate at. 0 disables rotation
+	maxBackups int           // number of rotated files kept. 0 keeps all
+	maxAge     time.Duration // age of the rotated files removed. 0 keeps all
+	compress   bool          // whether the rotated files are gzipped
+}
+
+// GetLogRotation returns the rotation policy of the debug log file from
+// 'MCP_TEXT_MIRROR_LOG_MAX_SIZE', 'MCP_TEXT_MIRROR_LOG_MAX_BACKUPS',
+// 'MCP_TEXT_MIRROR_LOG_MAX_AGE' and 'MCP_TEXT_MIRROR_LOG_COMPRESS' environment
+// variables.
+//
+// The log file is renamed to "text-mirror-<UTC time>.log" when it would exceed
+// the max size, 100 MB by default, and a new one is started. The 5 latest
+// rotated files are kept by default, regardless of their age.
+func GetLogRotation() (logRotation, error) {
+	return loadedSettings().logRotation()
+}
+
+// logRotation returns GetLogRotation of the settings.
+func (v *settingValues) logRotation() (logRotation, error) {
+	var rotation logRotation
+
+	maxSize, err := v.envInt(envNameLogMaxSize, logMaxSizeDefault)
+	if err != nil {
+		return rotation, err
+	}
+
+	rotation.maxSize = int64(maxSize) * megabyte
+
+	rotation.maxBackups, err = v.envInt(envNameLogMaxBackups, logMaxBackupsDefault)
+	if err != nil {
+		return rotation, err
+	}
+
+	rotation.maxAge, err = v.envDuration(envNameLogMaxAge)
+	if err != nil {
+		return rotation, err
+	}
+
+	rotation.compress, err = v.envBool(envNameLogCompress)
+
+	return rotation, err
+}
+
+// rotate renames the log file to a backup and continues in a new file at the
+// same path. The file is closed first, as Windows can't rename open files. If
+// the new file can't be opened, the log continues in the previous one or the
+// standard error. The caller holds the lock.
+func (w *logWriter) rotate() {
+	path := w.file.Name()
+	backup := logBackupName(path, w.now())
+
+	_ = w.file.Close()
+
+	renamed := os.Rename(path, backup) == nil
+
+	file, err := os.OpenFile(filepath.Clean(path), logFlag, logPerm)
+	if err != nil {
+		file = os.Stderr
+	}
+
+	w.file, w.size = file, 0
+
+	if renamed {
+		rotation, now := w.rotation, w.now()
+
+		w.pruning.Go(func() {
+			w.pruneMu.Lock()
+			defer w.pruneMu.Unlock()
+
+			pruneLogBackups(path, rotation, now)
+		})
+	}
+}
+
+// logBackupName returns the name of the rotated log file at the time, in the
+// same directory. E.g. "text-mirror-2025-01-02T03-04-05.678.log".
+func logBackupName(path string, at time.Time) string {
+	ext := filepath.Ext(path)
+
+	return strings.TrimSuffix(path, ext) + logBackupSep + at.UTC().Format(logBackupTimeFmt) + ext
+}
+
+// logBackup is a rotated log file.
+type logBackup struct {
+	path string
+	at   time.Time // time of the rotation
+}
+
+// logBackups returns the rotated files of the log file at path, the latest
+// first.
+func logBackups(path string) []logBackup {
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return nil
+	}
+
+	ext := filepath.Ext(path)
+	prefix := strings.TrimSuffix(filepath.Base(path), ext) + logBackupSep
+
+	var backups []logBackup
+
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), logCompressExt)
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+
+		at, err := time.Parse(logBackupTimeFmt, strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext))
+		if err != nil {
+			continue // not a backup, e.g. "text-mirror-old.log"
+		}
+
+		backups = append(backups, logBackup{filepath.Join(filepath.Dir(path), entry.Name()), at})
+	}
+
+	slices.SortFunc(backups, func(a, b logBackup) int { return b.at.Compare(a.at) })
+
+	return backups
+}
+
+// pruneLogBackups removes the rotated files of the log file at path beyond the
+// max number of backups or older than the max age, and compresses the rest if
+// enabled. Failures are ignored and retried on the next rotation.
+func pruneLogBackups(path string, rotation logRotation, now time.Time) {
+	for index, backup := range logBackups(path) {
+		expired := rotation.maxAge > 0 && now.Sub(backup.at) > rotation.maxAge
+		if expired || (rotation.maxBackups > 0 && index >= rotation.maxBackups) {
+			_ = os.Remove(backup.path)
+
+			continue
+		}
+
+		if rotation.compress && !strings.HasSuffix(backup.path, logCompressExt) {
+			_ = compressLogBackup(backup.path)
+		}
+	}
+}
+
+// compressLogBackup gzips the rotated log file and removes the original. A
+// partially written file is removed on failure.
+func compressLogBackup(path string) error {
+	src, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return wrapError(err, "failed to open %s", path)
+	}
+
+	defer src.Close()
+
+	dst, err := os.OpenFile(filepath.Clean(path+logCompressExt), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, logPerm)
+	if err != nil {
+		return wrapError(err, "failed to create %s", path+logCompressExt)
+	}
+
+	zw := gzip.NewWriter(dst)
+
+	_, err = io.Copy(zw, src)
+	if closeErr := zw.Close(); err == nil {
+		err = closeErr
+	}
+
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		_ = os.Remove(dst.Name())
+
+		return wrapError(err, "failed to compress %s", path)
+	}
+
+	_ = src.Close() // before removal on Windows
+
+	return wrapError(os.Remove(path), "failed to remove %s", path)
+}
@@ -0,0 +1,150 @@
+package server
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  GetLogRotation
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_GetLogRotation(t *testing.T) {
+	for index, test := range []struct {
+		name                        string
+		maxSize, maxBackups, maxAge string
+		compress                    string
+		want                        logRotation
+		wantErr                     error
+	}{
+		{"default", "", "", "", "", logRotation{100 * megabyte, 5, 0, false}, nil},
+		{"all", "10", "3", "168h", "true", logRotation{10 * megabyte, 3, 168 * time.Hour, true}, nil},
+		{"disabled", "0", "0", "", "", logRotation{0, 0, 0, false}, nil},
+		{"invalid_size", "-1", "", "", "", logRotation{}, errInvalidNumber},
+		{"invalid_backups", "", "many", "", "", logRotation{100 * megabyte, 0, 0, false}, errInvalidNumber},
+		{"invalid_age", "", "", "7d", "", logRotation{100 * megabyte, 5, 0, false}, errInvalidDuration},
+		{"invalid_compress", "", "", "", "gzip", logRotation{100 * megabyte, 5, 0, false}, errInvalidBool},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		setEnv(t, envNameLogMaxSize, test.maxSize)
+		setEnv(t, envNameLogMaxBackups, test.maxBackups)
+		setEnv(t, envNameLogMaxAge, test.maxAge)
+		setEnv(t, envNameLogCompress, test.compress)
+
+		got, err := GetLogRotation()
+		require.ErrorIs(t, err, test.wantErr, name)
+		require.Equal(t, test.want, got, name)
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  logWriter.rotate
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // reads env var
+func Test_logWriter_rotate(t *testing.T) {
+	unsetEnv(t, envNameLogMaxSize, envNameLogMaxBackups, envNameLogMaxAge, envNameLogCompress)
+
+	path := filepath.Join(t.TempDir(), "text-mirror.log")
+
+	file, err := os.OpenFile(path, logFlag, logPerm)
+	require.NoError(t, err)
+
+	handler := newLogHandler(file)
+	out := handler.out
+
+	defer func() { _ = out.file.Close() }()
+
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	out.now = func() time.Time {
+		now = now.Add(time.Second)
+
+		return now
+	}
+	out.rotation = logRotation{maxSize: 30, maxBackups: 2, compress: true}
+
+	for i := range 5 {
+		_, err := fmt.Fprintf(out, "entry %d of twenty bytes\n", i) // 25 bytes, a file each
+		require.NoError(t, err)
+	}
+
+	out.pruning.Wait()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "entry 4 of twenty bytes\n", string(data), "the current file should have the last entry")
+
+	backups := logBackups(path)
+	require.Len(t, backups, 2, "the backups beyond the max should be removed")
+
+	for index, backup := range backups {
+		require.True(t, strings.HasSuffix(backup.path, ".log.gz"), "the backups should be compressed")
+
+		compressed, err := os.Open(backup.path)
+		require.NoError(t, err)
+
+		reader, err := gzip.NewReader(compressed)
+		require.NoError(t, err)
+
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.NoError(t, compressed.Close())
+		require.Equal(t, fmt.Sprintf("entry %d of twenty bytes\n", 3-index), string(data), "the latest backup should be first")
+	}
+
+	// Disabled
+	out.rotation.maxSize = 0
+
+	_, err = fmt.Fprint(out, strings.Repeat("x", 100))
+	require.NoError(t, err)
+	require.Len(t, logBackups(path), 2, "it should not rotate if disabled")
+}
+
+// ----------------------------------------------------------------------------
+//  pruneLogBackups
+// ----------------------------------------------------------------------------
+
+func Test_pruneLogBackups_max_age(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "text-mirror.log")
+	now := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	for _, name := range []string{
+		"text-mirror.log",
+		"text-mirror-old.log",                                        // not a backup
+		logBackupName(path, now.Add(-time.Hour)),                     // kept
+		logBackupName(path, now.Add(-48*time.Hour)),                  // expired
+		logBackupName(path, now.Add(-72*time.Hour)) + logCompressExt, // expired
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, filepath.Base(name)), []byte("entry\n"), logPerm))
+	}
+
+	pruneLogBackups(path, logRotation{maxAge: 24 * time.Hour}, now)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	require.Equal(t, []string{
+		"text-mirror-2025-01-09T23-00-00.000.log",
+		"text-mirror-old.log",
+		"text-mirror.log",
+	}, slices.Sorted(slices.Values(names)))
+}
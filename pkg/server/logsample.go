@@ -0,0 +1,54 @@
+package server
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Sampling of the log entries of the successful tool calls.
+//
+// E.g.: MCP_TEXT_MIRROR_LOG_SAMPLE=100 logs 1 in 100 successful calls.
+const (
+	envNameLogSample  = envPrefix + "LOG_SAMPLE" // env var of the sampling rate of the successful tool calls
+	logSampleDefault  = 1                        // log every call
+	logKeySampleEvery = "sample"                 // 1 in how many calls the entry stands for
+)
+
+// sampledCalls counts the successful tool calls to log 1 in N of them.
+//
+//nolint:gochecknoglobals // counter shared by the tools and the sessions
+var sampledCalls atomic.Uint64
+
+// GetLogSample returns N to log 1 in N successful tool calls at the debug
+// level, from 'MCP_TEXT_MIRROR_LOG_SAMPLE' environment variable. 0 and 1 log
+// every call, which is the default.
+//
+// The failed calls are always logged, so that the sampling keeps the debug
+// logging affordable for the agents calling the tools thousands of times a
+// minute without losing sight of the errors.
+func GetLogSample() (int, error) {
+	return loadedSettings().logSample()
+}
+
+// logSample returns GetLogSample of the settings.
+func (v *settingValues) logSample() (int, error) {
+	return v.envInt(envNameLogSample, logSampleDefault)
+}
+
+// callLog logs the entry of a successful tool call at the debug level as
+// debugLog does, for 1 in GetLogSample calls. The sampled entries have the
+// "sample" attribute with the rate, to scale the counts of the entries back.
+func callLog(ctx context.Context, msg string, args ...any) {
+	every, _ := GetLogSample() // invalid values are reported by loadSettings
+	if every <= 1 {
+		debugLog(ctx, msg, args...)
+
+		return
+	}
+
+	if (sampledCalls.Add(1)-1)%uint64(every) != 0 {
+		return
+	}
+
+	debugLog(ctx, msg, append(args, logKeySampleEvery, every)...)
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  callLog
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_GetLogSample(t *testing.T) {
+	unsetEnv(t, envNameLogSample)
+
+	every, err := GetLogSample()
+	require.NoError(t, err)
+	require.Equal(t, 1, every, "every call should be logged by default")
+
+	setEnv(t, envNameLogSample, "-1")
+
+	_, err = GetLogSample()
+	require.ErrorIs(t, err, errInvalidNumber)
+}
+
+//nolint:paralleltest // sets env var
+func Test_callLog(t *testing.T) {
+	setEnv(t, envNameDebug, "test.log")
+	unsetEnv(t, envNameLogLevel)
+
+	var logged []string
+
+	ctx := withLogger(context.Background(), mockLogger(func(entry string) { logged = append(logged, entry) }))
+
+	for index, test := range []struct {
+		name   string
+		sample string
+		want   int
+	}{
+		{"default", "", 10},
+		{"zero", "0", 10},
+		{"one_in_five", "5", 2},
+		{"one_in_many", "1000", 1},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		setEnv(t, envNameLogSample, test.sample)
+		sampledCalls.Store(0)
+
+		logged = nil
+
+		for range 10 {
+			callLog(ctx, "text mirrored", logKeyTool, toolName)
+		}
+
+		require.Len(t, logged, test.want, name)
+
+		if test.sample != "" && test.sample != "0" {
+			require.Equal(t, "text mirrored tool=mirror sample="+test.sample, logged[0], name)
+		}
+	}
+}
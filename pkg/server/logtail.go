@@ -0,0 +1,245 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Debug log tail resource.
+const (
+	logTailURI      = "text-mirror://debug-log"         // URI of the resource. subscribe to it for updates
+	logTailTemplate = "text-mirror://debug-log{?lines}" // URI template to read the last N lines
+	logTailMIME     = "text/plain"
+	logTailMax      = 1000                   // max lines kept in memory
+	logTailDefault  = 100                    // lines returned if not specified
+	logTailNotify   = 500 * time.Millisecond // min interval between update notifications
+	logTailTimeFmt  = "2006/01/02 15:04:05"  // same as log.LstdFlags
+)
+
+// errLogTailForbidden is returned to the network clients not allowed to read
+// the debug log.
+var errLogTailForbidden = errors.New("not allowed to read the debug log")
+
+// debugTail keeps the latest debug log entries for the debug log resource.
+//
+//nolint:gochecknoglobals // debugLog is global as well
+var debugTail = newLogTail(logTailMax)
+
+// logTail is a ring buffer of the latest log lines which notifies the
+// subscribed servers as lines are appended.
+type logTail struct {
+	lines   []string
+	next    int  // index of the next line to write
+	full    bool // true once the buffer wrapped around
+	pending bool // true while an update notification is scheduled
+	servers map[*mcp.Server]int
+	mu      sync.Mutex
+}
+
+// newLogTail returns a logTail keeping up to size lines.
+func newLogTail(size int) *logTail {
+	tail := new(logTail)
+	tail.lines = make([]string, size)
+	tail.servers = make(map[*mcp.Server]int)
+
+	return tail
+}
+
+// add appends the log line and schedules an update notification to the
+// subscribed servers.
+//
+// The notifications are coalesced to one per logTailNotify and sent from
+// another goroutine, so that logging never blocks on the clients and that
+// clients re-reading the resource on each update, which may be logged, don't
+// flood themselves.
+func (t *logTail) add(line string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.lines[t.next] = time.Now().UTC().Format(logTailTimeFmt) + " " + strings.TrimRight(line, "\n")
+	t.next = (t.next + 1) % len(t.lines)
+	t.full = t.full || t.next == 0
+
+	if t.pending || len(t.servers) == 0 {
+		return
+	}
+
+	t.pending = true
+
+	time.AfterFunc(logTailNotify, t.notify)
+}
+
+// notify sends the resources/updated notification to the subscribed servers.
+func (t *logTail) notify() {
+	t.mu.Lock()
+	t.pending = false
+
+	servers := make([]*mcp.Server, 0, len(t.servers))
+	for server := range t.servers {
+		servers = append(servers, server)
+	}
+	t.mu.Unlock()
+
+	params := new(mcp.ResourceUpdatedNotificationParams)
+	params.URI = logTailURI
+
+	for _, server := range servers {
+		_ = server.ResourceUpdated(context.Background(), params)
+	}
+}
+
+// last returns up to n latest lines, oldest first.
+func (t *logTail) last(n int) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	count := t.next
+	if t.full {
+		count = len(t.lines)
+	}
+
+	n = min(n, count)
+	lines := make([]string, 0, n)
+
+	for i := t.next - n; i < t.next; i++ {
+		lines = append(lines, t.lines[(i+len(t.lines))%len(t.lines)])
+	}
+
+	return lines
+}
+
+// watch registers the server to be notified on updates. Calls are counted per
+// server, one per subscribed session.
+func (t *logTail) watch(server *mcp.Server) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.servers[server]++
+}
+
+// unwatch cancels a call of watch.
+func (t *logTail) unwatch(server *mcp.Server) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.servers[server]--; t.servers[server] <= 0 {
+		delete(t.servers, server)
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  MCP resource
+// ----------------------------------------------------------------------------
+
+// setLogTailSubscription sets the handlers of the subscriptions to the debug log
+// resource to the options. server is the server created with the options.
+func setLogTailSubscription(options *mcp.ServerOptions, server **mcp.Server) {
+	options.SubscribeHandler = func(_ context.Context, req *mcp.SubscribeRequest) error {
+		if req.Params.URI != logTailURI {
+			return mcp.ResourceNotFoundError(req.Params.URI)
+		}
+
+		err := checkLogTailClient(req)
+		if err != nil {
+			return err
+		}
+
+		debugTail.watch(*server)
+
+		return nil
+	}
+
+	options.UnsubscribeHandler = func(_ context.Context, req *mcp.UnsubscribeRequest) error {
+		if req.Params.URI == logTailURI {
+			debugTail.unwatch(*server)
+		}
+
+		return nil
+	}
+}
+
+// addLogTailResource registers the debug log resource and its URI template to
+// the server.
+func addLogTailResource(server *mcp.Server) {
+	resource := new(mcp.Resource)
+	resource.URI = logTailURI
+	resource.Name = "debug-log"
+	resource.Title = "Debug log"
+	resource.Description = fmt.Sprintf(
+		"Last %d lines of the debug log. Subscribe for updates as entries are appended", logTailDefault)
+	resource.MIMEType = logTailMIME
+
+	server.AddResource(resource, handleLogTail)
+
+	template := new(mcp.ResourceTemplate)
+	template.URITemplate = logTailTemplate
+	template.Name = "debug-log-lines"
+	template.Title = "Debug log (last N lines)"
+	template.Description = fmt.Sprintf("Last N lines of the debug log, up to %d", logTailMax)
+	template.MIMEType = logTailMIME
+
+	server.AddResourceTemplate(template, handleLogTail)
+}
+
+// handleLogTail returns the latest lines of the debug log. The number of lines
+// is given by the 'lines' query parameter of the URI, logTailDefault if omitted.
+//
+// NOTE: It must not log, since the read could be logged and notified again.
+func handleLogTail(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	err := checkLogTailClient(req)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := logTailDefault
+
+	parsed, err := url.Parse(req.Params.URI)
+	if err != nil {
+		return nil, mcp.ResourceNotFoundError(req.Params.URI)
+	}
+
+	if value := parsed.Query().Get("lines"); value != "" {
+		lines, err = strconv.Atoi(value)
+		if err != nil || lines < 0 {
+			return nil, fmt.Errorf("invalid lines %q: %w", value, errInvalidNumber)
+		}
+	}
+
+	text := strings.Join(debugTail.last(lines), "\n")
+	if text != "" {
+		text += "\n"
+	}
+
+	contents := new(mcp.ResourceContents)
+	contents.URI = req.Params.URI
+	contents.MIMEType = logTailMIME
+	contents.Text = text
+
+	return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{contents}}, nil
+}
+
+// checkLogTailClient returns errLogTailForbidden unless the request came over
+// stdio or from a client listed in GetAdminClients. An empty list allows no
+// network client at all, since the log holds the texts of every client.
+func checkLogTailClient(req mcp.Request) error {
+	extra := req.GetExtra()
+	if extra == nil || extra.Header == nil {
+		return nil // stdio
+	}
+
+	clientID := extra.Header.Get(headerClientID)
+	if clientID != "" && slices.Contains(GetAdminClients(), clientID) {
+		return nil
+	}
+
+	return fmt.Errorf("client %q is %w", clientID, errLogTailForbidden)
+}
@@ -0,0 +1,243 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  logTail
+// ----------------------------------------------------------------------------
+
+func Test_logTail_last(t *testing.T) {
+	t.Parallel()
+
+	tail := newLogTail(3)
+	require.Empty(t, tail.last(10))
+
+	tail.add("one")
+	tail.add("two\n")
+	require.Len(t, tail.last(10), 2)
+	require.True(t, strings.HasSuffix(tail.last(1)[0], " two"), "trailing new line should be trimmed")
+
+	// Oldest lines are dropped once full
+	tail.add("three")
+	tail.add("four")
+
+	lines := tail.last(10)
+	require.Len(t, lines, 3)
+	require.True(t, strings.HasSuffix(lines[0], " two"))
+	require.True(t, strings.HasSuffix(lines[2], " four"))
+	require.Empty(t, tail.last(0))
+}
+
+func Test_logTail_watch(t *testing.T) {
+	t.Parallel()
+
+	tail := newLogTail(1)
+	server := newServer()
+
+	tail.watch(server)
+	tail.watch(server)
+	tail.unwatch(server)
+	require.Len(t, tail.servers, 1, "server should be watched while a session is subscribed")
+
+	tail.unwatch(server)
+	require.Empty(t, tail.servers)
+}
+
+// ----------------------------------------------------------------------------
+//  Debug log resource
+// ----------------------------------------------------------------------------
+
+func Test_handleLogTail(t *testing.T) {
+	t.Parallel()
+
+	debugTail.add("Test_handleLogTail")
+
+	session := connectInMemory(t, newServer())
+	ctx := context.Background()
+
+	res, err := session.ListResources(ctx, nil)
+	require.NoError(t, err)
+	require.True(t, slices.ContainsFunc(res.Resources, func(r *mcp.Resource) bool { return r.URI == logTailURI }),
+		"debug log resource should be listed")
+
+	read := func(uri string) (string, error) {
+		params := new(mcp.ReadResourceParams)
+		params.URI = uri
+
+		res, err := session.ReadResource(ctx, params)
+		if err != nil {
+			return "", err
+		}
+
+		require.Equal(t, logTailMIME, res.Contents[0].MIMEType)
+
+		return res.Contents[0].Text, nil
+	}
+
+	text, err := read(logTailURI)
+	require.NoError(t, err)
+	require.Contains(t, text, "Test_handleLogTail")
+
+	text, err = read(logTailURI + "?lines=1")
+	require.NoError(t, err)
+	require.Equal(t, 1, strings.Count(text, "\n"))
+
+	text, err = read(logTailURI + "?lines=0")
+	require.NoError(t, err)
+	require.Empty(t, text)
+
+	_, err = read(logTailURI + "?lines=-1")
+	require.ErrorContains(t, err, errInvalidNumber.Error())
+}
+
+func Test_debugLog_resource_updated(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+
+	serverSession, err := newServer().Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+
+	updated := make(chan string, 1)
+	options := new(mcp.ClientOptions)
+	options.ResourceUpdatedHandler = func(_ context.Context, req *mcp.ResourceUpdatedNotificationRequest) {
+		select {
+		case updated <- req.Params.URI:
+		default:
+		}
+	}
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.0"}, options) //nolint:exhaustruct // minimal client
+
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+
+	defer func() {
+		_ = session.Close()
+		_ = serverSession.Wait()
+	}()
+
+	// Only the debug log can be subscribed
+	params := new(mcp.SubscribeParams)
+	params.URI = "text-mirror://unknown"
+	require.Error(t, session.Subscribe(ctx, params))
+
+	params.URI = logTailURI
+	require.NoError(t, session.Subscribe(ctx, params))
+
+	debugTail.add("Test_debugLog_resource_updated")
+
+	select {
+	case uri := <-updated:
+		require.Equal(t, logTailURI, uri)
+	case <-time.After(timeoutEventually):
+		require.Fail(t, "subscribed client should be notified of new entries")
+	}
+
+	unsubscribe := new(mcp.UnsubscribeParams)
+	unsubscribe.URI = logTailURI
+	require.NoError(t, session.Unsubscribe(ctx, unsubscribe))
+}
+
+//nolint:paralleltest // sets env var
+func Test_debugLog_tail(t *testing.T) {
+	ctx := withLogger(context.Background(), mockLogger(func(string) {}))
+
+	setEnv(t, envNameDebug, "")
+	debugLog(ctx, "Test_debugLog_tail disabled")
+
+	setEnv(t, envNameDebug, filepath.Join(t.TempDir(), "test.log"))
+	debugLog(ctx, "Test_debugLog_tail", "enabled", true)
+
+	text := strings.Join(debugTail.last(logTailMax), "\n")
+	require.NotContains(t, text, "Test_debugLog_tail disabled", "entries should be kept only in debug mode")
+	require.Contains(t, text, "Test_debugLog_tail enabled=true", "entries should be kept with their fields")
+}
+
+//nolint:paralleltest // sets env var
+func Test_checkLogTailClient(t *testing.T) {
+	request := func(header http.Header) *mcp.ReadResourceRequest {
+		req := new(mcp.ReadResourceRequest)
+		if header != nil {
+			req.Extra = new(mcp.RequestExtra)
+			req.Extra.Header = header
+		}
+
+		return req
+	}
+
+	for index, test := range []struct {
+		name    string
+		allowed string
+		header  http.Header
+		wantErr bool
+	}{
+		{"stdio", "", nil, false},
+		{"no_allowlist", "", http.Header{headerClientID: []string{"ops"}}, true},
+		{"allowed_client", "ops,dev", http.Header{headerClientID: []string{"dev"}}, false},
+		{"other_client", "ops", http.Header{headerClientID: []string{"dev"}}, true},
+		{"anonymous", "ops", http.Header{headerClientID: []string{anonymousClient}}, true},
+		{"no_identity", "ops", http.Header{}, true},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		setEnv(t, envNameAdminClients, test.allowed)
+
+		err := checkLogTailClient(request(test.header))
+		if test.wantErr {
+			require.ErrorIs(t, err, errLogTailForbidden, name)
+
+			continue
+		}
+
+		require.NoError(t, err, name)
+	}
+}
+
+//nolint:paralleltest // sets env var
+func Test_handleLogTail_http(t *testing.T) {
+	unsetEnv(t, envNameAdminClients)
+
+	debugTail.add("Test_handleLogTail_http")
+
+	server := httptest.NewServer(newHTTPHandler(context.Background(), newServer(), new(atomic.Bool)))
+	defer server.Close()
+
+	transport := new(mcp.StreamableClientTransport)
+	transport.Endpoint = server.URL + httpPathMCP
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.0"}, nil) //nolint:exhaustruct // minimal client
+
+	ctx := context.Background()
+
+	session, err := client.Connect(ctx, transport, nil)
+	require.NoError(t, err)
+
+	defer session.Close()
+
+	read := new(mcp.ReadResourceParams)
+	read.URI = logTailURI
+
+	_, err = session.ReadResource(ctx, read)
+	require.ErrorContains(t, err, errLogTailForbidden.Error(), "network clients should not read the debug log")
+
+	subscribe := new(mcp.SubscribeParams)
+	subscribe.URI = logTailURI
+	require.ErrorContains(t, session.Subscribe(ctx, subscribe), errLogTailForbidden.Error(),
+		"network clients should not subscribe to the debug log")
+}
@@ -0,0 +1,753 @@
+// Package server is the text-mirror MCP (Model Context Protocol) server and its
+// command line. It mirrors (reverses) UTF‑8 text while preserving grapheme
+// clusters.
+//
+// Embed the server with New and its options, each Server logging to its own
+// logger:
+//
+//	srv := server.New(server.WithLogger(logger), server.WithTools("mirror"))
+//	err := srv.Run(ctx)
+//
+// Main runs the text-mirror command.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Logger configuration.
+const (
+	envNameDebug   = envPrefix + "DEBUG_LOG" // env var to enable debug logging. the value is the log path
+	fileLogDefault = false                   // set to true to enable debug logging to a file by default
+	logName        = "text-mirror.log"
+	logDir         = "." // fallback directory if the user's log directory is unknown
+	logFlag        = os.O_APPEND | os.O_CREATE | os.O_WRONLY
+	logPerm        = os.FileMode(0o644)
+)
+
+// Service metadata.
+const (
+	serviceName    = "text-mirror"
+	serviceVersion = "(devel)" // default version if not set in build info
+	serviceTitle   = "Text mirroring/reversing tool"
+	revisionLen    = 7 // short revision length for display
+
+	toolName        = "mirror"
+	toolDescription = "Reverses the given UTF-8 text"
+)
+
+// Predefined errors.
+var (
+	errNilContext     = errors.New("given context is nil")
+	errUnknownCommand = errors.New("unknown subcommand")
+)
+
+// App is the text-mirror command with its dependencies: the logger, the
+// standard input and output, the build info and the way the MCP server is run.
+// main runs the one made by newApp, while tests and the programs embedding the
+// command make their own rather than replacing package-level variables.
+type App struct {
+	Logger        *slog.Logger                                        // logger of the debug logs and the errors
+	Stdin         io.Reader                                           // input of the pipe mode, the REPL and the install prompts
+	Stdout        io.Writer                                           // output of the usage, the version and the subcommands
+	ReadBuildInfo func() (*debug.BuildInfo, bool)                     // reads the build info of --version
+	RunServer     func(ctx context.Context, server *mcp.Server) error // runs the MCP server until ctx is done
+	Exit          func(code int)                                      // terminates the process on errors
+}
+
+// newApp returns the App of the process: logging as configured by the
+// environment variables, on the standard input and output, serving over the
+// configured transport and exiting with os.Exit.
+func newApp() *App {
+	app := new(App)
+	app.Logger = newLogger(IsDebugMode(), GetLogPath())
+	app.Stdin = os.Stdin
+	app.Stdout = os.Stdout
+	app.ReadBuildInfo = debug.ReadBuildInfo
+	app.RunServer = runServer
+	app.Exit = os.Exit
+
+	return app
+}
+
+// runServer runs the MCP server over the HTTP transport if configured,
+// otherwise over the standard IO. It will error if given context is nil.
+func runServer(ctx context.Context, server *mcp.Server) error {
+	if ctx == nil {
+		return errNilContext
+	}
+
+	if addr := GetHTTPAddr(); addr != "" {
+		return serveHTTP(ctx, server, addr)
+	}
+
+	if instances, _ := GetInstances(); len(instances) > 0 {
+		warnLog(ctx, "server instances are served over HTTP only, ignored with stdio", "instances", len(instances))
+	}
+
+	return server.Run(ctx, newWireTapTransport(&mcp.StdioTransport{}, openWireTap(ctx)))
+}
+
+// ============================================================================
+//  Main
+// ============================================================================
+
+// Main runs the text-mirror command with the arguments of the process, and
+// exits with 1 on error. It is the main function of the command.
+func Main() {
+	app := newApp()
+	defer reportPanics(logContext(app.Logger))
+
+	app.exitOnError(app.Run(context.Background(), os.Args[1:]))
+}
+
+// IsDebugMode returns whether debug mode is enabled. If true then logging to a
+// file is enabled. By default, it return fileLogDefault constant value.
+//
+// If 'MCP_TEXT_MIRROR_DEBUG_LOG' environment variable is set to a non-empty
+// value, the value is used as the log path and debug mode is enabled.
+func IsDebugMode() bool {
+	if settingValue(envNameDebug) != "" {
+		return true
+	}
+
+	return fileLogDefault
+}
+
+// GetLogPath returns the path to the log file. It defaults to "text-mirror.log"
+// in the user's log directory, such as "~/.local/share/text-mirror", rather
+// than the current directory which is wherever the client launched the server.
+//
+// If 'MCP_TEXT_MIRROR_DEBUG_LOG' environment variable is set to a non-empty
+// value, it returns the value as the log path. Relative paths are also in the
+// user's log directory.
+func GetLogPath() string {
+	logPath := settingValue(envNameDebug)
+	if logPath == "" {
+		logPath = logName
+	}
+
+	if !filepath.IsAbs(logPath) {
+		logPath = filepath.Join(defaultLogDir(), logPath)
+	}
+
+	return filepath.Clean(logPath)
+}
+
+// GetServiceVersion returns the service version string based on build info.
+// If the build info is not available, it returns "unknown (devel)".
+func GetServiceVersion() string {
+	return serviceVersionOf(debug.ReadBuildInfo)
+}
+
+// serviceVersionOf returns the service version string based on the build info
+// read by readBuildInfo. See GetServiceVersion.
+func serviceVersionOf(readBuildInfo func() (*debug.BuildInfo, bool)) string {
+	version := serviceVersion // default version (devel)
+	revision := "unknown"
+
+	info, ok := readBuildInfo()
+	if ok {
+		// version
+		if info.Main.Version != "" {
+			version = info.Main.Version
+		}
+
+		// revision
+		for _, s := range info.Settings {
+			if s.Key == "vcs.revision" && s.Value != "" {
+				revision = s.Value
+
+				break
+			}
+		}
+
+		// Found version. E.g.: v1.0.0 (abcdef0)
+		if version != serviceVersion {
+			return fmt.Sprintf("%s (%s)", version, revision[:min(len(revision), revisionLen)])
+		}
+	}
+
+	return fmt.Sprintf("%s %s", revision[:min(len(revision), revisionLen)], version)
+}
+
+// ----------------------------------------------------------------------------
+//  Helper functions
+// ----------------------------------------------------------------------------
+
+// Run parses the flags in args and runs the subcommand after them. If no known
+// subcommand is given, it starts the MCP server until ctx is done.
+//
+// The config file given by the --config flag, or the default one if it exists,
+// is loaded beforehand. The environment variables override its values, and the
+// flags override both.
+func (a *App) Run(ctx context.Context, args []string) error {
+	ctx = withLogger(ctx, a.Logger)
+
+	opts, err := parseFlags(args)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case opts.help:
+		printUsage(a.Stdout)
+
+		return nil
+	case opts.version:
+		_, err = fmt.Fprintln(a.Stdout, serviceName, serviceVersionOf(a.ReadBuildInfo))
+
+		return err
+	}
+
+	var command subcommand
+
+	if len(opts.args) > 0 {
+		var ok bool
+
+		command, ok = subcommands[opts.args[0]]
+		if !ok {
+			return fmt.Errorf("%w %q. run '%s --help' for usage", errUnknownCommand, opts.args[0], serviceName)
+		}
+
+		if !command.configured {
+			return command.run(ctx, a, opts)
+		}
+	}
+
+	config, err := loadConfig(opts.configPath)
+	if err != nil {
+		return err
+	}
+
+	loaded := storeSettings(func(*settingValues) *settingValues {
+		return newSettingValues(config.env(), opts.env)
+	})
+	if loaded.overridden(envNameDebug) || loaded.overridden(envNameLogFormat) {
+		// Debug logging or the log format set by the config file, a flag or the
+		// profile. Reopen the logger.
+		a.Logger = newLogger(IsDebugMode(), GetLogPath())
+		ctx = withLogger(ctx, a.Logger)
+	}
+
+	if opts.listTools {
+		return listTools(ctx, a.Stdout)
+	}
+
+	if opts.dryRun {
+		return dryRun(ctx, a.Stdout, opts.configPath)
+	}
+
+	if command.run != nil {
+		return command.run(ctx, a, opts)
+	}
+
+	return a.serve(ctx, newConfigReloader(opts.configPath))
+}
+
+// subcommand is a subcommand of the command line.
+type subcommand struct {
+	// run runs the subcommand. opts.args holds the subcommand and its arguments.
+	run func(ctx context.Context, a *App, opts *cliOptions) error
+	// configured is true if the subcommand runs with the config file, the flags
+	// and the profile applied, after --list-tools and --dry-run. The others run
+	// beforehand, with the environment only.
+	configured bool
+}
+
+// subcommands are the subcommands of the command line by name.
+//
+//nolint:gochecknoglobals // read-only table
+var subcommands = map[string]subcommand{
+	// Validate the config file without loading it.
+	cmdNameConfig: {run: func(_ context.Context, a *App, opts *cliOptions) error {
+		return runConfig(opts.args[1:], opts.configPath, a.Stdout)
+	}},
+	// Mirror the text in a shell pipeline, without MCP.
+	cmdNameMirror: {run: func(ctx context.Context, a *App, opts *cliOptions) error {
+		return runMirror(ctx, opts.args[1:], a.Stdin, a.Stdout)
+	}},
+	// Print or write the configuration of the MCP clients.
+	cmdNameInstall: {run: func(_ context.Context, a *App, opts *cliOptions) error {
+		return runInstall(opts.args[1:], opts, a.Stdin, a.Stdout)
+	}},
+	// Mirror the files matching the globs.
+	cmdNameFiles: {run: func(ctx context.Context, a *App, opts *cliOptions) error {
+		return runFiles(ctx, opts.args[1:], a.Stdout)
+	}},
+	// Measure the throughput of the reversal.
+	cmdNameBench: {run: func(ctx context.Context, a *App, opts *cliOptions) error {
+		return runBench(ctx, opts.args[1:], a.Stdout)
+	}},
+	// Check the invariants of the reversal with random inputs.
+	cmdNameFuzz: {run: func(ctx context.Context, a *App, opts *cliOptions) error {
+		return runFuzz(ctx, opts.args[1:], a.Stdout)
+	}},
+	// Check the settings, the log file and the transport without serving.
+	cmdNameDoctor: {configured: true, run: func(ctx context.Context, a *App, _ *cliOptions) error {
+		return runDoctor(ctx, a.Stdout)
+	}},
+	// Call the tools interactively.
+	cmdNameREPL: {configured: true, run: func(ctx context.Context, a *App, _ *cliOptions) error {
+		return runREPL(ctx, a.Stdin, a.Stdout)
+	}},
+	// Call a tool once, in process or over HTTP.
+	cmdNameCall: {configured: true, run: func(ctx context.Context, a *App, opts *cliOptions) error {
+		return runCall(ctx, opts.args[1:], a.Stdout)
+	}},
+	// Install, uninstall or run the Windows service.
+	cmdNameService: {configured: true, run: runService},
+}
+
+// serve starts the MCP server with a.RunServer and returns any error
+// encountered. If reloader is not nil, the config file is reloaded on SIGHUP.
+func (a *App) serve(ctx context.Context, reloader *configReloader) error {
+	err := loadSettings()
+	if err != nil {
+		return wrapError(err, "invalid configuration")
+	}
+
+	state := newServerState(WithLogger(loggerFrom(ctx)))
+	server := state.server
+
+	if reloader != nil {
+		reloadCtx, stopReload := context.WithCancel(ctx)
+		defer stopReload()
+
+		watchReload(reloadCtx, reloader, state)
+	}
+
+	upstreams, err := GetUpstreams()
+	if err != nil {
+		return wrapError(err, "invalid %s", envNameUpstreams)
+	}
+
+	// Aggregator mode. Re-expose the tools of the upstream servers if any.
+	if len(upstreams) > 0 {
+		closeUpstreams, err := addUpstreams(ctx, state.tools, upstreams)
+		if err != nil {
+			return wrapError(err, "MCP server failed to start")
+		}
+		defer closeUpstreams()
+	}
+
+	// Extra tools of the plugin executables, if any.
+	plugins, _ := GetPlugins() // checked by loadSettings
+	if plugins != "" {
+		closePlugins, err := addPlugins(ctx, state.tools, plugins)
+		if err != nil {
+			return wrapError(err, "MCP server failed to start")
+		}
+		defer closePlugins()
+	}
+
+	// Report the effective configuration, in the log and as a resource.
+	configPath := ""
+	if reloader != nil {
+		configPath = reloader.path
+	}
+
+	report := newStartupReport(state, configPath)
+	addStartupResource(server, report)
+	infoLog(ctx, "server starting", report.logAttrs()...)
+
+	// Run server with the configured transport (standard IO by default). The
+	// server instances served over HTTP are reloaded along with it.
+	err = a.RunServer(withParentState(ctx, state), server)
+	if err != nil {
+		return wrapError(err, "MCP server failed to run")
+	}
+
+	return nil
+}
+
+// newServer constructs and configures an MCP server with the mirror tool.
+func newServer(opts ...Option) *mcp.Server {
+	return newServerState(opts...).server
+}
+
+// newServerState constructs and configures an MCP server with the mirror tool,
+// and returns it with its parts which follow the settings on reload. The
+// options of New override the settings they cover.
+func newServerState(opts ...Option) *serverState {
+	given := newServerOptions(opts)
+
+	logger := given.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	// Initialize with zero values (default options) then set the configured ones.
+	// Invalid configurations are reported by loadSettings beforehand.
+	options := new(mcp.ServerOptions)
+	options.KeepAlive, _ = GetKeepAlive()
+	options.InitializedHandler = handleInitialized
+	options.CompletionHandler = handleComplete
+	options.PageSize, _ = GetPageSize()
+	options.Instructions = serverInstructions()
+	options.Logger = newSDKLogger(logger)
+
+	var server *mcp.Server
+
+	setLogTailSubscription(options, &server)
+
+	server = mcp.NewServer(
+		&mcp.Implementation{
+			Name:    serviceName,
+			Title:   serviceTitle,
+			Version: GetServiceVersion(),
+		},
+		options,
+	)
+
+	// Tools which can be enabled or disabled at runtime.
+	tools := newToolSet(server)
+	tools.logger = logger
+	tools.allowed, _ = GetToolFilter()
+
+	if given.tools != nil {
+		tools.allowed = allowOnly(given.tools)
+	}
+
+	// Usage statistics of the tools, counted by the middleware below and pushed
+	// to StatsD if configured.
+	stats := newCallStats()
+	statsd, _ := GetStatsd()
+	stats.statsd = newStatsdClient(statsd, logger)
+	addStatsResource(server, stats)
+
+	// Memory and goroutines of the process, since the statistics started.
+	addRuntimeResource(server, stats.started)
+
+	// Expose the debug log as a subscribable resource.
+	addLogTailResource(server)
+
+	// Report the supported and negotiated protocol versions and capabilities.
+	handshakes := newHandshakes()
+	addCompatResource(server, handshakes)
+
+	// Middlewares of the incoming requests. The first one is the outermost.
+	// Log to the logger of the server, log the negotiated protocol version, record the log levels set by the
+	// clients, advertise the opt-in features to the clients on initialize,
+	// assign the request IDs to the tool calls and echo the trace IDs of the
+	// requests back in the tool results. Then fill in the default arguments,
+	// count the tool calls and apply the limits to them, which can change on
+	// reload, and serve the repeated calls from the cache. Finally, log the
+	// slow calls and recover from the panics of the tool handlers.
+	defaults := new(toolDefaults)
+	limits := new(limitSet)
+	limits.fixed = given.limits
+	cache := newResultCache()
+	cache.statsd = stats.statsd
+	stats.cache = cache
+
+	server.AddReceivingMiddleware(loggerMiddleware(logger), handshakes.middleware, clientLog.middleware,
+		experimentalMiddleware(tools), requestIDMiddleware, metaEchoMiddleware, defaults.middleware, stats.middleware, limits.middleware, cache.middleware, slowCallMiddleware,
+		recoverMiddleware)
+
+	// Register the built-in tools, then add the admin tool and load the
+	// defaults and the limits as configured.
+	state := new(serverState)
+	state.server = server
+	state.logger = logger
+	state.tools = tools
+	state.defaults = defaults
+	state.limits = limits
+	state.stats = stats
+	state.cache = cache
+	state.gatedAdded = make(map[string]bool)
+	state.fixedTools = given.tools
+	state.registerTools()
+	state.apply()
+
+	return state
+}
+
+// newLogger creates a default logger, writing structured entries in the
+// "key=value" text form.
+//
+// If toFile is true, it logs to the given path. Otherwise, it logs to standard error.
+// If the log file cannot be opened, it silently falls back to logging to standard
+// error.
+//
+// NOTE: The log file is intentionally kept open for the lifetime of the process.
+func newLogger(toFile bool, path string) *slog.Logger {
+	return slog.New(newLogHandler(logOutput(toFile, path)))
+}
+
+// logOutput returns the log file at path if toFile is true and it can be
+// opened, or standard error otherwise. Missing directories are created only in
+// the user's log directory.
+func logOutput(toFile bool, path string) *os.File {
+	if toFile {
+		if isInLogDir(path) {
+			_ = os.MkdirAll(filepath.Dir(path), logDirPerm)
+		}
+
+		osFile, err := os.OpenFile(filepath.Clean(path), logFlag, logPerm)
+		if err == nil {
+			return osFile
+		}
+	}
+
+	return os.Stderr
+}
+
+// loggerKey is the context key of the logger of the log functions.
+type loggerKey struct{}
+
+// withLogger returns the context whose log functions, such as debugLog, write
+// to l: App.Run sets the logger of the App, and the Server the one of its
+// requests.
+func withLogger(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// loggerFrom returns the logger of the context set by withLogger, or
+// slog.Default if none.
+func loggerFrom(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok && l != nil {
+		return l
+	}
+
+	return slog.Default()
+}
+
+// loggerMiddleware returns a middleware which sets l as the logger of the
+// requests, for the log functions of the tool handlers and the middlewares.
+func loggerMiddleware(l *slog.Logger) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			return next(withLogger(ctx, l), method, req)
+		}
+	}
+}
+
+// logContext returns the context of the log functions writing to l, for the
+// parts of the server logging outside of a request, such as the toolSet.
+func logContext(l *slog.Logger) context.Context {
+	return withLogger(context.Background(), l)
+}
+
+// debugLog logs the message at the debug level. See logAt.
+func debugLog(ctx context.Context, msg string, args ...any) {
+	logAt(ctx, slog.LevelDebug, msg, args...)
+}
+
+// infoLog logs the message at the info level. See logAt.
+func infoLog(ctx context.Context, msg string, args ...any) {
+	logAt(ctx, slog.LevelInfo, msg, args...)
+}
+
+// warnLog logs the message at the warn level. See logAt.
+func warnLog(ctx context.Context, msg string, args ...any) {
+	logAt(ctx, slog.LevelWarn, msg, args...)
+}
+
+// errorLog logs the message at the error level. See logAt.
+func errorLog(ctx context.Context, msg string, args ...any) {
+	logAt(ctx, slog.LevelError, msg, args...)
+}
+
+// logAt logs the message with the attributes given as key-value pairs to the
+// logger of ctx, as slog.Logger.Log does, if the level is at or above
+// GetLogLevel. The entry written is also kept in debugTail for the debug log
+// resource.
+//
+// Regardless of the log level, the entry is sent to the clients which enabled
+// the MCP logging at or below the level: to the client of the session given by
+// a logKeySession attribute, or to the stdio client if none. See logBroadcaster.
+func logAt(ctx context.Context, level slog.Level, msg string, args ...any) {
+	minLevel, _ := GetLogLevel()
+	logged := level >= minLevel
+
+	// The entry holds the texts of the tool calls as is, so it is built only if
+	// written or sent.
+	notify := notifyLevel(level)
+	session := entrySession(args)
+	sent := clientLog.wants(notify, session)
+
+	if !logged && !sent {
+		return
+	}
+
+	entry := logEntry(msg, args...)
+
+	if logged {
+		loggerFrom(ctx).Log(ctx, level, msg, args...)
+		debugTail.add(entry)
+	}
+
+	if sent {
+		clientLog.send(notify, entry, session)
+	}
+}
+
+// wrapError returns nil if err is nil.
+// Otherwise it wraps the error with given message. If args are provided, it
+// formats the message with them.
+func wrapError(err error, msg string, args ...any) error {
+	if err == nil {
+		return nil
+	}
+
+	if len(args) > 0 {
+		msg = fmt.Sprintf(msg, args...)
+	}
+
+	return fmt.Errorf("%s: %w", msg, err)
+}
+
+// exitOnError logs and reports the error and terminates the process with the
+// exit code 1 by a.Exit. If err is nil, it does nothing.
+func (a *App) exitOnError(err error) {
+	if err != nil {
+		a.Logger.Error("failed to run", logKeyError, err)
+		reportError(logContext(a.Logger), errorKindFatal, err.Error(), nil)
+		a.Exit(1)
+	}
+}
+
+// ============================================================================
+//  'reverse' tool handler
+// ============================================================================
+
+// MirrorInput is the input for the mirror tool.
+type MirrorInput struct {
+	Text        string `json:"text"                  jsonschema:"The UTF-8 text to mirror (reverse), by grapheme clusters unless granularity is given. Leave it empty to read the text from path."`
+	Path        string `json:"path,omitempty"        jsonschema:"Path of a UTF-8 text file to mirror if text is empty. Relative paths are resolved against the roots of the client. Over stdio only."`
+	Render      string `json:"render,omitempty"      jsonschema:"Set to png to also return the mirrored text rendered as an image, to check the rendering of bidi texts and emoji visually."`
+	Granularity string `json:"granularity,omitempty" jsonschema:"The unit of the reversal. grapheme (default) keeps the characters as seen by humans, such as emoji and accented letters, intact. rune reverses the Unicode code points, which splits the combining marks and the emoji sequences. byte reverses the bytes of the ASCII texts only, rejecting the others. word reverses the order of the word segments: the words, the runs of spaces and the punctuation, each kept as is, so e.g. \"Hello, big world!\" becomes \"!world big ,Hello\". Use the reverse_words tool to keep the punctuation in place. line reverses the order of the lines, keeping the line breaks in place."`
+}
+
+// MirrorOutput is the output from the mirror tool.
+type MirrorOutput struct {
+	Text string `json:"text" jsonschema:"The mirrored (reversed) text. Grapheme clusters such as emoji and combining marks are kept intact."`
+}
+
+// mirrorTool is the mirror tool, the main tool of this server.
+type mirrorTool struct{}
+
+// Name returns the name of the tool.
+func (mirrorTool) Name() string { return toolName }
+
+// Description returns the description of the tool.
+func (mirrorTool) Description() string { return toolDescription }
+
+// Schema returns the schemas of the input and the output of the tool.
+func (mirrorTool) Schema() (*jsonschema.Schema, *jsonschema.Schema) {
+	return mirrorInputSchema(), mirrorOutputSchema()
+}
+
+// Annotations returns the hints of the tool.
+func (mirrorTool) Annotations() *mcp.ToolAnnotations { return readOnlyAnnotations() }
+
+// Handler returns handleReverse.
+func (mirrorTool) Handler(*serverState) ToolHandler { return TypedHandler(handleReverse) }
+
+// version returns the version of the behavior of the tool, served as mirror.v1.
+func (mirrorTool) version() int { return 1 }
+
+// cacheable returns whether the result of the call can be cached: not if the
+// text is read from a file or asked to the user, nor if the client's LLM
+// verifies the results.
+func (mirrorTool) cacheable(args map[string]any) bool {
+	text, _ := args["text"].(string)
+	verify, _ := GetVerifyEnabled()
+
+	return text != "" && !verify
+}
+
+// handleReverse returns (meta, output, error) per MCP tool handler contract.
+// The returned output contains the reversed/mirrored input text.
+//
+// If the context is canceled, even in the middle of the reversal, it returns an
+// error. This tool doesn’t care who called it, the CallToolRequest parameter is
+// only used to log the client identity, to report the progress, to resolve the
+// file path against the client roots and to ask the user for the text if empty.
+func handleReverse(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input MirrorInput,
+) (*mcp.CallToolResult, MirrorOutput, error) {
+	err := ctx.Err()
+	if err != nil {
+		return nil, MirrorOutput{}, wrapError(err, "request canceled")
+	}
+
+	switch {
+	case input.Path != "" && input.Text != "":
+		return nil, MirrorOutput{}, errTextAndPath
+	case input.Path != "":
+		// Read the text from the file within the client roots.
+		input.Text, err = readInputFile(ctx, req, input.Path)
+		if err != nil {
+			return nil, MirrorOutput{}, wrapError(err, "failed to read %s", input.Path)
+		}
+	case input.Text == "":
+		// Ask the user for the text if empty, in case it was not intended.
+		input.Text, err = elicitText(ctx, req)
+		if err != nil {
+			return nil, MirrorOutput{}, err
+		}
+	}
+
+	// Apply the per client limit, if configured.
+	err = checkTextLimit(req, input.Text)
+	if err != nil {
+		return nil, MirrorOutput{}, err
+	}
+
+	// This is the core function of this tool: reverses the input text. It stops
+	// once the request is canceled and reports the progress of large inputs if
+	// the client asked for it.
+	start := time.Now()
+
+	outputText, err := reverseBy(ctx, input.Text, input.Granularity, progressReporter(ctx, req, input.Text))
+	if err != nil {
+		return nil, MirrorOutput{}, err
+	}
+
+	// log if debug mode is enabled (fileLogDefault = true or env var is set)
+	// with the client implementation and identity if known, for 1 in
+	// MCP_TEXT_MIRROR_LOG_SAMPLE calls. The texts are redacted per
+	// MCP_TEXT_MIRROR_LOG_REDACT.
+	callLog(ctx, "text mirrored", callLogAttrs(ctx, req, logKeyInputSize, len(input.Text), logKeyDuration, time.Since(start),
+		logKeyText, userText(input.Text), logKeyMirrored, userText(outputText))...)
+
+	// Return the mirrored text as is in the content as well, for the clients that
+	// ignore the structured content. Otherwise the SDK fills it with the JSON.
+	result := new(mcp.CallToolResult)
+	result.Content = []mcp.Content{&mcp.TextContent{Text: outputText}} //nolint:exhaustruct // text only
+
+	// Also render it as an image if asked.
+	if input.Render == renderPNG {
+		rendered, err := renderImage(outputText)
+		if err != nil {
+			return nil, MirrorOutput{}, err
+		}
+
+		result.Content = append(result.Content, rendered)
+	}
+
+	// Ask the client's LLM to verify tricky texts reversed by grapheme clusters,
+	// if enabled.
+	if input.Granularity == "" || input.Granularity == granularityGrapheme {
+		if verification := verifyMirror(ctx, req, input.Text, outputText); verification != nil {
+			result.Meta = mcp.Meta{verifyMetaKey: verification}
+		}
+	}
+
+	return result, MirrorOutput{Text: outputText}, nil
+}
@@ -1,14 +1,16 @@
-package main
+package server
 
 import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"runtime/debug"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/rivo/uniseg"
@@ -17,24 +19,65 @@ import (
 
 var errTest = errors.New("test error")
 
+// Timeouts for require.Eventually.
+const (
+	timeoutEventually = 5 * time.Second
+	tickEventually    = 10 * time.Millisecond
+)
+
 // =============================================================================
 //  Helpers for testing
 // =============================================================================
 
-// mockLogger is a mock implementation of CustomLogger for testing.
-type mockLogger struct {
-	Fn func(v ...any)
+// mockHandler is a mock slog.Handler of the logger for testing.
+type mockHandler struct {
+	Fn func(entry string)
+}
+
+// Enabled returns true for all levels. It is an implementation of slog.Handler.
+func (m mockHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+// Handle calls the Fn function with the entry in the form of logEntry instead of
+// writing it. It is an implementation of slog.Handler.
+func (m mockHandler) Handle(_ context.Context, record slog.Record) error {
+	var args []any
+
+	record.Attrs(func(attr slog.Attr) bool {
+		args = append(args, attr)
+
+		return true
+	})
+
+	m.Fn(logEntry(record.Message, args...))
+
+	return nil
+}
+
+// WithAttrs returns the handler as is. It is an implementation of slog.Handler.
+func (m mockHandler) WithAttrs([]slog.Attr) slog.Handler {
+	return m
+}
+
+// WithGroup returns the handler as is. It is an implementation of slog.Handler.
+func (m mockHandler) WithGroup(string) slog.Handler {
+	return m
 }
 
-// Fatal calls the Fn mock function. It is an implementation of CustomLogger.
-func (m mockLogger) Fatal(v ...any) {
-	m.Fn(v...)
+// newTestApp returns the App of newApp, whose settings stored by App.Run are
+// restored on cleanup.
+func newTestApp(t *testing.T) *App {
+	t.Helper()
+	keepSettings(t)
+
+	return newApp()
 }
 
-// Print calls the Fn function instead of printing. It is an implementation of
-// CustomLogger.
-func (m mockLogger) Print(v ...any) {
-	m.Fn(v...)
+// mockLogger returns a logger calling fn with the entries, such as
+// `text mirrored tool=mirror input_size=3`, instead of writing them.
+func mockLogger(fn func(entry string)) *slog.Logger {
+	return slog.New(mockHandler{Fn: fn})
 }
 
 // =============================================================================
@@ -290,32 +333,18 @@ var dataGetServiceVersion = []struct {
 //  main
 // ----------------------------------------------------------------------------
 
-//nolint:paralleltest // because of monkey patching
+//nolint:paralleltest // reads the config file and the env vars
 func Test_main_failure(t *testing.T) {
-	// Replace logger with one that panics instead of exiting the process.
-	originalLogger := logger
-
-	defer func() {
-		logger = originalLogger
-	}()
-
-	logger = mockLogger{
-		Fn: func(v ...any) { panic(v[0]) },
-	}
-
-	// override context to cause failure
-	defer func() {
-		defaultCtx = context.Background()
-	}()
-
-	// setting to nil to simulate failure
-	//nolint:fatcontext // to simulate failure
-	defaultCtx = nil
-
-	require.Panics(t, func() {
-		// Run main with a context that will cause failure
-		main()
-	}, "Expected main to panic on error")
+	// Exit with a panic instead of exiting the process.
+	app := newTestApp(t)
+	app.Logger = mockLogger(func(string) {})
+	app.Exit = func(code int) { panic(code) }
+	app.RunServer = func(context.Context, *mcp.Server) error { return errTest }
+
+	require.PanicsWithValue(t, 1, func() {
+		// As main does, with a server failing to run
+		app.exitOnError(app.Run(context.Background(), nil))
+	})
 }
 
 // ----------------------------------------------------------------------------
@@ -325,7 +354,7 @@ func Test_main_failure(t *testing.T) {
 func Test_IsDebugMode(t *testing.T) {
 	t.Run("default", func(t *testing.T) {
 		// Ensure env variable is not set
-		t.Setenv(envNameDebug, "")
+		setEnv(t, envNameDebug, "")
 
 		// Clear env variable
 		expect := fileLogDefault
@@ -337,7 +366,7 @@ func Test_IsDebugMode(t *testing.T) {
 
 	t.Run("env_var_set", func(t *testing.T) {
 		// Set env variable to enable debug mode
-		t.Setenv(envNameDebug, "debug.log")
+		setEnv(t, envNameDebug, "debug.log")
 
 		actual := IsDebugMode()
 
@@ -351,21 +380,34 @@ func Test_IsDebugMode(t *testing.T) {
 // ----------------------------------------------------------------------------
 
 func Test_GetLogPath(t *testing.T) {
+	dataHome := t.TempDir()
+	setEnv(t, envNameXDGDataHome, dataHome)
+	setEnv(t, envNameLocalAppData, dataHome)
+
 	t.Run("default", func(t *testing.T) {
 		// Ensure env variable is not set
-		t.Setenv(envNameDebug, "")
+		setEnv(t, envNameDebug, "")
 
-		expect := filepath.Clean(filepath.Join(logDir, logName))
+		expect := filepath.Join(dataHome, serviceName, logName)
 		actual := GetLogPath()
 
 		require.Equal(t, expect, actual,
 			"GetLogPath should return the default log path when env var is not set")
 	})
 
+	t.Run("relative_path", func(t *testing.T) {
+		setEnv(t, envNameDebug, "debug.log")
+
+		actual := GetLogPath()
+
+		require.Equal(t, filepath.Join(dataHome, serviceName, "debug.log"), actual,
+			"relative log path should be in the user's log directory, not the current one")
+	})
+
 	t.Run("env_var_set", func(t *testing.T) {
 		// Set env variable to specify log path
 		customPath := "/custom/path/debug.log"
-		t.Setenv(envNameDebug, customPath)
+		setEnv(t, envNameDebug, customPath)
 
 		actual := GetLogPath()
 
@@ -378,19 +420,16 @@ func Test_GetLogPath(t *testing.T) {
 //  GetServiceVersion
 // ----------------------------------------------------------------------------
 
-//nolint:paralleltest // because of monkey patching
-func Test_GetServiceVersion(t *testing.T) {
-	originalDebugReadBuildInfo := debugReadBuildInfo
-
-	defer func() {
-		debugReadBuildInfo = originalDebugReadBuildInfo
-	}()
+func Test_serviceVersionOf(t *testing.T) {
+	t.Parallel()
 
 	for index, test := range dataGetServiceVersion {
 		title := fmt.Sprintf("Test #%d: %s", index+1, test.name)
 
 		t.Run(title, func(t *testing.T) {
-			debugReadBuildInfo = func() (*debug.BuildInfo, bool) {
+			t.Parallel()
+
+			readBuildInfo := func() (*debug.BuildInfo, bool) {
 				if !test.hasInfo {
 					return nil, false
 				}
@@ -403,10 +442,10 @@ func Test_GetServiceVersion(t *testing.T) {
 			}
 
 			expect := test.expected
-			actual := GetServiceVersion()
+			actual := serviceVersionOf(readBuildInfo)
 
 			require.Equal(t, expect, actual,
-				"GetServiceVersion did not return expected version string")
+				"serviceVersionOf did not return expected version string")
 		})
 	}
 }
@@ -415,24 +454,22 @@ func Test_GetServiceVersion(t *testing.T) {
 //  exitOnError
 // ----------------------------------------------------------------------------
 
-//nolint:paralleltest // monkey patches global state
 func Test_exitOnError(t *testing.T) {
-	// Replace logger with one that panics instead of exiting the process.
-	originalLogger := logger
+	t.Parallel()
 
-	defer func() {
-		logger = originalLogger
-	}()
+	var logged string
 
-	logger = mockLogger{
-		Fn: func(v ...any) { panic(v[0]) },
-	}
+	// Exit with a panic instead of exiting the process.
+	app := newTestApp(t)
+	app.Logger = mockLogger(func(entry string) { logged = entry })
+	app.Exit = func(code int) { panic(code) }
 
 	err := errTest
 
-	require.Panics(t, func() {
-		exitOnError(err)
-	}, "Expected exitOnError to panic on error")
+	require.PanicsWithValue(t, 1, func() {
+		app.exitOnError(err)
+	}, "Expected exitOnError to exit with 1 on error")
+	require.Equal(t, `failed to run error="`+errTest.Error()+`"`, logged, "error should be logged as a field")
 }
 
 func Test_exitOnError_nil(t *testing.T) {
@@ -440,7 +477,7 @@ func Test_exitOnError_nil(t *testing.T) {
 
 	// Should not panic when err is nil
 	require.NotPanics(t, func() {
-		exitOnError(nil)
+		newApp().exitOnError(nil)
 	})
 }
 
@@ -448,17 +485,15 @@ func Test_exitOnError_nil(t *testing.T) {
 //  run
 // ----------------------------------------------------------------------------
 
-//nolint:paralleltest // because of monkey patching
 func Test_run_success(t *testing.T) {
-	orig := runServer
-
-	defer func() { runServer = orig }()
+	t.Parallel()
 
-	runServer = func(_ context.Context, _ *mcp.Server) error {
+	app := newTestApp(t)
+	app.RunServer = func(_ context.Context, _ *mcp.Server) error {
 		return nil // success
 	}
 
-	err := run(context.Background())
+	err := app.serve(context.Background(), nil)
 	require.NoError(t, err)
 }
 
@@ -470,7 +505,7 @@ func Test_run_error(t *testing.T) {
 	cancel()
 
 	require.NotPanics(t, func() {
-		err := run(ctx)
+		err := newApp().serve(ctx, nil)
 
 		require.Error(t, err)
 		require.ErrorIs(t, err, context.Canceled)
@@ -517,7 +552,7 @@ func Test_newLogger_out_file(t *testing.T) {
 	const logMsg = "test log entry"
 
 	// Log something to ensure the file is created
-	logger.Print(logMsg)
+	logger.Info(logMsg, logKeyTool, toolName)
 
 	require.FileExists(t, logFilePath,
 		"log file should be created if 'toFile' is true")
@@ -525,8 +560,9 @@ func Test_newLogger_out_file(t *testing.T) {
 	content, err := os.ReadFile(logFilePath)
 	require.NoError(t, err, "should be able to read the log file")
 
-	require.Contains(t, string(content), logMsg,
-		"log file should contain the logged entry")
+	require.Contains(t, string(content), `level=INFO msg="`+logMsg+`" tool=`+toolName,
+		"log file should contain the logged entry with its fields")
+	require.Regexp(t, `^time=\S+Z `, string(content), "time should be in UTC")
 }
 
 func Test_newLogger_file_open_failure(t *testing.T) {
@@ -541,7 +577,7 @@ func Test_newLogger_file_open_failure(t *testing.T) {
 
 	// Verify the logger works (writes to stderr, not to file)
 	require.NotPanics(t, func() {
-		logger.Print("test message")
+		logger.Info("test message")
 	}, "logger should not panic when writing after file open failure")
 
 	// The file should not exist since the directory doesn't exist
@@ -572,6 +608,18 @@ func Test_handleReverse(t *testing.T) {
 	}
 }
 
+func Test_handleReverse_text_content(t *testing.T) {
+	t.Parallel()
+
+	session := connectInMemory(t, newServer())
+
+	res := callTool(t, session, toolName, map[string]any{"text": "👍🏽 Hello"})
+	require.False(t, res.IsError)
+	require.Equal(t, map[string]any{"text": "olleH 👍🏽"}, res.StructuredContent)
+	require.Equal(t, []mcp.Content{&mcp.TextContent{Text: "olleH 👍🏽"}}, res.Content, //nolint:exhaustruct // text only
+		"content should be the mirrored text as is, not the JSON of the structured content")
+}
+
 func Test_handleReverse_cancelled(t *testing.T) {
 	t.Parallel()
 
@@ -584,54 +632,82 @@ func Test_handleReverse_cancelled(t *testing.T) {
 	require.ErrorIs(t, err, context.Canceled)
 }
 
+func Benchmark_handleReverse(b *testing.B) {
+	for _, input := range []struct {
+		name string
+		text string
+	}{
+		{"ascii", strings.Repeat("Hello, World! ", 64*1024/14)},
+		{"emoji", strings.Repeat("👍🏽👨‍👩‍👧🇯🇵", 64*1024/37)},
+	} {
+		b.Run(input.name, func(b *testing.B) {
+			b.SetBytes(int64(len(input.text)))
+			b.ReportAllocs()
+
+			for b.Loop() {
+				_, _, _ = handleReverse(context.Background(), nil, MirrorInput{Text: input.text})
+			}
+		})
+	}
+}
+
 // ----------------------------------------------------------------------------
 //  debugLog
 // ----------------------------------------------------------------------------
 
 func Test_debugLog(t *testing.T) {
-	originalLogger := logger
-
-	defer func() {
-		logger = originalLogger
-	}()
-
 	var loggedMessages []string // log to trace messages for testing
 
-	logger = mockLogger{
-		Fn: func(v ...any) {
-			loggedMessages = append(loggedMessages, fmt.Sprint(v...))
-		},
-	}
+	ctx := withLogger(context.Background(), mockLogger(func(entry string) {
+		loggedMessages = append(loggedMessages, entry)
+	}))
 
 	t.Run("debug_mode_enabled", func(t *testing.T) {
 		// Enable debug mode
-		t.Setenv(envNameDebug, "debug.log")
+		setEnv(t, envNameDebug, "debug.log")
 
 		loggedMessages = nil // reset
 
-		debugLog("Debug message 1:", 123)
-		debugLog("Debug message 2:", true)
+		debugLog(ctx, "Debug message 1", "count", 123)
+		debugLog(ctx, "Debug message 2", "enabled", true)
 
 		require.Len(t, loggedMessages, 2,
 			"Expected 2 log messages when debug mode is enabled")
-		require.Contains(t, loggedMessages[0], "Debug message 1:123")
-		require.Contains(t, loggedMessages[1], "Debug message 2:true")
+		require.Equal(t, "Debug message 1 count=123", loggedMessages[0])
+		require.Equal(t, "Debug message 2 enabled=true", loggedMessages[1])
 	})
 
 	t.Run("debug_mode_disabled", func(t *testing.T) {
 		// Disable debug mode
-		t.Setenv(envNameDebug, "")
+		setEnv(t, envNameDebug, "")
 
 		loggedMessages = nil // reset
 
-		debugLog("Debug message 1:", 123)
-		debugLog("Debug message 2:", true)
+		debugLog(ctx, "Debug message 1", "count", 123)
+		debugLog(ctx, "Debug message 2", "enabled", true)
 
 		require.Empty(t, loggedMessages,
 			"Expected no log messages when debug mode is disabled")
+
+		evaluated := 0
+
+		debugLog(ctx, "Debug message 3", "text", lazyLogValue(func() slog.Value {
+			evaluated++
+
+			return slog.StringValue("large text")
+		}))
+		require.Zero(t, evaluated, "entries neither logged nor sent to the clients should not be built")
 	})
 }
 
+// lazyLogValue is a slog.LogValuer calling the function.
+type lazyLogValue func() slog.Value
+
+// LogValue returns the value of the function.
+func (v lazyLogValue) LogValue() slog.Value {
+	return v()
+}
+
 // ----------------------------------------------------------------------------
 //  wrapError
 // ----------------------------------------------------------------------------
@@ -735,7 +811,7 @@ func FuzzHandleReverse(f *testing.F) {
 		// Property 3: Involution within uniseg's semantics - reversing twice
 		// should produce the same result as uniseg.ReverseString applied twice.
 		// Note: Due to combining mark handling, reverse(reverse(x)) may not equal x.
-		_, out2, err := handleReverse(ctx, nil, MirrorInput(out))
+		_, out2, err := handleReverse(ctx, nil, MirrorInput{Text: out.Text})
 		require.NoError(t, err, "second handleReverse should not return error for input: %q", input)
 
 		expectedAfterDoubleReverse := uniseg.ReverseString(uniseg.ReverseString(input))
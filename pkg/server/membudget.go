@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Memory budget configuration.
+const (
+	envNameMemoryBudget = envPrefix + "MEMORY_BUDGET" // env var of the max input bytes of the tool calls in progress. 0 disables it (default)
+	envNameMemoryWait   = envPrefix + "MEMORY_WAIT"   // env var of the max duration a call waits for the budget. 0 rejects at once (default)
+)
+
+// Errors of the memory budget. errMemoryBudget is retryable, as the budget
+// frees up once the calls in progress finish, unlike errOverBudget.
+var (
+	errMemoryBudget = errors.New("memory budget exceeded")
+	errOverBudget   = errors.New("input larger than the memory budget")
+)
+
+// GetMemoryBudget returns the max total size in bytes of the arguments of the
+// tool calls in progress, and the max duration a call waits for the budget to
+// free up, from 'MCP_TEXT_MIRROR_MEMORY_BUDGET' and 'MCP_TEXT_MIRROR_MEMORY_WAIT'
+// environment variables.
+//
+// A zero budget disables it, and a zero wait rejects the calls at once.
+func GetMemoryBudget() (int, time.Duration, error) {
+	return loadedSettings().memoryBudget()
+}
+
+// memoryBudget returns GetMemoryBudget of the settings.
+func (v *settingValues) memoryBudget() (int, time.Duration, error) {
+	budget, err := v.envInt(envNameMemoryBudget, 0)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	wait, err := v.envDuration(envNameMemoryWait)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return budget, wait, nil
+}
+
+// memoryBudget bounds the total size of the arguments of the tool calls in
+// progress, which the tools hold in memory several times over while running,
+// so that a burst of large inputs is pushed back instead of getting the process
+// killed for running out of memory.
+type memoryBudget struct {
+	freed chan struct{} // closed and replaced whenever bytes are released
+	limit int
+	used  int
+	wait  time.Duration
+	mu    sync.Mutex
+}
+
+// newMemoryBudget returns a budget of limit bytes, where the calls wait up to
+// wait for the budget to free up.
+func newMemoryBudget(limit int, wait time.Duration) *memoryBudget {
+	budget := new(memoryBudget)
+	budget.freed = make(chan struct{})
+	budget.limit = limit
+	budget.wait = wait
+
+	return budget
+}
+
+// acquire reserves size bytes of the budget, waiting up to the wait of the
+// budget for the calls in progress to release theirs. It fails at once if size
+// exceeds the whole budget.
+func (b *memoryBudget) acquire(ctx context.Context, size int) error {
+	if size > b.limit {
+		return fmt.Errorf("%w: %d bytes given, %d bytes allowed", errOverBudget, size, b.limit)
+	}
+
+	var expired <-chan time.Time
+
+	if b.wait > 0 {
+		timer := time.NewTimer(b.wait)
+		defer timer.Stop()
+
+		expired = timer.C
+	}
+
+	for {
+		b.mu.Lock()
+
+		if b.used+size <= b.limit {
+			b.used += size
+			b.mu.Unlock()
+
+			return nil
+		}
+
+		used, freed := b.used, b.freed
+		b.mu.Unlock()
+
+		if expired == nil {
+			return fmt.Errorf("%w: %d of %d bytes in use, retry later", errMemoryBudget, used, b.limit)
+		}
+
+		select {
+		case <-freed:
+		case <-expired:
+			return fmt.Errorf("%w: %d of %d bytes in use after %s, retry later", errMemoryBudget, used, b.limit, b.wait)
+		case <-ctx.Done():
+			return wrapError(ctx.Err(), "canceled while waiting for the memory budget")
+		}
+	}
+}
+
+// release frees the size bytes reserved by acquire and wakes up the waiting
+// calls.
+func (b *memoryBudget) release(size int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.used -= size
+
+	close(b.freed)
+	b.freed = make(chan struct{})
+}
+
+// middleware runs the tool calls within the budget, counting the size of their
+// arguments in JSON. Rejected calls are reported as tool errors, marked as
+// retryable unless the arguments exceed the whole budget.
+func (b *memoryBudget) middleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		params, ok := req.GetParams().(*mcp.CallToolParamsRaw)
+		if method != methodCallTool || !ok || params == nil {
+			return next(ctx, method, req)
+		}
+
+		size := len(params.Arguments)
+
+		err := b.acquire(ctx, size)
+		if err != nil {
+			warnLog(ctx, "tool call rejected", logKeyRequestID, requestID(ctx), logKeySession, sessionLog(req),
+				logKeyError, err)
+
+			if errors.Is(err, errMemoryBudget) {
+				return retryableErrorResult(err), nil
+			}
+
+			return toolErrorResult(err), nil
+		}
+		defer b.release(size)
+
+		return next(ctx, method, req)
+	}
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  GetMemoryBudget
+// ----------------------------------------------------------------------------
+
+func Test_GetMemoryBudget(t *testing.T) {
+	for index, test := range []struct {
+		name       string
+		budget     string
+		wait       string
+		wantBudget int
+		wantWait   time.Duration
+		wantErr    error
+	}{
+		{"unset", "", "", 0, 0, nil},
+		{"custom", "1048576", "5s", 1048576, 5 * time.Second, nil},
+		{"invalid_budget", "1MiB", "", 0, 0, errInvalidNumber},
+		{"negative_budget", "-1", "", 0, 0, errInvalidNumber},
+		{"invalid_wait", "1024", "soon", 0, 0, errInvalidDuration},
+	} {
+		t.Run(fmt.Sprintf("Test #%d: %s", index+1, test.name), func(t *testing.T) {
+			setEnv(t, envNameMemoryBudget, test.budget)
+			setEnv(t, envNameMemoryWait, test.wait)
+
+			budget, wait, err := GetMemoryBudget()
+			if test.wantErr != nil {
+				require.ErrorIs(t, err, test.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.wantBudget, budget)
+			require.Equal(t, test.wantWait, wait)
+		})
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  memoryBudget
+// ----------------------------------------------------------------------------
+
+func Test_memoryBudget_acquire(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	// Rejected at once without wait
+	budget := newMemoryBudget(10, 0)
+
+	require.NoError(t, budget.acquire(ctx, 6))
+	require.ErrorIs(t, budget.acquire(ctx, 5), errMemoryBudget)
+	require.NoError(t, budget.acquire(ctx, 4), "the calls within the budget left should pass")
+	require.ErrorIs(t, budget.acquire(ctx, 11), errOverBudget)
+
+	budget.release(6)
+	budget.release(4)
+	require.Zero(t, budget.used, "all bytes should be released")
+
+	// Queued until the bytes are released
+	budget = newMemoryBudget(10, time.Minute)
+
+	require.NoError(t, budget.acquire(ctx, 8))
+
+	acquired := make(chan error, 1)
+
+	go func() { acquired <- budget.acquire(ctx, 5) }()
+
+	select {
+	case err := <-acquired:
+		require.Fail(t, "the call should wait for the budget", "got: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	budget.release(8)
+	require.NoError(t, <-acquired, "the waiting call should get the released bytes")
+
+	// Waiting call is canceled with the context
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	require.ErrorIs(t, budget.acquire(canceledCtx, 6), context.Canceled)
+
+	// Waiting call gives up after the wait
+	budget = newMemoryBudget(10, 10*time.Millisecond)
+
+	require.NoError(t, budget.acquire(ctx, 10))
+	require.ErrorIs(t, budget.acquire(ctx, 1), errMemoryBudget)
+}
+
+func Test_memoryBudget_middleware(t *testing.T) {
+	t.Parallel()
+
+	budget := newMemoryBudget(100, 0)
+	server := newServer()
+	server.AddReceivingMiddleware(budget.middleware)
+
+	session := connectInMemory(t, server)
+
+	require.False(t, callTool(t, session, toolName, map[string]any{"text": "abc"}).IsError)
+	require.Zero(t, budget.used, "the bytes should be released after the call")
+
+	// Too large for the whole budget: not worth retrying
+	res := callTool(t, session, toolName, map[string]any{"text": strings.Repeat("a", 100)})
+	require.True(t, res.IsError)
+	require.Contains(t, toolErrorText(res), errOverBudget.Error())
+	require.Nil(t, res.Meta[metaKeyRetryable])
+
+	// Budget used by the calls in progress: retryable
+	require.NoError(t, budget.acquire(context.Background(), 90))
+	defer budget.release(90)
+
+	res = callTool(t, session, toolName, map[string]any{"text": "abc"})
+	require.True(t, res.IsError)
+	require.Contains(t, toolErrorText(res), errMemoryBudget.Error())
+	require.Equal(t, true, res.Meta[metaKeyRetryable])
+
+	// Other methods are not bounded
+	_, err := session.ListTools(context.Background(), nil)
+	require.NoError(t, err)
+}
+
+//nolint:paralleltest // sets env var
+func Test_newServer_memory_budget(t *testing.T) {
+	setEnv(t, envNameMemoryBudget, "16")
+	setEnv(t, envNameMemoryWait, "")
+
+	session := connectInMemory(t, newServer())
+
+	res := callTool(t, session, toolName, map[string]any{"text": "abc"})
+	require.False(t, res.IsError, "the arguments within the budget should pass")
+
+	res = callTool(t, session, toolName, map[string]any{"text": "abcdefghijklmnop"})
+	require.True(t, res.IsError)
+	require.Contains(t, toolErrorText(res), errOverBudget.Error())
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Passthrough of the request _meta.
+const (
+	envNameMetaKeys = envPrefix + "META_KEYS"  // env var of the comma separated request _meta keys to log and echo back
+	metaKeysDefault = "traceparent,tracestate" // W3C trace context
+	progressKey     = "progressToken"          // _meta key reserved for the progress notifications
+)
+
+// GetMetaKeys returns the keys of the request _meta to propagate, from
+// 'MCP_TEXT_MIRROR_META_KEYS' environment variable. It defaults to the W3C trace
+// context keys ("traceparent" and "tracestate").
+//
+// The values of these keys are logged with the tool call and echoed back in the
+// _meta of the tool result, so that agent frameworks can correlate their traces
+// with this server end-to-end.
+func GetMetaKeys() []string {
+	value := settingValue(envNameMetaKeys)
+	if value == "" {
+		value = metaKeysDefault
+	}
+
+	return slices.DeleteFunc(splitList(value), func(key string) bool { return key == progressKey })
+}
+
+// selectMeta returns the entries of the _meta of the request params to
+// propagate, or nil if none.
+func selectMeta(params mcp.Params) mcp.Meta {
+	if params == nil {
+		return nil
+	}
+
+	meta := params.GetMeta()
+	if len(meta) == 0 {
+		return nil
+	}
+
+	var selected mcp.Meta
+
+	for _, key := range GetMetaKeys() {
+		value, ok := meta[key]
+		if !ok {
+			continue
+		}
+
+		if selected == nil {
+			selected = make(mcp.Meta)
+		}
+
+		selected[key] = value
+	}
+
+	return selected
+}
+
+// metaLogAttrs returns the entries of the _meta of the request params to
+// propagate as the key-value pairs of the debug logs, in the configured key
+// order.
+func metaLogAttrs(params mcp.Params) []any {
+	selected := selectMeta(params)
+	if selected == nil {
+		return nil
+	}
+
+	var attrs []any
+
+	for _, key := range GetMetaKeys() {
+		if value, ok := selected[key]; ok {
+			attrs = append(attrs, key, value)
+		}
+	}
+
+	return attrs
+}
+
+// metaEchoMiddleware is a middleware which echoes the _meta entries of the tool
+// call requests back in the _meta of the tool results. Entries already set by
+// the tool are kept as is.
+func metaEchoMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		res, err := next(ctx, method, req)
+		if method != methodCallTool || err != nil {
+			return res, err
+		}
+
+		result, ok := res.(*mcp.CallToolResult)
+		if !ok || result == nil {
+			return res, nil
+		}
+
+		for key, value := range selectMeta(req.GetParams()) {
+			if result.Meta == nil {
+				result.Meta = make(mcp.Meta)
+			}
+
+			if _, exists := result.Meta[key]; !exists {
+				result.Meta[key] = value
+			}
+		}
+
+		return res, nil
+	}
+}
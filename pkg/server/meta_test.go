@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+const testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+// callToolWithMeta calls the tool with the arguments and the request _meta, and
+// returns the result.
+func callToolWithMeta(t *testing.T, session *mcp.ClientSession, name string, args any, meta mcp.Meta) *mcp.CallToolResult {
+	t.Helper()
+
+	params := new(mcp.CallToolParams)
+	params.Name = name
+	params.Arguments = args
+	params.Meta = meta
+
+	res, err := session.CallTool(context.Background(), params)
+	require.NoError(t, err)
+
+	return res
+}
+
+// ----------------------------------------------------------------------------
+//  GetMetaKeys
+// ----------------------------------------------------------------------------
+
+func Test_GetMetaKeys(t *testing.T) {
+	setEnv(t, envNameMetaKeys, "")
+	require.Equal(t, []string{"traceparent", "tracestate"}, GetMetaKeys())
+
+	setEnv(t, envNameMetaKeys, " x-request-id , progressToken, io.example/run")
+	require.Equal(t, []string{"x-request-id", "io.example/run"}, GetMetaKeys(),
+		"progressToken should never be propagated")
+}
+
+// ----------------------------------------------------------------------------
+//  metaLogAttrs
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_metaLogAttrs(t *testing.T) {
+	setEnv(t, envNameMetaKeys, "b,a")
+
+	for index, test := range []struct {
+		name   string
+		params mcp.Params
+		want   []any
+	}{
+		{"nil", nil, nil},
+		{"no_meta", new(mcp.CallToolParams), nil},
+		{"not_selected", &mcp.CallToolParams{Meta: mcp.Meta{"c": 1}}, nil}, //nolint:exhaustruct // meta only
+		{"key_order", &mcp.CallToolParams{Meta: mcp.Meta{"a": 1, "b": "x", "c": 3}}, []any{"b", "x", "a", 1}}, //nolint:exhaustruct // meta only
+	} {
+		require.Equal(t, test.want, metaLogAttrs(test.params), fmt.Sprintf("Test #%d: %s", index+1, test.name))
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  metaEchoMiddleware
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_metaEchoMiddleware(t *testing.T) {
+	setEnv(t, envNameMetaKeys, "")
+	setEnv(t, envNameVerify, "")
+
+	session := connectInMemory(t, newServer())
+
+	meta := mcp.Meta{"traceparent": testTraceparent, "other": "dropped"}
+
+	// Echoed on success, tool errors and other tools
+	for _, call := range []struct {
+		name string
+		args map[string]any
+	}{
+		{toolName, map[string]any{"text": "abc"}},
+		{toolName, map[string]any{"text": "abc", "path": "a.txt"}}, // tool error
+		{batchToolName, map[string]any{"texts": []string{"abc"}}},
+	} {
+		res := callToolWithMeta(t, session, call.name, call.args, meta)
+		delete(res.Meta, requestIDMetaKey)
+		require.Equal(t, mcp.Meta{"traceparent": testTraceparent}, res.Meta, call)
+	}
+
+	res := callTool(t, session, toolName, map[string]any{"text": "abc"})
+	require.Equal(t, []string{requestIDMetaKey}, slices.Collect(maps.Keys(res.Meta)),
+		"nothing should be echoed without request _meta")
+}
+
+func Test_metaEchoMiddleware_keeps_tool_meta(t *testing.T) {
+	t.Parallel()
+
+	handler := metaEchoMiddleware(func(context.Context, string, mcp.Request) (mcp.Result, error) {
+		res := new(mcp.CallToolResult)
+		res.Meta = mcp.Meta{"traceparent": "from-tool"}
+
+		return res, nil
+	})
+
+	req := new(mcp.CallToolRequest)
+	req.Params = new(mcp.CallToolParamsRaw)
+	req.Params.Meta = mcp.Meta{"traceparent": testTraceparent}
+
+	res, err := handler(context.Background(), methodCallTool, req)
+	require.NoError(t, err)
+	require.Equal(t, mcp.Meta{"traceparent": "from-tool"}, res.(*mcp.CallToolResult).Meta) //nolint:forcetypeassert // tool result
+}
+
+// ----------------------------------------------------------------------------
+//  _meta logging
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_callLogAttrs_meta(t *testing.T) {
+	setEnv(t, envNameDebug, "test.log")
+	setEnv(t, envNameMetaKeys, "")
+
+	var (
+		mu     sync.Mutex
+		logged []string
+	)
+
+	logger := mockLogger(func(entry string) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		logged = append(logged, entry)
+	})
+
+	session := connectInMemory(t, newServer(WithLogger(logger)))
+	callToolWithMeta(t, session, toolName, map[string]any{"text": "abc"}, mcp.Meta{"traceparent": testTraceparent})
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	require.Regexp(t, `text mirrored request_id=\w{26} tool=mirror app="test-client v0.0.0" meta.traceparent=`+testTraceparent+" input_size=3",
+		strings.Join(logged, "\n"))
+}
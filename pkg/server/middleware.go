@@ -0,0 +1,79 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// MCP method names handled by the middlewares.
+const (
+	methodCallTool   = "tools/call"
+	methodInitialize = "initialize"
+)
+
+// metaKeyRetryable is the _meta key of the tool errors worth retrying later,
+// such as the ones of the limits.
+const metaKeyRetryable = "text-mirror/retryable"
+
+// toolErrorResult returns a tool result reporting err to the client as a tool
+// execution error (isError: true) per MCP spec, so that the LLM can see it and
+// react to it.
+func toolErrorResult(err error) *mcp.CallToolResult {
+	content := new(mcp.TextContent)
+	content.Text = err.Error()
+
+	res := new(mcp.CallToolResult)
+	res.Content = []mcp.Content{content}
+	res.IsError = true
+
+	return res
+}
+
+// retryableErrorResult returns the toolErrorResult of err with
+// `"text-mirror/retryable": true` in its _meta, so that the clients can tell the
+// transient errors, such as the server being busy, from the ones of the input.
+func retryableErrorResult(err error) *mcp.CallToolResult {
+	res := toolErrorResult(err)
+	res.Meta = mcp.Meta{metaKeyRetryable: true}
+
+	return res
+}
+
+// toolErrorText returns the error message of the tool execution error result,
+// i.e. its text contents.
+func toolErrorText(res *mcp.CallToolResult) string {
+	texts := make([]string, 0, len(res.Content))
+
+	for _, content := range res.Content {
+		if text, ok := content.(*mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+
+	return strings.Join(texts, "\n")
+}
+
+// clientKey returns the key to identify the client of the request. It is the
+// verified client identity on network transports with mTLS, otherwise the
+// session.
+func clientKey(req mcp.Request) string {
+	if extra := req.GetExtra(); extra != nil && extra.Header != nil {
+		if id := extra.Header.Get(headerClientID); id != "" && id != anonymousClient {
+			return "client:" + id
+		}
+	}
+
+	session, ok := req.GetSession().(*mcp.ServerSession)
+	if !ok || session == nil {
+		return ""
+	}
+
+	// In-memory and stdio sessions have no ID. Use the session itself.
+	if id := session.ID(); id != "" {
+		return "session:" + id
+	}
+
+	return fmt.Sprintf("session:%p", session)
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  toolErrorResult
+// ----------------------------------------------------------------------------
+
+func Test_toolErrorResult(t *testing.T) {
+	t.Parallel()
+
+	res := toolErrorResult(errTest)
+
+	require.True(t, res.IsError)
+	require.Len(t, res.Content, 1)
+	require.Equal(t, errTest.Error(), res.Content[0].(*mcp.TextContent).Text) //nolint:forcetypeassert // text content
+}
+
+// ----------------------------------------------------------------------------
+//  clientKey
+// ----------------------------------------------------------------------------
+
+func Test_clientKey(t *testing.T) {
+	t.Parallel()
+
+	require.Empty(t, clientKey(new(mcp.CallToolRequest)), "request without session should have no key")
+
+	req := new(mcp.CallToolRequest)
+	req.Extra = &mcp.RequestExtra{Header: http.Header{}} //nolint:exhaustruct // header only
+	req.Extra.Header.Set(headerClientID, anonymousClient)
+
+	require.Empty(t, clientKey(req), "anonymous clients should be identified by the session")
+
+	req.Extra.Header.Set(headerClientID, "agent-1")
+
+	require.Equal(t, "client:agent-1", clientKey(req))
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalize tool.
+const (
+	normalizeToolName        = "normalize"
+	normalizeToolDescription = "Normalizes the UTF-8 text to a Unicode normalization form: NFC (default), NFD, " +
+		"NFKC or NFKD, and tells whether the text was already normalized. e.g. to compare or search texts " +
+		"whose accented letters were composed differently"
+)
+
+// Values of the form argument of the normalize tool.
+const (
+	formNFC  = "NFC"  // canonical composition (default)
+	formNFD  = "NFD"  // canonical decomposition
+	formNFKC = "NFKC" // compatibility composition
+	formNFKD = "NFKD" // compatibility decomposition
+)
+
+// normForms are the normalization forms by the values of the form argument.
+var normForms = map[string]norm.Form{
+	formNFC:  norm.NFC,
+	formNFD:  norm.NFD,
+	formNFKC: norm.NFKC,
+	formNFKD: norm.NFKD,
+}
+
+// errInvalidForm is returned for an unknown form argument, which the schema
+// normally rejects beforehand.
+var errInvalidForm = errors.New("form must be NFC, NFD, NFKC or NFKD")
+
+// NormalizeInput is the input for the normalize tool.
+type NormalizeInput struct {
+	Text string `json:"text"           jsonschema:"The UTF-8 text to normalize."`
+	Form string `json:"form,omitempty" jsonschema:"The Unicode normalization form. NFC (default) and NFD compose and decompose the accented letters, NFKC and NFKD also replace the compatibility characters such as ligatures and full-width letters with their plain equivalents."`
+}
+
+// NormalizeOutput is the output from the normalize tool.
+type NormalizeOutput struct {
+	Text              string `json:"text"               jsonschema:"The text in the normalization form."`
+	Form              string `json:"form"               jsonschema:"The normalization form applied."`
+	AlreadyNormalized bool   `json:"already_normalized" jsonschema:"True if the input was already in the normalization form, i.e. the text is returned unchanged."`
+}
+
+// normalizeTool is the normalize tool, exposing the Unicode normalization
+// forms on their own with a report of the input.
+type normalizeTool struct{}
+
+// Name returns the name of the tool.
+func (normalizeTool) Name() string { return normalizeToolName }
+
+// Description returns the description of the tool.
+func (normalizeTool) Description() string { return normalizeToolDescription }
+
+// Schema returns the schemas of the input and the output of the tool.
+func (normalizeTool) Schema() (*jsonschema.Schema, *jsonschema.Schema) {
+	return normalizeInputSchema(), nil
+}
+
+// Annotations returns the hints of the tool.
+func (normalizeTool) Annotations() *mcp.ToolAnnotations { return readOnlyAnnotations() }
+
+// Handler returns handleNormalize.
+func (normalizeTool) Handler(*serverState) ToolHandler { return TypedHandler(handleNormalize) }
+
+// cacheable returns true, as the result depends on the text and the form only.
+func (normalizeTool) cacheable(map[string]any) bool { return true }
+
+// normalizeInputSchema returns the JSON schema of NormalizeInput.
+func normalizeInputSchema() *jsonschema.Schema {
+	schema := mustInferSchema[NormalizeInput]()
+	schema.Title = "Normalize input"
+	schema.Required = []string{"text"}
+
+	text := schema.Properties["text"]
+	text.Title = "Text"
+	text.MaxLength = jsonschema.Ptr(textMaxLength)
+	text.Examples = []any{"Cafe\u0301 \ufb01le"}
+
+	form := schema.Properties["form"]
+	form.Title = "Form"
+	form.Enum = []any{formNFC, formNFD, formNFKC, formNFKD}
+
+	return schema
+}
+
+// handleNormalize returns the text in the normalization form and whether it
+// already was.
+func handleNormalize(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input NormalizeInput,
+) (*mcp.CallToolResult, NormalizeOutput, error) {
+	err := ctx.Err()
+	if err != nil {
+		return nil, NormalizeOutput{}, wrapError(err, "request canceled")
+	}
+
+	err = checkTextLimit(req, input.Text)
+	if err != nil {
+		return nil, NormalizeOutput{}, err
+	}
+
+	name := input.Form
+	if name == "" {
+		name = formNFC
+	}
+
+	form, ok := normForms[name]
+	if !ok {
+		return nil, NormalizeOutput{}, fmt.Errorf("%w: %q given", errInvalidForm, input.Form)
+	}
+
+	start := time.Now()
+
+	output := NormalizeOutput{Text: input.Text, Form: name, AlreadyNormalized: form.IsNormalString(input.Text)}
+	if !output.AlreadyNormalized {
+		output.Text = form.String(input.Text)
+	}
+
+	callLog(ctx, "text normalized", callLogAttrs(ctx, req, logKeyInputSize, len(input.Text), logKeyDuration, time.Since(start),
+		logKeyText, userText(input.Text), logKeyMirrored, userText(output.Text))...)
+
+	return nil, output, nil
+}
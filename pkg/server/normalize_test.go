@@ -0,0 +1,60 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  handleNormalize
+// ----------------------------------------------------------------------------
+
+func Test_handleNormalize(t *testing.T) {
+	t.Parallel()
+
+	session := connectInMemory(t, newServer())
+
+	for index, test := range []struct {
+		name        string
+		args        map[string]any
+		wantText    string
+		wantForm    string
+		wantAlready bool
+	}{
+		{"default_nfc", map[string]any{"text": "Cafe\u0301"}, "Caf\u00e9", formNFC, false},
+		{"already_nfc", map[string]any{"text": "Caf\u00e9"}, "Caf\u00e9", formNFC, true},
+		{"nfd", map[string]any{"text": "Caf\u00e9", "form": formNFD}, "Cafe\u0301", formNFD, false},
+		{"already_nfd", map[string]any{"text": "Cafe\u0301", "form": formNFD}, "Cafe\u0301", formNFD, true},
+		{"nfkc_ligature", map[string]any{"text": "\ufb01le", "form": formNFKC}, "file", formNFKC, false},
+		{"nfc_keeps_ligature", map[string]any{"text": "\ufb01le", "form": formNFC}, "\ufb01le", formNFC, true},
+		{"nfkd_full_width", map[string]any{"text": "\uff21\u00e9", "form": formNFKD}, "Ae\u0301", formNFKD, false},
+		{"hangul", map[string]any{"text": "\u1100\u1161", "form": formNFC}, "\uac00", formNFC, false},
+		{"ascii", map[string]any{"text": "abc", "form": formNFKD}, "abc", formNFKD, true},
+		{"empty", map[string]any{"text": ""}, "", formNFC, true},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		res := callTool(t, session, normalizeToolName, test.args)
+		require.False(t, res.IsError, name)
+		require.Equal(t, map[string]any{
+			"text": test.wantText, "form": test.wantForm, "already_normalized": test.wantAlready,
+		}, res.StructuredContent, name)
+	}
+}
+
+func Test_handleNormalize_invalid_form(t *testing.T) {
+	t.Parallel()
+
+	session := connectInMemory(t, newServer())
+
+	params := new(mcp.CallToolParams)
+	params.Name = normalizeToolName
+	params.Arguments = map[string]any{"text": "abc", "form": "nfc"}
+
+	_, err := session.CallTool(context.Background(), params)
+	require.ErrorContains(t, err, "form", "unknown form should be rejected by the schema")
+}
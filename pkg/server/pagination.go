@@ -0,0 +1,32 @@
+package server
+
+import "github.com/modelcontextprotocol/go-sdk/mcp"
+
+// Pagination configuration.
+const envNamePageSize = envPrefix + "PAGE_SIZE" // env var of the max items per page of the list methods such as tools/list
+
+// GetPageSize returns the max number of items returned per page by the list
+// methods (tools/list, resources/list, etc.) from 'MCP_TEXT_MIRROR_PAGE_SIZE'
+// environment variable. It defaults to mcp.DefaultPageSize.
+//
+// The next page is given by an opaque cursor holding the name of the last item
+// returned. Since the items are listed in name order, the cursors stay valid
+// and no item listed before is repeated or skipped even if tools are added or
+// removed between the pages.
+func GetPageSize() (int, error) {
+	return loadedSettings().pageSize()
+}
+
+// pageSize returns GetPageSize of the settings.
+func (v *settingValues) pageSize() (int, error) {
+	size, err := v.envInt(envNamePageSize, mcp.DefaultPageSize)
+	if err != nil {
+		return 0, err
+	}
+
+	if size == 0 {
+		return mcp.DefaultPageSize, nil
+	}
+
+	return size, nil
+}
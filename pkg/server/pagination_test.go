@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  GetPageSize
+// ----------------------------------------------------------------------------
+
+func Test_GetPageSize(t *testing.T) {
+	setEnv(t, envNamePageSize, "")
+
+	size, err := GetPageSize()
+	require.NoError(t, err)
+	require.Equal(t, mcp.DefaultPageSize, size)
+
+	setEnv(t, envNamePageSize, "0")
+
+	size, err = GetPageSize()
+	require.NoError(t, err)
+	require.Equal(t, mcp.DefaultPageSize, size, "zero should fall back to the default")
+
+	setEnv(t, envNamePageSize, "2")
+
+	size, err = GetPageSize()
+	require.NoError(t, err)
+	require.Equal(t, 2, size)
+
+	setEnv(t, envNamePageSize, "-1")
+
+	_, err = GetPageSize()
+	require.ErrorIs(t, err, errInvalidNumber)
+}
+
+//nolint:paralleltest // sets env var
+func Test_run_invalid_page_size(t *testing.T) {
+	setEnv(t, envNamePageSize, "ten")
+
+	err := newApp().serve(context.Background(), nil)
+	require.ErrorIs(t, err, errInvalidNumber)
+}
+
+// ----------------------------------------------------------------------------
+//  tools/list pagination
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_newServer_tools_pagination(t *testing.T) {
+	setEnv(t, envNamePageSize, "1")
+
+	server := newServer()
+	addTool := func(name string) {
+		tool := new(mcp.Tool)
+		tool.Name = name
+		tool.InputSchema = map[string]any{"type": "object"}
+
+		server.AddTool(tool, func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return new(mcp.CallToolResult), nil
+		})
+	}
+
+	addTool("a_tool")
+	addTool("z_tool")
+
+	session := connectInMemory(t, server)
+	ctx := context.Background()
+
+	params := new(mcp.ListToolsParams)
+
+	res, err := session.ListTools(ctx, params)
+	require.NoError(t, err)
+	require.Len(t, res.Tools, 1)
+	require.Equal(t, "a_tool", res.Tools[0].Name)
+	require.NotEmpty(t, res.NextCursor)
+
+	// Registry changes between the pages must not break the cursor
+	addTool("b_tool")
+	server.RemoveTools("a_tool")
+
+	var names []string
+
+	for params.Cursor = res.NextCursor; params.Cursor != ""; params.Cursor = res.NextCursor {
+		res, err = session.ListTools(ctx, params)
+		require.NoError(t, err)
+
+		for _, tool := range res.Tools {
+			names = append(names, tool.Name)
+		}
+	}
+
+	require.Equal(t, slices.Concat([]string{"b_tool"}, sortedNames(defaultTools()...), []string{"z_tool"}), names)
+
+	// Invalid cursor
+	params.Cursor = "invalid"
+
+	_, err = session.ListTools(ctx, params)
+	require.Error(t, err)
+}
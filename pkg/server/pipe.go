@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+
+	"github.com/KEINOS/mcp-text-mirror/pkg/mirror"
+)
+
+// Pipe mode. E.g.: echo "Hello, 世界" | text-mirror mirror
+const (
+	cmdNameMirror = "mirror"
+
+	flagNameExpr = "expr" // transform expression applied instead of mirroring
+)
+
+// errMirrorUsage is returned if the flags of the mirror subcommand are invalid.
+var errMirrorUsage = errors.New("usage: text-mirror mirror [--expr expression] [text...]")
+
+// runMirror is the "mirror" subcommand, which mirrors the text without MCP: the
+// arguments joined by spaces if any, otherwise the text read from in. The mirrored
+// text is written to out. With --expr, the operations of the transform
+// expression are applied instead, as the pipeline tool does.
+//
+// The line break at the end of the text, if any, is kept at the end rather than
+// moved to the beginning, so that it behaves as expected in shell pipelines.
+func runMirror(ctx context.Context, args []string, in io.Reader, out io.Writer) error {
+	var expr string
+
+	flags := flag.NewFlagSet(cmdNameMirror, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.StringVar(&expr, flagNameExpr, "", "transform expression")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errMirrorUsage, err)
+	}
+
+	args = flags.Args()
+	text := []byte(strings.Join(args, " ") + "\n")
+
+	if len(args) == 0 {
+		text, err = io.ReadAll(in)
+		if err != nil {
+			return wrapError(err, "failed to read the standard input")
+		}
+	}
+
+	var result []byte
+
+	if expr == "" {
+		result, err = appendMirrored(ctx, make([]byte, 0, len(text)), text)
+	} else {
+		result, err = appendTransformed(ctx, text, expr)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	_, err = out.Write(result)
+
+	return wrapError(err, "failed to write the standard output")
+}
+
+// appendTransformed returns the text transformed by the operations of the
+// expression but the line break at its end, if any, which stays at the end.
+// The steps without locale use the server-wide one.
+func appendTransformed(ctx context.Context, text []byte, expr string) ([]byte, error) {
+	plan, err := parseTransformExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	locale, err := GetLocale()
+	if err != nil {
+		return nil, err
+	}
+
+	body, lineBreak := cutLineBreak(string(text))
+
+	transformed, err := applyPlan(ctx, body, plan, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(transformed), lineBreak...), nil
+}
+
+// appendMirrored appends the text mirrored but the line break at its end, if
+// any, to dst. No other buffer is allocated if dst has the capacity.
+func appendMirrored(ctx context.Context, dst, text []byte) ([]byte, error) {
+	body, lineBreak := cutLineBreak(text)
+
+	mirrored, err := mirror.AppendReverse(ctx, dst, body, mirror.WithParallelism(runtime.GOMAXPROCS(0)))
+	if err != nil {
+		return nil, err
+	}
+
+	return append(mirrored, lineBreak...), nil
+}
+
+// cutLineBreak returns the text without the line break at the end, and the
+// line break, either "\r\n", "\n" or empty.
+func cutLineBreak[T ~string | ~[]byte](text T) (T, string) {
+	for _, lineBreak := range []string{"\r\n", "\n"} {
+		if cut := len(text) - len(lineBreak); cut >= 0 && string(text[cut:]) == lineBreak {
+			return text[:cut], lineBreak
+		}
+	}
+
+	return text, ""
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/KEINOS/mcp-text-mirror/pkg/mirror"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  runMirror
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // runs the command, which loads the settings
+func Test_runCommand_mirror(t *testing.T) {
+	for index, test := range []struct {
+		name  string
+		args  []string
+		input string
+		want  string
+	}{
+		{"stdin", nil, "Hello, 世界\n", "界世 ,olleH\n"},
+		{"stdin_crlf", nil, "abc\r\n", "cba\r\n"},
+		{"stdin_no_line_break", nil, "abc", "cba"},
+		{"stdin_multiple_lines", nil, "ab\ncd\n", "dc\nba\n"},
+		{"stdin_empty", nil, "", ""},
+		{"graphemes", nil, "👨‍👩‍👧 🇯🇵 é\n", "é 🇯🇵 👨‍👩‍👧\n"},
+		{"args", []string{"Hello,", "世界"}, "ignored", "界世 ,olleH\n"},
+		{"expr", []string{"--expr", "nfc | strip_accents | mirror | upper"}, "Crème Brûlée\r\n", "EELURB EMERC\r\n"},
+		{"expr_args", []string{"--expr=upper(locale=tr)", "istanbul"}, "ignored", "İSTANBUL\n"},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		var out bytes.Buffer
+
+		app := newTestApp(t)
+		app.Stdin = strings.NewReader(test.input)
+		app.Stdout = &out
+
+		err := app.Run(context.Background(), append([]string{cmdNameMirror}, test.args...))
+		require.NoError(t, err, name)
+		require.Equal(t, test.want, out.String(), name)
+	}
+}
+
+func Test_runMirror_canceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := runMirror(ctx, []string{strings.Repeat("a", mirror.CheckInterval)}, strings.NewReader(""), io.Discard)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func Test_runMirror_invalid(t *testing.T) {
+	t.Parallel()
+
+	err := runMirror(context.Background(), []string{"--unknown"}, strings.NewReader(""), io.Discard)
+	require.ErrorIs(t, err, errMirrorUsage)
+
+	err = runMirror(context.Background(), []string{"--expr", "nfc | reverse"}, strings.NewReader("abc"), io.Discard)
+	require.ErrorIs(t, err, errUnknownStep)
+}
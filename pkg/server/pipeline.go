@@ -0,0 +1,172 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Pipeline tool.
+const (
+	pipelineToolName        = "pipeline"
+	pipelineToolDescription = "Applies the given operations to the UTF-8 text in order, in one call, " +
+		"such as normalize, strip_accents, mirror then upper"
+	pipelineMaxSteps = 32 // max operations per call
+)
+
+// Predefined errors of the pipeline tool.
+var (
+	errUnknownStep = errors.New("unknown operation")
+	errNoSteps     = errors.New("either steps or expr is required")
+)
+
+// PipelineInput is the input for the pipeline tool.
+type PipelineInput struct {
+	Text     string   `json:"text"               jsonschema:"The UTF-8 text to transform."`
+	Steps    []string `json:"steps,omitempty"    jsonschema:"The operations to apply to the text, in order."`
+	Expr     string   `json:"expr,omitempty"     jsonschema:"The operations as an expression instead of steps, separated by |. e.g. nfc | mirror | upper(locale=tr)"`
+	Locale   string   `json:"locale,omitempty"   jsonschema:"BCP 47 locale of the case mapping, such as tr. Defaults to the one of the server."`
+	Validate bool     `json:"validate,omitempty" jsonschema:"Set to true to only return the parsed plan, without transforming the text."`
+}
+
+// PipelineOutput is the output from the pipeline tool.
+type PipelineOutput struct {
+	Text string          `json:"text"           jsonschema:"The text once transformed by all the operations. Empty with validate."`
+	Plan []TransformStep `json:"plan,omitempty" jsonschema:"The operations as parsed, with validate."`
+}
+
+// pipelineTool is the pipeline tool, chaining the text transforms.
+type pipelineTool struct{}
+
+// Name returns the name of the tool.
+func (pipelineTool) Name() string { return pipelineToolName }
+
+// Description returns the description of the tool.
+func (pipelineTool) Description() string { return pipelineToolDescription }
+
+// Schema returns the schemas of the input and the output of the tool.
+func (pipelineTool) Schema() (*jsonschema.Schema, *jsonschema.Schema) {
+	return pipelineInputSchema(), nil
+}
+
+// Annotations returns the hints of the tool.
+func (pipelineTool) Annotations() *mcp.ToolAnnotations { return readOnlyAnnotations() }
+
+// Handler returns handlePipeline.
+func (pipelineTool) Handler(*serverState) ToolHandler { return TypedHandler(handlePipeline) }
+
+// cacheable returns true, as the result depends on the text, the operations
+// and the locale given only.
+func (pipelineTool) cacheable(map[string]any) bool { return true }
+
+// pipelineInputSchema returns the JSON schema of PipelineInput, with the
+// operations as the enum of the steps.
+func pipelineInputSchema() *jsonschema.Schema {
+	schema := mustInferSchema[PipelineInput]()
+	schema.Title = "Pipeline input"
+	schema.Required = []string{"text"}
+
+	text := schema.Properties["text"]
+	text.Title = "Text"
+	text.MaxLength = jsonschema.Ptr(textMaxLength)
+	text.Examples = []any{"Crème Brûlée"}
+
+	descriptions := make([]string, len(textTransforms))
+	for index, op := range textTransforms {
+		descriptions[index] = op.name + ": " + op.description
+	}
+
+	steps := schema.Properties["steps"]
+	steps.Title = "Operations"
+	steps.Description += " " + strings.Join(descriptions, "; ") + "."
+	steps.Types = nil // never null
+	steps.Type = "array"
+	steps.MinItems = jsonschema.Ptr(1)
+	steps.MaxItems = jsonschema.Ptr(pipelineMaxSteps)
+	steps.Items.Enum = transformNames()
+	steps.Examples = []any{[]any{"normalize", "strip_accents", "mirror", "upper"}}
+
+	expr := schema.Properties["expr"]
+	expr.Title = "Expression"
+	expr.MaxLength = jsonschema.Ptr(exprMaxLength)
+	expr.Examples = []any{"nfc | strip_accents | mirror | upper(locale=tr)"}
+
+	schema.Properties["validate"].Title = "Validate only"
+
+	locale := schema.Properties["locale"]
+	locale.Title = "Locale"
+	locale.Examples = []any{"tr"}
+
+	return schema
+}
+
+// handlePipeline applies the steps, or the operations of the expression, to
+// the text in order and returns the last result, which saves a round trip per
+// operation for composite transforms. With validate, it returns the plan
+// instead, to check an expression.
+//
+// The steps are checked before any is applied, and it stops once the request
+// is canceled.
+func handlePipeline(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input PipelineInput,
+) (*mcp.CallToolResult, PipelineOutput, error) {
+	err := ctx.Err()
+	if err != nil {
+		return nil, PipelineOutput{}, wrapError(err, "request canceled")
+	}
+
+	plan, err := pipelinePlan(input)
+	if err != nil {
+		return nil, PipelineOutput{}, err
+	}
+
+	if input.Validate {
+		return nil, PipelineOutput{Text: "", Plan: plan}, nil
+	}
+
+	err = checkTextLimit(req, input.Text)
+	if err != nil {
+		return nil, PipelineOutput{}, err
+	}
+
+	locale, err := resolveLocale(input.Locale)
+	if err != nil {
+		return nil, PipelineOutput{}, err
+	}
+
+	start := time.Now()
+
+	text, err := applyPlan(ctx, input.Text, plan, locale)
+	if err != nil {
+		return nil, PipelineOutput{}, err
+	}
+
+	ops := make([]string, len(plan))
+	for index, step := range plan {
+		ops[index] = step.Op
+	}
+
+	callLog(ctx, "text transformed", callLogAttrs(ctx, req, "steps", strings.Join(ops, ","),
+		logKeyInputSize, len(input.Text), logKeyDuration, time.Since(start))...)
+
+	return nil, PipelineOutput{Text: text, Plan: nil}, nil
+}
+
+// pipelinePlan returns the plan of the steps or of the expression of the
+// input, whichever is given.
+func pipelinePlan(input PipelineInput) ([]TransformStep, error) {
+	switch {
+	case (len(input.Steps) == 0) == (input.Expr == ""):
+		return nil, errNoSteps
+	case input.Expr != "":
+		return parseTransformExpr(input.Expr)
+	default:
+		return planOfSteps(input.Steps)
+	}
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  handlePipeline
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_handlePipeline(t *testing.T) {
+	unsetEnv(t, envNameLocale)
+
+	session := connectInMemory(t, newServer())
+
+	for index, test := range []struct {
+		name string
+		args map[string]any
+		want string
+	}{
+		{
+			"composite",
+			map[string]any{"text": "Crème Brûlée", "steps": []any{"normalize", "strip_accents", "mirror", "upper"}},
+			"EELURB EMERC",
+		},
+		{"in_order", map[string]any{"text": " ab ", "steps": []any{"mirror", "trim", "upper"}}, "BA"},
+		{"repeated", map[string]any{"text": "a👍🏽é", "steps": []any{"mirror", "mirror"}}, "a👍🏽é"},
+		{"locale", map[string]any{"text": "istanbul", "steps": []any{"upper"}, "locale": "tr"}, "İSTANBUL"},
+		{"empty_text", map[string]any{"text": "", "steps": []any{"mirror", "upper"}}, ""},
+		{"expr", map[string]any{"text": "Crème Brûlée", "expr": "nfc | strip_accents | mirror | upper"}, "EELURB EMERC"},
+		{"expr_locale", map[string]any{"text": "istanbul", "expr": "upper(locale=tr)", "locale": "en"}, "İSTANBUL"},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		res := callTool(t, session, pipelineToolName, test.args)
+		require.False(t, res.IsError, name)
+		require.Equal(t, map[string]any{"text": test.want}, res.StructuredContent, name)
+	}
+}
+
+//nolint:paralleltest // sets env var
+func Test_handlePipeline_server_locale(t *testing.T) {
+	setEnv(t, envNameLocale, "tr")
+
+	session := connectInMemory(t, newServer())
+
+	res := callTool(t, session, pipelineToolName, map[string]any{"text": "Istanbul", "steps": []any{"lower"}})
+	require.False(t, res.IsError)
+	require.Equal(t, map[string]any{"text": "ıstanbul"}, res.StructuredContent)
+
+	res = callTool(t, session, pipelineToolName, map[string]any{"text": "Istanbul", "steps": []any{"lower"}, "locale": "en"})
+	require.False(t, res.IsError)
+	require.Equal(t, map[string]any{"text": "istanbul"}, res.StructuredContent,
+		"the locale argument should override the one of the server")
+}
+
+func Test_handlePipeline_invalid(t *testing.T) {
+	t.Parallel()
+
+	session := connectInMemory(t, newServer())
+
+	for _, args := range []map[string]any{
+		{"steps": []any{"mirror"}},                 // missing text
+		{"text": "abc", "steps": nil},              // null
+		{"text": "abc", "steps": []any{}},          // empty
+		{"text": "abc", "steps": []any{"reverse"}}, // unknown operation
+		{"text": "abc", "steps": "mirror"},         // not an array
+		{"text": "abc", "steps": make([]any, pipelineMaxSteps+1)},
+	} {
+		params := new(mcp.CallToolParams)
+		params.Name = pipelineToolName
+		params.Arguments = args
+
+		_, err := session.CallTool(context.Background(), params)
+		require.Error(t, err, "arguments %v should be rejected", args)
+	}
+
+	for index, test := range []struct {
+		name    string
+		args    map[string]any
+		wantMsg string
+	}{
+		{"malformed_locale", map[string]any{"text": "abc", "steps": []any{"upper"}, "locale": "!!"}, `invalid locale "!!"`},
+		{"no_steps", map[string]any{"text": "abc"}, errNoSteps.Error()},
+		{"steps_and_expr", map[string]any{"text": "abc", "steps": []any{"upper"}, "expr": "lower"}, errNoSteps.Error()},
+		{"invalid_expr", map[string]any{"text": "abc", "expr": "upper | reverse"}, `unknown operation "reverse"`},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		res := callTool(t, session, pipelineToolName, test.args)
+		require.True(t, res.IsError, name)
+		require.Contains(t, res.Content[0].(*mcp.TextContent).Text, test.wantMsg, name) //nolint:forcetypeassert // text content
+	}
+}
+
+func Test_handlePipeline_validate(t *testing.T) {
+	t.Parallel()
+
+	session := connectInMemory(t, newServer())
+
+	res := callTool(t, session, pipelineToolName, map[string]any{
+		"text": "not transformed", "expr": "nfkc | mirror | upper(locale=tr)", "validate": true,
+	})
+	require.False(t, res.IsError, res.Content)
+	require.Equal(t, map[string]any{
+		"text": "",
+		"plan": []any{
+			map[string]any{"op": "normalize_nfkc"},
+			map[string]any{"op": "mirror"},
+			map[string]any{"op": "upper", "locale": "tr"},
+		},
+	}, res.StructuredContent)
+}
+
+func Test_handlePipeline_unknown_step(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := handlePipeline(context.Background(), nil, PipelineInput{Text: "abc", Steps: []string{"mirror", "reverse"}})
+	require.ErrorIs(t, err, errUnknownStep)
+	require.ErrorContains(t, err, `steps[1]: unknown operation "reverse"`)
+}
+
+func Test_handlePipeline_canceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := handlePipeline(ctx, nil, PipelineInput{Text: "abc", Steps: []string{"mirror"}})
+	require.ErrorIs(t, err, context.Canceled)
+}
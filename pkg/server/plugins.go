@@ -0,0 +1,396 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Plugins: extra tools provided by external executables or WebAssembly modules.
+//
+// E.g.: MCP_TEXT_MIRROR_PLUGINS=/usr/local/lib/text-mirror/plugins
+const (
+	envNamePlugins = envPrefix + "PLUGINS" // env var of the directory of the plugin executables
+
+	pluginMethodDescribe = "describe"      // method listing the tools of the plugin
+	pluginMethodCall     = "call"          // method calling a tool of the plugin
+	pluginToolSep        = "_"             // separator between the plugin name and its tool name
+	pluginDescribeWait   = 5 * time.Second // max duration of the describe method on startup
+	pluginStderrMax      = 512             // max bytes of the standard error of a failed plugin in the errors
+	pluginStdoutMax      = 64 << 20        // max bytes of the standard output of a plugin, above the longest text
+)
+
+// Predefined errors of the plugins.
+var (
+	errPluginsDir      = errors.New("must be a directory")
+	errPluginResponse  = errors.New("invalid plugin response")
+	errPluginDuplicate = errors.New("plugins must have unique names, apart from the extensions")
+)
+
+// pluginRequest is the request line written to the standard input of a plugin.
+type pluginRequest struct {
+	Method    string          `json:"method"`              // pluginMethodDescribe or pluginMethodCall
+	Tool      string          `json:"tool,omitempty"`      // name of the tool to call, without the plugin name
+	Arguments json.RawMessage `json:"arguments,omitempty"` // arguments of the call as given by the client
+}
+
+// pluginResponse is the response line read from the standard output of a
+// plugin. Tools is the response of the describe method, the others are of the
+// call method.
+type pluginResponse struct {
+	Tools      []pluginTool    `json:"tools,omitempty"`
+	Text       string          `json:"text,omitempty"`       // text result of the call
+	Structured json.RawMessage `json:"structured,omitempty"` // structured result of the call, a JSON object
+	Error      string          `json:"error,omitempty"`      // error of the call, reported to the client as a tool error
+}
+
+// pluginCaller sends the request to a plugin and returns its response: by
+// running the executable, or by instantiating the WebAssembly module.
+type pluginCaller func(ctx context.Context, req pluginRequest) (*pluginResponse, error)
+
+// pluginTool is a tool of a plugin, as described by the plugin.
+type pluginTool struct {
+	Name         string          `json:"name"`
+	Description  string          `json:"description"`
+	InputSchema  json.RawMessage `json:"input_schema,omitempty"`  // JSON schema of type "object". default any object
+	OutputSchema json.RawMessage `json:"output_schema,omitempty"` // JSON schema of type "object" of the structured result
+}
+
+// GetPlugins returns the directory of the plugin executables and WebAssembly
+// modules from 'MCP_TEXT_MIRROR_PLUGINS' environment variable. It returns an empty
+// string if not set, which disables the plugins.
+func GetPlugins() (string, error) {
+	return loadedSettings().plugins()
+}
+
+// plugins returns GetPlugins of the settings.
+func (v *settingValues) plugins() (string, error) {
+	dir := v.value(envNamePlugins)
+	if dir == "" {
+		return "", nil
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return "", wrapError(err, "invalid %s", envNamePlugins)
+	}
+
+	if !info.IsDir() {
+		return "", fmt.Errorf("invalid %s %q: %w", envNamePlugins, dir, errPluginsDir)
+	}
+
+	_, err = findPlugins(dir)
+	if err != nil {
+		return "", wrapError(err, "invalid %s", envNamePlugins)
+	}
+
+	return dir, nil
+}
+
+// findPlugins returns the paths of the plugin executables and WebAssembly
+// modules in dir by plugin name, which is the file name without its extension.
+// Hidden files and subdirectories are ignored. Two plugins of the same name,
+// such as "case.sh" and "case.wasm", are errPluginDuplicate rather than one
+// silently replacing the other.
+func findPlugins(dir string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, wrapError(err, "failed to read the plugins directory")
+	}
+
+	plugins := make(map[string]string)
+
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil || (!isExecutable(info) && !isWasmPlugin(info.Name())) {
+			continue
+		}
+
+		name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		if previous, ok := plugins[name]; ok {
+			return nil, fmt.Errorf("%w: %s and %s", errPluginDuplicate, filepath.Base(previous), entry.Name())
+		}
+
+		plugins[name] = filepath.Join(dir, entry.Name())
+	}
+
+	return plugins, nil
+}
+
+// isExecutable reports whether the file can be run as a plugin: with an
+// executable bit on Unix-like systems, or with the .exe extension on Windows.
+func isExecutable(info os.FileInfo) bool {
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(filepath.Ext(info.Name()), ".exe")
+	}
+
+	return info.Mode().IsRegular() && info.Mode().Perm()&0o111 != 0
+}
+
+// addPlugins registers the tools of the plugins in dir in the tool set as
+// '<plugin>_<tool>'. Calls to those tools are proxied to the plugins. The
+// returned function releases the WebAssembly runtime, if any module was loaded.
+//
+// If any plugin fails to describe its tools, or has a tool whose name is taken,
+// the error is returned so that a broken plugin is noticed on startup rather
+// than on the first call.
+func addPlugins(ctx context.Context, tools *toolSet, dir string) (func(), error) {
+	plugins, err := findPlugins(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	wasm := new(wasmPlugins)
+
+	for _, name := range slices.Sorted(maps.Keys(plugins)) {
+		err := addPlugin(ctx, tools, name, plugins[name], wasm)
+		if err != nil {
+			wasm.close()
+
+			return nil, err
+		}
+	}
+
+	return wasm.close, nil
+}
+
+// addPlugin registers the tools of the plugin at path, loaded in wasm if it is
+// a WebAssembly module.
+func addPlugin(ctx context.Context, tools *toolSet, name, path string, wasm *wasmPlugins) error {
+	call := execPlugin(path)
+
+	if isWasmPlugin(path) {
+		var err error
+
+		call, err = wasm.load(ctx, path)
+		if err != nil {
+			return wrapError(err, "failed to load plugin %q", name)
+		}
+	}
+
+	describeCtx, cancel := context.WithTimeout(ctx, pluginDescribeWait)
+	res, err := call(describeCtx, pluginRequest{Method: pluginMethodDescribe, Tool: "", Arguments: nil})
+
+	cancel()
+
+	if err != nil {
+		return wrapError(err, "failed to describe plugin %q", name)
+	}
+
+	for _, tool := range res.Tools {
+		info, err := tool.info(name)
+		if err != nil {
+			return wrapError(err, "plugin %q", name)
+		}
+
+		err = tools.registerExternal(info, pluginToolHandler(call, tool.Name))
+		if err != nil {
+			return wrapError(err, "plugin %q", name)
+		}
+
+		debugLog(ctx, "plugin tool added", logKeyTool, info.Name)
+	}
+
+	return nil
+}
+
+// info returns the definition of the tool of the plugin to list in tools/list.
+func (p pluginTool) info(plugin string) (*mcp.Tool, error) {
+	if p.Name == "" {
+		return nil, wrapError(errPluginResponse, "tool without name")
+	}
+
+	info := new(mcp.Tool)
+	info.Name = plugin + pluginToolSep + p.Name
+	info.Description = p.Description
+	info.InputSchema = json.RawMessage(`{"type":"object"}`)
+
+	if len(p.InputSchema) > 0 {
+		info.InputSchema = p.InputSchema
+	}
+
+	if len(p.OutputSchema) > 0 {
+		info.OutputSchema = p.OutputSchema
+	}
+
+	if !isObjectSchema(info.InputSchema) || (info.OutputSchema != nil && !isObjectSchema(info.OutputSchema)) {
+		return nil, wrapError(errPluginResponse, "tool %q with non-object schema", p.Name)
+	}
+
+	return info, nil
+}
+
+// pluginToolHandler returns a tool handler that forwards the call to the tool
+// named toolName of the plugin.
+func pluginToolHandler(call pluginCaller, toolName string) mcp.ToolHandler {
+	return func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		res, err := call(ctx, pluginRequest{
+			Method:    pluginMethodCall,
+			Tool:      toolName,
+			Arguments: req.Params.Arguments,
+		})
+		if err != nil {
+			return nil, wrapError(err, "plugin tool %q failed", toolName)
+		}
+
+		result := new(mcp.CallToolResult)
+
+		if res.Error != "" {
+			result.IsError = true
+			result.Content = []mcp.Content{&mcp.TextContent{Text: res.Error}} //nolint:exhaustruct // text only
+
+			return result, nil
+		}
+
+		text := res.Text
+		if len(res.Structured) > 0 {
+			result.StructuredContent = res.Structured
+
+			if text == "" {
+				text = string(res.Structured)
+			}
+		}
+
+		result.Content = []mcp.Content{&mcp.TextContent{Text: text}} //nolint:exhaustruct // text only
+
+		return result, nil
+	}
+}
+
+// execPlugin returns the caller of the plugin executable at path.
+func execPlugin(path string) pluginCaller {
+	return func(ctx context.Context, req pluginRequest) (*pluginResponse, error) {
+		return callPlugin(ctx, path, req)
+	}
+}
+
+// callPlugin runs the plugin at path with the request as a JSON line on its
+// standard input, and returns the first JSON line of its standard output. The
+// plugin is killed once ctx is done, and its output is cut at
+// pluginStdoutMax bytes.
+func callPlugin(ctx context.Context, path string, req pluginRequest) (*pluginResponse, error) {
+	line, err := req.line()
+	if err != nil {
+		return nil, err
+	}
+
+	stdout := newPluginOutput(pluginStdoutMax, true)
+	stderr := newPluginOutput(pluginStderrMax, false)
+
+	cmd := exec.CommandContext(ctx, path) //nolint:gosec // running the plugins of the configured directory is the whole point
+	cmd.Stdin = bytes.NewReader(line)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err = cmd.Run()
+
+	switch {
+	case stdout.over:
+		return nil, stdout.overError()
+	case err != nil:
+		return nil, pluginFailure(err, stderr.Bytes())
+	}
+
+	return parsePluginResponse(stdout.Bytes())
+}
+
+// pluginOutput keeps the first bytes written to it, up to its limit, as the
+// standard output or error of a plugin. It is not an io.ReaderFrom, so that
+// io.Copy goes through Write.
+type pluginOutput struct {
+	buf    bytes.Buffer
+	limit  int
+	strict bool // fail the writes past the limit, stopping the plugin, instead of discarding them
+	over   bool // whether a write went past the limit
+}
+
+// newPluginOutput returns the output keeping limit bytes at most. If strict,
+// the writes past the limit fail.
+func newPluginOutput(limit int, strict bool) *pluginOutput {
+	out := new(pluginOutput)
+	out.limit = limit
+	out.strict = strict
+
+	return out
+}
+
+// Write keeps p up to the limit. It is an implementation of io.Writer.
+func (o *pluginOutput) Write(p []byte) (int, error) {
+	rest := o.limit - o.buf.Len()
+	if len(p) <= rest {
+		return o.buf.Write(p)
+	}
+
+	o.over = true
+
+	if o.strict {
+		return 0, o.overError()
+	}
+
+	o.buf.Write(p[:max(0, rest)])
+
+	return len(p), nil
+}
+
+// overError returns the error of the output past the limit.
+func (o *pluginOutput) overError() error {
+	return fmt.Errorf("%w: output exceeds %d bytes", errPluginResponse, o.limit)
+}
+
+// Bytes returns the bytes kept.
+func (o *pluginOutput) Bytes() []byte {
+	return o.buf.Bytes()
+}
+
+// line returns the request as a JSON line.
+func (r pluginRequest) line() ([]byte, error) {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return nil, wrapError(err, "failed to marshal the plugin request")
+	}
+
+	return append(line, '\n'), nil
+}
+
+// pluginFailure wraps the error of a failed plugin with the first bytes of its
+// standard error, if any.
+func pluginFailure(err error, stderr []byte) error {
+	if len(stderr) == 0 {
+		return wrapError(err, "plugin failed")
+	}
+
+	stderr = stderr[:min(len(stderr), pluginStderrMax)]
+
+	return wrapError(err, "plugin failed: %s", strings.TrimSpace(string(stderr)))
+}
+
+// parsePluginResponse returns the response of the first JSON line of the
+// standard output of a plugin.
+func parsePluginResponse(out []byte) (*pluginResponse, error) {
+	first, _, _ := bytes.Cut(out, []byte("\n"))
+
+	res := new(pluginResponse)
+
+	err := json.Unmarshal(first, res)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errPluginResponse, err)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,301 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// testPlugin is a plugin describing the upper, fail and crash tools, as a shell
+// script.
+const testPlugin = `#!/bin/sh
+read -r line
+case "$line" in
+*'"describe"'*)
+  echo '{"tools":[{"name":"upper","description":"Uppercases the text","input_schema":{"type":"object","properties":{"text":{"type":"string"}}}},{"name":"fail","description":"Fails"},{"name":"crash","description":"Crashes"}]}'
+  ;;
+*'"upper"'*)
+  echo "$line" | sed 's/.*"text":"\([^"]*\)".*/{"text":"\1","structured":{"upper":"\1"}}/' | tr 'a-z' 'A-Z' | sed 's/"TEXT"/"text"/; s/"STRUCTURED"/"structured"/; s/"UPPER"/"upper"/'
+  ;;
+*'"fail"'*)
+  echo '{"error":"failed as asked"}'
+  ;;
+*)
+  echo 'crashed' >&2
+  exit 3
+  ;;
+esac
+`
+
+// writePlugin writes the plugin script to dir as name, executable if exec.
+func writePlugin(t *testing.T, dir, name, script string, exec bool) {
+	t.Helper()
+
+	perm := os.FileMode(0o600)
+	if exec {
+		perm = 0o700
+	}
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(script), perm))
+}
+
+// skipWithoutShell skips the test on Windows, where the test plugins, being
+// shell scripts, can't run.
+func skipWithoutShell(t *testing.T) {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("the test plugins are shell scripts")
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  GetPlugins
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func TestGetPlugins(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(file, nil, 0o600))
+
+	for index, test := range []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"default", "", "", false},
+		{"directory", dir, dir, false},
+		{"missing", filepath.Join(dir, "missing"), "", true},
+		{"file", file, "", true},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		setEnv(t, envNamePlugins, test.value)
+
+		got, err := GetPlugins()
+		if test.wantErr {
+			require.Error(t, err, name)
+			require.ErrorContains(t, err, envNamePlugins, name)
+		} else {
+			require.NoError(t, err, name)
+		}
+
+		require.Equal(t, test.want, got, name)
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  findPlugins
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // writes and runs executables, which may fail with ETXTBSY in parallel
+func Test_findPlugins(t *testing.T) {
+	skipWithoutShell(t)
+
+	dir := t.TempDir()
+	writePlugin(t, dir, "text.sh", testPlugin, true)
+	writePlugin(t, dir, "readme.txt", "not a plugin", false)
+	writePlugin(t, dir, "module.wasm", "", false)
+	writePlugin(t, dir, ".hidden", testPlugin, true)
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0o700))
+
+	got, err := findPlugins(dir)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"text": filepath.Join(dir, "text.sh"), "module": filepath.Join(dir, "module.wasm"),
+	}, got, "WebAssembly modules should be plugins without the executable bit")
+
+	_, err = findPlugins(filepath.Join(dir, "missing"))
+	require.Error(t, err)
+
+	writePlugin(t, dir, "text.wasm", "", false)
+
+	_, err = findPlugins(dir)
+	require.ErrorIs(t, err, errPluginDuplicate, "plugins of the same name should be reported")
+	require.ErrorContains(t, err, "text.sh and text.wasm")
+
+	setEnv(t, envNamePlugins, dir)
+
+	_, err = GetPlugins()
+	require.ErrorIs(t, err, errPluginDuplicate, "the duplicates should be reported at startup")
+}
+
+// ----------------------------------------------------------------------------
+//  addPlugins
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // writes and runs executables, which may fail with ETXTBSY in parallel
+func Test_addPlugins(t *testing.T) {
+	skipWithoutShell(t)
+
+	dir := t.TempDir()
+	writePlugin(t, dir, "text.sh", testPlugin, true)
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v0.0.0"}, nil) //nolint:exhaustruct // minimal server
+	closePlugins, err := addPlugins(context.Background(), newToolSet(server), dir)
+	require.NoError(t, err)
+	t.Cleanup(closePlugins)
+
+	session := connectInMemory(t, server)
+
+	var names []string
+
+	for tool, err := range session.Tools(context.Background(), nil) {
+		require.NoError(t, err)
+
+		names = append(names, tool.Name)
+	}
+
+	require.Equal(t, []string{"text_crash", "text_fail", "text_upper"}, names)
+
+	// Text and structured results
+	res := callTool(t, session, "text_upper", map[string]any{"text": "abc"})
+	require.False(t, res.IsError)
+	require.Len(t, res.Content, 1)
+	require.Equal(t, "ABC", res.Content[0].(*mcp.TextContent).Text) //nolint:forcetypeassert // fails the test anyway
+
+	structured, err := json.Marshal(res.StructuredContent)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"upper":"ABC"}`, string(structured))
+
+	// Tool error
+	res = callTool(t, session, "text_fail", nil)
+	require.True(t, res.IsError)
+	require.Equal(t, "failed as asked", res.Content[0].(*mcp.TextContent).Text) //nolint:forcetypeassert // fails the test anyway
+
+	// Crash of the plugin
+	params := new(mcp.CallToolParams)
+	params.Name = "text_crash"
+
+	_, err = session.CallTool(context.Background(), params)
+	require.ErrorContains(t, err, "crashed")
+}
+
+//nolint:paralleltest // writes and runs executables, which may fail with ETXTBSY in parallel
+func Test_callPlugin_output_limit(t *testing.T) {
+	skipWithoutShell(t)
+
+	dir := t.TempDir()
+	writePlugin(t, dir, "endless", "#!/bin/sh\nyes '{\"text\":\"y\"}'\n", true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	_, err := callPlugin(ctx, filepath.Join(dir, "endless"), pluginRequest{Method: pluginMethodDescribe}) //nolint:exhaustruct // describe only
+	require.ErrorIs(t, err, errPluginResponse)
+	require.ErrorContains(t, err, "output exceeds")
+	require.NoError(t, ctx.Err(), "the plugin should be stopped at the limit, not by the timeout")
+}
+
+// ----------------------------------------------------------------------------
+//  pluginOutput
+// ----------------------------------------------------------------------------
+
+func Test_pluginOutput(t *testing.T) {
+	t.Parallel()
+
+	for index, test := range []struct {
+		name    string
+		strict  bool
+		writes  []string
+		want    string
+		wantErr bool
+	}{
+		{"under", true, []string{"ab", "cd"}, "abcd", false},
+		{"at_limit", true, []string{"abcd", "ef"}, "abcdef", false},
+		{"over_strict", true, []string{"abcd", "efg"}, "abcd", true},
+		{"over_discarded", false, []string{"abcd", "efg", "h"}, "abcdef", false},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+		out := newPluginOutput(6, test.strict)
+
+		var err error
+
+		for _, write := range test.writes {
+			var n int
+
+			n, err = out.Write([]byte(write))
+			if err != nil {
+				break
+			}
+
+			require.Equal(t, len(write), n, name)
+		}
+
+		if test.wantErr {
+			require.ErrorIs(t, err, errPluginResponse, name)
+		} else {
+			require.NoError(t, err, name)
+		}
+
+		require.Equal(t, test.want, string(out.Bytes()), name)
+		require.Equal(t, test.want != strings.Join(test.writes, ""), out.over, name)
+	}
+}
+
+//nolint:paralleltest // writes and runs executables, which may fail with ETXTBSY in parallel
+func Test_addPlugins_invalid(t *testing.T) {
+	skipWithoutShell(t)
+
+	for index, test := range []struct {
+		name    string
+		plugins map[string]string // scripts by file name
+		wantErr string
+	}{
+		{
+			"failed_describe",
+			map[string]string{"broken": "#!/bin/sh\necho 'oops' >&2\nexit 1\n"},
+			"oops",
+		},
+		{
+			"not_json",
+			map[string]string{"garbage": "#!/bin/sh\necho 'tools'\n"},
+			errPluginResponse.Error(),
+		},
+		{
+			"non_object_schema",
+			map[string]string{"array": "#!/bin/sh\necho '{\"tools\":[{\"name\":\"a\",\"input_schema\":{\"type\":\"array\"}}]}'\n"},
+			"non-object schema",
+		},
+		{
+			"without_name",
+			map[string]string{"anonymous": "#!/bin/sh\necho '{\"tools\":[{\"description\":\"a\"}]}'\n"},
+			"tool without name",
+		},
+		{
+			"builtin_clash",
+			map[string]string{"mirror": "#!/bin/sh\necho '{\"tools\":[{\"name\":\"batch\"}]}'\n"},
+			errToolExists.Error(),
+		},
+		{
+			"plugin_clash",
+			map[string]string{
+				"a":   "#!/bin/sh\necho '{\"tools\":[{\"name\":\"b_c\"}]}'\n",
+				"a_b": "#!/bin/sh\necho '{\"tools\":[{\"name\":\"c\"}]}'\n",
+			},
+			errToolExists.Error(),
+		},
+	} {
+		t.Run(fmt.Sprintf("Test #%d: %s", index+1, test.name), func(t *testing.T) {
+			dir := t.TempDir()
+			for name, script := range test.plugins {
+				writePlugin(t, dir, name, script, true)
+			}
+
+			server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v0.0.0"}, nil) //nolint:exhaustruct // minimal server
+			_, err := addPlugins(context.Background(), newToolSet(server), dir)
+			require.ErrorContains(t, err, test.wantErr)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Profiles bundling the defaults of the settings for an environment.
+//
+// E.g.: text-mirror --profile prod
+const (
+	envNameProfile = envPrefix + "PROFILE" // env var of the profile
+	profileDev     = "dev"                 // development: debug logging, wire tap, pprof and the admin tool
+	profileProd    = "prod"                // production: limits of the tool calls, JSON logs and no debug endpoints
+)
+
+// errUnknownProfile is returned if the profile is neither dev nor prod.
+var errUnknownProfile = errors.New("unknown profile. must be dev or prod")
+
+// profiles are the defaults of the settings by profile and environment
+// variable. They apply only to the settings set nowhere else.
+//
+//nolint:gochecknoglobals // read-only table
+var profiles = map[string]map[string]string{
+	profileDev: {
+		envNameDebug:     logName, // in the user's log directory
+		envNameLogLevel:  logLevelDebug,
+		envNameWireTap:   wireTapName, // in the user's log directory
+		envNameDebugHTTP: "true",      // over HTTP, on a loopback listener or to the admin clients
+		envNameAdmin:     "true",
+	},
+	profileProd: {
+		envNameRateLimit:    "10",
+		envNameRateBurst:    "20",
+		envNameCallTimeout:  "30s",
+		envNameClientLimits: "*=1048576",
+		envNameAdmin:        "false",
+		envNameLogLevel:     logLevelInfo,
+		envNameLogFormat:    logFormatJSON,
+		envNameSlowCall:     "500ms",
+		envNameLogRedact:    redactHash,
+		envNameDebugHTTP:    "false",
+		envNameWireTap:      "", // off, as it writes the texts as sent
+	},
+}
+
+// GetProfile returns the profile from 'MCP_TEXT_MIRROR_PROFILE' environment
+// variable. It is empty if no profile is set.
+func GetProfile() (string, error) {
+	return loadedSettings().profile()
+}
+
+// profile returns GetProfile of the settings.
+func (v *settingValues) profile() (string, error) {
+	profile := v.value(envNameProfile)
+	if profile != "" && profiles[profile] == nil {
+		return "", fmt.Errorf("invalid %s %q: %w", envNameProfile, profile, errUnknownProfile)
+	}
+
+	return profile, nil
+}
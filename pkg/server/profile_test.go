@@ -0,0 +1,162 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// unsetProfileEnv unsets the environment variables set by the profiles for the
+// duration of the test.
+func unsetProfileEnv(t *testing.T) {
+	t.Helper()
+
+	for _, defaults := range profiles {
+		for name := range defaults {
+			unsetEnv(t, name)
+		}
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  GetProfile
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func TestGetProfile(t *testing.T) {
+	for index, test := range []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"unset", "", "", false},
+		{"dev", "dev", profileDev, false},
+		{"prod", "prod", profileProd, false},
+		{"unknown", "staging", "", true},
+		{"case_sensitive", "Prod", "", true},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		setEnv(t, envNameProfile, test.value)
+
+		got, err := GetProfile()
+		if test.wantErr {
+			require.ErrorIs(t, err, errUnknownProfile, name)
+
+			continue
+		}
+
+		require.NoError(t, err, name)
+		require.Equal(t, test.want, got, name)
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  mergeSettings with profile
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_mergeSettings_profile(t *testing.T) {
+	unsetEnv(t, envNameProfile)
+	unsetProfileEnv(t)
+
+	require.Empty(t, settingValue(envNameCallTimeout), "no profile should set nothing")
+
+	setEnv(t, envNameProfile, profileProd)
+	setEnv(t, envNameRateLimit, "100") // set explicitly
+
+	require.Equal(t, "100", settingValue(envNameRateLimit), "explicit settings should override the profile")
+	require.Equal(t, "30s", settingValue(envNameCallTimeout))
+	require.Equal(t, "false", settingValue(envNameDebugHTTP), "debug endpoints should be turned off explicitly")
+	require.Empty(t, settingValue(envNameWireTap), "the wire tap should be off")
+	require.True(t, loadedSettings().overridden(envNameCallTimeout))
+	require.NoError(t, loadSettings(), "defaults of the profile should be valid")
+
+	level, err := GetLogLevel()
+	require.NoError(t, err)
+	require.Equal(t, slog.LevelInfo, level, "the log level should be of the profile")
+
+	// The profile of the config file, overridden by the flags
+	values := mergeSettings(map[string]string{envNameProfile: profileDev}, nil, map[string]string{envNameAdmin: "false"})
+	require.Equal(t, "false", values.values[envNameAdmin])
+	require.Equal(t, logLevelDebug, values.values[envNameLogLevel])
+
+	// Invalid profile sets nothing
+	setEnv(t, envNameProfile, "staging")
+
+	require.Empty(t, settingValue(envNameCallTimeout))
+	require.ErrorIs(t, loadSettings(), errUnknownProfile)
+}
+
+//nolint:paralleltest // sets env var
+func Test_profiles_valid(t *testing.T) {
+	for profile, defaults := range profiles {
+		unsetEnv(t, envNameProfile, envNameDebug)
+
+		for name := range defaults {
+			unsetEnv(t, name)
+		}
+
+		setEnv(t, envNameProfile, profile)
+
+		require.NoError(t, loadSettings(), "defaults of the %s profile should be valid", profile)
+
+		for name := range defaults {
+			unsetEnv(t, name)
+		}
+	}
+}
+
+func Test_profiles(t *testing.T) {
+	t.Parallel()
+
+	dev := profiles[profileDev]
+	require.NotEmpty(t, dev[envNameDebug], "dev should enable debug logging")
+	require.Equal(t, logLevelDebug, dev[envNameLogLevel])
+	require.NotEmpty(t, dev[envNameWireTap], "dev should enable the wire tap")
+	require.Equal(t, "true", dev[envNameDebugHTTP], "dev should enable pprof")
+
+	prod := profiles[profileProd]
+	require.Equal(t, logFormatJSON, prod[envNameLogFormat], "prod should log in JSON")
+	require.Equal(t, "false", prod[envNameDebugHTTP], "prod should disable pprof")
+	require.Contains(t, prod, envNameWireTap)
+	require.Empty(t, prod[envNameWireTap], "prod should disable the wire tap")
+	require.Equal(t, "false", prod[envNameAdmin], "prod should disable the admin tool")
+}
+
+// ----------------------------------------------------------------------------
+//  configReloader
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_configReloader_reload_profile(t *testing.T) {
+	unsetEnv(t, envNameProfile)
+	unsetProfileEnv(t)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("server:\n  profile: prod\nlimits:\n  call_timeout: 5s\n"), 0o600))
+
+	config, err := loadConfig(path)
+	require.NoError(t, err)
+
+	keepSettings(t)
+	storeSettings(func(v *settingValues) *settingValues { return v.withConfig(config.env()) })
+
+	reloader := newConfigReloader(path)
+	require.Equal(t, "5s", settingValue(envNameCallTimeout), "config file should override the profile")
+	require.Equal(t, "10", settingValue(envNameRateLimit))
+
+	// Profile removed from the config file
+	require.NoError(t, os.WriteFile(path, []byte("limits:\n  call_timeout: 5s\n"), 0o600))
+
+	_, err = reloader.reload()
+	require.NoError(t, err)
+
+	require.Empty(t, settingValue(envNameRateLimit), "defaults of the removed profile should be unset")
+	require.Equal(t, "5s", settingValue(envNameCallTimeout))
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+
+	"github.com/KEINOS/mcp-text-mirror/pkg/mirror"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Progress notification configuration.
+const (
+	progressMinBytes = 64 * 1024 // min input size in bytes to report the progress
+	progressInterval = 16 * 1024 // number of graphemes processed between notifications. multiple of mirror.CheckInterval
+
+	streamMinBytes = 1024 * 1024           // min input size in bytes to stream the partial results
+	streamMetaKey  = "text-mirror/stream"  // _meta key of the request to opt in to the partial results
+	partialMetaKey = "text-mirror/partial" // _meta key of the partial result in the progress notifications
+)
+
+// PartialResult is a part of the mirrored text streamed in the progress
+// notifications, under the partialMetaKey key of _meta.
+//
+// Since the text is mirrored from the start of the input, the partial results
+// come from the end of the output to its start. Place each text at its byte
+// offset of the output, whose size is given, to start consuming it before the
+// tool call completes.
+type PartialResult struct {
+	Offset int    `json:"offset"` // byte offset of the text in the output
+	Size   int    `json:"size"`   // byte size of the whole output
+	Text   string `json:"text"`
+}
+
+// progressReporter returns the function to send the progress notifications of
+// the tool call, tied to the progress token of the request.
+//
+// If the client opted in by setting streamMetaKey to true in the _meta of the
+// request, the notifications also carry the partial results of large inputs.
+//
+// It returns nil if the client did not ask for the progress or if the input is
+// too small to be worth it.
+func progressReporter(ctx context.Context, req *mcp.CallToolRequest, text string) mirror.ProgressFunc {
+	if req == nil || req.Params == nil || req.Session == nil || len(text) < progressMinBytes {
+		return nil
+	}
+
+	token := req.Params.GetProgressToken()
+	if token == nil {
+		return nil
+	}
+
+	stream, _ := req.Params.GetMeta()[streamMetaKey].(bool)
+	stream = stream && len(text) >= streamMinBytes
+
+	return func(done, total int, chunk mirror.Chunk) {
+		params := new(mcp.ProgressNotificationParams)
+		params.ProgressToken = token
+		params.Progress = float64(done)
+		params.Total = float64(total)
+		params.Message = "graphemes mirrored"
+
+		if stream && len(chunk.Data) > 0 {
+			params.Meta = mcp.Meta{partialMetaKey: PartialResult{
+				Offset: chunk.Offset,
+				Size:   len(text),
+				Text:   string(chunk.Data),
+			}}
+		}
+
+		// Progress is best effort. Failing to notify must not fail the call.
+		_ = req.Session.NotifyProgress(ctx, params)
+	}
+}
@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rivo/uniseg"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  progressReporter
+// ----------------------------------------------------------------------------
+
+func Test_progressReporter_disabled(t *testing.T) {
+	t.Parallel()
+
+	large := strings.Repeat("a", progressMinBytes)
+	ctx := context.Background()
+
+	require.Nil(t, progressReporter(ctx, nil, large), "nil request")
+
+	req := new(mcp.CallToolRequest)
+	req.Params = new(mcp.CallToolParamsRaw)
+	req.Session = new(mcp.ServerSession)
+	require.Nil(t, progressReporter(ctx, req, large), "no progress token")
+
+	req.Params.Meta = mcp.Meta{"progressToken": "token"} // SetProgressToken ignores nil Meta
+	require.Nil(t, progressReporter(ctx, req, "small"), "small input")
+	require.NotNil(t, progressReporter(ctx, req, large))
+}
+
+func Test_handleReverse_progress(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+
+	serverSession, err := newServer().Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+
+	var (
+		mu       sync.Mutex
+		progress []*mcp.ProgressNotificationParams
+	)
+
+	options := new(mcp.ClientOptions)
+	options.ProgressNotificationHandler = func(_ context.Context, req *mcp.ProgressNotificationClientRequest) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		progress = append(progress, req.Params)
+	}
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.0"}, options) //nolint:exhaustruct // minimal client
+
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+
+	defer func() {
+		_ = session.Close()
+		_ = serverSession.Wait()
+	}()
+
+	input := strings.Repeat("ab", progressMinBytes)
+
+	params := new(mcp.CallToolParams)
+	params.Name = toolName
+	params.Arguments = map[string]any{"text": input}
+	params.Meta = mcp.Meta{"progressToken": "mirror-1"} // SetProgressToken ignores nil Meta
+
+	res, err := session.CallTool(ctx, params)
+	require.NoError(t, err)
+	require.False(t, res.IsError)
+	require.Equal(t, map[string]any{"text": uniseg.ReverseString(input)}, res.StructuredContent)
+
+	total := float64(len(input))
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return len(progress) > 0 && progress[len(progress)-1].Progress == total
+	}, timeoutEventually, tickEventually, "final progress should be notified")
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	require.Len(t, progress, len(input)/progressInterval)
+
+	for _, p := range progress {
+		require.Equal(t, "mirror-1", p.ProgressToken)
+		require.Equal(t, total, p.Total)
+		require.Nil(t, p.Meta, "partial results should be sent only if requested")
+	}
+}
+
+func Test_handleReverse_stream(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+
+	serverSession, err := newServer().Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+
+	input := strings.Repeat("👍🏽", streamMinBytes/len("👍🏽")+1)
+	assembled := make([]byte, len(input))
+
+	var (
+		mu       sync.Mutex
+		received int
+	)
+
+	options := new(mcp.ClientOptions)
+	options.ProgressNotificationHandler = func(_ context.Context, req *mcp.ProgressNotificationClientRequest) {
+		data, err := json.Marshal(req.Params.Meta[partialMetaKey])
+		require.NoError(t, err)
+
+		var partial PartialResult
+
+		require.NoError(t, json.Unmarshal(data, &partial))
+		require.Equal(t, len(input), partial.Size)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		received += copy(assembled[partial.Offset:], partial.Text)
+	}
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.0"}, options) //nolint:exhaustruct // minimal client
+
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+
+	defer func() {
+		_ = session.Close()
+		_ = serverSession.Wait()
+	}()
+
+	params := new(mcp.CallToolParams)
+	params.Name = toolName
+	params.Arguments = map[string]any{"text": input}
+	params.Meta = mcp.Meta{"progressToken": "mirror-2", streamMetaKey: true}
+
+	res, err := session.CallTool(ctx, params)
+	require.NoError(t, err)
+	require.False(t, res.IsError)
+
+	want := uniseg.ReverseString(input)
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return received == len(input)
+	}, timeoutEventually, tickEventually, "partial results should cover the whole output")
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	require.Equal(t, want, string(assembled))
+}
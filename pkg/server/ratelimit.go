@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/time/rate"
+)
+
+// Rate limit configuration.
+const (
+	envNameRateLimit  = envPrefix + "RATE_LIMIT" // env var of the max tool calls per second per client. 0 or unset disables rate limiting
+	envNameRateBurst  = envPrefix + "RATE_BURST" // env var of the max burst of tool calls per client. defaults to the rate limit (min 1)
+	rateSweepInterval = time.Minute              // interval to forget idle clients
+)
+
+// errRateLimited is reported to the client if the rate limit is exceeded.
+var errRateLimited = errors.New("rate limit exceeded")
+
+// GetRateLimit returns the rate limit (tool calls per second) and the burst size
+// per client from 'MCP_TEXT_MIRROR_RATE_LIMIT' and 'MCP_TEXT_MIRROR_RATE_BURST'
+// environment variables.
+//
+// A zero limit means rate limiting is disabled.
+func GetRateLimit() (float64, int, error) {
+	return loadedSettings().rateLimit()
+}
+
+// rateLimit returns GetRateLimit of the settings.
+func (v *settingValues) rateLimit() (float64, int, error) {
+	limit, err := v.envFloat(envNameRateLimit)
+	if err != nil || limit == 0 {
+		return 0, 0, err
+	}
+
+	burst, err := v.envInt(envNameRateBurst, 0)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if burst == 0 {
+		burst = int(max(1, math.Ceil(limit)))
+	}
+
+	return limit, burst, nil
+}
+
+// rateLimiter limits the tool calls per client with a token bucket each.
+type rateLimiter struct {
+	now       func() time.Time // replaceable in tests
+	clients   map[string]*rate.Limiter
+	lastSweep time.Time
+	limit     rate.Limit
+	burst     int
+	mu        sync.Mutex
+}
+
+// newRateLimiter returns a rate limiter allowing limit calls per second with
+// the burst size per client.
+func newRateLimiter(limit float64, burst int) *rateLimiter {
+	limiter := new(rateLimiter)
+	limiter.now = time.Now
+	limiter.clients = make(map[string]*rate.Limiter)
+	limiter.lastSweep = limiter.now()
+	limiter.limit = rate.Limit(limit)
+	limiter.burst = burst
+
+	return limiter
+}
+
+// allow reports whether the client may call a tool now. It never blocks.
+func (l *rateLimiter) allow(client string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	// Forget the clients whose bucket is full again, which means idle.
+	if now.Sub(l.lastSweep) >= rateSweepInterval {
+		for key, limiter := range l.clients {
+			if limiter.TokensAt(now) >= float64(l.burst) {
+				delete(l.clients, key)
+			}
+		}
+
+		l.lastSweep = now
+	}
+
+	limiter, ok := l.clients[client]
+	if !ok {
+		limiter = rate.NewLimiter(l.limit, l.burst)
+		l.clients[client] = limiter
+	}
+
+	return limiter.AllowN(now, 1)
+}
+
+// middleware rejects the tool calls exceeding the rate limit of the client
+// with a retryable tool error instead of queueing them.
+func (l *rateLimiter) middleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if method != methodCallTool {
+			return next(ctx, method, req)
+		}
+
+		client := clientKey(req)
+		if !l.allow(client) {
+			warnLog(ctx, "rate limit exceeded", logKeyRequestID, requestID(ctx), logKeySession, sessionLog(req),
+				logKeyClient, client)
+
+			return retryableErrorResult(fmt.Errorf("%w: max %v calls per second (burst %d), retry later",
+				errRateLimited, float64(l.limit), l.burst)), nil
+		}
+
+		return next(ctx, method, req)
+	}
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  GetRateLimit
+// ----------------------------------------------------------------------------
+
+func Test_GetRateLimit(t *testing.T) {
+	for index, test := range []struct {
+		name      string
+		limit     string
+		burst     string
+		wantLimit float64
+		wantBurst int
+		wantErr   bool
+	}{
+		{"unset", "", "", 0, 0, false},
+		{"zero_disables", "0", "10", 0, 0, false},
+		{"default_burst", "2.5", "", 2.5, 3, false},
+		{"default_burst_min_one", "0.1", "", 0.1, 1, false},
+		{"custom_burst", "5", "20", 5, 20, false},
+		{"invalid_limit", "fast", "", 0, 0, true},
+		{"negative_limit", "-1", "", 0, 0, true},
+		{"infinite_limit", "+Inf", "", 0, 0, true},
+		{"invalid_burst", "5", "many", 0, 0, true},
+		{"fractional_burst", "5", "2.5", 0, 0, true},
+		{"negative_burst", "5", "-1", 0, 0, true},
+	} {
+		t.Run(fmt.Sprintf("Test #%d: %s", index+1, test.name), func(t *testing.T) {
+			setEnv(t, envNameRateLimit, test.limit)
+			setEnv(t, envNameRateBurst, test.burst)
+
+			limit, burst, err := GetRateLimit()
+			if test.wantErr {
+				require.ErrorIs(t, err, errInvalidNumber)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.InDelta(t, test.wantLimit, limit, 0)
+			require.Equal(t, test.wantBurst, burst)
+		})
+	}
+}
+
+//nolint:paralleltest // sets env var
+func Test_run_invalid_rate_limit(t *testing.T) {
+	setEnv(t, envNameRateLimit, "fast")
+
+	err := newApp().serve(context.Background(), nil)
+	require.ErrorIs(t, err, errInvalidNumber)
+}
+
+// ----------------------------------------------------------------------------
+//  rateLimiter
+// ----------------------------------------------------------------------------
+
+func Test_rateLimiter_allow(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	limiter := newRateLimiter(1, 2)
+	limiter.now = func() time.Time { return now }
+
+	require.True(t, limiter.allow("a"))
+	require.True(t, limiter.allow("a"))
+	require.False(t, limiter.allow("a"), "burst should be exhausted")
+	require.True(t, limiter.allow("b"), "clients should be limited independently")
+
+	now = now.Add(time.Second)
+	require.True(t, limiter.allow("a"), "a token should be refilled after a second")
+	require.False(t, limiter.allow("a"))
+
+	// Idle clients are forgotten after the sweep interval
+	now = now.Add(rateSweepInterval)
+	require.True(t, limiter.allow("c"))
+	require.Len(t, limiter.clients, 1, "idle clients a and b should be swept")
+}
+
+func Test_rateLimiter_middleware(t *testing.T) {
+	t.Parallel()
+
+	server := newServer()
+	server.AddReceivingMiddleware(newRateLimiter(0.001, 2).middleware)
+
+	session1 := connectInMemory(t, server)
+	session2 := connectInMemory(t, server)
+
+	args := map[string]any{"text": "abc"}
+
+	require.False(t, callTool(t, session1, toolName, args).IsError)
+	require.False(t, callTool(t, session1, toolName, args).IsError)
+
+	res := callTool(t, session1, toolName, args)
+	require.True(t, res.IsError, "third call should exceed the burst")
+	require.Contains(t, res.Content[0].(*mcp.TextContent).Text, errRateLimited.Error()) //nolint:forcetypeassert // text content
+
+	require.False(t, callTool(t, session2, toolName, args).IsError,
+		"other sessions should not be affected")
+
+	// Other methods are not limited
+	_, err := session1.ListTools(context.Background(), nil)
+	require.NoError(t, err)
+}
+
+//nolint:paralleltest // sets env var
+func Test_newServer_rate_limit(t *testing.T) {
+	setEnv(t, envNameRateLimit, "0.001")
+	setEnv(t, envNameRateBurst, "1")
+
+	session := connectInMemory(t, newServer())
+	args := map[string]any{"text": "abc"}
+
+	require.False(t, callTool(t, session, toolName, args).IsError)
+	require.True(t, callTool(t, session, toolName, args).IsError,
+		"rate limit from env vars should be applied")
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// logKeyStack is the attribute key of the stack trace of the panics.
+const logKeyStack = "stack"
+
+// errToolPanic is reported to the client if the tool panicked.
+var errToolPanic = errors.New("internal error of the tool")
+
+// recoverMiddleware is a middleware which recovers from the panics of the tool
+// handlers, so that a bad input can't kill the whole stdio session. The panic
+// is logged with its stack trace, reported to the ErrorReporter if any, and
+// returned to the client as a tool error with the request ID to find the log
+// entry.
+//
+// It is the innermost middleware, so that the others see the tool error as
+// they do for the other failures.
+func recoverMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (res mcp.Result, err error) {
+		if method != methodCallTool {
+			return next(ctx, method, req)
+		}
+
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+
+			stack := debug.Stack()
+			id := requestID(ctx)
+			call, _ := req.(*mcp.CallToolRequest)
+
+			errorLog(ctx, "tool panicked", callLogAttrs(ctx, call, "panic", fmt.Sprint(recovered),
+				logKeyStack, string(stack))...)
+
+			go reportError(context.WithoutCancel(ctx), errorKindPanic, fmt.Sprint(recovered), stack) // not to hold the call
+
+			res, err = toolErrorResult(fmt.Errorf("%w (request ID %s)", errToolPanic, id)), nil
+		}()
+
+		return next(ctx, method, req)
+	}
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  recoverMiddleware
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // replaces the error reporter
+func Test_recoverMiddleware(t *testing.T) {
+	unsetEnv(t, envNameToolsEnabled, envNameToolsDisabled)
+
+	reporter := new(syncReporter)
+	errorReporter = reporter
+
+	defer func() { errorReporter = nil }()
+
+	var (
+		mu     sync.Mutex
+		logged []string
+	)
+
+	logger := mockLogger(func(entry string) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		logged = append(logged, entry)
+	})
+
+	state := newServerState(WithLogger(logger))
+
+	info := new(mcp.Tool)
+	info.Name = "explode"
+	addTool(state.tools, info, func(context.Context, *mcp.CallToolRequest, MirrorInput) (*mcp.CallToolResult, any, error) {
+		var texts []string
+
+		return nil, texts[1], nil // index out of range
+	})
+
+	session := connectInMemory(t, state.server)
+
+	res := callTool(t, session, "explode", map[string]any{"text": "abc"})
+	require.True(t, res.IsError, "the panic should be a tool error")
+
+	id, ok := res.Meta[requestIDMetaKey].(string)
+	require.True(t, ok)
+
+	text, ok := res.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	require.Equal(t, "internal error of the tool (request ID "+id+")", text.Text)
+
+	// The session goes on
+	res = callTool(t, session, toolName, map[string]any{"text": "abc"})
+	require.False(t, res.IsError)
+
+	mu.Lock()
+	entries := strings.Join(logged, "\n")
+	mu.Unlock()
+
+	require.Contains(t, entries, `tool panicked request_id=`+id+` tool=explode`)
+	require.Contains(t, entries, `panic="runtime error: index out of range [1] with length 0" stack="goroutine `)
+	require.Contains(t, entries, "Test_recoverMiddleware", "the stack should be the one of the panic")
+
+	require.Eventually(t, func() bool { return reporter.count() == 1 }, timeoutEventually, tickEventually,
+		"the panic should be reported")
+}
+
+// syncReporter is an ErrorReporter counting the reports, safe for concurrent
+// use.
+type syncReporter struct {
+	reports int
+	mu      sync.Mutex
+}
+
+func (r *syncReporter) Report(context.Context, *ErrorReport) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.reports++
+
+	return nil
+}
+
+func (r *syncReporter) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.reports
+}
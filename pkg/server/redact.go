@@ -0,0 +1,126 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/rivo/uniseg"
+)
+
+// Redaction of the texts of the users in the log entries.
+//
+// E.g.: MCP_TEXT_MIRROR_LOG_REDACT=truncate:16
+const (
+	envNameLogRedact = envPrefix + "LOG_REDACT" // env var of the redaction of the texts in the log entries
+
+	redactNone     = "none"     // log the texts as is
+	redactOmit     = "omit"     // never log the texts
+	redactHash     = "hash"     // log the SHA-256 of the texts
+	redactTruncate = "truncate" // log the first N graphemes of the texts. e.g. "truncate:16"
+
+	redactOmitted  = "[redacted]" // value of the texts omitted
+	redactHashSize = 8            // bytes of the SHA-256 logged, enough to tell the texts apart
+)
+
+// errInvalidRedact is the error of an unknown redaction.
+var errInvalidRedact = errors.New("must be none, omit, hash or truncate:N")
+
+// logRedaction is the redaction of the texts in the log entries.
+type logRedaction struct {
+	mode      string // redactNone, redactOmit, redactHash or redactTruncate
+	graphemes int    // graphemes kept by redactTruncate
+}
+
+// GetLogRedact returns the redaction of the texts of the users in the log
+// entries from 'MCP_TEXT_MIRROR_LOG_REDACT' environment variable, since the
+// mirrored texts may contain secrets or personal data:
+//
+//   - "none" logs the texts as is, which is the default.
+//   - "omit" never logs them, logging "[redacted]" instead.
+//   - "hash" logs their SHA-256, to tell the texts apart without their content.
+//   - "truncate:N" logs their first N grapheme clusters only.
+//
+// The redaction applies to every log entry of the texts, in the log file, the
+// syslog, the debug log resource and the log messages to the clients alike,
+// as they are marked userText by the call sites. If the value is invalid, the
+// texts are omitted along with the error, rather than logged as is.
+func GetLogRedact() (logRedaction, error) {
+	return loadedSettings().logRedact()
+}
+
+// logRedact returns GetLogRedact of the settings.
+func (v *settingValues) logRedact() (logRedaction, error) {
+	value := v.value(envNameLogRedact)
+
+	mode, count, hasCount := strings.Cut(strings.ToLower(strings.TrimSpace(value)), ":")
+
+	switch {
+	case mode == "" && !hasCount:
+		return logRedaction{mode: redactNone, graphemes: 0}, nil
+	case (mode == redactNone || mode == redactOmit || mode == redactHash) && !hasCount:
+		return logRedaction{mode: mode, graphemes: 0}, nil
+	case mode == redactTruncate && hasCount:
+		graphemes, err := strconv.Atoi(count)
+		if err == nil && graphemes >= 0 {
+			return logRedaction{mode: mode, graphemes: graphemes}, nil
+		}
+	}
+
+	return logRedaction{mode: redactOmit, graphemes: 0}, // fails closed, as the value meant to redact
+		fmt.Errorf("invalid %s %q: %w", envNameLogRedact, value, errInvalidRedact)
+}
+
+// apply returns the text redacted.
+func (r logRedaction) apply(text string) string {
+	switch r.mode {
+	case redactOmit:
+		return redactOmitted
+	case redactHash:
+		sum := sha256.Sum256([]byte(text))
+
+		return "sha256:" + hex.EncodeToString(sum[:redactHashSize])
+	case redactTruncate:
+		return truncateGraphemes(text, r.graphemes)
+	default:
+		return text
+	}
+}
+
+// truncateGraphemes returns the first n grapheme clusters of the text, with the
+// number of the clusters cut if any. E.g. "abc… (+5 graphemes)".
+func truncateGraphemes(text string, n int) string {
+	rest := text
+	state := -1
+
+	for range n {
+		if rest == "" {
+			return text
+		}
+
+		_, rest, _, state = uniseg.FirstGraphemeClusterInString(rest, state)
+	}
+
+	if rest == "" {
+		return text
+	}
+
+	return fmt.Sprintf("%s… (+%d graphemes)", text[:len(text)-len(rest)], uniseg.GraphemeClusterCount(rest))
+}
+
+// userText is a text of the users, such as the input and the output of the
+// tools, in the arguments of debugLog and the other log functions. It is
+// redacted per GetLogRedact when logged.
+type userText string
+
+// LogValue returns the text redacted. It is an implementation of
+// slog.LogValuer.
+func (t userText) LogValue() slog.Value {
+	redaction, _ := GetLogRedact() // omits the texts if invalid, which is reported by loadSettings
+
+	return slog.StringValue(redaction.apply(string(t)))
+}
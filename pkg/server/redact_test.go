@@ -0,0 +1,94 @@
+package server
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  GetLogRedact
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func TestGetLogRedact(t *testing.T) {
+	for index, test := range []struct {
+		name    string
+		value   string
+		want    logRedaction
+		wantErr bool
+	}{
+		{"default", "", logRedaction{mode: redactNone, graphemes: 0}, false},
+		{"none", "none", logRedaction{mode: redactNone, graphemes: 0}, false},
+		{"omit", "omit", logRedaction{mode: redactOmit, graphemes: 0}, false},
+		{"hash", " Hash ", logRedaction{mode: redactHash, graphemes: 0}, false},
+		{"truncate", "truncate:16", logRedaction{mode: redactTruncate, graphemes: 16}, false},
+		{"truncate_zero", "truncate:0", logRedaction{mode: redactTruncate, graphemes: 0}, false},
+		{"truncate_without_count", "truncate", logRedaction{mode: redactOmit, graphemes: 0}, true},
+		{"truncate_negative", "truncate:-1", logRedaction{mode: redactOmit, graphemes: 0}, true},
+		{"hash_with_count", "hash:8", logRedaction{mode: redactOmit, graphemes: 0}, true},
+		{"unknown", "mask", logRedaction{mode: redactOmit, graphemes: 0}, true},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		setEnv(t, envNameLogRedact, test.value)
+
+		got, err := GetLogRedact()
+		if test.wantErr {
+			require.ErrorIs(t, err, errInvalidRedact, name)
+		} else {
+			require.NoError(t, err, name)
+		}
+
+		require.Equal(t, test.want, got, name)
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  logRedaction.apply
+// ----------------------------------------------------------------------------
+
+func Test_logRedaction_apply(t *testing.T) {
+	t.Parallel()
+
+	for index, test := range []struct {
+		name      string
+		redaction logRedaction
+		text      string
+		want      string
+	}{
+		{"none", logRedaction{mode: redactNone, graphemes: 0}, "my secret", "my secret"},
+		{"omit", logRedaction{mode: redactOmit, graphemes: 0}, "my secret", redactOmitted},
+		{"hash", logRedaction{mode: redactHash, graphemes: 0}, "my secret", "sha256:b9d1d013f600ec1b"},
+		{"truncate", logRedaction{mode: redactTruncate, graphemes: 5}, "my secret", "my se… (+4 graphemes)"},
+		{"truncate_clusters", logRedaction{mode: redactTruncate, graphemes: 2}, "👨‍👩‍👧éx🇯🇵", "👨‍👩‍👧é… (+2 graphemes)"},
+		{"truncate_short", logRedaction{mode: redactTruncate, graphemes: 9}, "my secret", "my secret"},
+		{"truncate_zero", logRedaction{mode: redactTruncate, graphemes: 0}, "abc", "… (+3 graphemes)"},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		require.Equal(t, test.want, test.redaction.apply(test.text), name)
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  userText
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_userText(t *testing.T) {
+	setEnv(t, envNameLogRedact, redactOmit)
+	require.Equal(t, "text mirrored text=[redacted] mirrored=[redacted] input_size=3",
+		logEntry("text mirrored", logKeyText, userText("abc"), logKeyMirrored, userText("cba"), logKeyInputSize, 3))
+
+	setEnv(t, envNameLogRedact, "truncate:1")
+	require.Equal(t, `text mirrored text="a… (+2 graphemes)"`, logEntry("text mirrored", logKeyText, userText("abc")))
+
+	setEnv(t, envNameLogRedact, "mask")
+	require.Equal(t, "text mirrored text=[redacted]", logEntry("text mirrored", logKeyText, userText("abc")),
+		"invalid redaction should omit the texts")
+
+	unsetEnv(t, envNameLogRedact)
+	require.Equal(t, "text mirrored text=abc", logEntry("text mirrored", logKeyText, userText("abc")))
+}
@@ -0,0 +1,258 @@
+package server
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Tool is a built-in tool of this server. To add a tool, implement Tool and
+// list it in toolRegistry: it is then registered on startup, subject to the
+// allowlist and the denylist, and can be enabled or disabled with the admin
+// tool like the others.
+type Tool interface {
+	// Name returns the name of the tool, unique in the server.
+	Name() string
+	// Description returns the description of the tool for the clients.
+	Description() string
+	// Schema returns the JSON schemas of the arguments and the structured
+	// result of the tool. A nil output is inferred from the type of the result.
+	Schema() (input, output *jsonschema.Schema)
+	// Annotations returns the hints of the behavior of the tool, or nil.
+	Annotations() *mcp.ToolAnnotations
+	// Handler returns the handler of the calls of the tool. It is called once
+	// the tool is first added with the state of the server, for the tools
+	// reporting or changing it.
+	Handler(state *serverState) ToolHandler
+}
+
+// gatedTool is a Tool added only while its setting enables it, such as the
+// admin tool. serverState.apply adds or removes it on startup and on reload,
+// instead of the toolSet, so that it can't be enabled or disabled at runtime.
+type gatedTool interface {
+	Tool
+	// gateEnabled returns whether the setting of the tool enables it.
+	gateEnabled() (bool, error)
+}
+
+// versionedTool is a Tool served under its versioned name as well, such as
+// mirror.v1, so that prompts can pin its behavior. The unversioned name follows
+// the latest version: once the behavior changes, the previous version stays in
+// toolRegistry as a deprecatedTool named after its version only.
+type versionedTool interface {
+	Tool
+	// version returns the version of the behavior of the tool, from 1.
+	version() int
+}
+
+// deprecatedTool is a Tool still served but to be replaced, such as a previous
+// version of a tool. The deprecation is in its description and the _meta of its
+// definition, and its calls are logged as warnings.
+type deprecatedTool interface {
+	Tool
+	// deprecation returns what replaces the tool.
+	deprecation() ToolDeprecation
+}
+
+// _meta keys of the tool definitions.
+const (
+	metaKeyToolVersion = "text-mirror/version"    // version of the behavior of a versionedTool
+	metaKeyDeprecated  = "text-mirror/deprecated" // ToolDeprecation of a deprecatedTool
+	toolVersionSep     = ".v"                     // separates the name and the version, e.g. mirror.v1
+)
+
+// ToolDeprecation is the deprecation notice of a deprecatedTool.
+type ToolDeprecation struct {
+	Replacement string `json:"replacement"`      // name of the tool to use instead, e.g. mirror.v2
+	Reason      string `json:"reason,omitempty"` // change of the behavior in the replacement
+	Sunset      string `json:"sunset,omitempty"` // planned removal date, YYYY-MM-DD, if any
+}
+
+// versionAlias is the versioned name of a versionedTool, e.g. mirror.v1 of the
+// mirror tool, with the same schemas and handler.
+type versionAlias struct {
+	versionedTool
+}
+
+// Name returns the name of the tool suffixed with its version.
+func (a versionAlias) Name() string {
+	return a.versionedTool.Name() + toolVersionSep + strconv.Itoa(a.version())
+}
+
+// withVersions returns the tools, each followed by its versioned name if
+// versioned.
+func withVersions(tools []Tool) []Tool {
+	all := make([]Tool, 0, len(tools))
+
+	for _, tool := range tools {
+		all = append(all, tool)
+
+		if versioned, ok := tool.(versionedTool); ok {
+			all = append(all, versionAlias{versioned})
+		}
+	}
+
+	return all
+}
+
+// ToolHandler is the handler of the calls of a Tool. Make it with TypedHandler.
+type ToolHandler interface {
+	// add adds the tool with the handler to the server.
+	add(server *mcp.Server, tool *mcp.Tool)
+}
+
+// typedHandler is a ToolHandler taking typed arguments.
+type typedHandler[In, Out any] mcp.ToolHandlerFor[In, Out]
+
+// TypedHandler returns the ToolHandler of the typed handler. The arguments and
+// the result are validated against the schemas of the tool by the SDK, as with
+// mcp.AddTool.
+func TypedHandler[In, Out any](handler mcp.ToolHandlerFor[In, Out]) ToolHandler {
+	return typedHandler[In, Out](handler)
+}
+
+// add adds the tool with the handler to the server. The calls of deprecated
+// tools are logged as warnings, to find the clients still using them.
+func (h typedHandler[In, Out]) add(server *mcp.Server, tool *mcp.Tool) {
+	handler := mcp.ToolHandlerFor[In, Out](h)
+
+	if _, deprecated := tool.Meta[metaKeyDeprecated]; deprecated {
+		handler = func(ctx context.Context, req *mcp.CallToolRequest, input In) (*mcp.CallToolResult, Out, error) {
+			warnLog(ctx, "deprecated tool called", callLogAttrs(ctx, req, "replacement", tool.Meta[metaKeyDeprecated])...)
+
+			return h(ctx, req, input)
+		}
+	}
+
+	mcp.AddTool(server, tool, handler)
+}
+
+// rawHandler is a ToolHandler taking the arguments as is, such as the ones of
+// the tools of the upstream servers and the plugins, which are forwarded.
+type rawHandler mcp.ToolHandler
+
+// add adds the tool with the handler to the server.
+func (h rawHandler) add(server *mcp.Server, tool *mcp.Tool) {
+	server.AddTool(tool, mcp.ToolHandler(h))
+}
+
+// toolRegistry are the built-in tools of this server.
+//
+//nolint:gochecknoglobals // read-only table
+var toolRegistry = []Tool{
+	mirrorTool{},
+	batchTool{},
+	pipelineTool{},
+	transformTool{},
+	wordsTool{},
+	linesTool{},
+	eachLineTool{},
+	textStatsTool{},
+	normalizeTool{},
+	statsTool{},
+	adminTool{},
+}
+
+// builtinTools are the names of the tools of this server, including the
+// versioned ones. The tools of the upstream servers and the plugins can't take
+// these names.
+//
+//nolint:gochecknoglobals // read-only table
+var builtinTools = toolNames(withVersions(toolRegistry))
+
+// readOnlyAnnotations returns the hints of the tools which neither change the
+// server nor reach outside of it, such as the mirror tool.
+func readOnlyAnnotations() *mcp.ToolAnnotations {
+	annotations := new(mcp.ToolAnnotations)
+	annotations.ReadOnlyHint = true
+	annotations.IdempotentHint = true
+	annotations.OpenWorldHint = new(bool)
+
+	return annotations
+}
+
+// toolNames returns the names of the tools.
+func toolNames(tools []Tool) []string {
+	names := make([]string, 0, len(tools))
+	for _, tool := range tools {
+		names = append(names, tool.Name())
+	}
+
+	return names
+}
+
+// toolInfo returns the definition of the tool listed in tools/list.
+func toolInfo(tool Tool) *mcp.Tool {
+	// Initialize with zero values then set required fields (avoid exhaustruct
+	// linter error)
+	info := new(mcp.Tool)
+	info.Name = tool.Name()
+	info.Description = tool.Description()
+	info.Annotations = tool.Annotations()
+
+	input, output := tool.Schema()
+	info.InputSchema = input
+
+	if output != nil {
+		info.OutputSchema = output
+	}
+
+	if versioned, ok := tool.(versionedTool); ok {
+		info.Meta = mcp.Meta{metaKeyToolVersion: versioned.version()}
+	}
+
+	if deprecated, ok := tool.(deprecatedTool); ok {
+		notice := deprecated.deprecation()
+		info.Description = "Deprecated: use " + notice.Replacement + " instead. " + info.Description
+
+		if info.Meta == nil {
+			info.Meta = make(mcp.Meta)
+		}
+
+		info.Meta[metaKeyDeprecated] = notice
+	}
+
+	return info
+}
+
+// registerTools adds the tools of toolRegistry and their versioned names to
+// the toolSet of the server, except the gated ones added by apply. The tools
+// are built once allowed by the configuration, see toolSet.registerLazy.
+func (s *serverState) registerTools() {
+	for _, tool := range withVersions(toolRegistry) {
+		if _, gated := tool.(gatedTool); gated {
+			continue
+		}
+
+		s.tools.registerLazy(tool.Name(), func() (*mcp.Tool, ToolHandler) {
+			return toolInfo(tool), tool.Handler(s)
+		})
+	}
+}
+
+// applyGatedTools adds or removes the gated tools of toolRegistry as their
+// settings enable them. Tools not allowed by the configuration are not added.
+// It must be called with s.mu held.
+func (s *serverState) applyGatedTools() {
+	for _, tool := range toolRegistry {
+		gated, ok := tool.(gatedTool)
+		if !ok {
+			continue
+		}
+
+		name := tool.Name()
+		enabled, err := gated.gateEnabled()
+		enabled = err == nil && enabled && s.tools.isAllowed(name)
+
+		switch {
+		case enabled && !s.gatedAdded[name]:
+			tool.Handler(s).add(s.server, toolInfo(tool))
+		case !enabled && s.gatedAdded[name]:
+			s.server.RemoveTools(name)
+		}
+
+		s.gatedAdded[name] = enabled
+	}
+}
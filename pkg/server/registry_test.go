@@ -0,0 +1,193 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// mirrorV1Name is the versioned name of the mirror tool.
+const mirrorV1Name = toolName + toolVersionSep + "1"
+
+// defaultTools returns the names of the built-in tools registered by default,
+// i.e. all but the gatedTool, without the given ones. They are in the order of
+// toolRegistry, followed each by its versioned name.
+func defaultTools(except ...string) []string {
+	names := make([]string, 0, len(builtinTools))
+
+	for _, tool := range withVersions(toolRegistry) {
+		if _, gated := tool.(gatedTool); !gated && !slices.Contains(except, tool.Name()) {
+			names = append(names, tool.Name())
+		}
+	}
+
+	return names
+}
+
+// sortedNames returns the names sorted as the tools are listed by tools/list, the
+// stats and the admin tools.
+func sortedNames(names ...string) []string {
+	return slices.Sorted(slices.Values(names))
+}
+
+// ----------------------------------------------------------------------------
+//  toolRegistry
+// ----------------------------------------------------------------------------
+
+func Test_toolRegistry(t *testing.T) {
+	t.Parallel()
+
+	require.Len(t, builtinTools, len(slices.Compact(sortedNames(builtinTools...))), "names should be unique")
+	require.Contains(t, builtinTools, mirrorV1Name, "versioned names should be included")
+	require.Equal(t, append(defaultTools(), adminToolName), builtinTools, "the admin tool should be the only gated one")
+
+	for index, tool := range toolRegistry {
+		name := fmt.Sprintf("Test #%d: %s", index+1, tool.Name())
+
+		info := toolInfo(tool)
+		require.Equal(t, tool.Name(), info.Name, name)
+		require.NotEmpty(t, info.Description, name)
+		require.NotNil(t, info.Annotations, name)
+
+		input, ok := info.InputSchema.(*jsonschema.Schema)
+		require.True(t, ok, name)
+		require.Equal(t, "object", input.Type, name)
+
+		_, output := tool.Schema()
+		if output == nil {
+			require.Nil(t, info.OutputSchema, "nil output schema should be inferred by the SDK: %s", name)
+		}
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  registerTools and applyGatedTools
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_registerTools(t *testing.T) {
+	unsetEnv(t, envNameToolsEnabled, envNameToolsDisabled)
+
+	listed := func(session *mcp.ClientSession) map[string]*mcp.Tool {
+		t.Helper()
+
+		tools := make(map[string]*mcp.Tool)
+
+		for tool, err := range session.Tools(context.Background(), nil) {
+			require.NoError(t, err)
+
+			tools[tool.Name] = tool
+		}
+
+		return tools
+	}
+
+	// The gated admin tool is added only if enabled
+	setEnv(t, envNameAdmin, "false")
+
+	state := newServerState()
+	tools := listed(connectInMemory(t, state.server))
+	require.Len(t, tools, len(builtinTools)-1)
+	require.NotContains(t, tools, adminToolName)
+
+	mirrorInfo := tools[toolName]
+	require.NotNil(t, mirrorInfo)
+	require.NotNil(t, mirrorInfo.Annotations)
+	require.True(t, mirrorInfo.Annotations.ReadOnlyHint)
+	require.NotNil(t, mirrorInfo.OutputSchema, "output schema should be inferred or given")
+	require.NotNil(t, tools[statsToolName].OutputSchema, "output schema should be inferred from StatsReport")
+
+	setEnv(t, envNameAdmin, "true")
+	state.apply()
+
+	tools = listed(connectInMemory(t, state.server))
+	require.Contains(t, tools, adminToolName)
+	require.False(t, tools[adminToolName].Annotations.ReadOnlyHint)
+
+	// Removed once disabled on reload
+	setEnv(t, envNameAdmin, "false")
+	state.apply()
+	require.NotContains(t, listed(connectInMemory(t, state.server)), adminToolName)
+}
+
+// ----------------------------------------------------------------------------
+//  versionedTool and deprecatedTool
+// ----------------------------------------------------------------------------
+
+// legacyMirrorTool is a deprecated previous version of the mirror tool.
+type legacyMirrorTool struct{ mirrorTool }
+
+func (legacyMirrorTool) Name() string { return toolName + toolVersionSep + "0" }
+
+func (legacyMirrorTool) deprecation() ToolDeprecation {
+	return ToolDeprecation{Replacement: mirrorV1Name, Reason: "test", Sunset: "2030-01-02"}
+}
+
+func Test_withVersions(t *testing.T) {
+	t.Parallel()
+
+	tools := withVersions([]Tool{batchTool{}, mirrorTool{}})
+	require.Equal(t, []string{batchToolName, toolName, mirrorV1Name}, toolNames(tools))
+
+	for _, tool := range tools[1:] {
+		info := toolInfo(tool)
+		require.Equal(t, mcp.Meta{metaKeyToolVersion: 1}, info.Meta, tool.Name())
+		require.Equal(t, toolDescription, info.Description, tool.Name())
+	}
+
+	require.Nil(t, toolInfo(tools[0]).Meta, "unversioned tools should have no version")
+}
+
+func Test_registerTools_versioned(t *testing.T) {
+	t.Parallel()
+
+	session := connectInMemory(t, newServer())
+
+	for _, name := range []string{toolName, mirrorV1Name} {
+		res := callTool(t, session, name, map[string]any{"text": "a👍🏽é"})
+		require.False(t, res.IsError, name)
+		require.Equal(t, map[string]any{"text": "é👍🏽a"}, res.StructuredContent, name)
+	}
+}
+
+//nolint:paralleltest // sets env var
+func Test_toolInfo_deprecated(t *testing.T) {
+	setEnv(t, envNameLogLevel, logLevelInfo)
+
+	var logged []string
+
+	logger := mockLogger(func(entry string) { logged = append(logged, entry) })
+
+	tool := legacyMirrorTool{}
+
+	info := toolInfo(tool)
+	require.Equal(t, "Deprecated: use mirror.v1 instead. "+toolDescription, info.Description)
+	require.Equal(t, ToolDeprecation{Replacement: mirrorV1Name, Reason: "test", Sunset: "2030-01-02"},
+		info.Meta[metaKeyDeprecated])
+	require.Equal(t, 1, info.Meta[metaKeyToolVersion], "the version should be of the embedded tool")
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v0.0.1"}, nil)
+	server.AddReceivingMiddleware(loggerMiddleware(logger))
+	tool.Handler(nil).add(server, info)
+
+	session := connectInMemory(t, server)
+
+	listed, err := session.ListTools(context.Background(), nil)
+	require.NoError(t, err)
+	require.Len(t, listed.Tools, 1)
+	require.Equal(t, map[string]any{"replacement": mirrorV1Name, "reason": "test", "sunset": "2030-01-02"},
+		listed.Tools[0].Meta[metaKeyDeprecated], "the deprecation should be in the _meta of tools/list")
+
+	res := callTool(t, session, tool.Name(), map[string]any{"text": "ab"})
+	require.False(t, res.IsError)
+	require.Equal(t, map[string]any{"text": "ba"}, res.StructuredContent)
+
+	require.Len(t, logged, 1)
+	require.Contains(t, logged[0], "deprecated tool called")
+	require.Contains(t, logged[0], "replacement=")
+}
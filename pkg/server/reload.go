@@ -0,0 +1,266 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"os"
+	"os/signal"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// restartSettings are the environment variables of the settings read only at
+// startup. Changing them in the config file takes effect after a restart.
+//
+//nolint:gochecknoglobals // read-only table
+var restartSettings = []string{
+	envNameHTTPAddr, envNameTLSCert, envNameTLSKey, envNameTLSClientCA,
+	envNameAllowedOrigins, envNameCORSHeaders, envNameKeepAlive, envNameIdleTimeout, envNameMaxBody, envNameDebugHTTP,
+	envNamePageSize, envNameUpstreams, envNamePlugins, envNameWireTap, envNameStatsd, envNameInstances, envNameLogFormat,
+}
+
+// ----------------------------------------------------------------------------
+//  serverState
+// ----------------------------------------------------------------------------
+
+// serverState is the server with its parts which follow the settings on
+// reload: the tools allowed, the admin tool, the default arguments and the
+// limits of the tool calls.
+//
+// The other settings are either read on each call, such as the client limits
+// and the _meta keys, or only at startup (see restartSettings).
+type serverState struct {
+	server     *mcp.Server
+	logger     *slog.Logger // logger of the requests and the parts of the server
+	tools      *toolSet
+	defaults   *toolDefaults
+	limits     *limitSet
+	stats      *callStats
+	cache      *resultCache
+	gatedAdded map[string]bool // gated tools added by name
+	fixedTools []string        // built-in tools given to New. nil follows the configured filter
+	instances  []*serverState  // server instances served along with the server, applied with it
+	mu         sync.Mutex
+}
+
+// apply applies the settings in the environment variables to the server and
+// its instances. Invalid settings are reported by loadSettings beforehand.
+func (s *serverState) apply() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.fixedTools != nil {
+		s.tools.setAllowed(allowOnly(s.fixedTools))
+	} else if allowed, err := GetToolFilter(); err == nil {
+		s.tools.setAllowed(allowed)
+	}
+
+	// Gated tools, such as the admin tool, if enabled.
+	s.applyGatedTools()
+
+	s.defaults.load()
+	s.limits.load()
+	s.cache.load()
+
+	for _, instance := range s.instances {
+		instance.apply()
+	}
+}
+
+// addInstance adds the state of a server instance, to be applied along with
+// the server.
+func (s *serverState) addInstance(instance *serverState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.instances = append(s.instances, instance)
+}
+
+// ----------------------------------------------------------------------------
+//  limitSet
+// ----------------------------------------------------------------------------
+
+// limitSet applies the rate limit, the memory budget, the worker pool and the
+// timeout to the tool calls. They are replaced as a whole on reload, so that the
+// calls in progress finish with the previous limits and the new calls get the
+// new ones.
+type limitSet struct {
+	middlewares atomic.Pointer[[]mcp.Middleware]
+	fixed       *Limits // limits given to New. nil loads the configured ones
+}
+
+// load replaces the limits with the configured ones, or the fixed ones if
+// given. The counters, such as the rate limit buckets, start over.
+func (l *limitSet) load() {
+	limits := l.fixed
+	if limits == nil {
+		limits = configuredLimits()
+	}
+
+	var middlewares []mcp.Middleware
+
+	// Reject tool calls exceeding the per client rate limit, if configured.
+	if limits.RateLimit > 0 {
+		burst := limits.RateBurst
+		if burst <= 0 {
+			burst = int(max(1, math.Ceil(limits.RateLimit)))
+		}
+
+		middlewares = append(middlewares, newRateLimiter(limits.RateLimit, burst).middleware)
+	}
+
+	// Bound the input bytes of the tool calls in progress, including the ones
+	// waiting for a worker, since they are held in memory already.
+	if limits.MemoryBudget > 0 {
+		middlewares = append(middlewares, newMemoryBudget(limits.MemoryBudget, limits.MemoryWait).middleware)
+	}
+
+	// Bound the concurrent tool calls.
+	if limits.Workers > 0 {
+		middlewares = append(middlewares, newWorkerPool(limits.Workers, max(0, limits.QueueDepth)).middleware)
+	}
+
+	// Bound the duration of the tool calls, once they got a worker.
+	if limits.CallTimeout > 0 {
+		middlewares = append(middlewares, newCallTimeout(limits.CallTimeout))
+	}
+
+	l.middlewares.Store(&middlewares)
+}
+
+// middleware runs the requests through the current limits, the first one being
+// the outermost.
+func (l *limitSet) middleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		handler := next
+
+		if middlewares := l.middlewares.Load(); middlewares != nil {
+			for _, middleware := range slices.Backward(*middlewares) {
+				handler = middleware(handler)
+			}
+		}
+
+		return handler(ctx, method, req)
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  configReloader
+// ----------------------------------------------------------------------------
+
+// configReloader reloads the config file into the loaded settings.
+type configReloader struct {
+	path string // config file given by --config. empty for the default one
+}
+
+// newConfigReloader returns the reloader of the config file at path.
+func newConfigReloader(path string) *configReloader {
+	reloader := new(configReloader)
+	reloader.path = path
+
+	return reloader
+}
+
+// reload re-reads the config file and replaces the values of the previous one
+// and of its profile in the loaded settings at once. The environment variables
+// and the flags still take precedence.
+//
+// If the new config is invalid, the previous values are kept and the error is
+// returned. Otherwise it returns the names of the settings read only at startup
+// which changed.
+func (r *configReloader) reload() ([]string, error) {
+	config, err := loadConfig(r.path)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := checkSettings(func(v *settingValues) *settingValues {
+		return v.withConfig(config.env())
+	})
+	if err != nil {
+		return nil, wrapError(err, "invalid configuration")
+	}
+
+	var needRestart []string
+
+	for _, name := range restartSettings {
+		if previous.values[name] != settingValue(name) {
+			needRestart = append(needRestart, name)
+		}
+	}
+
+	return needRestart, nil
+}
+
+// watchReload reloads the config file and applies it to the server on each
+// SIGHUP until the context is done. The MCP sessions are kept. Failures are
+// logged to the logger of the server and the previous settings are kept.
+//
+// SIGHUP is never sent on Windows, where the server has to be restarted.
+func watchReload(ctx context.Context, reloader *configReloader, state *serverState) {
+	ctx = withLogger(ctx, state.logger)
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+
+	go func() {
+		defer reportPanics(ctx)
+		defer signal.Stop(hangup)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hangup:
+				reloadServer(ctx, reloader, state)
+			}
+		}
+	}()
+}
+
+// reloadServer reloads the config file and applies it to the server state and
+// the states of its instances, logging to the logger of ctx.
+func reloadServer(ctx context.Context, reloader *configReloader, state *serverState) {
+	needRestart, err := reloader.reload()
+	if err != nil {
+		errorLog(ctx, "failed to reload the config file", logKeyError, err)
+
+		return
+	}
+
+	reopenLog(state.logger)
+	state.apply()
+
+	infoLog(ctx, "config reloaded")
+
+	for _, name := range needRestart {
+		warnLog(ctx, "setting changed, restart to apply", "env", name)
+	}
+}
+
+// reopenLog redirects the logger to the configured debug log, syslog and event
+// log, so that changes of MCP_TEXT_MIRROR_DEBUG_LOG, MCP_TEXT_MIRROR_SYSLOG and
+// MCP_TEXT_MIRROR_EVENT_LOG take effect without restart. The previous log file,
+// syslog connection and event log are closed. Loggers not made by newLogger,
+// such as the one given to WithLogger, are left as is.
+func reopenLog(logger *slog.Logger) {
+	handler, ok := logger.Handler().(*logHandler)
+	if !ok {
+		return
+	}
+
+	previous := handler.out.swap(logOutput(IsDebugMode(), GetLogPath()))
+	if previous != os.Stderr {
+		_ = previous.Close()
+	}
+
+	target, _ := GetSyslog() // invalid values are reported by loadSettings
+	handler.swapSyslog(newSyslogWriter(target))
+
+	eventLog, _ := GetEventLog() // invalid values are reported by loadSettings
+	handler.swapEventLog(newEventLogWriter(eventLog))
+}
@@ -0,0 +1,244 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  configReloader
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_configReloader_reload(t *testing.T) {
+	unsetEnv(t, envNameWorkers, envNameQueueDepth, envNamePageSize, envNameRateLimit)
+	setEnv(t, envNameQueueDepth, "8") // set by the environment, not the config file
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig := func(data string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+	}
+
+	writeConfig("limits:\n  workers: 4\n  rate_limit: 2\n")
+
+	config, err := loadConfig(path)
+	require.NoError(t, err)
+
+	keepSettings(t)
+	storeSettings(func(v *settingValues) *settingValues { return v.withConfig(config.env()) })
+
+	reloader := newConfigReloader(path)
+	require.Equal(t, "4", settingValue(envNameWorkers))
+
+	// Changed, removed and added settings
+	writeConfig("limits:\n  workers: 2\n  queue_depth: 16\n  page_size: 10\n")
+
+	needRestart, err := reloader.reload()
+	require.NoError(t, err)
+	require.Equal(t, []string{envNamePageSize}, needRestart)
+	require.Equal(t, "2", settingValue(envNameWorkers))
+	require.Equal(t, "8", settingValue(envNameQueueDepth), "env var should still override the config file")
+
+	require.Empty(t, settingValue(envNameRateLimit), "settings removed from the config file should be unset")
+
+	// Invalid config keeps the previous settings
+	writeConfig("limits:\n  workers: -1\n")
+
+	_, err = reloader.reload()
+	require.ErrorIs(t, err, errInvalidNumber)
+	require.Equal(t, "2", settingValue(envNameWorkers))
+	require.Equal(t, "10", settingValue(envNamePageSize))
+
+	// Unreadable config
+	writeConfig("limits: [")
+
+	_, err = reloader.reload()
+	require.Error(t, err)
+	require.Equal(t, "2", settingValue(envNameWorkers))
+}
+
+// ----------------------------------------------------------------------------
+//  serverState
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_serverState_apply(t *testing.T) {
+	unsetEnv(t, envNameToolsEnabled, envNameToolsDisabled, envNameAdmin, envNameRateLimit)
+
+	state := newServerState()
+
+	changed := make(chan struct{}, 1)
+	options := new(mcp.ClientOptions)
+	options.ToolListChangedHandler = func(context.Context, *mcp.ToolListChangedRequest) {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+
+	serverSession, err := state.server.Connect(context.Background(), serverTransport, nil)
+	require.NoError(t, err)
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.0"}, options) //nolint:exhaustruct // minimal client
+
+	session, err := client.Connect(context.Background(), clientTransport, nil)
+	require.NoError(t, err)
+
+	defer func() {
+		_ = session.Close()
+		_ = serverSession.Wait()
+	}()
+
+	listed := func() []string {
+		t.Helper()
+
+		res, err := session.ListTools(context.Background(), nil)
+		require.NoError(t, err)
+
+		var names []string
+		for _, tool := range res.Tools {
+			names = append(names, tool.Name)
+		}
+
+		return names
+	}
+
+	require.Equal(t, sortedNames(defaultTools()...), listed())
+
+	for index, test := range []struct {
+		name     string
+		env      map[string]string
+		wantList []string
+	}{
+		{"disable_batch", map[string]string{envNameToolsDisabled: batchToolName}, sortedNames(defaultTools(batchToolName)...)},
+		{"enable_admin", map[string]string{envNameAdmin: "true"}, sortedNames(append(defaultTools(batchToolName), adminToolName)...)},
+		{"allow_batch_only", map[string]string{envNameToolsDisabled: "", envNameToolsEnabled: batchToolName}, []string{batchToolName}},
+		{"all", map[string]string{envNameToolsEnabled: "", envNameAdmin: "false"}, sortedNames(defaultTools()...)},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		for key, value := range test.env {
+			setEnv(t, key, value)
+		}
+
+		state.apply()
+
+		select {
+		case <-changed:
+		case <-time.After(timeoutEventually):
+			require.Fail(t, "tools/list_changed should be notified", name)
+		}
+
+		require.Equal(t, test.wantList, listed(), name)
+	}
+
+	// Limits follow the settings
+	setEnv(t, envNameRateLimit, "0.001")
+	setEnv(t, envNameRateBurst, "1")
+	state.apply()
+
+	args := map[string]any{"text": "abc"}
+	require.False(t, callTool(t, session, toolName, args).IsError)
+	require.True(t, callTool(t, session, toolName, args).IsError, "second call should be rate limited")
+
+	setEnv(t, envNameRateLimit, "")
+	state.apply()
+
+	require.False(t, callTool(t, session, toolName, args).IsError, "rate limit should be removed")
+}
+
+// ----------------------------------------------------------------------------
+//  watchReload
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var and sends SIGHUP
+func Test_watchReload(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("SIGHUP is not supported on Windows")
+	}
+
+	unsetEnv(t, envNameToolsDisabled, envNameDebug)
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("tools:\n  disabled: [mirror_batch]\n"), 0o600))
+
+	var failed atomic.Bool
+
+	logger := mockLogger(func(entry string) {
+		if strings.HasPrefix(entry, "failed to reload the config file error=") {
+			failed.Store(true)
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	state := newServerState(WithLogger(logger))
+	watchReload(ctx, newConfigReloader(configPath), state)
+
+	process, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+	require.NoError(t, process.Signal(syscall.SIGHUP))
+
+	require.Eventually(t, func() bool {
+		return !state.tools.enabled(batchToolName)
+	}, timeoutEventually, 10*time.Millisecond, "config should be reloaded on SIGHUP")
+	require.True(t, state.tools.enabled(toolName))
+
+	// Invalid config is logged and ignored
+	require.NoError(t, os.WriteFile(configPath, []byte("tools:\n  disabled: [mirorr]\n"), 0o600))
+	require.NoError(t, process.Signal(syscall.SIGHUP))
+
+	require.Eventually(t, failed.Load, timeoutEventually, 10*time.Millisecond, "reload failure should be logged")
+	require.False(t, state.tools.enabled(batchToolName), "previous config should be kept")
+}
+
+// ----------------------------------------------------------------------------
+//  reopenLog
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_reopenLog(t *testing.T) {
+	unsetEnv(t, envNameDebug)
+
+	logger := newLogger(false, "")
+
+	handler, ok := logger.Handler().(*logHandler)
+	require.True(t, ok)
+
+	logPath := filepath.Join(t.TempDir(), "text-mirror.log")
+	setEnv(t, envNameDebug, logPath)
+
+	reopenLog(logger)
+	debugLog(withLogger(context.Background(), logger), "reopened", "count", 1)
+
+	data, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "level=DEBUG msg=reopened count=1")
+
+	// Back to stderr, closing the log file
+	file := handler.out.file
+
+	unsetEnv(t, envNameDebug)
+	reopenLog(logger)
+
+	require.Equal(t, os.Stderr, handler.out.file)
+	require.ErrorIs(t, file.Close(), os.ErrClosed, "previous log file should be closed")
+
+	// Loggers not made by newLogger are kept as is
+	require.NotPanics(t, func() { reopenLog(mockLogger(func(string) {})) })
+}
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Rendering of the mirrored text as an image.
+const (
+	envNameRenderFont = envPrefix + "RENDER_FONT" // env var of the TrueType/OpenType font file to render with
+
+	renderPNG       = "png"       // value of the render argument for PNG images
+	renderMIME      = "image/png" // MIME type of the rendered image
+	renderFontSize  = 16          // font size in points at 72 DPI, i.e. in pixels
+	renderPadding   = 8           // margin around the text in pixels
+	renderMaxWidth  = 1200        // max width of the image in pixels. Longer lines are clipped
+	renderMaxLines  = 40          // max number of lines rendered. The rest is dropped
+	renderTabSpaces = "    "      // tabs are rendered as spaces, since fonts have no glyph for them
+)
+
+// GetRenderFont returns the font to render the mirrored text with, from
+// 'MCP_TEXT_MIRROR_RENDER_FONT' environment variable. It defaults to the Go
+// Regular font, which covers Latin, Greek and Cyrillic scripts only.
+//
+// Set it to a font covering the scripts to check, such as Noto Sans Arabic,
+// since the characters missing in the font are rendered as boxes.
+func GetRenderFont() (*opentype.Font, error) {
+	return loadedSettings().renderFont()
+}
+
+// renderFont returns GetRenderFont of the settings.
+func (v *settingValues) renderFont() (*opentype.Font, error) {
+	data := goregular.TTF
+
+	if path := v.value(envNameRenderFont); path != "" {
+		var err error
+
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return nil, wrapError(err, "failed to read %s", envNameRenderFont)
+		}
+	}
+
+	parsed, err := opentype.Parse(data)
+	if err != nil {
+		return nil, wrapError(err, "failed to parse the font of %s", envNameRenderFont)
+	}
+
+	return parsed, nil
+}
+
+// renderImage rasterizes the text into a PNG image content, black on white.
+//
+// The graphemes are drawn in their order in the string from left to right,
+// without bidi reordering nor shaping, so that the image shows how the mirrored
+// text is actually stored.
+func renderImage(text string) (*mcp.ImageContent, error) {
+	textFont, err := GetRenderFont()
+	if err != nil {
+		return nil, err
+	}
+
+	//nolint:exhaustruct // defaults for the rest
+	face, err := opentype.NewFace(textFont, &opentype.FaceOptions{
+		Size:    renderFontSize,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, wrapError(err, "failed to create the font face")
+	}
+
+	defer face.Close()
+
+	lines := strings.Split(strings.ReplaceAll(text, "\t", renderTabSpaces), "\n")
+	if len(lines) > renderMaxLines {
+		lines = lines[:renderMaxLines]
+	}
+
+	width := 0
+	for index, line := range lines {
+		lines[index] = strings.TrimSuffix(line, "\r")
+		width = max(width, font.MeasureString(face, lines[index]).Ceil())
+	}
+
+	metrics := face.Metrics()
+	lineHeight := metrics.Height.Ceil()
+	bounds := image.Rect(0, 0,
+		min(width+2*renderPadding, renderMaxWidth),
+		len(lines)*lineHeight+2*renderPadding)
+
+	img := image.NewRGBA(bounds)
+	draw.Draw(img, bounds, image.White, image.Point{}, draw.Src)
+
+	drawer := new(font.Drawer)
+	drawer.Dst = img
+	drawer.Src = image.NewUniform(color.Black)
+	drawer.Face = face
+
+	for index, line := range lines {
+		drawer.Dot = fixed.P(renderPadding, renderPadding+index*lineHeight+metrics.Ascent.Ceil())
+		drawer.DrawString(line)
+	}
+
+	var buf bytes.Buffer
+
+	err = png.Encode(&buf, img)
+	if err != nil {
+		return nil, wrapError(err, "failed to encode the image")
+	}
+
+	content := new(mcp.ImageContent)
+	content.Data = buf.Bytes()
+	content.MIMEType = renderMIME
+
+	return content, nil
+}
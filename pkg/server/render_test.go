@@ -0,0 +1,108 @@
+package server
+
+import (
+	"bytes"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/font/gofont/gomono"
+)
+
+// ----------------------------------------------------------------------------
+//  GetRenderFont
+// ----------------------------------------------------------------------------
+
+func Test_GetRenderFont(t *testing.T) {
+	setEnv(t, envNameRenderFont, "")
+
+	textFont, err := GetRenderFont()
+	require.NoError(t, err)
+	require.NotNil(t, textFont, "Go Regular font should be used by default")
+
+	path := filepath.Join(t.TempDir(), "mono.ttf")
+	require.NoError(t, os.WriteFile(path, gomono.TTF, 0o600))
+	setEnv(t, envNameRenderFont, path)
+
+	textFont, err = GetRenderFont()
+	require.NoError(t, err)
+	require.NotNil(t, textFont)
+}
+
+//nolint:paralleltest // sets env var
+func Test_run_invalid_render_font(t *testing.T) {
+	notFont := filepath.Join(t.TempDir(), "font.txt")
+	require.NoError(t, os.WriteFile(notFont, []byte("not a font"), 0o600))
+
+	for _, path := range []string{notFont, filepath.Join(t.TempDir(), "missing.ttf")} {
+		setEnv(t, envNameRenderFont, path)
+
+		err := newApp().serve(t.Context(), nil)
+		require.ErrorContains(t, err, envNameRenderFont)
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  renderImage
+// ----------------------------------------------------------------------------
+
+func Test_renderImage(t *testing.T) {
+	t.Parallel()
+
+	content, err := renderImage("olleH\r\n\tdlroW")
+	require.NoError(t, err)
+	require.Equal(t, renderMIME, content.MIMEType)
+
+	img, err := png.Decode(bytes.NewReader(content.Data))
+	require.NoError(t, err)
+
+	lineHeight := (img.Bounds().Dy() - 2*renderPadding) / 2 // two lines
+	require.Positive(t, lineHeight)
+	require.Less(t, img.Bounds().Dx(), renderMaxWidth)
+
+	// Long and many lines are clipped
+	content, err = renderImage(strings.Repeat(strings.Repeat("W", 500)+"\n", 2*renderMaxLines))
+	require.NoError(t, err)
+
+	img, err = png.Decode(bytes.NewReader(content.Data))
+	require.NoError(t, err)
+	require.Equal(t, renderMaxWidth, img.Bounds().Dx())
+	require.Equal(t, renderMaxLines*lineHeight+2*renderPadding, img.Bounds().Dy())
+}
+
+//nolint:paralleltest // sets env var
+func Test_renderImage_invalid_font(t *testing.T) {
+	setEnv(t, envNameRenderFont, filepath.Join(t.TempDir(), "missing.ttf"))
+
+	_, err := renderImage("abc")
+	require.Error(t, err)
+}
+
+// ----------------------------------------------------------------------------
+//  render argument of the mirror tool
+// ----------------------------------------------------------------------------
+
+func Test_handleReverse_render(t *testing.T) {
+	t.Parallel()
+
+	session := connectInMemory(t, newServer())
+
+	res := callTool(t, session, toolName, map[string]any{"text": "Hello", "render": renderPNG})
+	require.False(t, res.IsError, res.Content)
+	require.Len(t, res.Content, 2, "content should be the text followed by the image")
+	require.Equal(t, &mcp.TextContent{Text: "olleH"}, res.Content[0]) //nolint:exhaustruct // text only
+
+	image, ok := res.Content[1].(*mcp.ImageContent)
+	require.True(t, ok)
+	require.Equal(t, renderMIME, image.MIMEType)
+
+	_, err := png.Decode(bytes.NewReader(image.Data))
+	require.NoError(t, err, "image should be a valid PNG")
+
+	res = callTool(t, session, toolName, map[string]any{"text": "Hello"})
+	require.Len(t, res.Content, 1, "image should be rendered only if asked")
+}
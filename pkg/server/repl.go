@@ -0,0 +1,119 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Interactive mode. E.g.: text-mirror repl, then `mirror {"text": "abc"}`
+const (
+	cmdNameREPL = "repl"
+
+	replPrompt    = "> "
+	replMaxLine   = 1024 * 1024 // max bytes of an input line
+	replCmdTools  = "tools"     // lists the tools
+	replCmdHelp   = "help"      // prints the usage
+	replCmdExit   = "exit"      // quits. as well as EOF (Ctrl+D)
+	replUsageText = `Type a tool name followed by its JSON arguments, e.g. mirror {"text": "abc"}.
+Commands: tools (list the tools), help, exit (or Ctrl+D).
+`
+)
+
+// errJSONArguments is returned if the arguments of a tool call given in JSON
+// are not an object.
+var errJSONArguments = errors.New("arguments must be a JSON object")
+
+// runREPL is the "repl" subcommand, which calls the tools of the server as
+// configured from the lines read from in, and pretty-prints the results to out,
+// without an external MCP client. It returns at EOF or on "exit".
+func runREPL(ctx context.Context, in io.Reader, out io.Writer) error {
+	err := loadSettings()
+	if err != nil {
+		return wrapError(err, "invalid configuration")
+	}
+
+	session, closeSession, err := connectInProcess(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer closeSession()
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(nil, replMaxLine)
+
+	_, _ = io.WriteString(out, replUsageText+replPrompt)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch line {
+		case "":
+		case replCmdExit:
+			return nil
+		case replCmdHelp:
+			_, _ = io.WriteString(out, replUsageText)
+		case replCmdTools:
+			for tool, err := range session.Tools(ctx, nil) {
+				if err != nil {
+					_, _ = fmt.Fprintln(out, "error:", err)
+
+					break
+				}
+
+				_, _ = fmt.Fprintf(out, "%s: %s\n", tool.Name, tool.Description)
+			}
+		default:
+			err = replCall(ctx, session, line, out)
+			if err != nil {
+				_, _ = fmt.Fprintln(out, "error:", err)
+			}
+		}
+
+		_, _ = io.WriteString(out, replPrompt)
+	}
+
+	_, _ = io.WriteString(out, "\n") // after the last prompt
+
+	return wrapError(scanner.Err(), "failed to read the input")
+}
+
+// replCall calls the tool of the line in "name {json arguments}" form, and
+// prints the result as indented JSON to out.
+func replCall(ctx context.Context, session *mcp.ClientSession, line string, out io.Writer) error {
+	name, rawArgs, _ := strings.Cut(line, " ")
+
+	res, err := callToolJSON(ctx, session, name, rawArgs)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(out, res)
+}
+
+// callToolJSON calls the tool with the arguments in JSON. Empty arguments are
+// omitted.
+func callToolJSON(ctx context.Context, session *mcp.ClientSession, name, rawArgs string) (*mcp.CallToolResult, error) {
+	params := new(mcp.CallToolParams)
+	params.Name = name
+
+	if rawArgs = strings.TrimSpace(rawArgs); rawArgs != "" {
+		var args map[string]any
+
+		err := json.Unmarshal([]byte(rawArgs), &args)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errJSONArguments, err)
+		}
+
+		params.Arguments = args
+	}
+
+	return session.CallTool(ctx, params)
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  runREPL
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_runCommand_repl(t *testing.T) {
+	unsetEnv(t, envNameToolsEnabled, envNameToolsDisabled, envNameAdmin, envNameProfile)
+
+	var out bytes.Buffer
+
+	app := newTestApp(t)
+	app.Stdin = strings.NewReader(strings.Join([]string{
+		replCmdTools,
+		`mirror {"text": "a👍🏽b"}`,
+		"",
+		`mirror {"text": `,
+		`mirror ["abc"]`,
+		`nope {}`,
+		replCmdExit,
+		`mirror {"text": "not called"}`,
+	}, "\n"))
+	app.Stdout = &out
+
+	err := app.Run(context.Background(), []string{cmdNameREPL})
+	require.NoError(t, err)
+
+	output := out.String()
+	require.True(t, strings.HasPrefix(output, replUsageText+replPrompt), "usage should be printed first")
+	require.Contains(t, output, "mirror: "+toolDescription+"\n", "tools should be listed")
+	require.Contains(t, output, "\"text\": \"b👍🏽a\"", "result should be pretty-printed")
+	require.Equal(t, 2, strings.Count(output, "error: "+errJSONArguments.Error()), "invalid arguments should be reported")
+	require.Contains(t, output, `error: calling "tools/call": unknown tool "nope"`)
+	require.NotContains(t, output, "not called", "exit should quit")
+}
+
+//nolint:paralleltest // sets env var
+func Test_runREPL_eof(t *testing.T) {
+	unsetEnv(t, envNameToolsEnabled, envNameToolsDisabled)
+
+	var out bytes.Buffer
+
+	err := runREPL(context.Background(), strings.NewReader(`mirror {"text": "abc"}`), &out)
+	require.NoError(t, err)
+	require.Contains(t, out.String(), `"text": "cba"`)
+	require.True(t, strings.HasSuffix(out.String(), replPrompt+"\n"), "EOF should end the line of the prompt")
+}
+
+//nolint:paralleltest // sets env var
+func Test_runREPL_invalid_configuration(t *testing.T) {
+	setEnv(t, envNameToolsDisabled, "mirorr")
+
+	err := runREPL(context.Background(), strings.NewReader(""), new(bytes.Buffer))
+	require.ErrorIs(t, err, errUnknownTool)
+}
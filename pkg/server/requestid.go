@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Request IDs of the tool calls.
+//
+// E.g.: time=... level=DEBUG msg="text mirrored" request_id=PL3GXQ2N4ZR5W7YHDKMBT6CVEA tool=mirror
+const (
+	requestIDMetaKey = "requestId" // _meta key of the request ID in the tool results
+)
+
+// requestIDKey is the context key of the request ID of the tool call.
+type requestIDKey struct{}
+
+// newRequestID returns a new random request ID, 26 characters of base32 with
+// 128 bits of randomness, so that IDs of concurrent clients don't collide.
+func newRequestID() string {
+	return rand.Text()
+}
+
+// requestID returns the request ID of the tool call of ctx, or an empty string
+// outside of tool calls.
+func requestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	id, _ := ctx.Value(requestIDKey{}).(string)
+
+	return id
+}
+
+// requestIDMiddleware is a middleware which assigns a request ID to each tool
+// call. The ID is in the context of the following middlewares and the tool
+// handler, logged with the entries of the call, and returned in the _meta of
+// the tool result, so that the client can find the log entries of the call.
+// The failed calls are logged here, regardless of the sampling of the log
+// entries of the successful ones.
+func requestIDMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if method != methodCallTool {
+			return next(ctx, method, req)
+		}
+
+		id := newRequestID()
+
+		res, err := next(context.WithValue(ctx, requestIDKey{}, id), method, req)
+		if err != nil {
+			debugLog(ctx, "tool call failed", logKeyRequestID, id, logKeySession, sessionLog(req), logKeyError, err)
+
+			return res, err
+		}
+
+		if result, ok := res.(*mcp.CallToolResult); ok && result != nil {
+			if result.IsError {
+				debugLog(ctx, "tool call failed", logKeyRequestID, id, logKeySession, sessionLog(req),
+					logKeyError, toolErrorText(result))
+			}
+
+			if result.Meta == nil {
+				result.Meta = make(mcp.Meta)
+			}
+
+			result.Meta[requestIDMetaKey] = id
+		}
+
+		return res, nil
+	}
+}
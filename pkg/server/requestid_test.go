@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  requestIDMiddleware
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_requestIDMiddleware(t *testing.T) {
+	setEnv(t, envNameDebug, "test.log")
+	unsetEnv(t, envNameLogLevel)
+
+	var (
+		mu     sync.Mutex
+		logged []string
+	)
+
+	logger := mockLogger(func(entry string) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		logged = append(logged, entry)
+	})
+
+	session := connectInMemory(t, newServer(WithLogger(logger)))
+
+	first := callTool(t, session, toolName, map[string]any{"text": "abc"})
+	second := callTool(t, session, toolName, map[string]any{"text": "abc", "path": "a.txt"}) // tool error
+
+	firstID, ok := first.Meta[requestIDMetaKey].(string)
+	require.True(t, ok, "the request ID should be in the _meta of the result")
+	require.Len(t, firstID, 26)
+
+	secondID, ok := second.Meta[requestIDMetaKey].(string)
+	require.True(t, ok, "the request ID should be in the _meta of the tool errors")
+	require.NotEqual(t, firstID, secondID, "each call should have its own ID")
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	require.Contains(t, strings.Join(logged, "\n"), "text mirrored request_id="+firstID+" tool=mirror",
+		"the log entries of the call should have the ID of the result")
+	require.Contains(t, strings.Join(logged, "\n"), "tool call failed request_id="+secondID+" error=",
+		"the tool errors should be logged")
+}
+
+//nolint:paralleltest // sets env var
+func Test_requestIDMiddleware_error(t *testing.T) {
+	setEnv(t, envNameDebug, "test.log")
+	unsetEnv(t, envNameLogLevel)
+
+	var logged []string
+
+	logger := mockLogger(func(entry string) { logged = append(logged, entry) })
+
+	var gotID string
+
+	errTest := errors.New("test error")
+
+	handler := requestIDMiddleware(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		gotID = requestID(ctx)
+
+		return nil, errTest
+	})
+
+	_, err := handler(withLogger(context.Background(), logger), methodCallTool, new(mcp.CallToolRequest))
+	require.ErrorIs(t, err, errTest)
+	require.NotEmpty(t, gotID, "the ID should be in the context of the handler")
+	require.Equal(t, []string{"tool call failed request_id=" + gotID + " error=\"test error\""}, logged)
+
+	// Other methods
+	handler = requestIDMiddleware(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		gotID = requestID(ctx)
+
+		return nil, nil
+	})
+
+	_, err = handler(context.Background(), "tools/list", new(mcp.ListToolsRequest))
+	require.NoError(t, err)
+	require.Empty(t, gotID, "only the tool calls should have an ID")
+	require.Empty(t, requestID(context.Background()))
+}
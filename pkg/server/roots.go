@@ -0,0 +1,162 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// File inputs.
+const (
+	fileMaxBytes = 64 * 1024 * 1024 // max size of the input files
+	fileScheme   = "file"           // the only URI scheme of the roots for now
+)
+
+// Predefined errors of the file inputs.
+var (
+	errNoRoots          = errors.New("client provided no roots to resolve file paths against")
+	errPathRemote       = errors.New("path is available over stdio only")
+	errPathOutsideRoots = errors.New("path is outside of the client roots")
+	errTextAndPath      = errors.New("give either text or path, not both")
+	errFileTooLarge     = fmt.Errorf("file exceeds %d bytes", fileMaxBytes)
+	errFileNotText      = errors.New("file is not UTF-8 text")
+)
+
+// readInputFile returns the content of the file at path, resolved against the
+// roots provided by the client.
+//
+// Relative paths are looked up in each root in order, and absolute paths must be
+// within one of them. Paths escaping the roots, including via symbolic links, are
+// refused. So are all paths if the client does not provide roots.
+//
+// The roots are declared by the client, so they are trusted only over stdio,
+// where the client runs on the same machine with the same user as the server.
+// Over HTTP, paths are refused with errPathRemote.
+func readInputFile(ctx context.Context, req *mcp.CallToolRequest, path string) (string, error) {
+	if req != nil && req.Extra != nil && req.Extra.Header != nil {
+		return "", errPathRemote
+	}
+
+	roots, err := clientRoots(ctx, req)
+	if err != nil {
+		return "", err
+	}
+
+	for _, root := range roots {
+		rel, ok := relativeTo(root, path)
+		if !ok {
+			continue
+		}
+
+		text, err := readInRoot(root, rel)
+		if errors.Is(err, fs.ErrNotExist) && !filepath.IsAbs(path) {
+			continue // try the next root
+		}
+
+		return text, err
+	}
+
+	return "", fmt.Errorf("%w: %s", errPathOutsideRoots, path)
+}
+
+// clientRoots returns the local directories of the roots provided by the client
+// of the request.
+func clientRoots(ctx context.Context, req *mcp.CallToolRequest) ([]string, error) {
+	if req == nil || req.Session == nil {
+		return nil, errNoRoots
+	}
+
+	res, err := req.Session.ListRoots(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errNoRoots, err)
+	}
+
+	roots := make([]string, 0, len(res.Roots))
+
+	for _, root := range res.Roots {
+		if dir, ok := rootDir(root.URI); ok {
+			roots = append(roots, dir)
+		}
+	}
+
+	if len(roots) == 0 {
+		return nil, errNoRoots
+	}
+
+	return roots, nil
+}
+
+// rootDir returns the local directory of the root URI. It returns false if the
+// URI is not a file URI.
+func rootDir(uri string) (string, bool) {
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Scheme != fileScheme || parsed.Path == "" {
+		return "", false
+	}
+
+	dir := filepath.FromSlash(parsed.Path)
+
+	// file:///C:/foo on Windows
+	if volume := filepath.VolumeName(strings.TrimPrefix(parsed.Path, "/")); volume != "" {
+		dir = filepath.FromSlash(strings.TrimPrefix(parsed.Path, "/"))
+	}
+
+	return filepath.Clean(dir), true
+}
+
+// relativeTo returns the path relative to the root. It returns false if the
+// path is not lexically within the root.
+func relativeTo(root, path string) (string, bool) {
+	rel := path
+
+	if filepath.IsAbs(path) {
+		var err error
+
+		rel, err = filepath.Rel(root, path)
+		if err != nil {
+			return "", false
+		}
+	}
+
+	return rel, filepath.IsLocal(rel)
+}
+
+// readInRoot reads the UTF-8 text file at the path relative to the root. The
+// file must not escape the root, even via symbolic links.
+func readInRoot(root, rel string) (string, error) {
+	dir, err := os.OpenRoot(root)
+	if err != nil {
+		return "", wrapError(err, "failed to open the root")
+	}
+	defer dir.Close()
+
+	file, err := dir.Open(rel)
+	if err != nil {
+		return "", wrapError(err, "failed to open the file")
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, fileMaxBytes+1))
+	if err != nil {
+		return "", wrapError(err, "failed to read the file")
+	}
+
+	if len(data) > fileMaxBytes {
+		return "", errFileTooLarge
+	}
+
+	if !utf8.Valid(data) {
+		return "", errFileNotText
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,204 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// fileURI returns the file URI of the local directory.
+func fileURI(dir string) string {
+	path := filepath.ToSlash(dir)
+	if runtime.GOOS == "windows" {
+		path = "/" + path
+	}
+
+	return (&url.URL{Scheme: fileScheme, Path: path}).String() //nolint:exhaustruct // minimal URL
+}
+
+// connectRoots connects a client providing the given roots to a new server. It
+// returns the client session.
+func connectRoots(t *testing.T, roots ...string) *mcp.ClientSession {
+	t.Helper()
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+
+	serverSession, err := newServer().Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.0"}, nil) //nolint:exhaustruct // minimal client
+
+	for _, root := range roots {
+		client.AddRoots(&mcp.Root{URI: root}) //nolint:exhaustruct // minimal root
+	}
+
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = session.Close()
+		_ = serverSession.Wait()
+	})
+
+	return session
+}
+
+// ----------------------------------------------------------------------------
+//  File inputs
+// ----------------------------------------------------------------------------
+
+func Test_handleReverse_path(t *testing.T) {
+	t.Parallel()
+
+	rootA := t.TempDir()
+	rootB := t.TempDir()
+	outside := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(rootA, "a.txt"), []byte("abc"), logPerm))
+	require.NoError(t, os.Mkdir(filepath.Join(rootB, "sub"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(rootB, "sub", "b.txt"), []byte("xyz"), logPerm))
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), logPerm))
+	require.NoError(t, os.WriteFile(filepath.Join(rootA, "binary.bin"), []byte{0xff, 0xfe}, logPerm))
+
+	if runtime.GOOS != "windows" {
+		require.NoError(t, os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(rootA, "link.txt")))
+	}
+
+	session := connectRoots(t, "https://example.com/not-a-file-root", fileURI(rootA), fileURI(rootB))
+
+	for index, test := range []struct {
+		name      string
+		path      string
+		want      string
+		wantError string
+	}{
+		{"relative_first_root", "a.txt", "cba", ""},
+		{"relative_second_root", filepath.Join("sub", "b.txt"), "zyx", ""},
+		{"absolute_in_root", filepath.Join(rootB, "sub", "b.txt"), "zyx", ""},
+		{"absolute_outside", filepath.Join(outside, "secret.txt"), "", errPathOutsideRoots.Error()},
+		{"relative_escape", filepath.Join("..", filepath.Base(outside), "secret.txt"), "", errPathOutsideRoots.Error()},
+		{"not_found", "missing.txt", "", errPathOutsideRoots.Error()},
+		{"absolute_not_found", filepath.Join(rootA, "missing.txt"), "", "failed to open the file"},
+		{"not_text", "binary.bin", "", errFileNotText.Error()},
+	} {
+		t.Run(fmt.Sprintf("Test #%d: %s", index+1, test.name), func(t *testing.T) {
+			t.Parallel()
+
+			res := callTool(t, session, toolName, map[string]any{"text": "", "path": test.path})
+
+			if test.wantError != "" {
+				require.True(t, res.IsError)
+				require.Contains(t, res.Content[0].(*mcp.TextContent).Text, test.wantError) //nolint:forcetypeassert // text content
+
+				return
+			}
+
+			require.False(t, res.IsError, res.Content)
+			require.Equal(t, map[string]any{"text": test.want}, res.StructuredContent)
+		})
+	}
+
+	t.Run("symlink_escape", func(t *testing.T) {
+		t.Parallel()
+
+		if runtime.GOOS == "windows" {
+			t.Skip("symbolic links need privileges on Windows")
+		}
+
+		res := callTool(t, session, toolName, map[string]any{"text": "", "path": "link.txt"})
+		require.True(t, res.IsError, "symbolic links escaping the root should be refused")
+	})
+
+	t.Run("text_and_path", func(t *testing.T) {
+		t.Parallel()
+
+		res := callTool(t, session, toolName, map[string]any{"text": "abc", "path": "a.txt"})
+		require.True(t, res.IsError)
+		require.Contains(t, res.Content[0].(*mcp.TextContent).Text, errTextAndPath.Error()) //nolint:forcetypeassert // text content
+	})
+}
+
+func Test_handleReverse_path_no_roots(t *testing.T) {
+	t.Parallel()
+
+	session := connectRoots(t)
+	res := callTool(t, session, toolName, map[string]any{"text": "", "path": "a.txt"})
+
+	require.True(t, res.IsError)
+	require.Contains(t, res.Content[0].(*mcp.TextContent).Text, errNoRoots.Error()) //nolint:forcetypeassert // text content
+
+	_, _, err := handleReverse(context.Background(), nil, MirrorInput{Path: "a.txt"})
+	require.ErrorIs(t, err, errNoRoots)
+}
+
+func Test_handleReverse_path_http(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "secret.txt"), []byte("secret"), logPerm))
+
+	server := httptest.NewServer(newHTTPHandler(context.Background(), newServer(), new(atomic.Bool)))
+	defer server.Close()
+
+	transport := new(mcp.StreamableClientTransport)
+	transport.Endpoint = server.URL + httpPathMCP
+
+	// A remote client declaring the root of the server's files
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.0"}, nil) //nolint:exhaustruct // minimal client
+	client.AddRoots(&mcp.Root{URI: fileURI(root)})                                            //nolint:exhaustruct // minimal root
+
+	session, err := client.Connect(context.Background(), transport, nil)
+	require.NoError(t, err)
+
+	defer session.Close()
+
+	for index, path := range []string{"secret.txt", filepath.Join(root, "secret.txt")} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, path)
+
+		res := callTool(t, session, toolName, map[string]any{"text": "", "path": path})
+		require.True(t, res.IsError, name)
+		require.Contains(t, res.Content[0].(*mcp.TextContent).Text, errPathRemote.Error(), name) //nolint:forcetypeassert // text content
+		require.NotContains(t, res.Content[0].(*mcp.TextContent).Text, "terces", name)           //nolint:forcetypeassert // text content
+	}
+}
+
+func Test_rootDir(t *testing.T) {
+	t.Parallel()
+
+	_, ok := rootDir("https://example.com/")
+	require.False(t, ok)
+
+	_, ok = rootDir("file://")
+	require.False(t, ok)
+
+	dir, ok := rootDir(fileURI(t.TempDir()))
+	require.True(t, ok)
+	require.True(t, filepath.IsAbs(dir))
+}
+
+func Test_readInRoot_too_large(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+
+	file, err := os.Create(filepath.Join(root, "large.txt"))
+	require.NoError(t, err)
+	require.NoError(t, file.Truncate(fileMaxBytes+1))
+	require.NoError(t, file.Close())
+
+	_, err = readInRoot(root, "large.txt")
+	require.ErrorIs(t, err, errFileTooLarge)
+
+	_, err = readInRoot(filepath.Join(root, "missing"), "large.txt")
+	require.ErrorContains(t, err, "failed to open the root")
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"runtime"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Runtime statistics of the process.
+const (
+	runtimeURI  = "text-mirror://runtime"
+	runtimeMIME = "application/json"
+)
+
+// RuntimeReport is the memory and the goroutines of the process as read, so
+// that the operators can confirm the large inputs aren't leaking memory. The
+// sizes are in bytes.
+type RuntimeReport struct {
+	Uptime     string    `json:"uptime"`      // duration since the server started. e.g. 1h2m3s
+	Goroutines int       `json:"goroutines"`  // goroutines running, including the ones of the sessions
+	HeapAlloc  uint64    `json:"heap_alloc"`  // bytes of the live and not yet collected heap objects
+	HeapInuse  uint64    `json:"heap_inuse"`  // bytes of the heap spans in use
+	HeapSys    uint64    `json:"heap_sys"`    // bytes of the heap obtained from the OS
+	HeapIdle   uint64    `json:"heap_idle"`   // bytes of the heap spans not in use, returnable to the OS
+	Sys        uint64    `json:"sys"`         // bytes obtained from the OS in total
+	TotalAlloc uint64    `json:"total_alloc"` // bytes allocated since the start, even if freed
+	Mallocs    uint64    `json:"mallocs"`     // heap objects allocated since the start
+	Frees      uint64    `json:"frees"`       // heap objects freed since the start
+	NumGC      uint32    `json:"num_gc"`      // completed GC cycles
+	PauseTotal string    `json:"pause_total"` // total stop-the-world pause of the GC. e.g. 1.5ms
+	LastGC     time.Time `json:"last_gc"`     // end of the last GC cycle, zero if none
+	GOMAXPROCS int       `json:"gomaxprocs"`
+	GoVersion  string    `json:"go_version"`
+
+	Buffers BufferPoolStats `json:"buffers"` // pool of the scratch buffers reused between the tool calls
+}
+
+// newRuntimeReport returns the runtime statistics of now, for the server
+// started at started.
+func newRuntimeReport(started time.Time) *RuntimeReport {
+	var mem runtime.MemStats
+
+	runtime.ReadMemStats(&mem)
+
+	report := new(RuntimeReport)
+	report.Uptime = time.Since(started).Round(time.Second).String()
+	report.Goroutines = runtime.NumGoroutine()
+	report.HeapAlloc = mem.HeapAlloc
+	report.HeapInuse = mem.HeapInuse
+	report.HeapSys = mem.HeapSys
+	report.HeapIdle = mem.HeapIdle
+	report.Sys = mem.Sys
+	report.TotalAlloc = mem.TotalAlloc
+	report.Mallocs = mem.Mallocs
+	report.Frees = mem.Frees
+	report.NumGC = mem.NumGC
+	report.PauseTotal = time.Duration(mem.PauseTotalNs).String() //nolint:gosec // pauses don't overflow int64
+	report.GOMAXPROCS = runtime.GOMAXPROCS(0)
+	report.GoVersion = runtime.Version()
+	report.Buffers = buffers.stats()
+
+	if mem.LastGC > 0 {
+		report.LastGC = time.Unix(0, int64(mem.LastGC)).UTC() //nolint:gosec // nanoseconds since 1970 fit in int64
+	}
+
+	return report
+}
+
+// addRuntimeResource adds the resource reporting the runtime statistics of the
+// server started at started, read on each request.
+func addRuntimeResource(server *mcp.Server, started time.Time) {
+	resource := new(mcp.Resource)
+	resource.URI = runtimeURI
+	resource.Name = "runtime"
+	resource.Title = "Runtime statistics"
+	resource.Description = "GC statistics, heap usage, goroutine count and uptime of the server process, " +
+		"to confirm the large inputs aren't leaking memory"
+	resource.MIMEType = runtimeMIME
+
+	server.AddResource(resource, func(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		data, err := json.MarshalIndent(newRuntimeReport(started), "", "  ")
+		if err != nil {
+			return nil, wrapError(err, "failed to marshal the runtime statistics")
+		}
+
+		contents := new(mcp.ResourceContents)
+		contents.URI = req.Params.URI
+		contents.MIMEType = runtimeMIME
+		contents.Text = string(data)
+
+		return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{contents}}, nil
+	})
+}
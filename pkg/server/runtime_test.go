@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  newRuntimeReport
+// ----------------------------------------------------------------------------
+
+func Test_newRuntimeReport(t *testing.T) {
+	t.Parallel()
+
+	report := newRuntimeReport(time.Now().Add(-90 * time.Second))
+
+	require.Equal(t, "1m30s", report.Uptime)
+	require.Positive(t, report.Goroutines)
+	require.Positive(t, report.HeapAlloc)
+	require.GreaterOrEqual(t, report.HeapSys, report.HeapInuse)
+	require.GreaterOrEqual(t, report.TotalAlloc, report.HeapAlloc)
+	require.GreaterOrEqual(t, report.Mallocs, report.Frees)
+	require.Positive(t, report.GOMAXPROCS)
+	require.NotEmpty(t, report.GoVersion)
+	require.NotEmpty(t, report.PauseTotal)
+	require.GreaterOrEqual(t, report.Buffers.Gets, report.Buffers.News)
+}
+
+// ----------------------------------------------------------------------------
+//  addRuntimeResource
+// ----------------------------------------------------------------------------
+
+func Test_addRuntimeResource(t *testing.T) {
+	t.Parallel()
+
+	session := connectInMemory(t, newServer())
+
+	params := new(mcp.ReadResourceParams)
+	params.URI = runtimeURI
+
+	read, err := session.ReadResource(context.Background(), params)
+	require.NoError(t, err)
+	require.Len(t, read.Contents, 1)
+	require.Equal(t, runtimeMIME, read.Contents[0].MIMEType)
+
+	report := new(RuntimeReport)
+	require.NoError(t, json.Unmarshal([]byte(read.Contents[0].Text), report))
+	require.NotEmpty(t, report.Uptime)
+	require.Positive(t, report.Goroutines, "the goroutines of the session should be counted")
+	require.Positive(t, report.HeapAlloc)
+}
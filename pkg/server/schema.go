@@ -0,0 +1,125 @@
+package server
+
+import "github.com/google/jsonschema-go/jsonschema"
+
+// Limits of the mirror tool arguments, advertised in the input schema.
+const (
+	textMaxLength = 16 * 1024 * 1024 // max characters of the text argument
+	pathMaxLength = 4096             // max characters of the path argument
+)
+
+// mirrorInputSchema returns the JSON schema of MirrorInput. It enriches the one
+// inferred from the struct with titles, limits and examples, so that LLMs
+// produce correct arguments more often.
+//
+// The text is required but may be empty: an empty text is mirrored from the
+// file at path if given, otherwise the user is asked for it via elicitation.
+func mirrorInputSchema() *jsonschema.Schema {
+	schema := mustInferSchema[MirrorInput]()
+	schema.Title = "Mirror input"
+	schema.Required = []string{"text"}
+
+	text := schema.Properties["text"]
+	text.Title = "Text"
+	text.MaxLength = jsonschema.Ptr(textMaxLength)
+	text.Examples = []any{"Hello, World!", "👍🏽 Café"}
+
+	path := schema.Properties["path"]
+	path.Title = "File path"
+	path.MinLength = jsonschema.Ptr(1)
+	path.MaxLength = jsonschema.Ptr(pathMaxLength)
+	path.Examples = []any{"docs/notes.txt"}
+
+	render := schema.Properties["render"]
+	render.Title = "Render"
+	render.Enum = []any{renderPNG}
+
+	granularity := schema.Properties["granularity"]
+	granularity.Title = "Granularity"
+	granularity.Enum = granularities()
+
+	return schema
+}
+
+// mirrorOutputSchema returns the JSON schema of MirrorOutput.
+func mirrorOutputSchema() *jsonschema.Schema {
+	schema := mustInferSchema[MirrorOutput]()
+	schema.Title = "Mirror output"
+	schema.Required = []string{"text"}
+
+	text := schema.Properties["text"]
+	text.Title = "Mirrored text"
+	text.Examples = []any{"!dlroW ,olleH", "éfaC 👍🏽"}
+
+	return schema
+}
+
+// mustInferSchema returns the JSON schema inferred from T. It panics on failure
+// since the types are static, so it is a programming error.
+func mustInferSchema[T any]() *jsonschema.Schema {
+	schema, err := jsonschema.For[T](nil)
+	if err != nil {
+		panic(wrapError(err, "failed to infer the JSON schema"))
+	}
+
+	return schema
+}
+
+// mirrorBatchInputSchema returns the JSON schema of MirrorBatchInput.
+func mirrorBatchInputSchema() *jsonschema.Schema {
+	schema := mustInferSchema[MirrorBatchInput]()
+	schema.Title = "Mirror batch input"
+	schema.Required = []string{"texts"}
+
+	texts := schema.Properties["texts"]
+	texts.Title = "Texts"
+	texts.Types = nil // never null
+	texts.Type = "array"
+	texts.MinItems = jsonschema.Ptr(1)
+	texts.MaxItems = jsonschema.Ptr(batchMaxItems)
+	texts.Items.MaxLength = jsonschema.Ptr(textMaxLength)
+	texts.Examples = []any{[]any{"abc", "Hello, World!"}}
+
+	return schema
+}
+
+// mirrorBatchOutputSchema returns the JSON schema of MirrorBatchOutput.
+func mirrorBatchOutputSchema() *jsonschema.Schema {
+	schema := mustInferSchema[MirrorBatchOutput]()
+	schema.Title = "Mirror batch output"
+	schema.Required = []string{"texts"}
+
+	texts := schema.Properties["texts"]
+	texts.Title = "Mirrored texts"
+	texts.Examples = []any{[]any{"cba", "!dlroW ,olleH"}}
+
+	return schema
+}
+
+// adminInputSchema returns the JSON schema of AdminInput.
+func adminInputSchema() *jsonschema.Schema {
+	schema := mustInferSchema[AdminInput]()
+	schema.Title = "Admin input"
+
+	action := schema.Properties["action"]
+	action.Title = "Action"
+	action.Enum = []any{adminActionList, adminActionEnable, adminActionDisable, adminActionLog, adminActionStats}
+
+	tool := schema.Properties["tool"]
+	tool.Title = "Tool name"
+	tool.Examples = []any{batchToolName}
+
+	level := schema.Properties["level"]
+	level.Title = "Log level"
+	level.Enum = []any{logLevelDebug, logLevelInfo, logLevelWarn, logLevelError, logLevelOff}
+
+	return schema
+}
+
+// statsInputSchema returns the JSON schema of StatsInput.
+func statsInputSchema() *jsonschema.Schema {
+	schema := mustInferSchema[StatsInput]()
+	schema.Title = "Stats input"
+
+	return schema
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  mirror tool schema
+// ----------------------------------------------------------------------------
+
+func Test_newServer_mirror_schema(t *testing.T) {
+	t.Parallel()
+
+	session := connectInMemory(t, newServer())
+
+	res, err := session.ListTools(context.Background(), nil)
+	require.NoError(t, err)
+
+	var tool *mcp.Tool
+
+	for _, listed := range res.Tools {
+		if listed.Name == toolName {
+			tool = listed
+		}
+	}
+
+	require.NotNil(t, tool)
+
+	// Schemas are JSON objects on the client side
+	input, ok := tool.InputSchema.(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, []any{"text"}, input["required"])
+
+	properties, ok := input["properties"].(map[string]any)
+	require.True(t, ok)
+
+	text, ok := properties["text"].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, "string", text["type"])
+	require.NotEmpty(t, text["description"])
+	require.NotEmpty(t, text["examples"])
+	require.InDelta(t, textMaxLength, text["maxLength"], 0)
+
+	path, ok := properties["path"].(map[string]any)
+	require.True(t, ok)
+	require.InDelta(t, 1, path["minLength"], 0)
+	require.InDelta(t, pathMaxLength, path["maxLength"], 0)
+
+	render, ok := properties["render"].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, []any{renderPNG}, render["enum"])
+
+	output, ok := tool.OutputSchema.(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, []any{"text"}, output["required"])
+}
+
+func Test_newServer_mirror_schema_validation(t *testing.T) {
+	t.Parallel()
+
+	session := connectInMemory(t, newServer())
+
+	for _, args := range []map[string]any{
+		{},                             // missing text
+		{"text": 1},                    // not a string
+		{"text": "", "path": ""},       // empty path
+		{"text": "a", "extra": ""},     // unknown argument
+		{"text": "a", "render": "gif"}, // unsupported render
+	} {
+		params := new(mcp.CallToolParams)
+		params.Name = toolName
+		params.Arguments = args
+
+		_, err := session.CallTool(context.Background(), params)
+		require.Error(t, err, "arguments %v should be rejected", args)
+	}
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+)
+
+// sdkComponent is the "component" attribute of the log entries of the MCP SDK.
+const sdkComponent = "mcp-sdk"
+
+// newSDKLogger returns the logger of the MCP SDK, such as the one of
+// mcp.ServerOptions, which bridges its records to logger, the one of the
+// server.
+func newSDKLogger(logger *slog.Logger) *slog.Logger {
+	handler := new(sdkLogHandler)
+	handler.logger = logger
+
+	return slog.New(handler)
+}
+
+// sdkLogHandler is the slog handler bridging the records of the MCP SDK to
+// logAt, so that they are written to the log and sent to the clients as MCP
+// log messages like the entries of the server. The info records, such as
+// "session initialized", are lowered to debug as they are details of the
+// protocol.
+type sdkLogHandler struct {
+	logger *slog.Logger
+	args   []any    // attributes given by WithAttrs, within the groups of the time
+	groups []string // groups given by WithGroup
+}
+
+// Enabled returns true as logAt decides whether to log.
+func (h *sdkLogHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+// Handle logs the record via logAt with the "component" attribute.
+func (h *sdkLogHandler) Handle(ctx context.Context, record slog.Record) error {
+	attrs := make([]slog.Attr, 0, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, attr)
+
+		return true
+	})
+
+	level := record.Level
+	if level == slog.LevelInfo {
+		level = slog.LevelDebug
+	}
+
+	args := append([]any{"component", sdkComponent}, h.args...)
+	logAt(withLogger(ctx, h.logger), level, record.Message, append(args, inGroups(h.groups, attrs)...)...)
+
+	return nil
+}
+
+// WithAttrs returns the handler adding the attributes to the records.
+func (h *sdkLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handler := new(sdkLogHandler)
+	handler.logger = h.logger
+	handler.args = append(slices.Clip(h.args), inGroups(h.groups, attrs)...)
+	handler.groups = h.groups
+
+	return handler
+}
+
+// WithGroup returns the handler putting the following attributes in the group.
+func (h *sdkLogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	handler := new(sdkLogHandler)
+	handler.logger = h.logger
+	handler.args = h.args
+	handler.groups = append(slices.Clip(h.groups), name)
+
+	return handler
+}
+
+// inGroups returns the attributes as key-value arguments of logAt, nested in
+// the groups from the outermost one.
+func inGroups(groups []string, attrs []slog.Attr) []any {
+	args := make([]any, 0, len(attrs))
+	for _, attr := range attrs {
+		args = append(args, attr)
+	}
+
+	for _, group := range slices.Backward(groups) {
+		if len(args) == 0 {
+			break
+		}
+
+		args = []any{slog.Group(group, args...)}
+	}
+
+	return args
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  sdkLogHandler
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_sdkLogHandler(t *testing.T) {
+	setEnv(t, envNameDebug, "test.log")
+	setEnv(t, envNameLogLevel, logLevelDebug)
+
+	var logged []string
+
+	logger := mockLogger(func(entry string) { logged = append(logged, entry) })
+
+	sdkLogger := newSDKLogger(logger).With("session_id", "abc")
+
+	sdkLogger.Info("session initialized")
+	sdkLogger.WithGroup("req").With("method", "ping").WithGroup("").Error("failed", "code", 1)
+	sdkLogger.WithGroup("empty").Warn("no attributes")
+
+	require.Equal(t, []string{
+		"session initialized component=mcp-sdk session_id=abc",
+		"failed component=mcp-sdk session_id=abc req.method=ping req.code=1",
+		"no attributes component=mcp-sdk session_id=abc",
+	}, logged)
+
+	// The info records of the SDK are lowered to debug
+	setEnv(t, envNameLogLevel, logLevelInfo)
+
+	logged = nil
+
+	sdkLogger.Info("session initialized")
+	sdkLogger.Log(t.Context(), slog.LevelWarn, "unexpected")
+	require.Equal(t, []string{"unexpected component=mcp-sdk session_id=abc"}, logged)
+}
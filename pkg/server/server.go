@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Server is the text-mirror MCP server: the built-in tools with the resources
+// and the middlewares of the command, wired as given to New rather than only
+// per the environment variables. The settings not covered by the options are
+// still read from the environment variables, as by the command.
+type Server struct {
+	state     *serverState
+	transport mcp.Transport // nil runs over the configured transport
+}
+
+// Option is an option of New.
+type Option func(*serverOptions)
+
+// serverOptions are the options of New.
+type serverOptions struct {
+	logger    *slog.Logger
+	tools     []string // names of the built-in tools registered. nil registers the configured ones
+	limits    *Limits  // nil applies the configured limits
+	transport mcp.Transport
+}
+
+// Limits are the limits of the tool calls of a Server. Zero values disable the
+// limits, except QueueDepth, where zero rejects the calls beyond the workers,
+// and MemoryWait, where zero rejects the calls beyond the budget.
+type Limits struct {
+	RateLimit    float64       // max tool calls per second per client
+	RateBurst    int           // max burst of tool calls per client. 0 is the rate limit rounded up
+	Workers      int           // max concurrent tool calls
+	QueueDepth   int           // max tool calls waiting for a worker
+	CallTimeout  time.Duration // max duration of a tool call
+	MemoryBudget int           // max total bytes of the arguments of the tool calls in progress
+	MemoryWait   time.Duration // max duration a tool call waits for MemoryBudget to free up
+}
+
+// configuredLimits returns the limits in the environment variables. Invalid
+// settings are reported by loadSettings beforehand.
+func configuredLimits() *Limits {
+	limits := new(Limits)
+	limits.RateLimit, limits.RateBurst, _ = GetRateLimit()
+	limits.Workers, limits.QueueDepth, _ = GetWorkerPool()
+	limits.CallTimeout, _ = GetCallTimeout()
+	limits.MemoryBudget, limits.MemoryWait, _ = GetMemoryBudget()
+
+	return limits
+}
+
+// WithLogger sets the logger of the debug logs and the errors of the Server,
+// slog.Default if not set. Each Server logs to its own logger.
+func WithLogger(logger *slog.Logger) Option {
+	return func(opts *serverOptions) {
+		opts.logger = logger
+	}
+}
+
+// WithTools registers only the built-in tools of the given names, such as
+// "mirror", instead of the ones of 'MCP_TEXT_MIRROR_TOOLS_ENABLED' and
+// 'MCP_TEXT_MIRROR_TOOLS_DISABLED' environment variables. Unknown names are
+// ignored, and no names registers no built-in tool.
+func WithTools(names ...string) Option {
+	return func(opts *serverOptions) {
+		// Non-nil even without names, as nil follows the configured tools.
+		opts.tools = append([]string{}, names...)
+	}
+}
+
+// allowOnly returns the tool filter allowing only the given names.
+func allowOnly(names []string) func(name string) bool {
+	return func(name string) bool {
+		return slices.Contains(names, name)
+	}
+}
+
+// WithLimits applies the given limits to the tool calls instead of the
+// configured ones.
+func WithLimits(limits Limits) Option {
+	return func(opts *serverOptions) {
+		opts.limits = &limits
+	}
+}
+
+// WithTransport runs the server over the given transport, such as
+// mcp.InMemoryTransport, instead of the configured one.
+func WithTransport(transport mcp.Transport) Option {
+	return func(opts *serverOptions) {
+		opts.transport = transport
+	}
+}
+
+// New returns the text-mirror server wired as per the options.
+func New(opts ...Option) *Server {
+	server := new(Server)
+	server.state = newServerState(opts...)
+	server.transport = newServerOptions(opts).transport
+
+	return server
+}
+
+// newServerOptions returns the options of New applied in order.
+func newServerOptions(opts []Option) *serverOptions {
+	options := new(serverOptions)
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return options
+}
+
+// MCPServer returns the underlying MCP server, to serve it with custom
+// wiring such as mcp.NewStreamableHTTPHandler.
+func (s *Server) MCPServer() *mcp.Server {
+	return s.state.server
+}
+
+// Run runs the server until ctx is done or the client disconnects, over the
+// transport of WithTransport if given, otherwise over the configured one: the
+// HTTP transport if 'MCP_TEXT_MIRROR_HTTP_ADDR' is set, the standard IO if not.
+func (s *Server) Run(ctx context.Context) error {
+	ctx = withLogger(ctx, s.state.logger)
+
+	if s.transport == nil {
+		return runServer(ctx, s.state.server)
+	}
+
+	return wrapError(s.state.server.Run(ctx, s.transport), "MCP server failed to run")
+}
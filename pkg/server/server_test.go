@@ -0,0 +1,235 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  New
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_New_options(t *testing.T) {
+	unsetEnv(t, envNameAdmin, envNameToolsEnabled, envNameWorkers)
+	setEnv(t, envNameToolsDisabled, toolName)
+	setEnv(t, envNameRateLimit, "100")
+
+	var logged []string
+
+	server := New(
+		WithLogger(mockLogger(func(entry string) { logged = append(logged, entry) })),
+		WithTools(toolName, "unknown"),
+		WithLimits(Limits{RateLimit: 0.001, RateBurst: 1}), //nolint:exhaustruct // unlimited otherwise
+	)
+
+	errorLog(logContext(server.state.logger), "logged by the logger of the options")
+	require.Contains(t, logged, "logged by the logger of the options")
+
+	session := connectInMemory(t, server.MCPServer())
+
+	list, err := session.ListTools(context.Background(), nil)
+	require.NoError(t, err)
+	require.Len(t, list.Tools, 1, "only the tools of the options should be registered")
+	require.Equal(t, toolName, list.Tools[0].Name)
+
+	args := map[string]any{"text": "abc"}
+
+	require.False(t, callTool(t, session, toolName, args).IsError)
+
+	res := callTool(t, session, toolName, args)
+	require.True(t, res.IsError, "second call should exceed the burst of the options")
+	require.Contains(t, res.Content[0].(*mcp.TextContent).Text, errRateLimited.Error()) //nolint:forcetypeassert // text content
+
+	// The options outlive the reloads of the settings.
+	server.state.apply()
+
+	list, err = session.ListTools(context.Background(), nil)
+	require.NoError(t, err)
+	require.Len(t, list.Tools, 1)
+	require.False(t, callTool(t, session, toolName, args).IsError, "rate limit buckets should start over")
+	require.True(t, callTool(t, session, toolName, args).IsError)
+}
+
+//nolint:paralleltest // sets env var
+func Test_New_loggers(t *testing.T) {
+	unsetEnv(t, envNameToolsEnabled, envNameToolsDisabled)
+	setEnv(t, envNameLogLevel, logLevelDebug)
+
+	var (
+		mu     sync.Mutex
+		logged = make(map[string][]string)
+	)
+
+	loggerOf := func(name string) *slog.Logger {
+		return mockLogger(func(entry string) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			logged[name] = append(logged[name], entry)
+		})
+	}
+
+	first := connectInMemory(t, New(WithLogger(loggerOf("first"))).MCPServer())
+	second := connectInMemory(t, New(WithLogger(loggerOf("second"))).MCPServer())
+
+	callTool(t, first, toolName, map[string]any{"text": "first"})
+	callTool(t, second, toolName, map[string]any{"text": "second"})
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	require.Contains(t, strings.Join(logged["first"], "\n"), "text=first mirrored=tsrif")
+	require.NotContains(t, strings.Join(logged["first"], "\n"), "text=second", "each server should log to its own logger")
+	require.Contains(t, strings.Join(logged["second"], "\n"), "text=second mirrored=dnoces")
+	require.NotContains(t, strings.Join(logged["second"], "\n"), "text=first", "each server should log to its own logger")
+}
+
+//nolint:paralleltest // sets env var
+func Test_New_defaults(t *testing.T) {
+	unsetEnv(t, envNameAdmin, envNameToolsEnabled, envNameToolsDisabled)
+
+	session := connectInMemory(t, New().MCPServer())
+
+	list, err := session.ListTools(context.Background(), nil)
+	require.NoError(t, err)
+	require.Len(t, list.Tools, len(builtinTools)-1, "configured tools, without the admin tool, should be registered")
+}
+
+//nolint:paralleltest // sets env var
+func Test_New_no_tools(t *testing.T) {
+	unsetEnv(t, envNameAdmin, envNameToolsEnabled, envNameToolsDisabled)
+
+	server := New(WithTools())
+	session := connectInMemory(t, server.MCPServer())
+
+	list, err := session.ListTools(context.Background(), nil)
+	require.NoError(t, err)
+	require.Empty(t, list.Tools, "no names should register no tool, not the configured ones")
+
+	server.state.apply()
+
+	list, err = session.ListTools(context.Background(), nil)
+	require.NoError(t, err)
+	require.Empty(t, list.Tools)
+}
+
+// Test_New_concurrent_calls calls the tools from several sessions at once while
+// the settings are reloaded, as the handlers of the HTTP transport do. Run with -race to catch unsynchronized state.
+//
+//nolint:paralleltest // sets env var
+func Test_New_concurrent_calls(t *testing.T) {
+	unsetEnv(t, envNameAdmin, envNameToolsEnabled, envNameToolsDisabled, envNameRateLimit, envNameWorkers)
+	setEnv(t, envNameLogLevel, logLevelDebug)
+
+	var logged atomic.Int64
+
+	countLogged := mockLogger(func(string) { logged.Add(1) })
+
+	state := newServerState(WithLogger(countLogged))
+
+	const (
+		sessions = 4
+		calls    = 20
+	)
+
+	clients := make([]*mcp.ClientSession, sessions)
+	for index := range clients {
+		clients[index] = connectInMemory(t, state.server)
+	}
+
+	tools := []struct {
+		name string
+		args map[string]any
+	}{
+		{toolName, map[string]any{"text": "abc 👨‍👩‍👧"}},
+		{batchToolName, map[string]any{"texts": []any{"abc", "déf"}}},
+		{pipelineToolName, map[string]any{"text": "Crème", "expr": "nfc | strip_accents | mirror | upper"}},
+		{transformToolName, map[string]any{"text": "abc", "op": "upper"}},
+		{statsToolName, map[string]any{}},
+	}
+
+	var (
+		wg, reloader sync.WaitGroup
+		errs         = make(chan error, sessions*calls)
+		done         = make(chan struct{})
+	)
+
+	// Reload the settings meanwhile
+	reloader.Go(func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				state.apply()
+			}
+		}
+	})
+
+	for _, session := range clients {
+		wg.Go(func() {
+			for index := range calls {
+				tool := tools[index%len(tools)]
+
+				params := new(mcp.CallToolParams)
+				params.Name = tool.name
+				params.Arguments = tool.args
+
+				res, err := session.CallTool(context.Background(), params)
+				if err == nil && res.IsError {
+					err = fmt.Errorf("%s failed: %v", tool.name, res.Content)
+				}
+
+				if err != nil {
+					errs <- err
+				}
+			}
+		})
+	}
+
+	wg.Wait()
+	close(done)
+	reloader.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	require.Positive(t, logged.Load(), "calls should be logged by the replaced logger")
+}
+
+//nolint:paralleltest // sets env var
+func Test_Server_Run_transport(t *testing.T) {
+	unsetEnv(t, envNameAdmin, envNameToolsEnabled, envNameToolsDisabled)
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+
+	go func() { done <- New(WithTransport(serverTransport)).Run(ctx) }()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.0"}, nil) //nolint:exhaustruct // minimal client
+
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+
+	res := callTool(t, session, toolName, map[string]any{"text": "abc"})
+	require.False(t, res.IsError)
+	require.Equal(t, "cba", res.Content[0].(*mcp.TextContent).Text) //nolint:forcetypeassert // text content
+
+	require.NoError(t, session.Close())
+	require.NoError(t, <-done, "server should stop once the client disconnects")
+}
@@ -0,0 +1,36 @@
+package server
+
+import "errors"
+
+// Service subcommands. E.g.: text-mirror service install 127.0.0.1:8080
+const (
+	cmdNameService          = "service"
+	cmdNameServiceInstall   = "install"
+	cmdNameServiceUninstall = "uninstall"
+	cmdNameServiceRun       = "run"
+
+	serviceDisplayName = "MCP text-mirror"
+	serviceDescription = "MCP server that mirrors (reverses) UTF-8 text over the streamable HTTP transport."
+	serviceDefaultAddr = "127.0.0.1:8080" // HTTP listen address if not given at install time
+)
+
+// Predefined errors of the service subcommands.
+var (
+	errServiceUsage       = errors.New("usage: text-mirror service <install [addr]|uninstall|run [addr]>")
+	errServiceUnsupported = errors.New("service subcommands are only supported on Windows")
+	errServiceExists      = errors.New("service already exists")
+)
+
+// serviceAddr returns the HTTP listen address given in args or the default
+// one. The address in args takes precedence over MCP_TEXT_MIRROR_HTTP_ADDR.
+func serviceAddr(args []string) string {
+	if len(args) > 0 && args[0] != "" {
+		return args[0]
+	}
+
+	if addr := GetHTTPAddr(); addr != "" {
+		return addr
+	}
+
+	return serviceDefaultAddr
+}
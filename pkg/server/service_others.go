@@ -0,0 +1,12 @@
+//go:build !windows
+
+package server
+
+import "context"
+
+// runService is the "service" subcommand. On non-Windows platforms it always
+// fails since there is no service manager to talk to. Use systemd, launchd or
+// similar with MCP_TEXT_MIRROR_HTTP_ADDR set instead.
+func runService(_ context.Context, _ *App, _ *cliOptions) error {
+	return errServiceUnsupported
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  runCommand
+// ----------------------------------------------------------------------------
+
+func Test_runCommand_service(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("service subcommands are supported on Windows")
+	}
+
+	err := newTestApp(t).Run(context.Background(), []string{cmdNameService, cmdNameServiceInstall})
+	require.ErrorIs(t, err, errServiceUnsupported)
+}
+
+// ----------------------------------------------------------------------------
+//  serviceAddr
+// ----------------------------------------------------------------------------
+
+func Test_serviceAddr(t *testing.T) {
+	setEnv(t, envNameHTTPAddr, "")
+	require.Equal(t, serviceDefaultAddr, serviceAddr(nil), "default address should be used")
+	require.Equal(t, "0.0.0.0:9000", serviceAddr([]string{"0.0.0.0:9000"}))
+
+	setEnv(t, envNameHTTPAddr, "127.0.0.1:8181")
+	require.Equal(t, "127.0.0.1:8181", serviceAddr(nil), "env var should be used if no arg given")
+	require.Equal(t, "0.0.0.0:9000", serviceAddr([]string{"0.0.0.0:9000"}),
+		"arg should take precedence over env var")
+}
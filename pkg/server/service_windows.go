@@ -0,0 +1,198 @@
+//go:build windows
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/eventlog"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// Event IDs written to the Windows Event Log.
+const (
+	eventIDStarted uint32 = 1
+	eventIDStopped uint32 = 2
+	eventIDFailed  uint32 = 3
+	eventIDLogged  uint32 = 4 // warnings and errors of the logger, see GetEventLog
+
+	eventTypes = eventlog.Error | eventlog.Warning | eventlog.Info
+)
+
+// runService is the "service" subcommand. It installs, uninstalls or runs
+// text-mirror as a Windows service serving MCP over the streamable HTTP
+// transport. opts.args holds the subcommand and its arguments.
+func runService(ctx context.Context, a *App, opts *cliOptions) error {
+	args := opts.args[1:]
+	if len(args) == 0 {
+		return errServiceUsage
+	}
+
+	switch args[0] {
+	case cmdNameServiceInstall:
+		return installService(serviceAddr(args[1:]))
+	case cmdNameServiceUninstall:
+		return uninstallService()
+	case cmdNameServiceRun:
+		return runAsService(ctx, a, opts.configPath, serviceAddr(args[1:]))
+	}
+
+	return errServiceUsage
+}
+
+// installService registers the running executable as an auto-start service
+// and its event log source. The service is started as "service run <addr>".
+func installService(addr string) error {
+	exePath, err := os.Executable()
+	if err != nil {
+		return wrapError(err, "failed to get executable path")
+	}
+
+	manager, err := mgr.Connect()
+	if err != nil {
+		return wrapError(err, "failed to connect to service manager")
+	}
+	defer manager.Disconnect()
+
+	if service, err := manager.OpenService(serviceName); err == nil {
+		service.Close()
+
+		return wrapError(errServiceExists, "failed to install %s", serviceName)
+	}
+
+	config := mgr.Config{} //nolint:exhaustruct // use default config
+	config.DisplayName = serviceDisplayName
+	config.Description = serviceDescription
+	config.StartType = mgr.StartAutomatic
+
+	service, err := manager.CreateService(serviceName, exePath, config, cmdNameService, cmdNameServiceRun, addr)
+	if err != nil {
+		return wrapError(err, "failed to create service")
+	}
+	defer service.Close()
+
+	err = eventlog.InstallAsEventCreate(serviceName, eventTypes)
+	if err != nil {
+		_ = service.Delete()
+
+		return wrapError(err, "failed to register event log source")
+	}
+
+	return nil
+}
+
+// uninstallService removes the service and its event log source.
+func uninstallService() error {
+	manager, err := mgr.Connect()
+	if err != nil {
+		return wrapError(err, "failed to connect to service manager")
+	}
+	defer manager.Disconnect()
+
+	service, err := manager.OpenService(serviceName)
+	if err != nil {
+		return wrapError(err, "service %s is not installed", serviceName)
+	}
+	defer service.Close()
+
+	err = service.Delete()
+	if err != nil {
+		return wrapError(err, "failed to delete service")
+	}
+
+	return wrapError(eventlog.Remove(serviceName), "failed to remove event log source")
+}
+
+// runAsService runs the server of the App over the HTTP transport on addr under
+// the service control manager until the service is stopped, as App.Run serves
+// it otherwise: with the upstreams, the plugins and the startup report, and
+// reloading the config file at configPath. Lifecycle events are written to the
+// event log.
+func runAsService(ctx context.Context, a *App, configPath, addr string) error {
+	elog, err := eventlog.Open(serviceName)
+	if err != nil {
+		return wrapError(err, "failed to open event log")
+	}
+	defer elog.Close()
+
+	app := *a
+	app.RunServer = func(ctx context.Context, server *mcp.Server) error {
+		return serveHTTP(ctx, server, addr)
+	}
+
+	serve := func(ctx context.Context) error {
+		return app.serve(ctx, newConfigReloader(configPath))
+	}
+
+	handler := &windowsService{ctx: ctx, serve: serve, addr: addr, elog: elog}
+
+	err = svc.Run(serviceName, handler)
+	if err != nil {
+		_ = elog.Error(eventIDFailed, fmt.Sprintf("%s service failed: %v", serviceName, err))
+
+		return wrapError(err, "failed to run service")
+	}
+
+	return nil
+}
+
+// windowsService implements svc.Handler.
+type windowsService struct {
+	ctx   context.Context                 //nolint:containedctx // parent context of the whole service lifetime
+	serve func(ctx context.Context) error // serves until ctx is done
+	elog  *eventlog.Log
+	addr  string
+}
+
+// Execute serves MCP over HTTP and reports the status to the service control
+// manager. It is an implementation of svc.Handler.
+func (s *windowsService) Execute(
+	_ []string,
+	requests <-chan svc.ChangeRequest,
+	status chan<- svc.Status,
+) (bool, uint32) {
+	const accepts = svc.AcceptStop | svc.AcceptShutdown
+
+	status <- svc.Status{State: svc.StartPending} //nolint:exhaustruct // state only
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+
+	errServe := make(chan error, 1)
+
+	go func() {
+		errServe <- s.serve(ctx)
+	}()
+
+	status <- svc.Status{State: svc.Running, Accepts: accepts} //nolint:exhaustruct // state only
+
+	_ = s.elog.Info(eventIDStarted, fmt.Sprintf("%s %s started. Listening on %s",
+		serviceName, GetServiceVersion(), s.addr))
+
+	for {
+		select {
+		case err := <-errServe:
+			_ = s.elog.Error(eventIDFailed, fmt.Sprintf("%s stopped unexpectedly: %v", serviceName, err))
+
+			return true, 1 // service specific exit code
+		case req := <-requests:
+			switch req.Cmd { //nolint:exhaustive // other commands are not accepted
+			case svc.Interrogate:
+				status <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending} //nolint:exhaustruct // state only
+
+				cancel()
+				<-errServe
+
+				_ = s.elog.Info(eventIDStopped, serviceName+" stopped")
+
+				return false, 0
+			}
+		}
+	}
+}
@@ -0,0 +1,352 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Environment variables of the settings.
+//
+// Every setting has an environment variable named after its config file key,
+// e.g. "MCP_TEXT_MIRROR_HTTP_ADDR" for "transport.http_addr", and a flag such
+// as "--http-addr".
+const (
+	envPrefix     = "MCP_TEXT_MIRROR_" // prefix of the environment variables of the settings
+	settingKeySep = "."                // separator between the section and the name of the config keys
+)
+
+// errUnknownEnv is returned if an environment variable with the prefix is not
+// a setting, which is most likely a typo.
+var errUnknownEnv = errors.New("unknown environment variable")
+
+// setting is a single setting of the server. Its value is read from the
+// loaded settingValues by the getters, such as GetHTTPAddr.
+type setting struct {
+	key    string                     // config file key, e.g. "transport.http_addr"
+	usage  string                     // description for --help. "`name`" is the name of the value
+	isBool bool                       // whether the flag can be given without value
+	check  func(*settingValues) error // validates the value. nil if any value is valid
+}
+
+// qualifiedKeys are the settings too generic to be named without the section,
+// whose environment variable and flag include it, e.g. "--tools-enabled".
+//
+//nolint:gochecknoglobals // read-only table
+var qualifiedKeys = map[string]bool{
+	"tools.enabled":  true,
+	"tools.disabled": true,
+	"tools.preset":   true,
+	"tools.defaults": true,
+}
+
+// name returns the name of the setting in its section of the config file, or
+// with the section if qualified.
+func (s setting) name() string {
+	if qualifiedKeys[s.key] {
+		return strings.ReplaceAll(s.key, settingKeySep, "_")
+	}
+
+	_, name, _ := strings.Cut(s.key, settingKeySep)
+
+	return name
+}
+
+// envName returns the environment variable of the setting.
+func (s setting) envName() string {
+	return envPrefix + strings.ToUpper(s.name())
+}
+
+// flagName returns the command line flag of the setting.
+func (s setting) flagName() string {
+	return strings.ReplaceAll(s.name(), "_", "-")
+}
+
+// settings are all the settings of the server, in the order of the usage.
+//
+//nolint:gochecknoglobals,lll // read-only table
+var settings = []setting{
+	{"server.profile", "`profile` of the defaults of the other settings: dev or prod", false, checkValue((*settingValues).profile)},
+	{"server.instances", "extra server `instances` served over HTTP at their own paths, as a JSON array. e.g. '[{\"name\":\"team-a\",\"tools\":[\"mirror\"],\"clients\":[\"alice\"]}]'", false, checkValue((*settingValues).instances)},
+	{"transport.http_addr", "serve MCP over HTTP at the listen `address` instead of stdio. e.g. 127.0.0.1:8080", false, checkHTTPAddr},
+	{"transport.tls_cert", "server certificate `file` (PEM) to serve over HTTPS", false, checkValue((*settingValues).tlsConfig)},
+	{"transport.tls_key", "server private key `file` (PEM) to serve over HTTPS", false, checkValue((*settingValues).tlsConfig)},
+	{"transport.tls_client_ca", "CA bundle `file` (PEM) to verify the client certificates. enables mTLS", false, checkValue((*settingValues).tlsConfig)},
+	{"transport.allowed_origins", "comma separated `origins` allowed to access over HTTP. \"*\" allows any", false, nil},
+	{"transport.cors_headers", "comma separated extra request `headers` allowed by CORS", false, nil},
+	{"transport.keepalive", "`interval` to ping the clients. e.g. 30s", false, checkValue((*settingValues).keepAlive)},
+	{"transport.idle_timeout", "`duration` to close the idle HTTP sessions. e.g. 10m", false, checkValue((*settingValues).idleTimeout)},
+	{"transport.max_body", "max `bytes` of the HTTP request bodies. 0 disables the limit (default 33554432)", false, checkValue((*settingValues).maxBody)},
+	{"transport.debug", "serve the pprof endpoints at /debug/pprof/ over HTTP to the admin clients, or on a loopback listener", true, checkValue((*settingValues).debugEnabled)},
+	{"logging.debug_log", "enable debug logging to the `file`. relative to the user's log directory", false, nil},
+	{"logging.wire_tap", "write every JSON-RPC frame to the trace `file`, pretty-printed and cut at 64 KiB. relative to the user's log directory", false, nil},
+	{"logging.log_format", "`format` of the log entries: text or json (default text)", false, checkValue((*settingValues).logFormat)},
+	{"logging.log_level", "minimum `level` of the log entries: debug, info, warn or error (default debug with debug_log, error otherwise)", false, checkLogLevel},
+	{"logging.log_max_size", "`megabytes` of the log file to rotate it at. 0 disables rotation (default 100)", false, checkValue((*settingValues).logRotation)},
+	{"logging.log_max_backups", "max rotated log `files` kept. 0 keeps all (default 5)", false, checkValue((*settingValues).logRotation)},
+	{"logging.log_max_age", "max `duration` to keep the rotated log files. e.g. 168h", false, checkValue((*settingValues).logRotation)},
+	{"logging.log_compress", "gzip the rotated log files", true, checkValue((*settingValues).logRotation)},
+	{"logging.log_sample", "log 1 in `N` successful tool calls. the failed ones are always logged (default 1)", false, checkValue((*settingValues).logSample)},
+	{"logging.syslog", "send the log entries to the syslog `target` too: local, or a udp://, tcp:// or unix:// address. e.g. udp://127.0.0.1:514?facility=local0", false, checkValue((*settingValues).syslog)},
+	{"logging.event_log", "write the warnings and errors to the Windows Event Log too, when running as a service", true, checkValue((*settingValues).eventLog)},
+	{"logging.error_webhook", "`URL` to POST the panics and the fatal errors to as JSON", false, checkValue((*settingValues).errorWebhook)},
+	{"logging.statsd", "push the metrics of the tool calls to the StatsD `endpoint` over UDP. e.g. statsd://127.0.0.1:8125 or dogstatsd://127.0.0.1:8125?prefix=text_mirror", false, checkValue((*settingValues).statsd)},
+	{"logging.log_redact", "`redaction` of the texts in the log entries: none, omit, hash or truncate:N graphemes (default none)", false, checkValue((*settingValues).logRedact)},
+	{"logging.slow_call", "`duration` above which a tool call is logged at the warn level with its input size. e.g. 500ms", false, checkValue((*settingValues).slowCall)},
+	{"logging.meta_keys", "comma separated request _meta `keys` to log and echo back (default \"traceparent,tracestate\")", false, nil},
+	{"limits.rate_limit", "max tool `calls` per second per client. e.g. 0.5", false, checkRateLimit},
+	{"limits.rate_burst", "max burst of tool `calls` per client", false, checkRateLimit},
+	{"limits.workers", "max concurrent tool `calls`. 0 disables the limit (default GOMAXPROCS)", false, checkWorkerPool},
+	{"limits.queue_depth", "max tool `calls` waiting for a worker (default 64)", false, checkWorkerPool},
+	{"limits.call_timeout", "max `duration` of a tool call. e.g. 30s", false, checkValue((*settingValues).callTimeout)},
+	{"limits.memory_budget", "max total `bytes` of the arguments of the tool calls in progress. 0 disables the budget (default)", false, checkMemoryBudget},
+	{"limits.memory_wait", "max `duration` a tool call waits for the memory budget. e.g. 5s (default rejects at once)", false, checkMemoryBudget},
+	{"limits.page_size", "max `items` per page of the list methods (default 1000)", false, checkValue((*settingValues).pageSize)},
+	{"limits.client_limits", "max text `bytes` per client name. e.g. \"vscode=16777216;*=65536\"", false, checkValue((*settingValues).clientLimits)},
+	{"tools.admin", "add the admin tool to enable/disable the tools at runtime", true, checkValue((*settingValues).adminEnabled)},
+	{"tools.admin_clients", "comma separated client `identities` (mTLS CN or \"anonymous\") allowed to use the admin tool over HTTP (default none)", false, nil},
+	{"tools.verify", "ask the client's LLM to verify the mirrored tricky texts via sampling", true, checkValue((*settingValues).verifyEnabled)},
+	{"tools.render_font", "TrueType/OpenType font `file` to render the mirrored text with", false, checkValue((*settingValues).renderFont)},
+	{"tools.enabled", "comma separated `tools` to register. others are neither registered nor listed (default all)", false, checkValue((*settingValues).toolFilter)},
+	{"tools.disabled", "comma separated `tools` not to register", false, checkValue((*settingValues).toolFilter)},
+	{"tools.preset", "`preset` of the tools to register if tools.enabled is not set: minimal (mirror only) or full (default)", false, checkValue((*settingValues).toolFilter)},
+	{"tools.cache_size", "max tool `results` cached to answer the repeated calls. 0 disables the cache (default)", false, checkValue((*settingValues).cacheSize)},
+	{"tools.cache_bytes", "max total `bytes` of the cached results, as JSON. 0 bounds the cache by cache_size only (default 67108864)", false, checkValue((*settingValues).cacheBytes)},
+	{"tools.locale", "BCP 47 `locale` of the case mapping, overridable per call. e.g. tr (default language neutral)", false, checkValue((*settingValues).locale)},
+	{"tools.defaults", "default `arguments` of the tools if omitted. e.g. \"mirror.render=png\"", false, checkValue((*settingValues).toolDefaults)},
+	{"tools.upstreams", "upstream MCP `servers` to aggregate. e.g. \"fs=mcp-fs --ro;web=http://127.0.0.1:9000/mcp\"", false, checkValue((*settingValues).upstreams)},
+	{"tools.plugins", "`directory` of the plugin executables and WebAssembly modules providing extra tools", false, checkValue((*settingValues).plugins)},
+}
+
+// checkValue returns the check of the setting read by the getter.
+func checkValue[T any](getter func(*settingValues) (T, error)) func(*settingValues) error {
+	return func(v *settingValues) error {
+		_, err := getter(v)
+
+		return err
+	}
+}
+
+// checkLogLevel is the check of the log level setting.
+func checkLogLevel(v *settingValues) error {
+	return v.logLevelErr
+}
+
+// checkRateLimit is the check of the rate limit settings.
+func checkRateLimit(v *settingValues) error {
+	_, _, err := v.rateLimit()
+
+	return err
+}
+
+// checkWorkerPool is the check of the worker pool settings.
+func checkWorkerPool(v *settingValues) error {
+	_, _, err := v.workerPool()
+
+	return err
+}
+
+// checkMemoryBudget is the check of the memory budget settings.
+func checkMemoryBudget(v *settingValues) error {
+	_, _, err := v.memoryBudget()
+
+	return err
+}
+
+// loadSettings checks the loaded settings, so that invalid values and unknown
+// environment variables with the prefix are reported at startup rather than
+// silently ignored.
+func loadSettings() error {
+	return loadedSettings().check()
+}
+
+// check returns the first error of the settings: an unknown environment
+// variable with the prefix or an invalid value. The checks read the settings
+// only, so that they can be checked before being stored.
+func (v *settingValues) check() error {
+	known := make(map[string]bool, len(settings))
+	for _, s := range settings {
+		known[s.envName()] = true
+	}
+
+	for name := range v.env {
+		if !known[name] {
+			return fmt.Errorf("%w: %s", errUnknownEnv, name)
+		}
+	}
+
+	for _, s := range settings {
+		if s.check == nil {
+			continue
+		}
+
+		err := s.check(v)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+//  settingValues
+// ----------------------------------------------------------------------------
+
+// settingValues are the values of the settings by environment variable, from
+// the layers in increasing precedence: the defaults of the profile, the config
+// file, the environment variables and the flags. Once stored, they are never
+// modified: reloading the config file or setting the log level at runtime
+// stores new ones, so that the getters read consistent values.
+type settingValues struct {
+	config map[string]string // from the config file
+	env    map[string]string // environment variables with envPrefix, read once
+	flags  map[string]string // from the setting flags and the admin tool
+	values map[string]string // non-empty values of the layers
+
+	logLevel    slog.Level // parsed once, as logAt checks it on every entry
+	logLevelErr error
+}
+
+// Settings loaded and the lock of their updates.
+//
+//nolint:gochecknoglobals // replaced as a whole
+var (
+	currentSettings atomic.Pointer[settingValues]
+	settingsMu      sync.Mutex
+)
+
+// newSettingValues returns the settings of the layers along with the
+// environment variables read at the call. The profile is the one of the
+// layers, whose defaults apply only to the settings set in none of them, even
+// to empty values.
+func newSettingValues(config, flags map[string]string) *settingValues {
+	env := make(map[string]string)
+
+	for _, entry := range os.Environ() {
+		name, value, _ := strings.Cut(entry, "=")
+		if strings.HasPrefix(name, envPrefix) {
+			env[name] = value
+		}
+	}
+
+	return mergeSettings(config, env, flags)
+}
+
+// mergeSettings returns the settings of the layers.
+func mergeSettings(config, env, flags map[string]string) *settingValues {
+	v := new(settingValues)
+	v.config = config
+	v.env = env
+	v.flags = flags
+
+	set := make(map[string]string, len(config)+len(env)+len(flags))
+	maps.Copy(set, config)
+	maps.Copy(set, env)
+	maps.Copy(set, flags)
+
+	for name, value := range profiles[set[envNameProfile]] { // invalid ones are reported by loadSettings
+		if _, ok := set[name]; !ok {
+			set[name] = value
+		}
+	}
+
+	v.values = make(map[string]string, len(set))
+
+	for name, value := range set {
+		if value != "" {
+			v.values[name] = value
+		}
+	}
+
+	v.logLevel, v.logLevelErr = parseLogLevel(v.values)
+
+	return v
+}
+
+// withConfig returns the settings with the values of the config file replaced.
+func (v *settingValues) withConfig(config map[string]string) *settingValues {
+	return mergeSettings(config, v.env, v.flags)
+}
+
+// withFlags returns the settings with the given values over the ones of the
+// flags. Empty values unset the settings, including from the other layers.
+func (v *settingValues) withFlags(flags map[string]string) *settingValues {
+	merged := maps.Clone(v.flags)
+	if merged == nil {
+		merged = make(map[string]string, len(flags))
+	}
+
+	maps.Copy(merged, flags)
+
+	return mergeSettings(v.config, v.env, merged)
+}
+
+// overridden returns whether the setting is not the one of the environment
+// variable, but of the config file, the flags or the profile.
+func (v *settingValues) overridden(name string) bool {
+	return v.values[name] != v.env[name]
+}
+
+// loadedSettings returns the settings stored by storeSettings, or the ones of
+// the environment variables if none.
+func loadedSettings() *settingValues {
+	v := currentSettings.Load()
+	if v != nil {
+		return v
+	}
+
+	currentSettings.CompareAndSwap(nil, newSettingValues(nil, nil))
+
+	return currentSettings.Load()
+}
+
+// storeSettings replaces the settings with the ones returned by update from the
+// loaded ones. The updates are serialized, so that none is lost.
+func storeSettings(update func(*settingValues) *settingValues) *settingValues {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+
+	v := update(loadedSettings())
+	currentSettings.Store(v)
+
+	return v
+}
+
+// checkSettings stores the settings returned by update from the loaded ones if
+// they pass their checks, and returns the previous ones. Otherwise nothing is
+// stored and the error is returned, so that the getters never read the
+// rejected settings.
+func checkSettings(update func(*settingValues) *settingValues) (*settingValues, error) {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+
+	previous := loadedSettings()
+	next := update(previous)
+
+	err := next.check()
+	if err != nil {
+		return nil, err
+	}
+
+	currentSettings.Store(next)
+
+	return previous, nil
+}
+
+// settingValue returns the value of the setting of the environment variable,
+// empty if not set.
+func settingValue(name string) string {
+	return loadedSettings().value(name)
+}
+
+// value returns the value of the setting of the environment variable in the
+// settings, empty if not set.
+func (v *settingValues) value(name string) string {
+	return v.values[name]
+}
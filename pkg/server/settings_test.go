@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  settings
+// ----------------------------------------------------------------------------
+
+func Test_settings(t *testing.T) {
+	t.Parallel()
+
+	// Each setting of the config file should be a setting with the same name,
+	// and vice versa.
+	config, err := parseConfig([]byte(testConfigYAML), false)
+	require.NoError(t, err)
+
+	var fromConfig, fromSettings []string
+
+	for name := range config.env() {
+		fromConfig = append(fromConfig, name)
+	}
+
+	for _, s := range settings {
+		fromSettings = append(fromSettings, s.envName())
+	}
+
+	require.ElementsMatch(t, fromConfig, fromSettings)
+}
+
+func Test_setting_names(t *testing.T) {
+	t.Parallel()
+
+	for index, test := range []struct {
+		key      string
+		wantEnv  string
+		wantFlag string
+	}{
+		{"server.profile", envNameProfile, "profile"},
+		{"transport.http_addr", envNameHTTPAddr, "http-addr"},
+		{"transport.tls_client_ca", envNameTLSClientCA, "tls-client-ca"},
+		{"logging.debug_log", envNameDebug, "debug-log"},
+		{"tools.admin", envNameAdmin, "admin"},
+		{"tools.enabled", envNameToolsEnabled, "tools-enabled"},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.key)
+		s := setting{key: test.key, usage: "", isBool: false, check: nil}
+
+		require.Equal(t, test.wantEnv, s.envName(), name)
+		require.Equal(t, test.wantFlag, s.flagName(), name)
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  loadSettings
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_loadSettings(t *testing.T) {
+	require.NoError(t, loadSettings())
+
+	setEnv(t, envPrefix+"WORKER", "4") // typo
+
+	err := loadSettings()
+	require.ErrorIs(t, err, errUnknownEnv)
+	require.ErrorContains(t, err, envPrefix+"WORKER")
+
+	err = newApp().serve(context.Background(), nil)
+	require.ErrorIs(t, err, errUnknownEnv, "unknown env vars should be reported at startup")
+}
+
+// ----------------------------------------------------------------------------
+//  checkSettings
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // stores the settings
+func Test_checkSettings(t *testing.T) {
+	keepSettings(t)
+
+	loaded := loadedSettings()
+	done := make(chan struct{})
+	seen := make(chan string, 1)
+
+	go func() { // reads the settings meanwhile
+		defer close(seen)
+
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			if value := settingValue(envNameWorkers); value != "" {
+				seen <- value
+
+				return
+			}
+		}
+	}()
+
+	for range 100 {
+		_, err := checkSettings(func(v *settingValues) *settingValues {
+			return v.withFlags(map[string]string{envNameWorkers: "-1"})
+		})
+		require.ErrorIs(t, err, errInvalidNumber)
+	}
+
+	close(done)
+	require.Empty(t, <-seen, "the rejected settings should never be read")
+	require.Same(t, loaded, loadedSettings(), "the rejected settings should not be stored")
+
+	previous, err := checkSettings(func(v *settingValues) *settingValues {
+		return v.withFlags(map[string]string{envNameWorkers: "4"})
+	})
+	require.NoError(t, err)
+	require.Same(t, loaded, previous)
+	require.Equal(t, "4", settingValue(envNameWorkers))
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Detection of the slow tool calls.
+//
+// E.g.: time=... level=WARN msg="slow tool call" request_id=... tool=mirror input_size=1048590 duration=812ms
+const (
+	envNameSlowCall = envPrefix + "SLOW_CALL" // env var of the duration above which a tool call is logged. e.g. 500ms
+)
+
+// GetSlowCall returns the duration above which a tool call is logged at the
+// warn level from 'MCP_TEXT_MIRROR_SLOW_CALL' environment variable, so that
+// the occasional unexpectedly slow calls are noticed without logging every
+// call. Zero, the default, disables it.
+func GetSlowCall() (time.Duration, error) {
+	return loadedSettings().slowCall()
+}
+
+// slowCall returns GetSlowCall of the settings.
+func (v *settingValues) slowCall() (time.Duration, error) {
+	return v.envDuration(envNameSlowCall)
+}
+
+// slowCallMiddleware logs the tool calls which took longer than GetSlowCall
+// with the size of their arguments and the duration. The duration is of the
+// tool execution, without the wait for a worker.
+func slowCallMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		params, ok := req.GetParams().(*mcp.CallToolParamsRaw)
+		if method != methodCallTool || !ok || params == nil {
+			return next(ctx, method, req)
+		}
+
+		threshold, _ := GetSlowCall() // invalid values are reported by loadSettings
+		if threshold <= 0 {
+			return next(ctx, method, req)
+		}
+
+		start := time.Now()
+		res, err := next(ctx, method, req)
+
+		if took := time.Since(start); took > threshold {
+			call, _ := req.(*mcp.CallToolRequest)
+
+			warnLog(ctx, "slow tool call", callLogAttrs(ctx, call, logKeyInputSize, len(params.Arguments),
+				logKeyDuration, took)...)
+		}
+
+		return res, err
+	}
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  slowCallMiddleware
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_slowCallMiddleware(t *testing.T) {
+	unsetEnv(t, envNameToolsEnabled, envNameToolsDisabled, envNameDebug)
+	setEnv(t, envNameLogLevel, "warn")
+
+	var (
+		mu     sync.Mutex
+		logged []string
+	)
+
+	logger := mockLogger(func(entry string) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		logged = append(logged, entry)
+	})
+
+	entries := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return strings.Join(logged, "\n")
+	}
+
+	state := newServerState(WithLogger(logger))
+
+	info := new(mcp.Tool)
+	info.Name = "slow"
+	addTool(state.tools, info, func(context.Context, *mcp.CallToolRequest, MirrorInput) (*mcp.CallToolResult, any, error) {
+		time.Sleep(50 * time.Millisecond)
+
+		return nil, nil, nil
+	})
+
+	session := connectInMemory(t, state.server)
+
+	// Disabled by default
+	unsetEnv(t, envNameSlowCall)
+	require.False(t, callTool(t, session, "slow", map[string]any{"text": "abc"}).IsError)
+	require.NotContains(t, entries(), "slow tool call")
+
+	// Slow call
+	setEnv(t, envNameSlowCall, "20ms")
+
+	res := callTool(t, session, "slow", map[string]any{"text": "abc"})
+	require.False(t, res.IsError)
+
+	id, ok := res.Meta[requestIDMetaKey].(string)
+	require.True(t, ok)
+	require.Contains(t, entries(), `slow tool call request_id=`+id+` tool=slow`)
+	require.Contains(t, entries(), `input_size=14 duration=`)
+
+	// Fast call
+	setEnv(t, envNameSlowCall, "1m")
+	require.False(t, callTool(t, session, "slow", map[string]any{"text": "abc"}).IsError)
+	require.Equal(t, 1, strings.Count(entries(), "slow tool call"), "calls under the threshold should not be logged")
+}
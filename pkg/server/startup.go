@@ -0,0 +1,238 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Startup report of the effective configuration.
+const (
+	startupURI  = "text-mirror://startup"
+	startupMIME = "application/json"
+	noneValue   = "none" // value of the report if not configured
+)
+
+// StartupReport is the effective configuration of the server as it started,
+// so that the operators can verify what is actually running. It is logged on
+// startup and served as the text-mirror://startup resource.
+type StartupReport struct {
+	Version   string        `json:"version"`
+	Started   time.Time     `json:"started"`
+	Config    string        `json:"config"`              // path of the config file, or "none"
+	Profile   string        `json:"profile"`             // "dev", "prod" or "none"
+	Transport string        `json:"transport"`           // "stdio" or the URL of the HTTP listener
+	Log       string        `json:"log"`                 // destinations of the log entries and the level
+	Tools     []string      `json:"tools"`               // tools enabled, without the ones of the upstream servers
+	Upstreams []string      `json:"upstreams,omitempty"` // "name = target" of the upstream servers
+	Instances []string      `json:"instances,omitempty"` // "name = path" of the extra server instances over HTTP
+	Metrics   string        `json:"metrics,omitempty"`   // StatsD endpoint the metrics are pushed to
+	Limits    StartupLimits `json:"limits"`
+}
+
+// StartupLimits are the limits of the tool calls of the StartupReport. Zero
+// values are unlimited.
+type StartupLimits struct {
+	RateLimit    float64        `json:"rate_limit"`              // calls per second per client
+	RateBurst    int            `json:"rate_burst"`              // burst of calls per client
+	Workers      int            `json:"workers"`                 // concurrent calls
+	QueueDepth   int            `json:"queue_depth"`             // calls waiting for a worker
+	CallTimeout  string         `json:"call_timeout"`            // max duration of a call. e.g. "30s"
+	MemoryBudget int            `json:"memory_budget"`           // max input bytes of the calls in progress
+	MemoryWait   string         `json:"memory_wait,omitempty"`   // max duration a call waits for the budget
+	PageSize     int            `json:"page_size"`               // items per page of the list methods
+	ClientLimits map[string]int `json:"client_limits,omitempty"` // max text bytes by client name
+}
+
+// newStartupReport returns the report of the server of state as configured.
+// configPath is the --config flag. Invalid settings are reported by
+// loadSettings beforehand.
+func newStartupReport(state *serverState, configPath string) *StartupReport {
+	report := new(StartupReport)
+	report.Version = GetServiceVersion()
+	report.Started = time.Now().UTC()
+	report.Config = startupConfigPath(configPath)
+	report.Transport = startupTransport()
+	report.Log = startupLog()
+	report.Limits = startupLimits()
+
+	report.Profile, _ = GetProfile()
+	if report.Profile == "" {
+		report.Profile = noneValue
+	}
+
+	for _, tool := range state.tools.states() {
+		if tool.Enabled && slices.Contains(builtinTools, tool.Name) {
+			report.Tools = append(report.Tools, tool.Name)
+		}
+	}
+
+	upstreams, _ := GetUpstreams()
+	for _, upstream := range upstreams {
+		report.Upstreams = append(report.Upstreams, upstream.Name+" = "+upstream.Target)
+	}
+
+	if GetHTTPAddr() != "" {
+		instances, _ := GetInstances()
+		for _, instance := range instances {
+			report.Instances = append(report.Instances, instance.Name+" = "+instance.Path)
+		}
+	}
+
+	if statsd, _ := GetStatsd(); statsd != nil {
+		report.Metrics = statsd.String()
+	}
+
+	return report
+}
+
+// startupConfigPath returns the path of the config file read, or "none".
+func startupConfigPath(configPath string) string {
+	if configPath == "" {
+		configPath = defaultConfigPath()
+	}
+
+	if _, err := os.Stat(configPath); configPath == "" || err != nil {
+		return noneValue
+	}
+
+	return configPath
+}
+
+// startupTransport returns "stdio" or the URL of the HTTP listener, with
+// " (mTLS)" if the client certificates are verified.
+func startupTransport() string {
+	addr := GetHTTPAddr()
+	if addr == "" {
+		return "stdio"
+	}
+
+	tlsConfig, _ := GetTLSConfig()
+
+	switch {
+	case tlsConfig == nil:
+		return "http://" + addr
+	case tlsConfig.ClientAuth == tls.RequireAndVerifyClientCert:
+		return "https://" + addr + " (mTLS)"
+	default:
+		return "https://" + addr
+	}
+}
+
+// startupLog returns the destinations of the log entries with the level. e.g.
+// "stderr (error), syslog udp://127.0.0.1:514".
+func startupLog() string {
+	logPath := "stderr"
+	if IsDebugMode() {
+		logPath = GetLogPath()
+	}
+
+	logLevel, _ := GetLogLevel()
+	logPath += " (" + strings.ToLower(logLevel.String()) + ")"
+
+	if target, _ := GetSyslog(); target != nil {
+		logPath += ", syslog " + target.String()
+	}
+
+	if eventLog, _ := GetEventLog(); eventLog {
+		logPath += ", event log"
+	}
+
+	return logPath
+}
+
+// startupLimits returns the limits as configured.
+func startupLimits() StartupLimits {
+	var limits StartupLimits
+
+	limits.RateLimit, limits.RateBurst, _ = GetRateLimit()
+	limits.Workers, limits.QueueDepth, _ = GetWorkerPool()
+	limits.PageSize, _ = GetPageSize()
+	limits.ClientLimits, _ = GetClientLimits()
+
+	if timeout, _ := GetCallTimeout(); timeout > 0 {
+		limits.CallTimeout = timeout.String()
+	}
+
+	budget, wait, _ := GetMemoryBudget()
+	limits.MemoryBudget = budget
+
+	if wait > 0 {
+		limits.MemoryWait = wait.String()
+	}
+
+	return limits
+}
+
+// logAttrs returns the report as key-value pairs for infoLog.
+func (r *StartupReport) logAttrs() []any {
+	attrs := []any{
+		"version", r.Version,
+		"config", r.Config,
+		"profile", r.Profile,
+		"transport", r.Transport,
+		"log", r.Log,
+		"tools", strings.Join(r.Tools, ","),
+	}
+
+	if len(r.Upstreams) > 0 {
+		attrs = append(attrs, "upstreams", strings.Join(r.Upstreams, ","))
+	}
+
+	if len(r.Instances) > 0 {
+		attrs = append(attrs, "instances", strings.Join(r.Instances, ","))
+	}
+
+	if r.Metrics != "" {
+		attrs = append(attrs, "metrics", r.Metrics)
+	}
+
+	limits := []any{
+		"rate_limit", r.Limits.RateLimit,
+		"rate_burst", r.Limits.RateBurst,
+		"workers", r.Limits.Workers,
+		"queue_depth", r.Limits.QueueDepth,
+		"call_timeout", r.Limits.CallTimeout,
+		"memory_budget", r.Limits.MemoryBudget,
+		"page_size", r.Limits.PageSize,
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(r.Limits.ClientLimits)) {
+		limits = append(limits, "client_limits."+name, r.Limits.ClientLimits[name])
+	}
+
+	return append(attrs, slog.Group("limits", limits...))
+}
+
+// addStartupResource adds the resource serving the report as JSON.
+func addStartupResource(server *mcp.Server, report *StartupReport) {
+	resource := new(mcp.Resource)
+	resource.URI = startupURI
+	resource.Name = "startup"
+	resource.Title = "Startup report"
+	resource.Description = "Effective configuration of the server as it started: version, transport, " +
+		"log destination, tools and limits"
+	resource.MIMEType = startupMIME
+
+	server.AddResource(resource, func(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return nil, wrapError(err, "failed to marshal the startup report")
+		}
+
+		contents := new(mcp.ResourceContents)
+		contents.URI = req.Params.URI
+		contents.MIMEType = startupMIME
+		contents.Text = string(data)
+
+		return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{contents}}, nil
+	})
+}
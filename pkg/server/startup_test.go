@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  StartupReport
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_newStartupReport(t *testing.T) {
+	unsetEnv(t, envNameProfile, envNameDebug, envNameLogLevel, envNameSyslog, envNameTLSCert, envNameTLSKey,
+		envNameRateLimit, envNameToolsEnabled, envNameUpstreams)
+	setEnv(t, envNameHTTPAddr, "127.0.0.1:8080")
+	setEnv(t, envNameToolsDisabled, batchToolName)
+	setEnv(t, envNameWorkers, "4")
+	setEnv(t, envNameCallTimeout, "30s")
+	setEnv(t, envNameClientLimits, "vscode=100;*=50")
+
+	report := newStartupReport(newServerState(), "/non-existent/config.yaml")
+
+	require.Equal(t, GetServiceVersion(), report.Version)
+	require.False(t, report.Started.IsZero())
+	require.Equal(t, noneValue, report.Config, "missing config files should not be reported")
+	require.Equal(t, noneValue, report.Profile)
+	require.Equal(t, "http://127.0.0.1:8080", report.Transport)
+	require.Equal(t, "stderr (error)", report.Log)
+	require.Equal(t, sortedNames(defaultTools(batchToolName)...), report.Tools, "disabled tools should not be reported")
+	require.Empty(t, report.Upstreams)
+	require.Equal(t, StartupLimits{
+		Workers:      4,
+		QueueDepth:   queueDepthDefault,
+		CallTimeout:  "30s",
+		PageSize:     mcp.DefaultPageSize,
+		ClientLimits: map[string]int{"vscode": 100, "*": 50},
+	}, report.Limits)
+}
+
+//nolint:paralleltest // sets env var
+func Test_run_startup_report(t *testing.T) {
+	unsetEnv(t, envNameHTTPAddr, envNameToolsDisabled, envNameToolsEnabled, envNameUpstreams)
+	setEnv(t, envNameDebug, "test.log")
+	setEnv(t, envNameLogLevel, logLevelInfo)
+
+	var logged []string
+
+	logger := mockLogger(func(entry string) { logged = append(logged, entry) })
+
+	report := new(StartupReport)
+
+	app := newTestApp(t)
+	app.RunServer = func(ctx context.Context, server *mcp.Server) error {
+		params := new(mcp.ReadResourceParams)
+		params.URI = startupURI
+
+		read, err := connectInMemory(t, server).ReadResource(ctx, params)
+		require.NoError(t, err)
+		require.Len(t, read.Contents, 1)
+		require.Equal(t, startupMIME, read.Contents[0].MIMEType)
+
+		return json.Unmarshal([]byte(read.Contents[0].Text), report)
+	}
+
+	require.NoError(t, app.serve(withLogger(context.Background(), logger), nil))
+	require.Equal(t, "stdio", report.Transport)
+	require.Equal(t, sortedNames(defaultTools()...), report.Tools)
+
+	require.NotEmpty(t, logged)
+	require.True(t, strings.HasPrefix(logged[0], "server starting version="), logged[0])
+	require.Contains(t, logged[0], " transport=stdio ")
+	require.Contains(t, logged[0], " tools=mirror,mirror.v1,mirror_batch,mirror_each_line,normalize,pipeline,reverse_lines,reverse_words,stats,text_stats,transform limits.rate_limit=0 ")
+}
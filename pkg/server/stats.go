@@ -0,0 +1,304 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Usage statistics of the tools.
+const (
+	statsToolName        = "stats"
+	statsToolDescription = "Reports the usage statistics of the tools of this server since it started: " +
+		"the number of calls and errors, the p50/p95/p99 latency and the bytes of the arguments by tool, " +
+		"and the calls, errors and bytes by client"
+	statsURI        = "text-mirror://stats"
+	statsMIME       = "application/json"
+	statsMaxClients = 1000        // clients counted separately at most. the others are counted as anyClient
+	unknownClient   = "(unknown)" // client name of the sessions neither authenticated nor identified
+)
+
+// ToolStats is the usage of a tool since the server started.
+type ToolStats struct {
+	Name   string  `json:"name"   jsonschema:"The name of the tool."`
+	Calls  int64   `json:"calls"  jsonschema:"The number of calls of the tool."`
+	Errors int64   `json:"errors" jsonschema:"The number of calls which failed, including the rejected ones."`
+	Bytes  int64   `json:"bytes"  jsonschema:"The total size in bytes of the arguments of the calls."`
+	P50    float64 `json:"p50_ms" jsonschema:"The median latency of the calls in milliseconds."`
+	P95    float64 `json:"p95_ms" jsonschema:"The 95th percentile latency of the calls in milliseconds."`
+	P99    float64 `json:"p99_ms" jsonschema:"The 99th percentile latency of the calls in milliseconds."`
+}
+
+// ClientStats is the usage of a client since the server started, to see which
+// agent is responsible for the load.
+type ClientStats struct {
+	Name          string `json:"name"          jsonschema:"The verified identity of the client, or its declared name."`
+	Authenticated bool   `json:"authenticated" jsonschema:"Whether the name is the identity verified by mTLS."`
+	Calls         int64  `json:"calls"         jsonschema:"The number of tool calls of the client."`
+	Errors        int64  `json:"errors"        jsonschema:"The number of tool calls of the client which failed."`
+	Bytes         int64  `json:"bytes"         jsonschema:"The total size in bytes of the arguments of the calls."`
+}
+
+// StatsReport is the usage statistics of the tools, reported by the stats tool
+// and resource and by the stats action of the admin tool.
+type StatsReport struct {
+	Uptime  string        `json:"uptime"  jsonschema:"The duration since the server started. e.g. 1h2m3s"`
+	Tools   []ToolStats   `json:"tools"   jsonschema:"The usage by tool, of the tools called so far."`
+	Clients []ClientStats `json:"clients" jsonschema:"The usage by client, of the clients which called the tools so far."`
+	Cache   *CacheStats   `json:"cache,omitempty" jsonschema:"The usage of the result cache, if enabled."`
+}
+
+// StatsInput is the input of the stats tool, which takes no arguments.
+type StatsInput struct{}
+
+// toolUsage is the usage of a tool with the histogram of its latencies.
+type toolUsage struct {
+	stats   ToolStats
+	latency latencyHistogram
+}
+
+// callStats counts the tool calls by tool since the server started.
+type callStats struct {
+	started time.Time
+	tools   map[string]*toolUsage
+	clients map[clientName]*ClientStats
+	statsd  *statsdClient // pushes the calls to StatsD too if not nil
+	cache   *resultCache  // reported along if not nil
+	mu      sync.Mutex
+}
+
+// newCallStats returns the statistics starting now.
+func newCallStats() *callStats {
+	stats := new(callStats)
+	stats.started = time.Now()
+	stats.tools = make(map[string]*toolUsage)
+	stats.clients = make(map[clientName]*ClientStats)
+
+	return stats
+}
+
+// clientName identifies the client of the tool calls in the statistics.
+type clientName struct {
+	name          string
+	authenticated bool
+}
+
+// statsClient returns the client of the request: the identity verified by
+// mTLS if any, otherwise the name the client declared in its clientInfo at
+// initialize time, or unknownClient.
+func statsClient(req mcp.Request) clientName {
+	if extra := req.GetExtra(); extra != nil && extra.Header != nil {
+		if id := extra.Header.Get(headerClientID); id != "" && id != anonymousClient {
+			return clientName{name: id, authenticated: true}
+		}
+	}
+
+	if session, ok := req.GetSession().(*mcp.ServerSession); ok && session != nil {
+		params := session.InitializeParams()
+		if params != nil && params.ClientInfo != nil && params.ClientInfo.Name != "" {
+			return clientName{name: params.ClientInfo.Name, authenticated: false}
+		}
+	}
+
+	return clientName{name: unknownClient, authenticated: false}
+}
+
+// record counts a call of the tool by the client with the size of its
+// arguments and the time it took.
+func (s *callStats) record(name string, client clientName, failed bool, size int, took time.Duration) {
+	s.statsd.record(name, failed, size, took)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.recordClient(client, failed, size)
+
+	tool, ok := s.tools[name]
+	if !ok {
+		tool = new(toolUsage)
+		tool.stats.Name = name
+		s.tools[name] = tool
+	}
+
+	tool.stats.Calls++
+	tool.stats.Bytes += int64(size)
+
+	if failed {
+		tool.stats.Errors++
+	}
+
+	tool.latency.record(took)
+}
+
+// recordClient counts a call of the client. The declared names are up to the
+// clients, so the clients beyond statsMaxClients are counted together as
+// anyClient to bound the memory. s.mu must be held.
+func (s *callStats) recordClient(client clientName, failed bool, size int) {
+	stats, ok := s.clients[client]
+	if !ok {
+		if len(s.clients) >= statsMaxClients {
+			client = clientName{name: anyClient, authenticated: false}
+		}
+
+		stats, ok = s.clients[client]
+	}
+
+	if !ok {
+		stats = new(ClientStats)
+		stats.Name = client.name
+		stats.Authenticated = client.authenticated
+		s.clients[client] = stats
+	}
+
+	stats.Calls++
+	stats.Bytes += int64(size)
+
+	if failed {
+		stats.Errors++
+	}
+}
+
+// snapshot returns the statistics of the tools called so far in name order, and
+// the duration since the server started.
+func (s *callStats) snapshot() ([]ToolStats, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tools := make([]ToolStats, 0, len(s.tools))
+	for _, name := range slices.Sorted(maps.Keys(s.tools)) {
+		tool := s.tools[name]
+
+		stats := tool.stats
+		stats.P50 = millis(tool.latency.quantile(0.5))
+		stats.P95 = millis(tool.latency.quantile(0.95))
+		stats.P99 = millis(tool.latency.quantile(0.99))
+
+		tools = append(tools, stats)
+	}
+
+	return tools, time.Since(s.started)
+}
+
+// clientSnapshot returns the statistics of the clients which called the tools
+// so far, the most calls first.
+func (s *callStats) clientSnapshot() []ClientStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clients := make([]ClientStats, 0, len(s.clients))
+	for _, client := range s.clients {
+		clients = append(clients, *client)
+	}
+
+	slices.SortFunc(clients, func(a, b ClientStats) int {
+		if a.Calls != b.Calls {
+			return cmp.Compare(b.Calls, a.Calls)
+		}
+
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return clients
+}
+
+// report returns the statistics as reported to the clients.
+func (s *callStats) report() *StatsReport {
+	tools, uptime := s.snapshot()
+
+	report := new(StatsReport)
+	report.Uptime = uptime.Round(time.Second).String()
+	report.Tools = tools
+	report.Clients = s.clientSnapshot()
+
+	if s.cache != nil {
+		report.Cache = s.cache.snapshot()
+	}
+
+	return report
+}
+
+// millis returns the duration in milliseconds.
+func millis(duration time.Duration) float64 {
+	return float64(duration) / float64(time.Millisecond)
+}
+
+// middleware counts the tool calls and their failures by tool and by client,
+// either protocol errors or tool execution errors, along with the size of the
+// arguments and the latency.
+func (s *callStats) middleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		params, ok := req.GetParams().(*mcp.CallToolParamsRaw)
+		if method != methodCallTool || !ok || params == nil {
+			return next(ctx, method, req)
+		}
+
+		start := time.Now()
+		res, err := next(ctx, method, req)
+
+		result, _ := res.(*mcp.CallToolResult)
+		s.record(params.Name, statsClient(req), err != nil || (result != nil && result.IsError), len(params.Arguments), time.Since(start))
+
+		return res, err
+	}
+}
+
+// statsTool is the stats tool reporting the usage statistics.
+type statsTool struct{}
+
+// Name returns the name of the tool.
+func (statsTool) Name() string { return statsToolName }
+
+// Description returns the description of the tool.
+func (statsTool) Description() string { return statsToolDescription }
+
+// Schema returns the schema of the input of the tool. The output is inferred
+// from StatsReport.
+func (statsTool) Schema() (*jsonschema.Schema, *jsonschema.Schema) { return statsInputSchema(), nil }
+
+// Annotations returns the hints of the tool.
+func (statsTool) Annotations() *mcp.ToolAnnotations {
+	annotations := readOnlyAnnotations()
+	annotations.IdempotentHint = false // the statistics change with each call
+
+	return annotations
+}
+
+// Handler returns the handler reporting the statistics of the server.
+func (statsTool) Handler(state *serverState) ToolHandler {
+	return TypedHandler(func(context.Context, *mcp.CallToolRequest, StatsInput) (*mcp.CallToolResult, *StatsReport, error) {
+		return nil, state.stats.report(), nil
+	})
+}
+
+// addStatsResource adds the resource reporting the usage statistics, for the
+// clients and the operators to read without calling a tool.
+func addStatsResource(server *mcp.Server, stats *callStats) {
+	resource := new(mcp.Resource)
+	resource.URI = statsURI
+	resource.Name = "stats"
+	resource.Title = "Usage statistics"
+	resource.Description = "Number of calls and errors, p50/p95/p99 latency and bytes of the arguments by tool " +
+		"since the server started"
+	resource.MIMEType = statsMIME
+
+	server.AddResource(resource, func(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		data, err := json.MarshalIndent(stats.report(), "", "  ")
+		if err != nil {
+			return nil, wrapError(err, "failed to marshal the stats")
+		}
+
+		contents := new(mcp.ResourceContents)
+		contents.URI = req.Params.URI
+		contents.MIMEType = statsMIME
+		contents.Text = string(data)
+
+		return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{contents}}, nil
+	})
+}
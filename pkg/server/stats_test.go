@@ -0,0 +1,137 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  callStats
+// ----------------------------------------------------------------------------
+
+// testClient is the client of the calls recorded in the tests.
+//
+//nolint:gochecknoglobals // read-only in tests
+var testClient = clientName{name: "test-client", authenticated: false}
+
+func Test_callStats_record(t *testing.T) {
+	t.Parallel()
+
+	stats := newCallStats()
+
+	for range 98 {
+		stats.record(toolName, testClient, false, 10, time.Millisecond)
+	}
+
+	stats.record(toolName, testClient, true, 1, 50*time.Millisecond)
+	stats.record(toolName, testClient, true, 1, time.Second)
+	stats.record(batchToolName, testClient, false, 5, 2*time.Millisecond)
+
+	tools, uptime := stats.snapshot()
+	require.Positive(t, uptime)
+	require.Equal(t, []ToolStats{
+		{Name: toolName, Calls: 100, Errors: 2, Bytes: 982, P50: 1, P95: 1, P99: tools[0].P99},
+		{Name: batchToolName, Calls: 1, Errors: 0, Bytes: 5, P50: 2, P95: 2, P99: 2},
+	}, tools)
+	require.InEpsilon(t, 50, tools[0].P99, histogramGrowth-1, "p99 should be within the bucket of 50ms")
+	require.Equal(t, []ClientStats{
+		{Name: "test-client", Authenticated: false, Calls: 101, Errors: 2, Bytes: 987},
+	}, stats.clientSnapshot())
+}
+
+func Test_callStats_recordClient(t *testing.T) {
+	t.Parallel()
+
+	stats := newCallStats()
+	ops := clientName{name: "ops", authenticated: true}
+	declared := clientName{name: "ops", authenticated: false}
+
+	stats.record(toolName, declared, false, 1, time.Millisecond)
+	stats.record(toolName, ops, true, 2, time.Millisecond)
+	stats.record(toolName, ops, false, 3, time.Millisecond)
+
+	require.Equal(t, []ClientStats{
+		{Name: "ops", Authenticated: true, Calls: 2, Errors: 1, Bytes: 5},
+		{Name: "ops", Authenticated: false, Calls: 1, Errors: 0, Bytes: 1},
+	}, stats.clientSnapshot(), "declared names should be apart from the verified identities, the most calls first")
+
+	// Too many clients
+	for index := range statsMaxClients {
+		stats.record(toolName, clientName{name: fmt.Sprint("agent-", index), authenticated: false}, false, 1, time.Millisecond)
+	}
+
+	clients := stats.clientSnapshot()
+	require.Len(t, clients, statsMaxClients+1, "the clients beyond the max should be counted together")
+	require.Contains(t, clients, ClientStats{Name: anyClient, Authenticated: false, Calls: 2, Errors: 0, Bytes: 2})
+
+	stats.record(toolName, ops, false, 1, time.Millisecond)
+	require.Equal(t, int64(3), stats.clientSnapshot()[0].Calls, "the clients counted so far should still be counted")
+}
+
+func Test_statsClient(t *testing.T) {
+	t.Parallel()
+
+	// Verified identity
+	req := new(mcp.CallToolRequest)
+	req.Extra = new(mcp.RequestExtra)
+	req.Extra.Header = make(http.Header)
+	req.Extra.Header.Set(headerClientID, "ops")
+	require.Equal(t, clientName{name: "ops", authenticated: true}, statsClient(req))
+
+	// Anonymous and without session
+	req.Extra.Header.Set(headerClientID, anonymousClient)
+	require.Equal(t, clientName{name: unknownClient, authenticated: false}, statsClient(req))
+}
+
+// ----------------------------------------------------------------------------
+//  stats tool and resource
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // reads env var
+func Test_statsTool(t *testing.T) {
+	unsetEnv(t, envNameToolsEnabled, envNameToolsDisabled)
+
+	session := connectInMemory(t, newServer())
+
+	callTool(t, session, toolName, map[string]any{"text": "abc"})
+
+	res := callTool(t, session, statsToolName, nil)
+	require.False(t, res.IsError)
+
+	data, err := json.Marshal(res.StructuredContent)
+	require.NoError(t, err)
+
+	report := new(StatsReport)
+	require.NoError(t, json.Unmarshal(data, report))
+	require.NotEmpty(t, report.Uptime)
+	require.Len(t, report.Tools, 1, "the stats call itself should be counted once done")
+	require.Equal(t, toolName, report.Tools[0].Name)
+	require.Equal(t, int64(1), report.Tools[0].Calls)
+	require.Equal(t, int64(len(`{"text":"abc"}`)), report.Tools[0].Bytes)
+
+	// The resource reports the same, including the stats call
+	params := new(mcp.ReadResourceParams)
+	params.URI = statsURI
+
+	read, err := session.ReadResource(context.Background(), params)
+	require.NoError(t, err)
+	require.Len(t, read.Contents, 1)
+	require.Equal(t, statsMIME, read.Contents[0].MIMEType)
+
+	report = new(StatsReport)
+	require.NoError(t, json.Unmarshal([]byte(read.Contents[0].Text), report))
+	require.Len(t, report.Tools, 2)
+	require.Equal(t, toolName, report.Tools[0].Name)
+	require.Equal(t, statsToolName, report.Tools[1].Name)
+	require.Positive(t, report.Tools[0].P50)
+	require.Equal(t, []ClientStats{
+		{Name: "test-client", Authenticated: false, Calls: 2, Errors: 0, Bytes: int64(len(`{"text":"abc"}`) + len(`{}`))},
+	}, report.Clients, "calls should be counted by the declared name of the client")
+}
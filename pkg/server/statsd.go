@@ -0,0 +1,241 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Push of the metrics of the tool calls to StatsD.
+//
+// E.g.: MCP_TEXT_MIRROR_STATSD="dogstatsd://127.0.0.1:8125?prefix=text_mirror"
+const (
+	envNameStatsd = envPrefix + "STATSD" // env var of the StatsD endpoint of the metrics
+
+	statsdScheme        = "statsd"        // scheme of the plain StatsD endpoints, with the tool in the metric names
+	dogstatsdScheme     = "dogstatsd"     // scheme of the DogStatsD endpoints, with the tool as a tag
+	statsdPrefixDefault = "text_mirror"   // prefix of the metric names unless given by the "prefix" query parameter
+	statsdMaxPacket     = 1432            // bytes of a UDP packet at most, to fit in the MTU of the Ethernet
+	statsdFlushInterval = 1 * time.Second // interval to send the metrics buffered
+	statsdDialTimeout   = 2 * time.Second // timeout to resolve the host of the endpoint
+)
+
+// errInvalidStatsd is returned if the StatsD endpoint is not a valid URL.
+var errInvalidStatsd = errors.New("must be a statsd:// or dogstatsd:// address such as statsd://127.0.0.1:8125")
+
+// statsdTarget is the StatsD endpoint of the metrics.
+type statsdTarget struct {
+	address string // host:port
+	prefix  string // prefix of the metric names
+	tagged  bool   // whether DogStatsD tags are supported
+}
+
+// GetStatsd returns the StatsD endpoint to push the metrics of the tool calls
+// to from 'MCP_TEXT_MIRROR_STATSD' environment variable, or nil if not set.
+//
+// The endpoint is "statsd://host:8125" for the plain StatsD, or
+// "dogstatsd://host:8125" for the DogStatsD agent of Datadog, which takes the
+// name of the tool as a tag rather than in the metric names. The "prefix"
+// query parameter sets the prefix of the metric names, "text_mirror" by
+// default. The metrics are sent over UDP, for the environments without
+// Prometheus scraping.
+func GetStatsd() (*statsdTarget, error) {
+	return loadedSettings().statsd()
+}
+
+// statsd returns GetStatsd of the settings.
+func (v *settingValues) statsd() (*statsdTarget, error) {
+	value := v.value(envNameStatsd)
+	if value == "" {
+		return nil, nil //nolint:nilnil // no StatsD is not an error
+	}
+
+	parsed, err := url.Parse(value)
+	if err != nil || (parsed.Scheme != statsdScheme && parsed.Scheme != dogstatsdScheme) {
+		return nil, fmt.Errorf("invalid %s %q: %w", envNameStatsd, value, errInvalidStatsd)
+	}
+
+	if _, _, err := net.SplitHostPort(parsed.Host); err != nil {
+		return nil, fmt.Errorf("invalid %s %q: %w", envNameStatsd, value, errInvalidStatsd)
+	}
+
+	target := new(statsdTarget)
+	target.address = parsed.Host
+	target.tagged = parsed.Scheme == dogstatsdScheme
+
+	target.prefix = statsdPrefixDefault
+	if parsed.Query().Has("prefix") {
+		target.prefix = parsed.Query().Get("prefix")
+	}
+
+	if target.prefix == "" || strings.ContainsAny(target.prefix, ":|@#\n") {
+		return nil, fmt.Errorf("invalid %s %q: invalid prefix %q", envNameStatsd, value, target.prefix)
+	}
+
+	return target, nil
+}
+
+// String returns the target as configured, for the reports.
+func (t *statsdTarget) String() string {
+	scheme := statsdScheme
+	if t.tagged {
+		scheme = dogstatsdScheme
+	}
+
+	return scheme + "://" + t.address
+}
+
+// statsdClient pushes the metrics of the tool calls to the StatsD endpoint.
+// The metrics are buffered and sent in packets up to statsdMaxPacket bytes,
+// at most statsdFlushInterval after recorded. Failures to send are logged and
+// the metrics dropped, as StatsD over UDP is lossy anyway.
+type statsdClient struct {
+	target  *statsdTarget
+	logger  *slog.Logger // logs the failures
+	conn    net.Conn
+	buf     bytes.Buffer
+	pending bool // whether a flush is scheduled
+	mu      sync.Mutex
+}
+
+// newStatsdClient returns the client of the target logging to logger, or nil
+// if target is nil.
+func newStatsdClient(target *statsdTarget, logger *slog.Logger) *statsdClient {
+	if target == nil {
+		return nil
+	}
+
+	client := new(statsdClient)
+	client.target = target
+	client.logger = logger
+
+	return client
+}
+
+// record pushes the metrics of a call of the tool: the calls, the errors and
+// the bytes of the arguments as counters, and the latency as a timing.
+func (c *statsdClient) record(name string, failed bool, size int, took time.Duration) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.add(name, "calls", "1|c")
+
+	if failed {
+		c.add(name, "errors", "1|c")
+	}
+
+	c.add(name, "bytes", strconv.Itoa(size)+"|c")
+	c.add(name, "latency", strconv.FormatFloat(millis(took), 'f', -1, 64)+"|ms")
+	c.schedule()
+}
+
+// schedule schedules the flush of the metrics buffered, unless scheduled
+// already. c.mu must be held.
+func (c *statsdClient) schedule() {
+	if !c.pending {
+		c.pending = true
+
+		time.AfterFunc(statsdFlushInterval, c.flush)
+	}
+}
+
+// recordCache pushes a hit or a miss of the result cache for the tool as a
+// counter.
+func (c *statsdClient) recordCache(name string, hit bool) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if hit {
+		c.add(name, "cache_hits", "1|c")
+	} else {
+		c.add(name, "cache_misses", "1|c")
+	}
+
+	c.schedule()
+}
+
+// add buffers the metric of the tool, sending the buffer first if the metric
+// doesn't fit in the packet. c.mu must be held.
+func (c *statsdClient) add(tool, metric, value string) {
+	var line string
+
+	if c.target.tagged {
+		line = c.target.prefix + "." + metric + ":" + value + "|#tool:" + statsdName(tool)
+	} else {
+		line = c.target.prefix + ".tool." + statsdName(tool) + "." + metric + ":" + value
+	}
+
+	if c.buf.Len() > 0 && c.buf.Len()+1+len(line) > statsdMaxPacket {
+		c.send()
+	}
+
+	if c.buf.Len() > 0 {
+		c.buf.WriteByte('\n')
+	}
+
+	c.buf.WriteString(line)
+}
+
+// flush sends the metrics buffered.
+func (c *statsdClient) flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pending = false
+	c.send()
+}
+
+// send sends the buffer as a packet, connecting on the first one. c.mu must be
+// held.
+func (c *statsdClient) send() {
+	if c.buf.Len() == 0 {
+		return
+	}
+
+	defer c.buf.Reset()
+
+	if c.conn == nil {
+		conn, err := net.DialTimeout("udp", c.target.address, statsdDialTimeout)
+		if err != nil {
+			warnLog(logContext(c.logger), "failed to connect to statsd", "target", c.target.String(), "error", err)
+
+			return
+		}
+
+		c.conn = conn
+	}
+
+	if _, err := c.conn.Write(c.buf.Bytes()); err != nil {
+		debugLog(logContext(c.logger), "failed to send the metrics to statsd", "target", c.target.String(), "error", err)
+	}
+}
+
+// statsdName returns the name of the tool usable in the metric names and the
+// tags, with the characters other than letters, digits, "_" and "-" replaced
+// by "_".
+func statsdName(name string) string {
+	return strings.Map(func(char rune) rune {
+		switch {
+		case char >= 'a' && char <= 'z', char >= 'A' && char <= 'Z', char >= '0' && char <= '9',
+			char == '_', char == '-':
+			return char
+		default:
+			return '_'
+		}
+	}, name)
+}
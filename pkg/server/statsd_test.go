@@ -0,0 +1,208 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  GetStatsd
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func TestGetStatsd(t *testing.T) {
+	for index, test := range []struct {
+		name    string
+		value   string
+		want    string // String of the target, empty if nil
+		prefix  string
+		wantErr string
+	}{
+		{name: "not set", value: ""},
+		{name: "statsd", value: "statsd://127.0.0.1:8125", want: "statsd://127.0.0.1:8125", prefix: "text_mirror"},
+		{
+			name: "dogstatsd with prefix", value: "dogstatsd://localhost:8125?prefix=prod.mirror",
+			want: "dogstatsd://localhost:8125", prefix: "prod.mirror",
+		},
+		{name: "other scheme", value: "udp://127.0.0.1:8125", wantErr: "must be a statsd://"},
+		{name: "no port", value: "statsd://127.0.0.1", wantErr: "must be a statsd://"},
+		{name: "empty prefix", value: "statsd://127.0.0.1:8125?prefix=", wantErr: "invalid prefix"},
+		{name: "prefix with separator", value: "statsd://127.0.0.1:8125?prefix=a:b", wantErr: "invalid prefix"},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		setEnv(t, envNameStatsd, test.value)
+
+		target, err := GetStatsd()
+		if test.wantErr != "" {
+			require.ErrorContains(t, err, test.wantErr, name)
+
+			continue
+		}
+
+		require.NoError(t, err, name)
+
+		if test.want == "" {
+			require.Nil(t, target, name)
+
+			continue
+		}
+
+		require.Equal(t, test.want, target.String(), name)
+		require.Equal(t, test.prefix, target.prefix, name)
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  statsdClient
+// ----------------------------------------------------------------------------
+
+// listenStatsd returns the UDP listener standing for the StatsD server and the
+// target of it.
+func listenStatsd(t *testing.T, tagged bool) (net.PacketConn, *statsdTarget) {
+	t.Helper()
+
+	listener, err := new(net.ListenConfig).ListenPacket(context.Background(), "udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = listener.Close() })
+
+	target := new(statsdTarget)
+	target.address = listener.LocalAddr().String()
+	target.prefix = statsdPrefixDefault
+	target.tagged = tagged
+
+	return listener, target
+}
+
+// readStatsd returns the next packet received by the listener.
+func readStatsd(t *testing.T, listener net.PacketConn) string {
+	t.Helper()
+
+	require.NoError(t, listener.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	buf := make([]byte, 64<<10)
+	n, _, err := listener.ReadFrom(buf)
+	require.NoError(t, err)
+
+	return string(buf[:n])
+}
+
+func Test_statsdClient_record(t *testing.T) {
+	t.Parallel()
+
+	listener, target := listenStatsd(t, false)
+	client := newStatsdClient(target, slog.Default())
+
+	client.record("mirror", false, 42, 350*time.Microsecond)
+	client.record("my.tool", true, 7, 2*time.Millisecond)
+	client.flush()
+
+	require.Equal(t, strings.Join([]string{
+		"text_mirror.tool.mirror.calls:1|c",
+		"text_mirror.tool.mirror.bytes:42|c",
+		"text_mirror.tool.mirror.latency:0.35|ms",
+		"text_mirror.tool.my_tool.calls:1|c",
+		"text_mirror.tool.my_tool.errors:1|c",
+		"text_mirror.tool.my_tool.bytes:7|c",
+		"text_mirror.tool.my_tool.latency:2|ms",
+	}, "\n"), readStatsd(t, listener))
+}
+
+func Test_statsdClient_record_tagged(t *testing.T) {
+	t.Parallel()
+
+	listener, target := listenStatsd(t, true)
+	client := newStatsdClient(target, slog.Default())
+
+	client.record("mirror", true, 42, time.Millisecond)
+
+	// Sent on the scheduled flush
+	require.Equal(t, strings.Join([]string{
+		"text_mirror.calls:1|c|#tool:mirror",
+		"text_mirror.errors:1|c|#tool:mirror",
+		"text_mirror.bytes:42|c|#tool:mirror",
+		"text_mirror.latency:1|ms|#tool:mirror",
+	}, "\n"), readStatsd(t, listener))
+}
+
+func Test_statsdClient_recordCache(t *testing.T) {
+	t.Parallel()
+
+	listener, target := listenStatsd(t, false)
+	client := newStatsdClient(target, slog.Default())
+
+	client.recordCache("mirror", false)
+	client.recordCache("mirror", true)
+
+	// Sent on the scheduled flush
+	require.Equal(t, strings.Join([]string{
+		"text_mirror.tool.mirror.cache_misses:1|c",
+		"text_mirror.tool.mirror.cache_hits:1|c",
+	}, "\n"), readStatsd(t, listener))
+}
+
+func Test_statsdClient_record_split(t *testing.T) {
+	t.Parallel()
+
+	listener, target := listenStatsd(t, false)
+	client := newStatsdClient(target, slog.Default())
+
+	const calls = 50
+
+	for range calls {
+		client.record("mirror", false, 1, time.Millisecond)
+	}
+
+	client.flush()
+
+	lines := 0
+
+	for lines < calls*3 {
+		packet := readStatsd(t, listener)
+		require.LessOrEqual(t, len(packet), statsdMaxPacket, "packets should fit in the MTU")
+
+		lines += strings.Count(packet, "\n") + 1
+	}
+
+	require.Equal(t, calls*3, lines, "every metric should be sent once")
+}
+
+func Test_statsdClient_nil(t *testing.T) {
+	t.Parallel()
+
+	require.Nil(t, newStatsdClient(nil, slog.Default()))
+	require.NotPanics(t, func() {
+		var client *statsdClient
+
+		client.record("mirror", false, 1, time.Millisecond)
+	}, "nil client should push nothing")
+}
+
+// ----------------------------------------------------------------------------
+//  callStats.record
+// ----------------------------------------------------------------------------
+
+func Test_callStats_record_statsd(t *testing.T) {
+	t.Parallel()
+
+	listener, target := listenStatsd(t, true)
+
+	stats := newCallStats()
+	stats.statsd = newStatsdClient(target, slog.Default())
+
+	stats.record(toolName, testClient, false, 10, time.Millisecond)
+	stats.statsd.flush()
+
+	require.Contains(t, readStatsd(t, listener), "text_mirror.calls:1|c|#tool:"+toolName)
+
+	tools, _ := stats.snapshot()
+	require.Len(t, tools, 1, "the call should be counted as well")
+}
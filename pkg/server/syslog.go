@@ -0,0 +1,273 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Syslog output of the log entries.
+//
+// E.g.: MCP_TEXT_MIRROR_SYSLOG="udp://logs.example.com:514?facility=local0"
+const (
+	envNameSyslog = envPrefix + "SYSLOG" // env var of the syslog target of the log entries
+
+	syslogLocal           = "local" // target of the local syslog daemon
+	syslogFacilityDefault = "user"  // facility unless given by the "facility" query parameter
+	syslogTimeFmt         = "2006-01-02T15:04:05.000000Z07:00"
+	syslogDialTimeout     = 2 * time.Second  // timeout to connect to the syslog server
+	syslogWriteTimeout    = 2 * time.Second  // timeout to send an entry, so that a stuck server doesn't block logging
+	syslogRetryInterval   = 10 * time.Second // interval to reconnect after a failure. entries are dropped meanwhile
+	syslogNil             = "-"              // NILVALUE of RFC 5424
+)
+
+// Predefined errors of the syslog output.
+var (
+	errInvalidSyslog = errors.New("must be local or a udp://, tcp:// or unix:// address such as udp://127.0.0.1:514")
+	errNoLocalSyslog = errors.New("no local syslog daemon")
+	errSyslogDown    = errors.New("syslog unreachable, retrying later")
+)
+
+// syslogFacilities are the facility codes of RFC 5424 by name.
+//
+//nolint:gochecknoglobals // read-only table
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19, "local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// syslogLocalSockets are the sockets of the local syslog daemon, tried in order.
+//
+//nolint:gochecknoglobals // read-only table
+var syslogLocalSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// syslogTarget is the destination of the syslog messages.
+type syslogTarget struct {
+	network  string // udp, tcp, unix or local
+	address  string // host:port or socket path. empty for local
+	facility int
+}
+
+// GetSyslog returns the syslog target of the log entries from
+// 'MCP_TEXT_MIRROR_SYSLOG' environment variable, or nil if not set.
+//
+// The target is "local" for the local syslog daemon via /dev/log, or the URL of
+// a remote one: "udp://host:514", "tcp://host:514" or "unix:///path/to/socket".
+// The "facility" query parameter sets the facility, "user" by default. The
+// entries are sent in RFC 5424 format, in addition to the log file or the
+// standard error, at or above the log level.
+func GetSyslog() (*syslogTarget, error) {
+	return loadedSettings().syslog()
+}
+
+// syslog returns GetSyslog of the settings.
+func (v *settingValues) syslog() (*syslogTarget, error) {
+	value := v.value(envNameSyslog)
+	if value == "" {
+		return nil, nil //nolint:nilnil // no syslog is not an error
+	}
+
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q: %w", envNameSyslog, value, errInvalidSyslog)
+	}
+
+	target := new(syslogTarget)
+	target.network = parsed.Scheme
+
+	switch {
+	case parsed.Scheme == "" && parsed.Path == syslogLocal: // local?facility=...
+		target.network = syslogLocal
+
+		if runtime.GOOS == "windows" {
+			return nil, fmt.Errorf("invalid %s %q: %w on Windows", envNameSyslog, value, errNoLocalSyslog)
+		}
+	case parsed.Scheme == "udp" || parsed.Scheme == "tcp":
+		target.address = parsed.Host
+		_, _, err = net.SplitHostPort(target.address)
+	case parsed.Scheme == "unix" && parsed.Path != "":
+		target.address = parsed.Path
+	default:
+		err = errInvalidSyslog
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q: %w", envNameSyslog, value, errInvalidSyslog)
+	}
+
+	facility := parsed.Query().Get("facility")
+	if facility == "" {
+		facility = syslogFacilityDefault
+	}
+
+	code, ok := syslogFacilities[facility]
+	if !ok {
+		return nil, fmt.Errorf("invalid %s %q: unknown facility %q", envNameSyslog, value, facility)
+	}
+
+	target.facility = code
+
+	return target, nil
+}
+
+// String returns the target as configured, for the reports.
+func (t *syslogTarget) String() string {
+	if t.network == syslogLocal {
+		return syslogLocal
+	}
+
+	return t.network + "://" + t.address
+}
+
+// syslogWriter sends the log entries to the syslog target. It connects on the
+// first entry and reconnects after a failure, so that a restart of the syslog
+// daemon doesn't stop the logging.
+type syslogWriter struct {
+	target   *syslogTarget
+	hostname string
+	conn     net.Conn
+	retryAt  time.Time // time to reconnect after a failure to connect
+	mu       sync.Mutex
+}
+
+// newSyslogWriter returns the writer to the target, or nil if target is nil.
+func newSyslogWriter(target *syslogTarget) *syslogWriter {
+	if target == nil {
+		return nil
+	}
+
+	writer := new(syslogWriter)
+	writer.target = target
+	writer.hostname = syslogNil
+
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		writer.hostname = hostname
+	}
+
+	return writer
+}
+
+// send sends the log record. It retries once on a new connection if the
+// previous one was broken. After a failure to connect, the records are dropped
+// until syslogRetryInterval passes, so that logging doesn't wait for the dial
+// timeout each time.
+func (w *syslogWriter) send(record slog.Record) error {
+	message := w.format(record)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var err error
+
+	for range 2 {
+		if w.conn == nil {
+			if time.Now().Before(w.retryAt) {
+				return errSyslogDown
+			}
+
+			w.conn, err = w.dial()
+			if err != nil {
+				w.retryAt = time.Now().Add(syslogRetryInterval)
+
+				return err
+			}
+		}
+
+		_ = w.conn.SetWriteDeadline(time.Now().Add(syslogWriteTimeout))
+
+		_, err = w.conn.Write(w.frame(message))
+		if err == nil {
+			return nil
+		}
+
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+
+	return wrapError(err, "failed to send to syslog")
+}
+
+// dial connects to the target. The local daemon is tried on its usual sockets,
+// as datagram sockets first.
+func (w *syslogWriter) dial() (net.Conn, error) {
+	dialer := new(net.Dialer)
+	dialer.Timeout = syslogDialTimeout
+
+	if w.target.network != syslogLocal {
+		conn, err := dialer.Dial(w.target.network, w.target.address)
+		if err != nil && w.target.network == "unix" {
+			conn, err = dialer.Dial("unixgram", w.target.address)
+		}
+
+		return conn, wrapError(err, "failed to connect to syslog %s", w.target)
+	}
+
+	for _, path := range syslogLocalSockets {
+		for _, network := range []string{"unixgram", "unix"} {
+			conn, err := dialer.Dial(network, path)
+			if err == nil {
+				return conn, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("%w at %s", errNoLocalSyslog, strings.Join(syslogLocalSockets, ", "))
+}
+
+// frame returns the message as sent over the connection. TCP streams need the
+// octet counting framing of RFC 6587 to separate the messages.
+func (w *syslogWriter) frame(message string) []byte {
+	if w.target.network == "tcp" {
+		return []byte(strconv.Itoa(len(message)) + " " + message)
+	}
+
+	return []byte(message)
+}
+
+// format returns the RFC 5424 message of the record. E.g.
+//
+//	<14>1 2025-01-02T03:04:05.678000Z host text-mirror 1234 - - config reloaded
+func (w *syslogWriter) format(record slog.Record) string {
+	return fmt.Sprintf("<%d>1 %s %s %s %d %s %s %s",
+		w.target.facility*8+syslogSeverity(record.Level),
+		record.Time.UTC().Format(syslogTimeFmt),
+		w.hostname, serviceName, os.Getpid(),
+		syslogNil, // MSGID
+		syslogNil, // STRUCTURED-DATA
+		recordEntry(record),
+	)
+}
+
+// close closes the connection if any.
+func (w *syslogWriter) close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+}
+
+// syslogSeverity returns the RFC 5424 severity of the log level.
+func syslogSeverity(level slog.Level) int {
+	switch {
+	case level >= slog.LevelError:
+		return 3 // error
+	case level >= slog.LevelWarn:
+		return 4 // warning
+	case level >= slog.LevelInfo:
+		return 6 // informational
+	default:
+		return 7 // debug
+	}
+}
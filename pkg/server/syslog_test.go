@@ -0,0 +1,200 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  GetSyslog
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_GetSyslog(t *testing.T) {
+	for index, test := range []struct {
+		name    string
+		value   string
+		want    *syslogTarget
+		wantErr error
+	}{
+		{"unset", "", nil, nil},
+		{"local", "local", &syslogTarget{syslogLocal, "", 1}, nil},
+		{"local_facility", "local?facility=daemon", &syslogTarget{syslogLocal, "", 3}, nil},
+		{"udp", "udp://127.0.0.1:514?facility=local0", &syslogTarget{"udp", "127.0.0.1:514", 16}, nil},
+		{"tcp", "tcp://logs.example.com:6514", &syslogTarget{"tcp", "logs.example.com:6514", 1}, nil},
+		{"unix", "unix:///run/systemd/journal/syslog", &syslogTarget{"unix", "/run/systemd/journal/syslog", 1}, nil},
+		{"missing_port", "udp://127.0.0.1", nil, errInvalidSyslog},
+		{"unknown_scheme", "http://127.0.0.1:514", nil, errInvalidSyslog},
+		{"no_scheme", "127.0.0.1:514", nil, errInvalidSyslog},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		setEnv(t, envNameSyslog, test.value)
+
+		if runtime.GOOS == "windows" && test.want != nil && test.want.network == syslogLocal {
+			_, err := GetSyslog()
+			require.ErrorIs(t, err, errNoLocalSyslog, name)
+
+			continue
+		}
+
+		got, err := GetSyslog()
+		require.ErrorIs(t, err, test.wantErr, name)
+		require.Equal(t, test.want, got, name)
+	}
+
+	setEnv(t, envNameSyslog, "udp://127.0.0.1:514?facility=local9")
+
+	_, err := GetSyslog()
+	require.ErrorContains(t, err, `unknown facility "local9"`)
+}
+
+// ----------------------------------------------------------------------------
+//  syslogWriter
+// ----------------------------------------------------------------------------
+
+func Test_syslogWriter_udp(t *testing.T) {
+	t.Parallel()
+
+	conn, err := new(net.ListenConfig).ListenPacket(context.Background(), "udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer conn.Close()
+
+	writer := newSyslogWriter(&syslogTarget{"udp", conn.LocalAddr().String(), 16})
+
+	defer writer.close()
+
+	record := slog.NewRecord(time.Date(2025, 1, 2, 3, 4, 5, 678e6, time.UTC), slog.LevelWarn, "rate limit exceeded", 0)
+	record.Add(logKeyClient, "agent-1")
+
+	require.NoError(t, writer.send(record))
+
+	buf := make([]byte, 1024)
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(timeoutEventually)))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+
+	hostname, _ := os.Hostname()
+	require.Equal(t,
+		fmt.Sprintf("<132>1 2025-01-02T03:04:05.678000Z %s text-mirror %d - - rate limit exceeded client=agent-1",
+			hostname, os.Getpid()),
+		string(buf[:n]), "local0 (16) * 8 + warning (4)")
+}
+
+func Test_syslogWriter_tcp(t *testing.T) {
+	t.Parallel()
+
+	listener, err := new(net.ListenConfig).Listen(context.Background(), "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer listener.Close()
+
+	writer := newSyslogWriter(&syslogTarget{"tcp", listener.Addr().String(), 1})
+
+	defer writer.close()
+
+	received := make(chan string, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+
+		var messages []string
+
+		for range 2 { // "<length> <message>"
+			length, _ := reader.ReadString(' ')
+			size, _ := strconv.Atoi(strings.TrimSpace(length))
+			message := make([]byte, size)
+			_, _ = io.ReadFull(reader, message)
+			messages = append(messages, string(message))
+		}
+
+		received <- strings.Join(messages, "\n")
+	}()
+
+	for _, msg := range []string{"reload failed", "session closed"} {
+		record := slog.NewRecord(time.Now(), slog.LevelError, msg, 0)
+		require.NoError(t, writer.send(record))
+	}
+
+	select {
+	case got := <-received:
+		require.Regexp(t, regexp.MustCompile(`^<11>1 .+ reload failed\n<11>1 .+ session closed$`), got,
+			"messages should be framed by their length")
+	case <-time.After(timeoutEventually):
+		require.Fail(t, "messages should be received")
+	}
+}
+
+func Test_syslogWriter_unreachable(t *testing.T) {
+	t.Parallel()
+
+	path := t.TempDir() + "/missing.sock"
+	writer := newSyslogWriter(&syslogTarget{"unix", path, 1})
+
+	err := writer.send(slog.NewRecord(time.Now(), slog.LevelError, "lost", 0))
+	require.ErrorContains(t, err, "failed to connect to syslog unix://"+path)
+
+	err = writer.send(slog.NewRecord(time.Now(), slog.LevelError, "lost", 0))
+	require.ErrorIs(t, err, errSyslogDown, "it should not reconnect right after a failure")
+	require.Nil(t, newSyslogWriter(nil))
+}
+
+// ----------------------------------------------------------------------------
+//  logHandler with syslog
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_logAt_syslog(t *testing.T) {
+	conn, err := new(net.ListenConfig).ListenPacket(context.Background(), "udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer conn.Close()
+
+	unsetEnv(t, envNameDebug, envNameLogLevel)
+	setEnv(t, envNameSyslog, "udp://"+conn.LocalAddr().String())
+
+	logger := newLogger(false, "")
+	handler, ok := logger.Handler().(*logHandler)
+	require.True(t, ok)
+
+	defer handler.swapSyslog(nil)
+
+	ctx := withLogger(context.Background(), logger)
+	debugLog(ctx, "Test_logAt_syslog below the level")
+	errorLog(ctx, "Test_logAt_syslog failed", logKeyError, "boom")
+
+	buf := make([]byte, 1024)
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(timeoutEventually)))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	require.Regexp(t, `^<11>1 \S+ \S+ text-mirror \d+ - - Test_logAt_syslog failed error=boom$`, string(buf[:n]),
+		"entries at or above the log level should be sent")
+
+	// Stopped on reload
+	setEnv(t, envNameSyslog, "")
+	reopenLog(logger)
+	require.Nil(t, handler.syslog.Load())
+}
@@ -0,0 +1,163 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/KEINOS/mcp-text-mirror/pkg/mirror"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rivo/uniseg"
+)
+
+// Text statistics tool.
+const (
+	textStatsToolName        = "text_stats"
+	textStatsToolDescription = "Measures the UTF-8 text: its size in bytes, code points (runes), grapheme clusters " +
+		"(characters as seen by humans), words and lines, and its display width in a monospace font"
+)
+
+// TextStatsInput is the input for the text_stats tool.
+type TextStatsInput struct {
+	Text string `json:"text" jsonschema:"The UTF-8 text to measure."`
+}
+
+// TextStatsOutput is the output from the text_stats tool.
+type TextStatsOutput struct {
+	Bytes        int `json:"bytes"          jsonschema:"The size of the text in bytes, as UTF-8."`
+	Runes        int `json:"runes"          jsonschema:"The number of Unicode code points. Each invalid UTF-8 byte counts as one."`
+	Graphemes    int `json:"graphemes"      jsonschema:"The number of grapheme clusters, i.e. the characters as seen by humans, the unit of the mirror tool."`
+	Words        int `json:"words"          jsonschema:"The number of words per UAX #29, not counting the whitespace and the punctuation, as reverse_words does."`
+	Lines        int `json:"lines"          jsonschema:"The number of lines ended by LF or CR LF, not counting the empty one after a trailing line break."`
+	Width        int `json:"width"          jsonschema:"The display width of the text in a monospace font, the wide characters such as CJK and emoji taking two columns."`
+	MaxLineWidth int `json:"max_line_width" jsonschema:"The display width of the widest line."`
+}
+
+// textStatsTool is the text_stats tool, telling how long a text really is.
+type textStatsTool struct{}
+
+// Name returns the name of the tool.
+func (textStatsTool) Name() string { return textStatsToolName }
+
+// Description returns the description of the tool.
+func (textStatsTool) Description() string { return textStatsToolDescription }
+
+// Schema returns the schemas of the input and the output of the tool.
+func (textStatsTool) Schema() (*jsonschema.Schema, *jsonschema.Schema) {
+	return textStatsInputSchema(), nil
+}
+
+// Annotations returns the hints of the tool.
+func (textStatsTool) Annotations() *mcp.ToolAnnotations { return readOnlyAnnotations() }
+
+// Handler returns handleTextStats.
+func (textStatsTool) Handler(*serverState) ToolHandler { return TypedHandler(handleTextStats) }
+
+// cacheable returns true, as the result depends on the text only.
+func (textStatsTool) cacheable(map[string]any) bool { return true }
+
+// textStatsInputSchema returns the JSON schema of TextStatsInput.
+func textStatsInputSchema() *jsonschema.Schema {
+	schema := mustInferSchema[TextStatsInput]()
+	schema.Title = "Text statistics input"
+	schema.Required = []string{"text"}
+
+	text := schema.Properties["text"]
+	text.Title = "Text"
+	text.MaxLength = jsonschema.Ptr(textMaxLength)
+	text.Examples = []any{"👍🏽 Café 世界"}
+
+	return schema
+}
+
+// handleTextStats returns the measures of the text.
+func handleTextStats(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input TextStatsInput,
+) (*mcp.CallToolResult, TextStatsOutput, error) {
+	err := ctx.Err()
+	if err != nil {
+		return nil, TextStatsOutput{}, wrapError(err, "request canceled")
+	}
+
+	err = checkTextLimit(req, input.Text)
+	if err != nil {
+		return nil, TextStatsOutput{}, err
+	}
+
+	start := time.Now()
+
+	stats, err := measureText(ctx, input.Text)
+	if err != nil {
+		return nil, TextStatsOutput{}, err
+	}
+
+	callLog(ctx, "text measured", callLogAttrs(ctx, req, logKeyInputSize, len(input.Text), logKeyDuration, time.Since(start),
+		logKeyText, userText(input.Text))...)
+
+	return nil, stats, nil
+}
+
+// measureText returns the measures of the text. It stops once ctx is
+// canceled, checking every mirror.CheckInterval grapheme clusters and words.
+func measureText(ctx context.Context, text string) (TextStatsOutput, error) {
+	var stats TextStatsOutput
+
+	stats.Bytes = len(text)
+	stats.Runes = utf8.RuneCountInString(text)
+
+	state, lineWidth := -1, 0
+
+	for rest := text; rest != ""; {
+		if stats.Graphemes%mirror.CheckInterval == 0 && ctx.Err() != nil {
+			return TextStatsOutput{}, wrapError(ctx.Err(), "request canceled after %d graphemes", stats.Graphemes)
+		}
+
+		var (
+			cluster    string
+			boundaries int
+		)
+
+		cluster, rest, boundaries, state = uniseg.StepString(rest, state)
+		width := boundaries >> uniseg.ShiftWidth
+
+		stats.Graphemes++
+		stats.Width += width
+
+		if strings.HasSuffix(cluster, "\n") {
+			stats.MaxLineWidth = max(stats.MaxLineWidth, lineWidth)
+			lineWidth = 0
+		} else {
+			lineWidth += width
+		}
+	}
+
+	stats.MaxLineWidth = max(stats.MaxLineWidth, lineWidth)
+
+	state = -1
+
+	for rest, segments := text, 0; rest != ""; segments++ {
+		if segments%mirror.CheckInterval == 0 && ctx.Err() != nil {
+			return TextStatsOutput{}, wrapError(ctx.Err(), "request canceled after %d words", stats.Words)
+		}
+
+		var segment string
+
+		segment, rest, state = uniseg.FirstWordInString(rest, state)
+		if isWord(segment) {
+			stats.Words++
+		}
+	}
+
+	if text != "" {
+		stats.Lines = strings.Count(text, "\n")
+		if !strings.HasSuffix(text, "\n") {
+			stats.Lines++
+		}
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  measureText
+// ----------------------------------------------------------------------------
+
+func Test_measureText(t *testing.T) {
+	t.Parallel()
+
+	for index, test := range []struct {
+		name  string
+		input string
+		want  TextStatsOutput
+	}{
+		{"empty", "", TextStatsOutput{}},
+		{"ascii", "Hello, world!", TextStatsOutput{Bytes: 13, Runes: 13, Graphemes: 13, Words: 2, Lines: 1, Width: 13, MaxLineWidth: 13}},
+		{"combining_mark", "café", TextStatsOutput{Bytes: 6, Runes: 5, Graphemes: 4, Words: 1, Lines: 1, Width: 4, MaxLineWidth: 4}},
+		{"emoji", "👍🏽", TextStatsOutput{Bytes: 8, Runes: 2, Graphemes: 1, Words: 1, Lines: 1, Width: 2, MaxLineWidth: 2}},
+		{"zwj_sequence", "👨‍👩‍👧", TextStatsOutput{Bytes: 18, Runes: 5, Graphemes: 1, Words: 1, Lines: 1, Width: 2, MaxLineWidth: 2}},
+		{"cjk", "世界", TextStatsOutput{Bytes: 6, Runes: 2, Graphemes: 2, Words: 2, Lines: 1, Width: 4, MaxLineWidth: 4}},
+		{"lines", "ab\r\n世界\nc\n", TextStatsOutput{Bytes: 13, Runes: 9, Graphemes: 8, Words: 4, Lines: 3, Width: 7, MaxLineWidth: 4}},
+		{"invalid_utf8", "a\xffb", TextStatsOutput{Bytes: 3, Runes: 3, Graphemes: 3, Words: 3, Lines: 1, Width: 3, MaxLineWidth: 3}},
+	} {
+		t.Run(fmt.Sprintf("Test #%d: %s", index+1, test.name), func(t *testing.T) {
+			t.Parallel()
+
+			got, err := measureText(context.Background(), test.input)
+			require.NoError(t, err)
+			require.Equal(t, test.want, got)
+		})
+	}
+}
+
+func Test_measureText_canceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := measureText(ctx, "abc")
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+// ----------------------------------------------------------------------------
+//  handleTextStats
+// ----------------------------------------------------------------------------
+
+func Test_handleTextStats(t *testing.T) {
+	t.Parallel()
+
+	session := connectInMemory(t, newServer())
+
+	res := callTool(t, session, textStatsToolName, map[string]any{"text": "👍🏽 Café"})
+	require.False(t, res.IsError)
+	require.Equal(t, map[string]any{
+		"bytes": float64(14), "runes": float64(7), "graphemes": float64(6), "words": float64(2),
+		"lines": float64(1), "width": float64(7), "max_line_width": float64(7),
+	}, res.StructuredContent)
+}
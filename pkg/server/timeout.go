@@ -0,0 +1,63 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Tool call timeout configuration.
+const (
+	envNameCallTimeout = envPrefix + "CALL_TIMEOUT" // env var of the max duration of a tool call. e.g. 30s. unset disables it
+)
+
+// errCallTimeout is reported to the client if the tool call took too long.
+var errCallTimeout = errors.New("tool call timed out")
+
+// GetCallTimeout returns the max duration of a tool call from
+// 'MCP_TEXT_MIRROR_CALL_TIMEOUT' environment variable, so that pathological
+// inputs can't hold a worker forever.
+//
+// A zero duration means tool calls have no deadline other than the client's.
+func GetCallTimeout() (time.Duration, error) {
+	return loadedSettings().callTimeout()
+}
+
+// callTimeout returns GetCallTimeout of the settings.
+func (v *settingValues) callTimeout() (time.Duration, error) {
+	return v.envDuration(envNameCallTimeout)
+}
+
+// newCallTimeout returns a middleware which runs the tool calls with the
+// timeout as the context deadline. Timed-out calls are reported as tool errors.
+//
+// The timeout covers the tool execution only, not the wait for a worker. The
+// tools stop at the deadline as they do on cancellation.
+func newCallTimeout(timeout time.Duration) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != methodCallTool {
+				return next(ctx, method, req)
+			}
+
+			callCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			res, err := next(callCtx, method, req)
+
+			// Canceled by the client or the server shutting down otherwise
+			if ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+				err = fmt.Errorf("%w after %s", errCallTimeout, timeout)
+				warnLog(ctx, "tool call timed out", logKeyRequestID, requestID(ctx), logKeySession, sessionLog(req),
+					logKeyError, err)
+
+				return toolErrorResult(err), nil
+			}
+
+			return res, err
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  GetCallTimeout
+// ----------------------------------------------------------------------------
+
+func Test_GetCallTimeout(t *testing.T) {
+	for index, test := range []struct {
+		name    string
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"unset", "", 0, false},
+		{"custom", "30s", 30 * time.Second, false},
+		{"invalid", "thirty", 0, true},
+		{"negative", "-1s", 0, true},
+	} {
+		t.Run(fmt.Sprintf("Test #%d: %s", index+1, test.name), func(t *testing.T) {
+			setEnv(t, envNameCallTimeout, test.value)
+
+			timeout, err := GetCallTimeout()
+			if test.wantErr {
+				require.ErrorIs(t, err, errInvalidDuration)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.want, timeout)
+		})
+	}
+}
+
+//nolint:paralleltest // sets env var
+func Test_run_invalid_call_timeout(t *testing.T) {
+	setEnv(t, envNameCallTimeout, "forever")
+
+	err := newApp().serve(context.Background(), nil)
+	require.ErrorIs(t, err, errInvalidDuration)
+}
+
+// ----------------------------------------------------------------------------
+//  newCallTimeout
+// ----------------------------------------------------------------------------
+
+func Test_newCallTimeout(t *testing.T) {
+	t.Parallel()
+
+	// Handler blocking until the context is done, like a pathological input
+	blocking := func(ctx context.Context, _ string, _ mcp.Request) (mcp.Result, error) {
+		<-ctx.Done()
+
+		return nil, ctx.Err()
+	}
+
+	handler := newCallTimeout(10 * time.Millisecond)(blocking)
+
+	res, err := handler(context.Background(), methodCallTool, new(mcp.CallToolRequest))
+	require.NoError(t, err, "timeout should be reported as a tool error")
+
+	toolRes, ok := res.(*mcp.CallToolResult)
+	require.True(t, ok)
+	require.True(t, toolRes.IsError)
+	require.Contains(t, toolRes.Content[0].(*mcp.TextContent).Text, "tool call timed out after 10ms") //nolint:forcetypeassert // text content
+
+	// Canceled by the client is not a timeout
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = handler(ctx, methodCallTool, new(mcp.CallToolRequest))
+	require.ErrorIs(t, err, context.Canceled)
+
+	// Other methods have no deadline
+	_, err = newCallTimeout(time.Nanosecond)(func(ctx context.Context, _ string, _ mcp.Request) (mcp.Result, error) {
+		_, hasDeadline := ctx.Deadline()
+		require.False(t, hasDeadline)
+
+		return nil, nil //nolint:nilnil // no result needed
+	})(context.Background(), "tools/list", new(mcp.ListToolsRequest))
+	require.NoError(t, err)
+}
+
+//nolint:paralleltest // sets env var
+func Test_newServer_call_timeout(t *testing.T) {
+	setEnv(t, envNameCallTimeout, "1m")
+
+	session := connectInMemory(t, newServer())
+
+	// Fast calls are not affected
+	res := callTool(t, session, toolName, map[string]any{"text": "abc"})
+	require.False(t, res.IsError)
+}
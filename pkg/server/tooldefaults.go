@@ -0,0 +1,178 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Per tool default arguments.
+//
+// E.g.: MCP_TEXT_MIRROR_TOOLS_DEFAULTS="mirror.render=png"
+const (
+	envNameToolDefaults = envPrefix + "TOOLS_DEFAULTS" // env var of the default arguments of the tools
+	toolDefaultSep      = ";"                          // separator between the defaults
+	toolDefaultNameSep  = "="                          // separator between the argument and the value
+	toolDefaultArgSep   = "."                          // separator between the tool and the argument names
+)
+
+// Predefined errors of the default arguments.
+var (
+	errToolDefaultFormat = errors.New("must be in 'tool.argument=value' form")
+	errUnknownArgument   = errors.New("unknown argument")
+	errRequiredArgument  = errors.New("required arguments can't have a default")
+)
+
+// toolInputSchemas returns the input schemas of the built-in tools by name.
+func toolInputSchemas() map[string]*jsonschema.Schema {
+	return map[string]*jsonschema.Schema{
+		toolName:      mirrorInputSchema(),
+		batchToolName: mirrorBatchInputSchema(),
+		adminToolName: adminInputSchema(),
+		statsToolName: statsInputSchema(),
+	}
+}
+
+// GetToolDefaults returns the default arguments of the tools by tool and
+// argument name, from 'MCP_TEXT_MIRROR_TOOLS_DEFAULTS' environment variable.
+// The defaults apply when the client omits the optional arguments.
+//
+// The value is a semicolon separated list of 'tool.argument=value' entries. The
+// values of non-string arguments are in JSON. Each value must be valid per the
+// input schema of the tool.
+func GetToolDefaults() (map[string]map[string]any, error) {
+	return loadedSettings().toolDefaults()
+}
+
+// toolDefaults returns GetToolDefaults of the settings.
+func (v *settingValues) toolDefaults() (map[string]map[string]any, error) {
+	schemas := toolInputSchemas()
+	defaults := make(map[string]map[string]any)
+
+	for entry := range strings.SplitSeq(v.value(envNameToolDefaults), toolDefaultSep) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		arg, argValue, found := strings.Cut(entry, toolDefaultNameSep)
+		tool, arg, dotted := strings.Cut(strings.TrimSpace(arg), toolDefaultArgSep)
+
+		if !found || !dotted || tool == "" || arg == "" {
+			return nil, fmt.Errorf("invalid %s entry %q: %w", envNameToolDefaults, entry, errToolDefaultFormat)
+		}
+
+		schema, ok := schemas[tool]
+		if !ok {
+			return nil, fmt.Errorf("invalid %s entry %q: %w: %s", envNameToolDefaults, entry, errUnknownTool, tool)
+		}
+
+		parsed, err := parseToolDefault(schema, arg, strings.TrimSpace(argValue))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s entry %q: %w", envNameToolDefaults, entry, err)
+		}
+
+		if defaults[tool] == nil {
+			defaults[tool] = make(map[string]any)
+		}
+
+		defaults[tool][arg] = parsed
+	}
+
+	return defaults, nil
+}
+
+// parseToolDefault parses the default value of the optional argument of the
+// tool with the input schema, and validates it against the schema.
+func parseToolDefault(schema *jsonschema.Schema, arg, value string) (any, error) {
+	property, ok := schema.Properties[arg]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errUnknownArgument, arg)
+	}
+
+	if slices.Contains(schema.Required, arg) {
+		return nil, fmt.Errorf("%w: %s", errRequiredArgument, arg)
+	}
+
+	var parsed any = value
+
+	if property.Type != "string" && !slices.Contains(property.Types, "string") {
+		err := json.Unmarshal([]byte(value), &parsed)
+		if err != nil {
+			return nil, wrapError(err, "invalid JSON value of %s", arg)
+		}
+	}
+
+	resolved, err := property.Resolve(nil)
+	if err == nil {
+		err = resolved.Validate(parsed)
+	}
+
+	if err != nil {
+		return nil, wrapError(err, "invalid value of %s", arg)
+	}
+
+	return parsed, nil
+}
+
+// toolDefaults fills in the omitted arguments of the tool calls with the
+// configured defaults, before the arguments are validated against the input
+// schema. They are replaced on reload.
+type toolDefaults struct {
+	args atomic.Pointer[map[string]map[string]any]
+}
+
+// load replaces the defaults with the configured ones. Invalid configurations
+// are reported by loadSettings beforehand.
+func (d *toolDefaults) load() {
+	defaults, _ := GetToolDefaults()
+
+	d.args.Store(&defaults)
+}
+
+// middleware adds the default arguments the tool call omits.
+func (d *toolDefaults) middleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		params, ok := req.GetParams().(*mcp.CallToolParamsRaw)
+		if method != methodCallTool || !ok || params == nil || d.args.Load() == nil {
+			return next(ctx, method, req)
+		}
+
+		defaults := (*d.args.Load())[params.Name]
+		if len(defaults) == 0 {
+			return next(ctx, method, req)
+		}
+
+		var args map[string]json.RawMessage
+
+		if len(params.Arguments) > 0 {
+			// Leave invalid arguments to the validation of the tool
+			if json.Unmarshal(params.Arguments, &args) != nil {
+				return next(ctx, method, req)
+			}
+		}
+
+		if args == nil { // omitted or null
+			args = make(map[string]json.RawMessage, len(defaults))
+		}
+
+		for arg, value := range defaults {
+			if _, ok := args[arg]; ok {
+				continue
+			}
+
+			args[arg], _ = json.Marshal(value)
+		}
+
+		params.Arguments, _ = json.Marshal(args)
+
+		return next(ctx, method, req)
+	}
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  GetToolDefaults
+// ----------------------------------------------------------------------------
+
+func Test_GetToolDefaults(t *testing.T) {
+	for index, test := range []struct {
+		name    string
+		value   string
+		want    map[string]map[string]any
+		wantErr error
+	}{
+		{"unset", "", map[string]map[string]any{}, nil},
+		{"render", " mirror.render = png ;", map[string]map[string]any{toolName: {"render": renderPNG}}, nil},
+		{"multiple", "mirror.render=png;admin.tool=mirror", map[string]map[string]any{
+			toolName:      {"render": renderPNG},
+			adminToolName: {"tool": toolName},
+		}, nil},
+		{"no_value_separator", "mirror.render", nil, errToolDefaultFormat},
+		{"no_tool", "render=png", nil, errToolDefaultFormat},
+		{"unknown_tool", "mirorr.render=png", nil, errUnknownTool},
+		{"unknown_argument", "mirror.unit=word", nil, errUnknownArgument},
+		{"required_argument", "mirror.text=abc", nil, errRequiredArgument},
+		{"invalid_value", "mirror.render=gif", nil, nil},
+	} {
+		t.Run(fmt.Sprintf("Test #%d: %s", index+1, test.name), func(t *testing.T) {
+			setEnv(t, envNameToolDefaults, test.value)
+
+			defaults, err := GetToolDefaults()
+
+			switch {
+			case test.wantErr != nil:
+				require.ErrorIs(t, err, test.wantErr)
+				require.ErrorContains(t, err, envNameToolDefaults)
+			case test.want == nil:
+				require.ErrorContains(t, err, "invalid value of render")
+			default:
+				require.NoError(t, err)
+				require.Equal(t, test.want, defaults)
+			}
+		})
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  toolDefaults
+// ----------------------------------------------------------------------------
+
+func Test_toolDefaults_middleware(t *testing.T) {
+	t.Parallel()
+
+	defaults := new(toolDefaults)
+	defaults.args.Store(&map[string]map[string]any{toolName: {"render": renderPNG, "path": "a.txt"}})
+
+	var got json.RawMessage
+
+	handler := defaults.middleware(func(_ context.Context, _ string, req mcp.Request) (mcp.Result, error) {
+		got = req.GetParams().(*mcp.CallToolParamsRaw).Arguments //nolint:forcetypeassert // tools/call
+
+		return nil, nil //nolint:nilnil // no result needed
+	})
+
+	for index, test := range []struct {
+		name string
+		tool string
+		args string
+		want string
+	}{
+		{"omitted", toolName, `{"text":"abc"}`, `{"path":"a.txt","render":"png","text":"abc"}`},
+		{"given", toolName, `{"text":"abc","render":"gif"}`, `{"path":"a.txt","render":"gif","text":"abc"}`},
+		{"null", toolName, `null`, `{"path":"a.txt","render":"png"}`},
+		{"invalid", toolName, `[1]`, `[1]`},
+		{"other_tool", batchToolName, `{"texts":["abc"]}`, `{"texts":["abc"]}`},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		req := new(mcp.CallToolRequest)
+		req.Params = &mcp.CallToolParamsRaw{Name: test.tool, Arguments: json.RawMessage(test.args)}
+
+		_, err := handler(context.Background(), methodCallTool, req)
+		require.NoError(t, err, name)
+		require.JSONEq(t, test.want, string(got), name)
+	}
+}
+
+//nolint:paralleltest // sets env var
+func Test_newServer_tool_defaults(t *testing.T) {
+	setEnv(t, envNameToolDefaults, "mirror.render=png")
+
+	session := connectInMemory(t, newServer())
+
+	res := callTool(t, session, toolName, map[string]any{"text": "abc"})
+	require.False(t, res.IsError)
+	require.Len(t, res.Content, 2, "default render should add the image")
+
+	_, ok := res.Content[1].(*mcp.ImageContent)
+	require.True(t, ok)
+}
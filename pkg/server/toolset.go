@@ -0,0 +1,327 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Tool allowlist and denylist.
+const (
+	envNameToolsEnabled  = envPrefix + "TOOLS_ENABLED"  // env var of the comma separated tools to register. unset registers all
+	envNameToolsDisabled = envPrefix + "TOOLS_DISABLED" // env var of the comma separated tools not to register
+	envNameToolsPreset   = envPrefix + "TOOLS_PRESET"   // env var of the preset of the tools to register if no allowlist is set
+	toolsPresetMinimal   = "minimal"                    // the mirror tool only
+	toolsPresetFull      = "full"                       // all the built-in tools. default
+)
+
+// Predefined errors of the tool set.
+var (
+	errUnknownTool   = errors.New("unknown tool")
+	errUnknownPreset = errors.New("must be minimal or full")
+	errToolExists    = errors.New("tool name already taken")
+)
+
+// toolsPresets are the tools registered by preset, for the deployments which
+// don't list them one by one. nil registers all.
+//
+//nolint:gochecknoglobals // read-only table
+var toolsPresets = map[string][]string{
+	toolsPresetMinimal: {toolName},
+	toolsPresetFull:    nil,
+}
+
+// GetToolsPreset returns the preset of the tools from
+// 'MCP_TEXT_MIRROR_TOOLS_PRESET' environment variable. It defaults to full.
+func GetToolsPreset() (string, error) {
+	return loadedSettings().toolsPreset()
+}
+
+// toolsPreset returns GetToolsPreset of the settings.
+func (v *settingValues) toolsPreset() (string, error) {
+	preset := v.value(envNameToolsPreset)
+	if preset == "" {
+		return toolsPresetFull, nil
+	}
+
+	if _, ok := toolsPresets[preset]; !ok {
+		return "", fmt.Errorf("invalid %s %q: %w", envNameToolsPreset, preset, errUnknownPreset)
+	}
+
+	return preset, nil
+}
+
+// GetToolFilter returns the function reporting whether the tool should be
+// registered, from 'MCP_TEXT_MIRROR_TOOLS_ENABLED' (allowlist) and
+// 'MCP_TEXT_MIRROR_TOOLS_DISABLED' (denylist) environment variables.
+//
+// Tools not in the allowlist, if set, and tools in the denylist are neither
+// registered nor listed, and can't be enabled with the admin tool. This applies
+// to the tools of the upstream servers and the plugins as well, listed with
+// their prefix such as "fs_read". Unknown tool names are errors to catch typos.
+// Without allowlist, the built-in tools of the preset of
+// 'MCP_TEXT_MIRROR_TOOLS_PRESET' are allowed, along with all the upstream and
+// plugin tools.
+func GetToolFilter() (func(name string) bool, error) {
+	return loadedSettings().toolFilter()
+}
+
+// toolFilter returns GetToolFilter of the settings.
+func (v *settingValues) toolFilter() (func(name string) bool, error) {
+	enabled := splitList(v.value(envNameToolsEnabled))
+	disabled := splitList(v.value(envNameToolsDisabled))
+
+	for _, name := range slices.Concat(enabled, disabled) {
+		if !v.isKnownTool(name) {
+			return nil, fmt.Errorf("%w: %q. must be one of %s, or an upstream or plugin tool with its prefix",
+				errUnknownTool, name, strings.Join(builtinTools, ", "))
+		}
+	}
+
+	preset, err := v.toolsPreset()
+	if err != nil {
+		return nil, err
+	}
+
+	presetTools := toolsPresets[preset]
+
+	return func(name string) bool {
+		switch {
+		case slices.Contains(disabled, name):
+			return false
+		case len(enabled) > 0:
+			return slices.Contains(enabled, name)
+		case len(presetTools) > 0 && slices.Contains(builtinTools, name):
+			return slices.Contains(presetTools, name)
+		default:
+			return true
+		}
+	}, nil
+}
+
+// isKnownTool reports whether the tool can be listed in the allowlist and the
+// denylist of the settings: a built-in tool, or a tool prefixed with the name of a configured
+// upstream server or plugin. The tools of the upstreams and the plugins are
+// only known once connected, so any name with their prefix is accepted.
+func (v *settingValues) isKnownTool(name string) bool {
+	if slices.Contains(builtinTools, name) {
+		return true
+	}
+
+	upstreams, _ := v.upstreams() // invalid values are reported by their checks
+	for _, upstream := range upstreams {
+		if strings.HasPrefix(name, upstream.Name+upstreamToolSep) {
+			return true
+		}
+	}
+
+	dir, _ := v.plugins() // invalid values are reported by their checks
+	if dir == "" {
+		return false
+	}
+
+	plugins, _ := findPlugins(dir)
+	for plugin := range plugins {
+		if strings.HasPrefix(name, plugin+pluginToolSep) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// toolSet keeps the tools which can be enabled or disabled at runtime.
+//
+// Disabled tools are removed from the server, so they are excluded from
+// tools/list and cannot be called. The server emits
+// notifications/tools/list_changed on each change, so that connected clients
+// refresh their tool list without reconnecting.
+type toolSet struct {
+	server   *mcp.Server
+	logger   *slog.Logger                 // logs the changes. nil logs to slog.Default
+	allowed  func(name string) bool       // reports whether the tool can be registered. nil allows all
+	adders   map[string]func(*mcp.Server) // adds the tool to the server by name
+	disabled map[string]bool
+	mu       sync.Mutex
+}
+
+// ToolState is the state of a tool of the toolSet.
+type ToolState struct {
+	Name    string `json:"name"    jsonschema:"The name of the tool."`
+	Enabled bool   `json:"enabled" jsonschema:"Whether the tool is listed and can be called."`
+}
+
+// newToolSet returns an empty toolSet of the server.
+func newToolSet(server *mcp.Server) *toolSet {
+	tools := new(toolSet)
+	tools.server = server
+	tools.adders = make(map[string]func(*mcp.Server))
+	tools.disabled = make(map[string]bool)
+
+	return tools
+}
+
+// addTool registers the typed tool and adds it to the server, enabled. See
+// register.
+func addTool[In, Out any](tools *toolSet, tool *mcp.Tool, handler mcp.ToolHandlerFor[In, Out]) {
+	tools.register(tool, TypedHandler(handler))
+}
+
+// register registers the tool and adds it to the server, enabled. The tool is
+// not added if it is not allowed by the configuration, until a reload allows
+// it.
+func (t *toolSet) register(tool *mcp.Tool, handler ToolHandler) {
+	t.registerAdder(tool.Name, func(server *mcp.Server) { handler.add(server, tool) })
+}
+
+// registerLazy registers the named tool as register does, but builds its
+// definition and handler once first added, so that the tools not allowed by
+// the configuration cost nothing, such as inferring their schemas.
+func (t *toolSet) registerLazy(name string, build func() (*mcp.Tool, ToolHandler)) {
+	build = sync.OnceValues(build)
+
+	t.registerAdder(name, func(server *mcp.Server) {
+		tool, handler := build()
+		handler.add(server, tool)
+	})
+}
+
+// registerExternal registers the tool of an upstream server or a plugin as
+// register does, subject to the allowlist and the denylist like the built-in
+// tools. It returns errToolExists if a built-in tool or another registered one
+// has the same name, rather than replacing it.
+func (t *toolSet) registerExternal(tool *mcp.Tool, handler mcp.ToolHandler) error {
+	t.mu.Lock()
+	_, taken := t.adders[tool.Name]
+	t.mu.Unlock()
+
+	if taken || slices.Contains(builtinTools, tool.Name) {
+		return fmt.Errorf("%w: %q", errToolExists, tool.Name)
+	}
+
+	t.register(tool, rawHandler(handler))
+
+	return nil
+}
+
+// registerAdder registers the function adding the named tool to the server,
+// and adds it if allowed.
+func (t *toolSet) registerAdder(name string, add func(*mcp.Server)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.adders[name] = add
+	delete(t.disabled, name)
+
+	if !t.allows(name) {
+		debugLog(logContext(t.logger), "tool disabled by configuration", logKeyTool, name)
+
+		return
+	}
+
+	add(t.server)
+}
+
+// setEnabled enables or disables the registered tool. It does nothing if the
+// tool is already in the given state.
+func (t *toolSet) setEnabled(name string, enabled bool) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	add, ok := t.adders[name]
+	if !ok || !t.allows(name) {
+		return fmt.Errorf("%w: %q", errUnknownTool, name)
+	}
+
+	if t.disabled[name] == !enabled {
+		return nil
+	}
+
+	if enabled {
+		delete(t.disabled, name)
+		add(t.server)
+	} else {
+		t.disabled[name] = true
+		t.server.RemoveTools(name)
+	}
+
+	infoLog(logContext(t.logger), "tool enabled or disabled", logKeyTool, name, "enabled", enabled)
+
+	return nil
+}
+
+// states returns the states of the registered tools allowed by the
+// configuration in name order.
+func (t *toolSet) states() []ToolState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	states := make([]ToolState, 0, len(t.adders))
+	for name := range t.adders {
+		if t.allows(name) {
+			states = append(states, ToolState{Name: name, Enabled: !t.disabled[name]})
+		}
+	}
+
+	slices.SortFunc(states, func(a, b ToolState) int { return strings.Compare(a.Name, b.Name) })
+
+	return states
+}
+
+// enabled reports whether the tool is registered and enabled.
+func (t *toolSet) enabled(name string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	_, ok := t.adders[name]
+
+	return ok && t.allows(name) && !t.disabled[name]
+}
+
+// isAllowed reports whether the tool can be registered per configuration.
+func (t *toolSet) isAllowed(name string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.allows(name)
+}
+
+// allows is isAllowed without locking.
+func (t *toolSet) allows(name string) bool {
+	return t.allowed == nil || t.allowed(name)
+}
+
+// setAllowed replaces the filter of the tools allowed by the configuration, and
+// adds or removes the enabled tools accordingly. Connected clients are notified
+// if the tool list changes.
+func (t *toolSet) setAllowed(allowed func(name string) bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	previous := make(map[string]bool, len(t.adders))
+	for name := range t.adders {
+		previous[name] = t.allows(name)
+	}
+
+	t.allowed = allowed
+
+	for name, add := range t.adders {
+		now := t.allows(name)
+		if now == previous[name] || t.disabled[name] {
+			continue
+		}
+
+		if now {
+			add(t.server)
+		} else {
+			t.server.RemoveTools(name)
+		}
+
+		debugLog(logContext(t.logger), "tool allowed or disallowed by configuration", logKeyTool, name, "allowed", now)
+	}
+}
@@ -0,0 +1,235 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  toolSet
+// ----------------------------------------------------------------------------
+
+func Test_toolSet_setEnabled(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v0.0.0"}, nil) //nolint:exhaustruct // minimal server
+	tools := newToolSet(server)
+
+	tool := new(mcp.Tool)
+	tool.Name = toolName
+
+	addTool(tools, tool, handleReverse)
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+
+	changed := make(chan struct{}, 1)
+	options := new(mcp.ClientOptions)
+	options.ToolListChangedHandler = func(context.Context, *mcp.ToolListChangedRequest) {
+		changed <- struct{}{}
+	}
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.0"}, options) //nolint:exhaustruct // minimal client
+
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+
+	defer func() {
+		_ = session.Close()
+		_ = serverSession.Wait()
+	}()
+
+	waitChanged := func() {
+		t.Helper()
+
+		select {
+		case <-changed:
+		case <-time.After(timeoutEventually):
+			require.Fail(t, "tools/list_changed should be notified")
+		}
+	}
+
+	listed := func() []string {
+		res, err := session.ListTools(ctx, nil)
+		require.NoError(t, err)
+
+		var names []string
+		for _, tool := range res.Tools {
+			names = append(names, tool.Name)
+		}
+
+		return names
+	}
+
+	// Disable
+	require.NoError(t, tools.setEnabled(toolName, false))
+	waitChanged()
+	require.Empty(t, listed())
+	require.Equal(t, []ToolState{{Name: toolName, Enabled: false}}, tools.states())
+
+	params := new(mcp.CallToolParams)
+	params.Name = toolName
+	params.Arguments = map[string]any{"text": "abc"}
+
+	_, err = session.CallTool(ctx, params)
+	require.ErrorContains(t, err, "unknown tool", "disabled tool should not be callable")
+
+	// No-op
+	require.NoError(t, tools.setEnabled(toolName, false))
+
+	// Enable
+	require.NoError(t, tools.setEnabled(toolName, true))
+	waitChanged()
+	require.Equal(t, []string{toolName}, listed())
+	require.Equal(t, []ToolState{{Name: toolName, Enabled: true}}, tools.states())
+
+	// Unknown
+	require.ErrorIs(t, tools.setEnabled("unknown", false), errUnknownTool)
+}
+
+func Test_toolSet_registerLazy(t *testing.T) {
+	t.Parallel()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v0.0.0"}, nil) //nolint:exhaustruct // minimal server
+	tools := newToolSet(server)
+	tools.allowed = allowOnly(nil)
+
+	built := 0
+
+	tools.registerLazy(toolName, func() (*mcp.Tool, ToolHandler) {
+		built++
+
+		return toolInfo(mirrorTool{}), mirrorTool{}.Handler(nil)
+	})
+
+	require.Zero(t, built, "tools not allowed should not be built")
+
+	tools.setAllowed(nil)
+	require.Equal(t, 1, built, "tools should be built once allowed")
+	require.True(t, tools.enabled(toolName))
+
+	tools.setAllowed(allowOnly(nil))
+	tools.setAllowed(nil)
+	require.Equal(t, 1, built, "tools should be built once only")
+}
+
+// ----------------------------------------------------------------------------
+//  GetToolFilter
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_GetToolFilter(t *testing.T) {
+	for index, test := range []struct {
+		name        string
+		enabled     string
+		disabled    string
+		preset      string
+		wantAllowed []string
+	}{
+		{"default", "", "", "", []string{toolName, batchToolName, adminToolName, "fs_read"}},
+		{"allowlist", toolName, "", "", []string{toolName}},
+		{"denylist", "", " mirror_batch, admin", "", []string{toolName, "fs_read"}},
+		{"both", "mirror,mirror_batch", batchToolName, "", []string{toolName}},
+		{"full_preset", "", "", toolsPresetFull, []string{toolName, batchToolName, adminToolName, "fs_read"}},
+		{"minimal_preset", "", "", toolsPresetMinimal, []string{toolName, "fs_read"}},
+		{"allowlist_over_preset", batchToolName, "", toolsPresetMinimal, []string{batchToolName}},
+		{"denylist_of_preset", "", toolName, toolsPresetMinimal, []string{"fs_read"}},
+		{"upstream_allowlist", "fs_read", "", "", []string{"fs_read"}},
+		{"upstream_denylist", "", "fs_read", "", []string{toolName, batchToolName, adminToolName}},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		setEnv(t, envNameUpstreams, "fs=mcp-fs")
+		setEnv(t, envNameToolsEnabled, test.enabled)
+		setEnv(t, envNameToolsDisabled, test.disabled)
+		setEnv(t, envNameToolsPreset, test.preset)
+
+		allowed, err := GetToolFilter()
+		require.NoError(t, err, name)
+
+		// Upstream tools, such as "fs_read", are subject to the lists but not to the presets
+		var got []string
+
+		for _, tool := range []string{toolName, batchToolName, adminToolName, "fs_read"} {
+			if allowed(tool) {
+				got = append(got, tool)
+			}
+		}
+
+		require.Equal(t, test.wantAllowed, got, name)
+	}
+}
+
+//nolint:paralleltest // sets env var
+func Test_GetToolFilter_unknown(t *testing.T) {
+	setEnv(t, envNameToolsDisabled, "mirorr") // typo
+
+	_, err := GetToolFilter()
+	require.ErrorIs(t, err, errUnknownTool)
+	require.ErrorContains(t, err, `"mirorr"`)
+
+	setEnv(t, envNameToolsDisabled, "fs_read") // tool of an unknown upstream
+
+	_, err = GetToolFilter()
+	require.ErrorIs(t, err, errUnknownTool)
+}
+
+//nolint:paralleltest // sets env var
+func Test_GetToolsPreset(t *testing.T) {
+	setEnv(t, envNameToolsPreset, "")
+
+	preset, err := GetToolsPreset()
+	require.NoError(t, err)
+	require.Equal(t, toolsPresetFull, preset)
+
+	setEnv(t, envNameToolsPreset, "tiny")
+
+	_, err = GetToolsPreset()
+	require.ErrorIs(t, err, errUnknownPreset)
+	require.ErrorContains(t, err, envNameToolsPreset)
+
+	_, err = GetToolFilter()
+	require.ErrorIs(t, err, errUnknownPreset, "the filter should report the invalid preset")
+}
+
+//nolint:paralleltest // sets env var
+func Test_newServer_tools_preset(t *testing.T) {
+	unsetEnv(t, envNameAdmin, envNameToolsEnabled, envNameToolsDisabled)
+	setEnv(t, envNameToolsPreset, toolsPresetMinimal)
+
+	session := connectInMemory(t, newServer())
+
+	res, err := session.ListTools(context.Background(), nil)
+	require.NoError(t, err)
+	require.Len(t, res.Tools, 1, "the minimal preset should register the mirror tool only")
+	require.Equal(t, toolName, res.Tools[0].Name)
+}
+
+//nolint:paralleltest // sets env var
+func Test_newServer_tool_filter(t *testing.T) {
+	setEnv(t, envNameAdmin, "true")
+	setEnv(t, envNameToolsEnabled, "mirror,admin")
+	setEnv(t, envNameToolsDisabled, adminToolName)
+
+	session := connectInMemory(t, newServer())
+
+	res, err := session.ListTools(context.Background(), nil)
+	require.NoError(t, err)
+	require.Len(t, res.Tools, 1)
+	require.Equal(t, toolName, res.Tools[0].Name)
+
+	params := new(mcp.CallToolParams)
+	params.Name = batchToolName
+	params.Arguments = map[string]any{"texts": []string{"abc"}}
+
+	_, err = session.CallTool(context.Background(), params)
+	require.ErrorContains(t, err, "unknown tool", "excluded tool should not be callable")
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Transform tool.
+const (
+	transformToolName        = "transform"
+	transformToolDescription = "Applies one operation, given by op, to the UTF-8 text, such as mirror, upper or " +
+		"strip_accents. It gives access to every operation with a single tool"
+)
+
+// TransformInput is the input for the transform tool.
+type TransformInput struct {
+	Text   string           `json:"text"             jsonschema:"The UTF-8 text to transform."`
+	Op     string           `json:"op"               jsonschema:"The operation to apply to the text."`
+	Params *TransformParams `json:"params,omitempty" jsonschema:"The parameters of the operation, if any."`
+}
+
+// TransformParams are the parameters of the operations. Which ones are allowed
+// depends on the operation, see transformInputSchema.
+type TransformParams struct {
+	Locale string `json:"locale,omitempty" jsonschema:"BCP 47 locale of the case mapping, such as tr. Defaults to the one of the server."`
+}
+
+// TransformOutput is the output from the transform tool.
+type TransformOutput struct {
+	Text string `json:"text" jsonschema:"The text once transformed by the operation."`
+}
+
+// transformTool is the transform tool, exposing the text transforms as one
+// tool for the clients limiting the number of tools.
+type transformTool struct{}
+
+// Name returns the name of the tool.
+func (transformTool) Name() string { return transformToolName }
+
+// Description returns the description of the tool.
+func (transformTool) Description() string { return transformToolDescription }
+
+// Schema returns the schemas of the input and the output of the tool.
+func (transformTool) Schema() (*jsonschema.Schema, *jsonschema.Schema) {
+	return transformInputSchema(), nil
+}
+
+// Annotations returns the hints of the tool.
+func (transformTool) Annotations() *mcp.ToolAnnotations { return readOnlyAnnotations() }
+
+// Handler returns handleTransform.
+func (transformTool) Handler(*serverState) ToolHandler { return TypedHandler(handleTransform) }
+
+// cacheable returns true, as the result depends on the text, the operation and
+// its parameters only.
+func (transformTool) cacheable(map[string]any) bool { return true }
+
+// transformInputSchema returns the JSON schema of TransformInput, with the
+// operations as the enum of op, and one branch of oneOf by operation telling
+// the parameters it takes.
+func transformInputSchema() *jsonschema.Schema {
+	schema := mustInferSchema[TransformInput]()
+	schema.Title = "Transform input"
+	schema.Required = []string{"text", "op"}
+
+	text := schema.Properties["text"]
+	text.Title = "Text"
+	text.MaxLength = jsonschema.Ptr(textMaxLength)
+	text.Examples = []any{"Crème Brûlée"}
+
+	op := schema.Properties["op"]
+	op.Title = "Operation"
+	op.Enum = transformNames()
+	op.Examples = []any{"strip_accents"}
+
+	params := schema.Properties["params"]
+	params.Title = "Parameters"
+	params.Properties["locale"].Examples = []any{"tr"}
+
+	for _, transform := range textTransforms {
+		branch := new(jsonschema.Schema)
+		branch.Description = transform.name + ": " + transform.description
+		branch.Properties = map[string]*jsonschema.Schema{
+			"op":     {Const: jsonschema.Ptr[any](transform.name)},
+			"params": {MaxProperties: jsonschema.Ptr(0)}, // no parameter
+		}
+
+		if transform.localized {
+			branch.Properties["params"] = new(jsonschema.Schema) // as of the properties
+		}
+
+		schema.OneOf = append(schema.OneOf, branch)
+	}
+
+	return schema
+}
+
+// handleTransform applies the operation of the input to the text, as a
+// pipeline of a single step does.
+func handleTransform(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input TransformInput,
+) (*mcp.CallToolResult, TransformOutput, error) {
+	err := ctx.Err()
+	if err != nil {
+		return nil, TransformOutput{}, wrapError(err, "request canceled")
+	}
+
+	params := input.Params
+	if params == nil {
+		params = new(TransformParams)
+	}
+
+	step, err := newTransformStep(input.Op, params.Locale)
+	if err != nil {
+		return nil, TransformOutput{}, fmt.Errorf("invalid op %q: %w", input.Op, err)
+	}
+
+	err = checkTextLimit(req, input.Text)
+	if err != nil {
+		return nil, TransformOutput{}, err
+	}
+
+	locale, err := GetLocale()
+	if err != nil {
+		return nil, TransformOutput{}, err
+	}
+
+	start := time.Now()
+
+	text, err := applyPlan(ctx, input.Text, []TransformStep{step}, locale)
+	if err != nil {
+		return nil, TransformOutput{}, err
+	}
+
+	callLog(ctx, "text transformed", callLogAttrs(ctx, req, "op", step.Op,
+		logKeyInputSize, len(input.Text), logKeyDuration, time.Since(start))...)
+
+	return nil, TransformOutput{Text: text}, nil
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  handleTransform
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_handleTransform(t *testing.T) {
+	unsetEnv(t, envNameLocale)
+
+	session := connectInMemory(t, newServer())
+
+	for index, test := range []struct {
+		name string
+		args map[string]any
+		want string
+	}{
+		{"mirror", map[string]any{"text": "a👍🏽é", "op": "mirror"}, "é👍🏽a"},
+		{"strip_accents", map[string]any{"text": "Crème Brûlée", "op": "strip_accents"}, "Creme Brulee"},
+		{"empty_params", map[string]any{"text": " ab ", "op": "trim", "params": map[string]any{}}, "ab"},
+		{"null_params", map[string]any{"text": "ab", "op": "upper", "params": nil}, "AB"},
+		{"locale", map[string]any{"text": "istanbul", "op": "upper", "params": map[string]any{"locale": "tr"}}, "İSTANBUL"},
+		{"without_locale", map[string]any{"text": "istanbul", "op": "upper"}, "ISTANBUL"},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		res := callTool(t, session, transformToolName, test.args)
+		require.False(t, res.IsError, name)
+		require.Equal(t, map[string]any{"text": test.want}, res.StructuredContent, name)
+	}
+}
+
+func Test_handleTransform_invalid(t *testing.T) {
+	t.Parallel()
+
+	session := connectInMemory(t, newServer())
+
+	for _, args := range []map[string]any{
+		{"text": "abc"},                  // missing op
+		{"op": "mirror"},                 // missing text
+		{"text": "abc", "op": "reverse"}, // unknown operation
+		{"text": "abc", "op": "nfc"},     // aliases are for the expressions only
+		{"text": "abc", "op": "mirror", "params": map[string]any{"locale": "tr"}}, // not localized
+		{"text": "abc", "op": "upper", "params": map[string]any{"lang": "tr"}},    // unknown parameter
+	} {
+		params := new(mcp.CallToolParams)
+		params.Name = transformToolName
+		params.Arguments = args
+
+		_, err := session.CallTool(context.Background(), params)
+		require.Error(t, err, "arguments %v should be rejected", args)
+	}
+
+	res := callTool(t, session, transformToolName, map[string]any{"text": "abc", "op": "upper", "params": map[string]any{"locale": "!!"}})
+	require.True(t, res.IsError, "malformed locales should be rejected")
+}
+
+func Test_handleTransform_params_checked(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := handleTransform(context.Background(), nil, TransformInput{
+		Text: "abc", Op: "mirror", Params: &TransformParams{Locale: "tr"},
+	})
+	require.ErrorIs(t, err, errUnknownArgument, "parameters should be checked without the schema as well")
+
+	_, _, err = handleTransform(context.Background(), nil, TransformInput{Text: "abc", Op: "reverse", Params: nil})
+	require.ErrorIs(t, err, errUnknownStep)
+}
+
+func Test_handleTransform_canceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := handleTransform(ctx, nil, TransformInput{Text: "abc", Op: "mirror", Params: nil})
+	require.ErrorIs(t, err, context.Canceled)
+}
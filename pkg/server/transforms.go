@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"unicode"
+
+	"github.com/KEINOS/mcp-text-mirror/pkg/mirror"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// textTransform is a named operation on a text, such as a step of the pipeline
+// tool. The locale is the one of the call, see resolveLocale.
+type textTransform struct {
+	name        string
+	description string
+	apply       func(ctx context.Context, text string, locale language.Tag) (string, error)
+	localized   bool // whether it depends on the locale, which is then an argument of the step
+}
+
+// textTransforms are the operations on the texts, by name.
+//
+//nolint:gochecknoglobals // read-only table
+var textTransforms = []textTransform{
+	{"mirror", "reverse by grapheme clusters", applyMirror, false},
+	{"upper", "upper case, per the locale", applyCases(cases.Upper), true},
+	{"lower", "lower case, per the locale", applyCases(cases.Lower), true},
+	{"title", "title case of the words, per the locale", applyCases(cases.Title), true},
+	{"normalize", "Unicode normalization form C (NFC)", applyNorm(norm.NFC), false},
+	{"normalize_nfd", "Unicode normalization form D (NFD)", applyNorm(norm.NFD), false},
+	{"normalize_nfkc", "Unicode normalization form KC (NFKC)", applyNorm(norm.NFKC), false},
+	{"normalize_nfkd", "Unicode normalization form KD (NFKD)", applyNorm(norm.NFKD), false},
+	{"strip_accents", "remove the combining marks, such as the accents of é", applyStripAccents, false},
+	{"trim", "remove the leading and trailing white spaces", applyTrim, false},
+}
+
+// transformAliases are the short names of the operations accepted in the
+// transform expressions, by alias.
+//
+//nolint:gochecknoglobals // read-only table
+var transformAliases = map[string]string{
+	"nfc":  "normalize",
+	"nfd":  "normalize_nfd",
+	"nfkc": "normalize_nfkc",
+	"nfkd": "normalize_nfkd",
+}
+
+// findTransform returns the operation of the name.
+func findTransform(name string) (textTransform, bool) {
+	for _, op := range textTransforms {
+		if op.name == name {
+			return op, true
+		}
+	}
+
+	return textTransform{}, false
+}
+
+// transformNames returns the names of the operations, as the enum of the
+// schemas.
+func transformNames() []any {
+	names := make([]any, len(textTransforms))
+	for index, op := range textTransforms {
+		names[index] = op.name
+	}
+
+	return names
+}
+
+// applyMirror reverses the text by grapheme clusters as the mirror tool does.
+func applyMirror(ctx context.Context, text string, _ language.Tag) (string, error) {
+	return mirror.Reverse(ctx, text, mirror.WithParallelism(runtime.GOMAXPROCS(0)))
+}
+
+// applyCases returns the operation mapping the case of the text with the
+// caser of the locale, such as cases.Upper.
+func applyCases(caser func(language.Tag, ...cases.Option) cases.Caser) func(context.Context, string, language.Tag) (string, error) {
+	return func(_ context.Context, text string, locale language.Tag) (string, error) {
+		return caser(locale).String(text), nil
+	}
+}
+
+// applyNorm returns the operation normalizing the text to the form.
+func applyNorm(form norm.Form) func(context.Context, string, language.Tag) (string, error) {
+	return func(_ context.Context, text string, _ language.Tag) (string, error) {
+		return form.String(text), nil
+	}
+}
+
+// applyStripAccents removes the nonspacing combining marks, once decomposed,
+// and composes the rest back.
+func applyStripAccents(_ context.Context, text string, _ language.Tag) (string, error) {
+	stripped, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), text)
+
+	return stripped, wrapError(err, "failed to strip the accents")
+}
+
+// applyTrim removes the leading and trailing white spaces.
+func applyTrim(_ context.Context, text string, _ language.Tag) (string, error) {
+	return strings.TrimSpace(text), nil
+}
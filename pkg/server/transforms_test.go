@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+// ----------------------------------------------------------------------------
+//  textTransforms
+// ----------------------------------------------------------------------------
+
+func Test_textTransforms(t *testing.T) {
+	t.Parallel()
+
+	for index, test := range []struct {
+		name   string
+		input  string
+		locale language.Tag
+		want   string
+	}{
+		{"mirror", "a👍🏽é", language.Und, "é👍🏽a"},
+		{"upper", "istanbul", language.Und, "ISTANBUL"},
+		{"upper", "istanbul", language.Turkish, "İSTANBUL"},
+		{"lower", "ΟΔΟΣ", language.Greek, "οδος"},
+		{"title", "hello wORLD", language.Und, "Hello World"},
+		{"normalize", "é", language.Und, "é"},
+		{"normalize_nfd", "é", language.Und, "é"},
+		{"normalize_nfkc", "ﬁ①", language.Und, "fi1"},
+		{"normalize_nfkd", "ﬁé", language.Und, "fié"},
+		{"strip_accents", "Crème Brûlée, é", language.Und, "Creme Brulee, e"},
+		{"trim", " \t text \n", language.Und, "text"},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		op, ok := findTransform(test.name)
+		require.True(t, ok, name)
+
+		got, err := op.apply(context.Background(), test.input, test.locale)
+		require.NoError(t, err, name)
+		require.Equal(t, test.want, got, name)
+	}
+}
+
+func Test_findTransform_unknown(t *testing.T) {
+	t.Parallel()
+
+	_, ok := findTransform("reverse")
+	require.False(t, ok)
+}
+
+func Test_transformNames(t *testing.T) {
+	t.Parallel()
+
+	names := transformNames()
+	require.Len(t, names, len(textTransforms))
+
+	for index, name := range names {
+		require.Equal(t, textTransforms[index].name, name)
+	}
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Request body limit of the HTTP transport.
+const (
+	envNameMaxBody = envPrefix + "MAX_BODY" // env var of the max bytes of the HTTP request bodies. 0 disables the limit
+
+	// maxBodyDefault fits the longest text argument of ASCII characters, or of
+	// 8 million characters of up to 4 bytes in UTF-8, unescaped. The frames of
+	// the longer texts, such as the escaped ones, are rejected before decoding.
+	maxBodyDefault = 2 * textMaxLength
+)
+
+// GetMaxBody returns the max size in bytes of the HTTP request bodies, the
+// JSON-RPC frames, from 'MCP_TEXT_MIRROR_MAX_BODY' environment variable. It
+// defaults to maxBodyDefault, and zero means unlimited.
+//
+// The frames are read whole and decoded by the MCP SDK before the arguments
+// are validated, so that an oversized text would be buffered several times
+// over. The limit rejects the frames while they are read instead, so that a
+// call takes a few times the limit in memory at most. Raise it for the longest
+// texts escaped, up to 12 bytes per character as "\ud83d\ude00".
+func GetMaxBody() (int, error) {
+	return loadedSettings().maxBody()
+}
+
+// maxBody returns GetMaxBody of the settings.
+func (v *settingValues) maxBody() (int, error) {
+	return v.envInt(envNameMaxBody, maxBodyDefault)
+}
+
+// withBodyLimit rejects the requests whose body exceeds limit bytes. A declared
+// Content-Length over the limit is rejected with 413 before reading the body,
+// and a body found over the limit while read fails the read, once limit bytes
+// are read at most. A limit of zero or less disables it.
+func withBodyLimit(limit int, next http.Handler) http.Handler {
+	if limit <= 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > int64(limit) {
+			http.Error(w, fmt.Sprintf("request body exceeds %d bytes", limit), http.StatusRequestEntityTooLarge)
+
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, int64(limit))
+
+		next.ServeHTTP(w, r)
+	})
+}
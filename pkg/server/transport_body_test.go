@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  GetMaxBody
+// ----------------------------------------------------------------------------
+
+func Test_maxBodyDefault(t *testing.T) {
+	t.Parallel()
+
+	frame := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"mirror","arguments":{"text":""}}}`
+
+	require.Less(t, len(frame)+textMaxLength, maxBodyDefault, "the longest ASCII text should fit")
+	require.Greater(t, len(frame)+6*textMaxLength, maxBodyDefault, "the longest escaped text should not fit")
+}
+
+//nolint:paralleltest // sets env var
+func Test_GetMaxBody(t *testing.T) {
+	for index, test := range []struct {
+		name    string
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{"default", "", 32 * 1024 * 1024, false},
+		{"set", "1024", 1024, false},
+		{"unlimited", "0", 0, false},
+		{"negative", "-1", 0, true},
+		{"not_a_number", "1MB", 0, true},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		setEnv(t, envNameMaxBody, test.value)
+
+		got, err := GetMaxBody()
+		if test.wantErr {
+			require.ErrorIs(t, err, errInvalidNumber, name)
+
+			continue
+		}
+
+		require.NoError(t, err, name)
+		require.Equal(t, test.want, got, name)
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  withBodyLimit
+// ----------------------------------------------------------------------------
+
+func Test_withBodyLimit(t *testing.T) {
+	t.Parallel()
+
+	var read atomic.Int64
+
+	handler := withBodyLimit(8, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, err := io.Copy(io.Discard, r.Body)
+		read.Store(n)
+
+		if err != nil {
+			http.Error(w, "failed to read body", http.StatusBadRequest)
+		}
+	}))
+
+	for index, test := range []struct {
+		name     string
+		body     string
+		declared bool // whether the Content-Length is declared
+		wantCode int
+		wantRead int64
+	}{
+		{"within", "12345678", true, http.StatusOK, 8},
+		{"declared_over", "123456789", true, http.StatusRequestEntityTooLarge, 0},
+		{"streamed_over", strings.Repeat("x", 1024), false, http.StatusBadRequest, 8},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		read.Store(0)
+
+		req := httptest.NewRequest(http.MethodPost, httpPathMCP, strings.NewReader(test.body))
+		if !test.declared {
+			req.ContentLength = -1 // chunked
+		}
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		require.Equal(t, test.wantCode, rec.Code, name)
+		require.Equal(t, test.wantRead, read.Load(), name)
+	}
+}
+
+func Test_withBodyLimit_disabled(t *testing.T) {
+	t.Parallel()
+
+	var read int64
+
+	handler := withBodyLimit(0, http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		read, _ = io.Copy(io.Discard, r.Body)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, httpPathMCP, strings.NewReader(strings.Repeat("x", 1024))))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, int64(1024), read, "the whole body should be read without limit")
+}
+
+//nolint:paralleltest // sets env var
+func Test_newHTTPHandler_max_body(t *testing.T) {
+	setEnv(t, envNameMaxBody, "64")
+
+	body := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18"}}`
+
+	req := httptest.NewRequest(http.MethodPost, httpPathMCP, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+
+	rec := httptest.NewRecorder()
+	newHTTPHandler(context.Background(), newServer(), new(atomic.Bool)).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
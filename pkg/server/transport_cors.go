@@ -0,0 +1,131 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Origin validation and CORS configuration of the HTTP transport.
+const (
+	envNameAllowedOrigins = envPrefix + "ALLOWED_ORIGINS" // env var of the comma separated origins allowed to access. "*" allows any
+	envNameCORSHeaders    = envPrefix + "CORS_HEADERS"    // env var of the comma separated extra request headers allowed by CORS
+
+	anyOrigin    = "*"
+	corsMethods  = "GET, POST, DELETE, OPTIONS"
+	corsHeaders  = "Content-Type, Accept, Authorization, Mcp-Session-Id, Mcp-Protocol-Version, Last-Event-ID"
+	corsExposed  = "Mcp-Session-Id"
+	corsMaxAge   = 10 * time.Minute
+	listSep      = ","
+	originHeader = "Origin"
+)
+
+// corsConfig is the origin allowlist and CORS settings.
+type corsConfig struct {
+	// origins allowed to access. If empty, only loopback origins are allowed.
+	origins []string
+	// headers are the request headers allowed in cross-origin requests.
+	headers string
+}
+
+// GetCORSConfig returns the origin validation and CORS settings from
+// 'MCP_TEXT_MIRROR_ALLOWED_ORIGINS' and 'MCP_TEXT_MIRROR_CORS_HEADERS'
+// environment variables.
+//
+// If no origin is configured, only loopback origins (localhost, 127.0.0.1 and
+// [::1] on any port) are allowed, which blocks DNS rebinding attacks against a
+// local server by default.
+func GetCORSConfig() corsConfig {
+	config := corsConfig{
+		origins: splitList(settingValue(envNameAllowedOrigins)),
+		headers: corsHeaders,
+	}
+
+	if extra := splitList(settingValue(envNameCORSHeaders)); len(extra) > 0 {
+		config.headers += ", " + strings.Join(extra, ", ")
+	}
+
+	return config
+}
+
+// splitList splits the comma separated list and drops empty elements.
+func splitList(value string) []string {
+	var list []string
+
+	for elem := range strings.SplitSeq(value, listSep) {
+		if elem = strings.TrimSpace(elem); elem != "" {
+			list = append(list, elem)
+		}
+	}
+
+	return list
+}
+
+// allowed reports whether requests from the origin are allowed.
+func (c corsConfig) allowed(origin string) bool {
+	if len(c.origins) == 0 {
+		return isLoopbackOrigin(origin)
+	}
+
+	return slices.Contains(c.origins, anyOrigin) ||
+		slices.ContainsFunc(c.origins, func(o string) bool { return strings.EqualFold(o, origin) })
+}
+
+// isLoopbackOrigin reports whether the origin is served from the local host.
+func isLoopbackOrigin(origin string) bool {
+	parsed, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+
+	switch parsed.Hostname() {
+	case "localhost", "127.0.0.1", "::1":
+		return true
+	}
+
+	return false
+}
+
+// withCORS is an HTTP middleware that rejects requests from origins not in the
+// allowlist with 403 Forbidden, and emits the CORS headers for the allowed ones
+// so that browser-based MCP clients work.
+//
+// Requests without Origin header, such as from non-browser clients, are passed
+// through as is since they are not subject to DNS rebinding.
+func withCORS(config corsConfig, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get(originHeader)
+		if origin == "" {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		if !config.allowed(origin) {
+			warnLog(r.Context(), "request from origin rejected", "origin", origin)
+			http.Error(w, "origin not allowed", http.StatusForbidden)
+
+			return
+		}
+
+		header := w.Header()
+		header.Set("Access-Control-Allow-Origin", origin)
+		header.Add("Vary", originHeader)
+		header.Set("Access-Control-Expose-Headers", corsExposed)
+
+		// Preflight request
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			header.Set("Access-Control-Allow-Methods", corsMethods)
+			header.Set("Access-Control-Allow-Headers", config.headers)
+			header.Set("Access-Control-Max-Age", strconv.Itoa(int(corsMaxAge.Seconds())))
+			w.WriteHeader(http.StatusNoContent)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  GetCORSConfig
+// ----------------------------------------------------------------------------
+
+func Test_GetCORSConfig(t *testing.T) {
+	setEnv(t, envNameAllowedOrigins, "")
+	setEnv(t, envNameCORSHeaders, "")
+
+	config := GetCORSConfig()
+	require.Empty(t, config.origins)
+	require.Equal(t, corsHeaders, config.headers)
+
+	setEnv(t, envNameAllowedOrigins, " https://a.example.com, ,https://b.example.com ")
+	setEnv(t, envNameCORSHeaders, "X-Trace-Id")
+
+	config = GetCORSConfig()
+	require.Equal(t, []string{"https://a.example.com", "https://b.example.com"}, config.origins)
+	require.Equal(t, corsHeaders+", X-Trace-Id", config.headers)
+}
+
+// ----------------------------------------------------------------------------
+//  corsConfig.allowed
+// ----------------------------------------------------------------------------
+
+func Test_corsConfig_allowed(t *testing.T) {
+	t.Parallel()
+
+	loopbackOnly := corsConfig{origins: nil, headers: corsHeaders}
+	allowlist := corsConfig{origins: []string{"https://app.example.com"}, headers: corsHeaders}
+	anyone := corsConfig{origins: []string{anyOrigin}, headers: corsHeaders}
+
+	for index, test := range []struct {
+		name   string
+		config corsConfig
+		origin string
+		want   bool
+	}{
+		{"default_localhost", loopbackOnly, "http://localhost:6274", true},
+		{"default_ipv4_loopback", loopbackOnly, "http://127.0.0.1", true},
+		{"default_ipv6_loopback", loopbackOnly, "http://[::1]:8080", true},
+		{"default_rebinding", loopbackOnly, "http://evil.example.com", false},
+		{"default_invalid", loopbackOnly, "http://%zz", false},
+		{"allowlist_match", allowlist, "https://app.example.com", true},
+		{"allowlist_case_insensitive", allowlist, "https://APP.example.com", true},
+		{"allowlist_no_localhost", allowlist, "http://localhost", false},
+		{"allowlist_other", allowlist, "https://evil.example.com", false},
+		{"any", anyone, "https://evil.example.com", true},
+	} {
+		t.Run(fmt.Sprintf("Test #%d: %s", index+1, test.name), func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, test.want, test.config.allowed(test.origin))
+		})
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  withCORS
+// ----------------------------------------------------------------------------
+
+func Test_withCORS(t *testing.T) {
+	t.Parallel()
+
+	config := corsConfig{origins: []string{"https://app.example.com"}, headers: corsHeaders}
+	handler := withCORS(config, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	serve := func(method, origin string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, httpPathMCP, nil)
+		for key, values := range header {
+			req.Header[key] = values
+		}
+
+		if origin != "" {
+			req.Header.Set(originHeader, origin)
+		}
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	t.Run("no_origin", func(t *testing.T) {
+		t.Parallel()
+
+		rec := serve(http.MethodPost, "", nil)
+		require.Equal(t, http.StatusAccepted, rec.Code, "non-browser requests should pass through")
+		require.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("allowed_origin", func(t *testing.T) {
+		t.Parallel()
+
+		rec := serve(http.MethodPost, "https://app.example.com", nil)
+		require.Equal(t, http.StatusAccepted, rec.Code)
+		require.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+		require.Equal(t, corsExposed, rec.Header().Get("Access-Control-Expose-Headers"))
+	})
+
+	t.Run("disallowed_origin", func(t *testing.T) {
+		t.Parallel()
+
+		rec := serve(http.MethodPost, "https://evil.example.com", nil)
+		require.Equal(t, http.StatusForbidden, rec.Code)
+		require.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("preflight", func(t *testing.T) {
+		t.Parallel()
+
+		rec := serve(http.MethodOptions, "https://app.example.com", http.Header{
+			"Access-Control-Request-Method": {http.MethodPost},
+		})
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		require.Equal(t, corsMethods, rec.Header().Get("Access-Control-Allow-Methods"))
+		require.Equal(t, corsHeaders, rec.Header().Get("Access-Control-Allow-Headers"))
+		require.Equal(t, "600", rec.Header().Get("Access-Control-Max-Age"))
+	})
+}
+
+//nolint:paralleltest // sets env var
+func Test_newHTTPHandler_rejects_foreign_origin(t *testing.T) {
+	setEnv(t, envNameAllowedOrigins, "")
+
+	req := httptest.NewRequest(http.MethodPost, httpPathMCP, nil)
+	req.Header.Set(originHeader, "http://rebinding.example.com")
+
+	rec := httptest.NewRecorder()
+	newHTTPHandler(context.Background(), newServer(), new(atomic.Bool)).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusForbidden, rec.Code,
+		"non-loopback origins should be rejected by default")
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// Health check endpoints of the HTTP listener, for load balancers and
+// orchestrators.
+const (
+	httpPathHealthz = "/healthz" // liveness: the process is up and serving HTTP
+	httpPathReadyz  = "/readyz"  // readiness: tools are registered and MCP sessions are accepted
+)
+
+// handleHealthz always responds 200 OK. It is the liveness probe.
+func handleHealthz(w http.ResponseWriter, _ *http.Request) {
+	writeProbe(w, true)
+}
+
+// handleReadyz returns the readiness probe handler. It responds 200 OK while
+// ready is true and 503 Service Unavailable otherwise.
+//
+// ready is set once the tools are registered and the listener accepts MCP
+// sessions, and cleared as soon as the server starts shutting down so that the
+// load balancer stops routing new sessions to it.
+func handleReadyz(ready *atomic.Bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		writeProbe(w, ready.Load())
+	}
+}
+
+// writeProbe writes the probe result as plain text.
+func writeProbe(w http.ResponseWriter, ok bool) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+
+	if !ok {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte("not ready\n"))
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok\n"))
+}
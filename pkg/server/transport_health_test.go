@@ -0,0 +1,181 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  /healthz and /readyz
+// ----------------------------------------------------------------------------
+
+func Test_newHTTPHandler_probes(t *testing.T) {
+	t.Parallel()
+
+	ready := new(atomic.Bool)
+	handler := newHTTPHandler(context.Background(), newServer(), ready)
+
+	for index, test := range []struct {
+		name     string
+		path     string
+		ready    bool
+		wantCode int
+	}{
+		{"healthz_not_ready", httpPathHealthz, false, http.StatusOK},
+		{"healthz_ready", httpPathHealthz, true, http.StatusOK},
+		{"readyz_not_ready", httpPathReadyz, false, http.StatusServiceUnavailable},
+		{"readyz_ready", httpPathReadyz, true, http.StatusOK},
+	} {
+		// Not parallel since the cases share the readiness
+		ready.Store(test.ready)
+
+		req := httptest.NewRequest(http.MethodGet, test.path, nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+		require.Equal(t, test.wantCode, rec.Code, name)
+		require.Equal(t, "no-store", rec.Header().Get("Cache-Control"), name)
+	}
+}
+
+func Test_serveHTTPListener_readyz(t *testing.T) {
+	t.Parallel()
+
+	listener, err := new(net.ListenConfig).Listen(context.Background(), "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errServe := make(chan error, 1)
+
+	go func() {
+		errServe <- serveHTTPListener(ctx, newServer(), listener)
+	}()
+
+	client := new(http.Client)
+	url := "http://" + listener.Addr().String() + httpPathReadyz
+
+	require.Eventually(t, func() bool {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		require.NoError(t, err)
+
+		res, err := client.Do(req)
+		if err != nil {
+			return false
+		}
+
+		defer res.Body.Close()
+
+		return res.StatusCode == http.StatusOK
+	}, timeoutEventually, tickEventually, "server should be ready once serving")
+
+	client.CloseIdleConnections()
+	cancel()
+	require.ErrorIs(t, <-errServe, context.Canceled)
+}
+
+// ----------------------------------------------------------------------------
+//  /debug/pprof/
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_newHTTPHandler_pprof(t *testing.T) {
+	loopback := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 8080} //nolint:exhaustruct // IP and port only
+	public := &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 8080}   //nolint:exhaustruct // IP and port only
+
+	for index, test := range []struct {
+		name     string
+		debug    string
+		clients  string
+		clientID string
+		local    net.Addr
+		wantCode int
+	}{
+		{"disabled", "", "", "", loopback, http.StatusNotFound},
+		{"enabled_loopback", "true", "", "", loopback, http.StatusOK},
+		{"enabled_public", "true", "", "", public, http.StatusForbidden},
+		{"admin_client", "true", "agent-1", "agent-1", public, http.StatusOK},
+		{"other_client", "true", "agent-1", "", loopback, http.StatusForbidden},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		setEnv(t, envNameDebugHTTP, test.debug)
+		setEnv(t, envNameAdminClients, test.clients)
+
+		handler := newHTTPHandler(context.Background(), newServer(), new(atomic.Bool))
+
+		req := httptest.NewRequest(http.MethodGet, httpPathPprof+"heap?debug=1", nil)
+		req = req.WithContext(context.WithValue(req.Context(), http.LocalAddrContextKey, test.local))
+		if test.clientID != "" { // verified client certificate
+			cert := new(x509.Certificate)
+			cert.Subject = pkix.Name{CommonName: test.clientID} //nolint:exhaustruct // CN only
+
+			req.TLS = new(tls.ConnectionState)
+			req.TLS.VerifiedChains = [][]*x509.Certificate{{cert}}
+		}
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		require.Equal(t, test.wantCode, rec.Code, name)
+
+		if test.wantCode == http.StatusOK {
+			require.Contains(t, rec.Body.String(), "heap profile", name)
+		}
+	}
+}
+
+//nolint:paralleltest // sets env var
+func Test_checkPprofListener(t *testing.T) {
+	loopback := &net.TCPAddr{IP: net.IPv6loopback, Port: 8080}  //nolint:exhaustruct // IP and port only
+	public := &net.TCPAddr{IP: net.IPv6unspecified, Port: 8080} //nolint:exhaustruct // IP and port only
+
+	for index, test := range []struct {
+		name    string
+		debug   string
+		clients string
+		addr    net.Addr
+		wantErr bool
+	}{
+		{"disabled", "", "", public, false},
+		{"loopback", "true", "", loopback, false},
+		{"admin_clients", "true", "agent-1", public, false},
+		{"exposed", "true", "", public, true},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		setEnv(t, envNameDebugHTTP, test.debug)
+		setEnv(t, envNameAdminClients, test.clients)
+
+		err := checkPprofListener(test.addr)
+		if test.wantErr {
+			require.ErrorIs(t, err, errPprofExposed, name)
+
+			continue
+		}
+
+		require.NoError(t, err, name)
+	}
+}
+
+//nolint:paralleltest // sets env var
+func Test_serveHTTPListener_pprof_exposed(t *testing.T) {
+	setEnv(t, envNameDebugHTTP, "true")
+	setEnv(t, envNameAdminClients, "")
+
+	listener, err := new(net.ListenConfig).Listen(context.Background(), "tcp", ":0")
+	require.NoError(t, err)
+
+	err = serveHTTPListener(context.Background(), newServer(), listener)
+	require.ErrorIs(t, err, errPprofExposed, "debug endpoints should not be served to the network without admin clients")
+}
@@ -0,0 +1,194 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// HTTP transport configuration.
+const (
+	envNameHTTPAddr   = envPrefix + "HTTP_ADDR" // env var to serve over HTTP instead of stdio. the value is the listen address
+	httpPathMCP       = "/mcp"                  // endpoint path of the streamable HTTP transport
+	httpHeaderTimeout = 10 * time.Second        // max time to read request headers
+	httpShutdownGrace = 5 * time.Second         // max time to wait for in-flight requests on shutdown
+)
+
+// errInvalidAddr is returned if the HTTP listen address is malformed.
+var errInvalidAddr = errors.New("must be a listen address such as 127.0.0.1:8080 or :8080")
+
+// GetHTTPAddr returns the address to listen on for the streamable HTTP
+// transport, such as "127.0.0.1:8080".
+//
+// It returns an empty string if 'MCP_TEXT_MIRROR_HTTP_ADDR' environment variable
+// is not set, which means the stdio transport is used.
+func GetHTTPAddr() string {
+	return loadedSettings().httpAddr()
+}
+
+// httpAddr returns GetHTTPAddr of the settings.
+func (v *settingValues) httpAddr() string {
+	return v.value(envNameHTTPAddr)
+}
+
+// checkHTTPAddr is the check of 'MCP_TEXT_MIRROR_HTTP_ADDR' environment
+// variable. The port must be a number, zero for any free port.
+func checkHTTPAddr(v *settingValues) error {
+	addr := v.httpAddr()
+	if addr == "" {
+		return nil
+	}
+
+	_, port, err := net.SplitHostPort(addr)
+	if err == nil {
+		_, err = strconv.ParseUint(port, 10, 16)
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid %s %q: %w", envNameHTTPAddr, addr, errInvalidAddr)
+	}
+
+	return nil
+}
+
+// newHTTPHandler returns the HTTP handler serving the given MCP server via the
+// streamable HTTP transport at httpPathMCP, and the configured server instances
+// at their paths, along with the health check endpoints and the pprof ones if
+// enabled. /readyz reports ready while ready is true. The logs go to the
+// logger of ctx, which the requests are expected to carry too.
+//
+// Requests from disallowed origins are rejected and the client identity is
+// resolved from the client certificate if any.
+func newHTTPHandler(ctx context.Context, server *mcp.Server, ready *atomic.Bool) http.Handler {
+	// Close the sessions idle for too long. Invalid values are reported by
+	// loadSettings beforehand.
+	options := new(mcp.StreamableHTTPOptions)
+	options.SessionTimeout, _ = GetIdleTimeout()
+	options.Logger = newSDKLogger(loggerFrom(ctx))
+
+	mux := http.NewServeMux()
+	// Invalid values are reported by loadSettings beforehand.
+	maxBody, _ := GetMaxBody()
+	tap := openWireTap(ctx)
+
+	mcpHandler := func(server *mcp.Server) http.Handler {
+		return withBodyLimit(maxBody, withWireTap(tap, mcp.NewStreamableHTTPHandler(
+			func(*http.Request) *mcp.Server { return server },
+			options,
+		)))
+	}
+
+	mux.Handle(httpPathMCP, mcpHandler(server))
+
+	for _, instance := range newInstances(ctx) {
+		mux.Handle(instance.config.Path, withInstanceClients(instance.config, mcpHandler(instance.state.server)))
+		debugLog(ctx, "instance added", "instance", instance.config.Name, "path", instance.config.Path)
+	}
+
+	mux.HandleFunc(httpPathHealthz, handleHealthz)
+	mux.HandleFunc(httpPathReadyz, handleReadyz(ready))
+
+	// Invalid values are reported by loadSettings beforehand.
+	if debug, _ := GetDebugEnabled(); debug {
+		addPprof(mux)
+	}
+
+	return withCORS(GetCORSConfig(), withClientIdentity(mux))
+}
+
+// serveHTTP serves the MCP server over the streamable HTTP transport on addr
+// until the context is canceled.
+//
+// Like the stdio transport, it returns the context error once the context is
+// canceled. In-flight requests are given httpShutdownGrace to finish.
+func serveHTTP(ctx context.Context, server *mcp.Server, addr string) error {
+	if ctx == nil {
+		return errNilContext
+	}
+
+	tlsConfig, err := GetTLSConfig()
+	if err != nil {
+		return wrapError(err, "invalid TLS configuration")
+	}
+
+	listener, err := new(net.ListenConfig).Listen(ctx, "tcp", addr)
+	if err != nil {
+		return wrapError(err, "failed to listen on %s", addr)
+	}
+
+	// Serve over TLS (and mTLS if a client CA bundle is configured).
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+
+	return serveHTTPListener(ctx, server, listener)
+}
+
+// serveHTTPListener is the listener based part of serveHTTP. The listener is
+// closed when this function returns.
+func serveHTTPListener(ctx context.Context, server *mcp.Server, listener net.Listener) error {
+	err := checkPprofListener(listener.Addr())
+	if err != nil {
+		_ = listener.Close()
+
+		return wrapError(err, "invalid configuration")
+	}
+
+	// The tools are already registered at this point. Ready once serving.
+	ready := new(atomic.Bool)
+
+	httpServer := new(http.Server)
+	httpServer.Handler = newHTTPHandler(ctx, server, ready)
+	httpServer.ReadHeaderTimeout = httpHeaderTimeout
+	// Carry the logger of ctx to the requests, but not its cancellation, so
+	// that the shutdown stays graceful.
+	httpServer.BaseContext = func(net.Listener) context.Context { return context.WithoutCancel(ctx) }
+
+	// Hanging SSE streams never become idle. Close the MCP sessions on shutdown
+	// so that their streams end.
+	httpServer.RegisterOnShutdown(func() {
+		for session := range server.Sessions() {
+			_ = session.Close()
+		}
+	})
+
+	errServe := make(chan error, 1)
+
+	go func() {
+		errServe <- httpServer.Serve(listener)
+	}()
+
+	ready.Store(true)
+	infoLog(ctx, "serving MCP over HTTP", "addr", listener.Addr().String(), "path", httpPathMCP)
+
+	select {
+	case err := <-errServe:
+		return wrapError(err, "HTTP server stopped")
+	case <-ctx.Done():
+	}
+
+	ready.Store(false)
+
+	// Use a fresh context since ctx is already done.
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), httpShutdownGrace)
+	defer cancel()
+
+	// Force close the connections remaining after the grace period. It is not
+	// an error since the server is being stopped anyway.
+	err = httpServer.Shutdown(shutdownCtx)
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		warnLog(ctx, "forced to close HTTP connections", logKeyError, err)
+
+		_ = httpServer.Close()
+	}
+
+	return ctx.Err()
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  GetHTTPAddr
+// ----------------------------------------------------------------------------
+
+func Test_GetHTTPAddr(t *testing.T) {
+	setEnv(t, envNameHTTPAddr, "")
+	require.Empty(t, GetHTTPAddr(), "HTTP address should be empty if env var is not set")
+
+	setEnv(t, envNameHTTPAddr, "127.0.0.1:8080")
+	require.Equal(t, "127.0.0.1:8080", GetHTTPAddr())
+}
+
+// ----------------------------------------------------------------------------
+//  serveHTTP
+// ----------------------------------------------------------------------------
+
+func Test_serveHTTP_round_trip(t *testing.T) {
+	t.Parallel()
+
+	listener, err := new(net.ListenConfig).Listen(context.Background(), "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errServe := make(chan error, 1)
+
+	go func() {
+		errServe <- serveHTTPListener(ctx, newServer(), listener)
+	}()
+
+	transport := new(mcp.StreamableClientTransport)
+	transport.Endpoint = "http://" + listener.Addr().String() + httpPathMCP
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.0"}, nil) //nolint:exhaustruct // minimal client
+
+	session, err := client.Connect(ctx, transport, nil)
+	require.NoError(t, err)
+
+	params := new(mcp.CallToolParams)
+	params.Name = toolName
+	params.Arguments = map[string]any{"text": "abc"}
+
+	res, err := session.CallTool(ctx, params)
+	require.NoError(t, err)
+	require.False(t, res.IsError)
+	require.Equal(t, map[string]any{"text": "cba"}, res.StructuredContent)
+
+	require.NoError(t, session.Close())
+
+	cancel()
+	require.ErrorIs(t, <-errServe, context.Canceled,
+		"serveHTTP should return the context error once canceled like the stdio transport")
+}
+
+func Test_serveHTTP_nil_context(t *testing.T) {
+	t.Parallel()
+
+	//nolint:staticcheck // nil context on purpose
+	err := serveHTTP(nil, newServer(), "127.0.0.1:0")
+	require.ErrorIs(t, err, errNilContext)
+}
+
+func Test_serveHTTP_listen_failure(t *testing.T) {
+	t.Parallel()
+
+	err := serveHTTP(context.Background(), newServer(), "invalid-address")
+	require.Error(t, err)
+	require.ErrorContains(t, err, "failed to listen on invalid-address")
+}
+
+func Test_serveHTTP_serve_failure(t *testing.T) {
+	t.Parallel()
+
+	listener, err := new(net.ListenConfig).Listen(context.Background(), "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	require.NoError(t, listener.Close()) // closed listener makes Serve fail immediately
+
+	err = serveHTTPListener(context.Background(), newServer(), listener)
+	require.ErrorContains(t, err, "HTTP server stopped")
+}
+
+//nolint:paralleltest // sets env var
+func Test_runServer_http(t *testing.T) {
+	setEnv(t, envNameHTTPAddr, "invalid-address")
+
+	err := runServer(context.Background(), newServer())
+	require.ErrorContains(t, err, "failed to listen on invalid-address",
+		"runServer should use the HTTP transport if the address is set")
+}
+
+// ----------------------------------------------------------------------------
+//  checkHTTPAddr
+// ----------------------------------------------------------------------------
+
+func Test_checkHTTPAddr(t *testing.T) {
+	for index, test := range []struct {
+		addr    string
+		wantErr bool
+	}{
+		{"", false},
+		{"127.0.0.1:8080", false},
+		{":0", false},
+		{"[::1]:443", false},
+		{"localhost", true},
+		{"localhost:http", true},
+		{"127.0.0.1:65536", true},
+	} {
+		t.Run(fmt.Sprintf("Test #%d: %s", index+1, test.addr), func(t *testing.T) {
+			setEnv(t, envNameHTTPAddr, test.addr)
+
+			err := checkHTTPAddr(loadedSettings())
+			if test.wantErr {
+				require.ErrorIs(t, err, errInvalidAddr)
+
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"slices"
+)
+
+// Profiling endpoints of the HTTP listener.
+//
+// E.g.: go tool pprof http://127.0.0.1:8080/debug/pprof/heap
+const (
+	envNameDebugHTTP = envPrefix + "DEBUG"      // env var to serve the pprof endpoints over HTTP
+	httpPathDebug    = "/debug/"                // paths reserved to the debug endpoints
+	httpPathPprof    = httpPathDebug + "pprof/" // index of the profiles, e.g. /debug/pprof/heap
+)
+
+// errPprofExposed is returned if the pprof endpoints would be served to anyone
+// on the network.
+var errPprofExposed = errors.New("debug endpoints need " + envNameAdminClients + " or a loopback listener")
+
+// GetDebugEnabled returns whether the net/http/pprof endpoints are served at
+// /debug/pprof/ over HTTP, from 'MCP_TEXT_MIRROR_DEBUG' environment variable.
+// It has no effect on stdio.
+//
+// The endpoints are restricted to the clients allowed to use the admin tool,
+// see GetAdminClients, as the profiles and the command line reveal the
+// internals of the server. Without admin clients, they are served on a loopback
+// listener only. See checkPprofListener.
+func GetDebugEnabled() (bool, error) {
+	return loadedSettings().debugEnabled()
+}
+
+// debugEnabled returns GetDebugEnabled of the settings.
+func (v *settingValues) debugEnabled() (bool, error) {
+	return v.envBool(envNameDebugHTTP)
+}
+
+// addPprof adds the pprof endpoints to the mux, for the CPU and heap profiles
+// of the running server.
+func addPprof(mux *http.ServeMux) {
+	mux.Handle(httpPathPprof, withAdminClients(http.HandlerFunc(pprof.Index))) // and the named profiles
+	mux.Handle(httpPathPprof+"cmdline", withAdminClients(http.HandlerFunc(pprof.Cmdline)))
+	mux.Handle(httpPathPprof+"profile", withAdminClients(http.HandlerFunc(pprof.Profile)))
+	mux.Handle(httpPathPprof+"symbol", withAdminClients(http.HandlerFunc(pprof.Symbol)))
+	mux.Handle(httpPathPprof+"trace", withAdminClients(http.HandlerFunc(pprof.Trace)))
+}
+
+// checkPprofListener returns errPprofExposed if the pprof endpoints are enabled
+// without admin clients on a listener other than loopback, where any client of
+// the network could use them.
+func checkPprofListener(addr net.Addr) error {
+	if debug, _ := GetDebugEnabled(); !debug || len(GetAdminClients()) > 0 || isLoopback(addr) {
+		return nil
+	}
+
+	return wrapError(errPprofExposed, "debug endpoints on %s", addr.String())
+}
+
+// isLoopback reports whether addr is a TCP address on the loopback interface.
+func isLoopback(addr net.Addr) bool {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+
+	return ok && tcpAddr.IP.IsLoopback()
+}
+
+// withAdminClients rejects the requests of the clients not allowed to use the
+// admin tool with 403 Forbidden. The client identity is set beforehand by
+// withClientIdentity. Without admin clients, only the requests received on a
+// loopback listener are allowed, as checkPprofListener does at startup.
+func withAdminClients(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clients := GetAdminClients()
+		clientID := r.Header.Get(headerClientID)
+		local, _ := r.Context().Value(http.LocalAddrContextKey).(net.Addr)
+
+		if (len(clients) > 0 && !slices.Contains(clients, clientID)) || (len(clients) == 0 && !isLoopback(local)) {
+			warnLog(r.Context(), "debug endpoint forbidden", logKeyClient, clientID, "path", r.URL.Path)
+			http.Error(w, "client is not allowed to use the debug endpoints", http.StatusForbidden)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// TLS configuration of the network transports.
+const (
+	envNameTLSCert     = envPrefix + "TLS_CERT"      // env var of the server certificate file (PEM)
+	envNameTLSKey      = envPrefix + "TLS_KEY"       // env var of the server private key file (PEM)
+	envNameTLSClientCA = envPrefix + "TLS_CLIENT_CA" // env var of the CA bundle to verify client certificates (PEM). enables mTLS
+
+	// headerClientID carries the verified client identity from the HTTP layer to
+	// the tool handlers. Any value sent by the client itself is discarded.
+	headerClientID = "X-Text-Mirror-Client-Id"
+	// anonymousClient is the client identity if no client certificate was given.
+	anonymousClient = "anonymous"
+)
+
+// Predefined errors of the TLS configuration.
+var (
+	errTLSKeyPair  = errors.New("both " + envNameTLSCert + " and " + envNameTLSKey + " must be set")
+	errTLSClientCA = errors.New("no valid certificate found in the client CA bundle")
+)
+
+// GetTLSConfig returns the TLS configuration for the network transports from the
+// 'MCP_TEXT_MIRROR_TLS_*' environment variables.
+//
+// It returns nil if neither the certificate nor the key is set, which means the
+// transport is served in plain text. If the client CA bundle is set, clients
+// must present a certificate signed by one of the CAs in the bundle (mTLS).
+func GetTLSConfig() (*tls.Config, error) {
+	return loadedSettings().tlsConfig()
+}
+
+// tlsConfig returns GetTLSConfig of the settings.
+func (v *settingValues) tlsConfig() (*tls.Config, error) {
+	certPath := v.value(envNameTLSCert)
+	keyPath := v.value(envNameTLSKey)
+	caPath := v.value(envNameTLSClientCA)
+
+	if certPath == "" && keyPath == "" && caPath == "" {
+		return nil, nil //nolint:nilnil // nil config means no TLS
+	}
+
+	if certPath == "" || keyPath == "" {
+		return nil, errTLSKeyPair
+	}
+
+	cert, err := tls.LoadX509KeyPair(filepath.Clean(certPath), filepath.Clean(keyPath))
+	if err != nil {
+		return nil, wrapError(err, "failed to load server certificate")
+	}
+
+	config := new(tls.Config)
+	config.MinVersion = tls.VersionTLS12
+	config.Certificates = []tls.Certificate{cert}
+
+	if caPath == "" {
+		return config, nil
+	}
+
+	caPEM, err := os.ReadFile(filepath.Clean(caPath))
+	if err != nil {
+		return nil, wrapError(err, "failed to read client CA bundle")
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, wrapError(errTLSClientCA, "invalid client CA bundle %s", caPath)
+	}
+
+	config.ClientCAs = pool
+	config.ClientAuth = tls.RequireAndVerifyClientCert
+
+	return config, nil
+}
+
+// withClientIdentity is an HTTP middleware that maps the common name (CN) of the
+// verified client certificate to the client identity. The identity is logged
+// and passed to the tool handlers via headerClientID.
+func withClientIdentity(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientID := anonymousClient
+
+		if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+			if cn := r.TLS.VerifiedChains[0][0].Subject.CommonName; cn != "" {
+				clientID = cn
+			}
+		}
+
+		// Never trust the identity sent by the client.
+		r.Header.Set(headerClientID, clientID)
+
+		debugLog(r.Context(), "client request", logKeyClient, clientID, "method", r.Method, "path", r.URL.Path)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientIdentity returns the client identity of the tool call request set by
+// withClientIdentity. It returns an empty string for non-HTTP transports.
+func clientIdentity(req *mcp.CallToolRequest) string {
+	if req == nil || req.Extra == nil || req.Extra.Header == nil {
+		return ""
+	}
+
+	return req.Extra.Header.Get(headerClientID)
+}
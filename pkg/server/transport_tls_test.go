@@ -0,0 +1,326 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// =============================================================================
+//  Helpers for testing
+// =============================================================================
+
+// testCert is a generated certificate and its key.
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+// newTestCert generates a certificate with the common name. If parent is nil,
+// the certificate is a self-signed CA.
+func newTestCert(t *testing.T, commonName string, parent *testCert) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := new(x509.Certificate)
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.Subject = pkix.Name{CommonName: commonName} //nolint:exhaustruct // CN only
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	template.IPAddresses = []net.IP{net.ParseIP("127.0.0.1")}
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	template.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign
+	template.BasicConstraintsValid = true
+	template.IsCA = parent == nil
+
+	signerCert, signerKey := template, key
+	if parent != nil {
+		signerCert, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signerCert, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+// writePEM writes the certificate and key as PEM files into dir and returns
+// their paths.
+func (c *testCert) writePEM(t *testing.T, dir, name string) (string, string) {
+	t.Helper()
+
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t, err)
+
+	certPath := filepath.Join(dir, name+".crt")
+	keyPath := filepath.Join(dir, name+".key")
+
+	require.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}), 0o600))    //nolint:exhaustruct // no headers
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)) //nolint:exhaustruct // no headers
+
+	return certPath, keyPath
+}
+
+// tlsCertificate returns the certificate usable in tls.Config.
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key} //nolint:exhaustruct // minimal
+}
+
+// setupMTLSEnv generates a CA, a server certificate and a client certificate
+// with the CN, and sets the TLS env vars for the server. It returns the CA and
+// client certificates.
+func setupMTLSEnv(t *testing.T, clientCN string) (*testCert, *testCert) {
+	t.Helper()
+
+	dir := t.TempDir()
+	ca := newTestCert(t, "test-ca", nil)
+	serverCert := newTestCert(t, "127.0.0.1", ca)
+	clientCert := newTestCert(t, clientCN, ca)
+
+	caPath, _ := ca.writePEM(t, dir, "ca")
+	certPath, keyPath := serverCert.writePEM(t, dir, "server")
+
+	setEnv(t, envNameTLSCert, certPath)
+	setEnv(t, envNameTLSKey, keyPath)
+	setEnv(t, envNameTLSClientCA, caPath)
+
+	return ca, clientCert
+}
+
+// =============================================================================
+//  Unit tests
+// =============================================================================
+
+// ----------------------------------------------------------------------------
+//  GetTLSConfig
+// ----------------------------------------------------------------------------
+
+func Test_GetTLSConfig_disabled(t *testing.T) {
+	setEnv(t, envNameTLSCert, "")
+	setEnv(t, envNameTLSKey, "")
+	setEnv(t, envNameTLSClientCA, "")
+
+	config, err := GetTLSConfig()
+	require.NoError(t, err)
+	require.Nil(t, config, "TLS should be disabled if no env var is set")
+}
+
+func Test_GetTLSConfig_server_only(t *testing.T) {
+	dir := t.TempDir()
+	certPath, keyPath := newTestCert(t, "127.0.0.1", nil).writePEM(t, dir, "server")
+
+	setEnv(t, envNameTLSCert, certPath)
+	setEnv(t, envNameTLSKey, keyPath)
+	setEnv(t, envNameTLSClientCA, "")
+
+	config, err := GetTLSConfig()
+	require.NoError(t, err)
+	require.Len(t, config.Certificates, 1)
+	require.Equal(t, tls.NoClientCert, config.ClientAuth, "client certificates should not be required without CA")
+}
+
+func Test_GetTLSConfig_mtls(t *testing.T) {
+	setupMTLSEnv(t, "agent-1")
+
+	config, err := GetTLSConfig()
+	require.NoError(t, err)
+	require.Equal(t, tls.RequireAndVerifyClientCert, config.ClientAuth)
+	require.NotNil(t, config.ClientCAs)
+}
+
+func Test_GetTLSConfig_errors(t *testing.T) {
+	dir := t.TempDir()
+	certPath, keyPath := newTestCert(t, "127.0.0.1", nil).writePEM(t, dir, "server")
+
+	notPEM := filepath.Join(dir, "not.pem")
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a PEM"), 0o600))
+
+	for index, test := range []struct {
+		name    string
+		cert    string
+		key     string
+		ca      string
+		wantMsg string
+	}{
+		{"ca_without_key_pair", "", "", notPEM, errTLSKeyPair.Error()},
+		{"cert_without_key", certPath, "", "", errTLSKeyPair.Error()},
+		{"invalid_key_pair", notPEM, notPEM, "", "failed to load server certificate"},
+		{"missing_ca", certPath, keyPath, filepath.Join(dir, "missing.pem"), "failed to read client CA bundle"},
+		{"invalid_ca", certPath, keyPath, notPEM, errTLSClientCA.Error()},
+	} {
+		t.Run(fmt.Sprintf("Test #%d: %s", index+1, test.name), func(t *testing.T) {
+			setEnv(t, envNameTLSCert, test.cert)
+			setEnv(t, envNameTLSKey, test.key)
+			setEnv(t, envNameTLSClientCA, test.ca)
+
+			config, err := GetTLSConfig()
+			require.ErrorContains(t, err, test.wantMsg)
+			require.Nil(t, config)
+		})
+	}
+}
+
+//nolint:paralleltest // sets env var
+func Test_serveHTTP_invalid_tls(t *testing.T) {
+	setEnv(t, envNameTLSCert, "cert.pem")
+	setEnv(t, envNameTLSKey, "")
+	setEnv(t, envNameTLSClientCA, "")
+
+	err := serveHTTP(context.Background(), newServer(), "127.0.0.1:0")
+	require.ErrorIs(t, err, errTLSKeyPair)
+}
+
+// ----------------------------------------------------------------------------
+//  withClientIdentity
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_mtls_round_trip(t *testing.T) {
+	ca, clientCert := setupMTLSEnv(t, "agent-1")
+	setEnv(t, envNameDebug, "debug.log")
+
+	var (
+		mu     sync.Mutex
+		logged []string
+	)
+
+	logger := mockLogger(func(entry string) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		logged = append(logged, entry)
+	})
+
+	tlsConfig, err := GetTLSConfig()
+	require.NoError(t, err)
+
+	listener, err := new(net.ListenConfig).Listen(context.Background(), "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(withLogger(context.Background(), logger))
+	errServe := make(chan error, 1)
+
+	go func() {
+		errServe <- serveHTTPListener(ctx, newServer(WithLogger(logger)), tls.NewListener(listener, tlsConfig))
+	}()
+
+	endpoint := "https://" + listener.Addr().String() + httpPathMCP
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	var transports []*http.Transport
+
+	newClient := func(certs ...tls.Certificate) *http.Client {
+		clientTLS := new(tls.Config)
+		clientTLS.MinVersion = tls.VersionTLS12
+		clientTLS.RootCAs = roots
+		clientTLS.Certificates = certs
+
+		transport := new(http.Transport)
+		transport.TLSClientConfig = clientTLS
+		transports = append(transports, transport)
+
+		return &http.Client{Transport: transport} //nolint:exhaustruct // minimal client
+	}
+
+	t.Run("with_client_cert", func(t *testing.T) {
+		transport := new(mcp.StreamableClientTransport)
+		transport.Endpoint = endpoint
+		transport.HTTPClient = newClient(clientCert.tlsCertificate())
+
+		client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.0"}, nil) //nolint:exhaustruct // minimal client
+
+		session, err := client.Connect(ctx, transport, nil)
+		require.NoError(t, err)
+
+		defer session.Close()
+
+		res := callTool(t, session, toolName, map[string]any{"text": "abc"})
+		require.Equal(t, map[string]any{"text": "cba"}, res.StructuredContent)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		require.Regexp(t, `text mirrored request_id=\w{26} tool=mirror session=\w+ app="test-client v0.0.0" client=agent-1 input_size=3`,
+			strings.Join(logged, "\n"), "client CN should be logged as the client identity")
+	})
+
+	t.Run("without_client_cert", func(t *testing.T) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, nil)
+		require.NoError(t, err)
+
+		res, err := newClient().Do(req)
+		if res != nil {
+			_ = res.Body.Close()
+		}
+
+		require.Error(t, err, "request without client certificate should be rejected")
+	})
+
+	// Close unused connections of the clients to shut down the server quickly
+	for _, transport := range transports {
+		transport.CloseIdleConnections()
+	}
+
+	cancel()
+	require.ErrorIs(t, <-errServe, context.Canceled)
+}
+
+func Test_withClientIdentity_spoofed_header(t *testing.T) {
+	t.Parallel()
+
+	var got string
+
+	handler := withClientIdentity(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(headerClientID)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, httpPathMCP, nil)
+	req.Header.Set(headerClientID, "admin")
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.Equal(t, anonymousClient, got, "client supplied identity must be discarded")
+}
+
+// ----------------------------------------------------------------------------
+//  clientIdentity
+// ----------------------------------------------------------------------------
+
+func Test_clientIdentity(t *testing.T) {
+	t.Parallel()
+
+	require.Empty(t, clientIdentity(nil))
+	require.Empty(t, clientIdentity(new(mcp.CallToolRequest)))
+
+	req := new(mcp.CallToolRequest)
+	req.Extra = &mcp.RequestExtra{Header: http.Header{}} //nolint:exhaustruct // header only
+	req.Extra.Header.Set(headerClientID, "agent-1")
+
+	require.Equal(t, "agent-1", clientIdentity(req))
+}
@@ -0,0 +1,209 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os/exec"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Aggregator (proxy) mode configuration.
+//
+// E.g.: MCP_TEXT_MIRROR_UPSTREAMS="fs=/usr/local/bin/mcp-fs --ro;web=http://127.0.0.1:9000/mcp"
+const (
+	envNameUpstreams  = envPrefix + "UPSTREAMS" // env var to list upstream MCP servers to aggregate
+	upstreamSep       = ";"                     // separator between upstreams
+	upstreamNameSep   = "="                     // separator between the name and the target
+	upstreamToolSep   = "_"                     // separator between the name and the upstream tool name
+	upstreamClientVer = "v1.0.0"                // client version reported to upstream servers
+)
+
+// Predefined errors of the aggregator mode.
+var (
+	errUpstreamFormat = errors.New("upstream must be in 'name=command args...' or 'name=http(s)://url' format")
+	errUpstreamDup    = errors.New("duplicate upstream name")
+)
+
+// upstreamConfig is a single upstream MCP server to aggregate.
+type upstreamConfig struct {
+	// Name is used to prefix the upstream tool names to avoid collisions.
+	Name string
+	// Target is either an HTTP(S) URL of a streamable HTTP endpoint or a command
+	// line to spawn a stdio server.
+	Target string
+}
+
+// GetUpstreams returns the upstream MCP servers to aggregate, parsed from
+// 'MCP_TEXT_MIRROR_UPSTREAMS' environment variable. It returns nil if the
+// variable is not set, which disables the aggregator mode.
+func GetUpstreams() ([]upstreamConfig, error) {
+	return loadedSettings().upstreams()
+}
+
+// upstreams returns GetUpstreams of the settings.
+func (v *settingValues) upstreams() ([]upstreamConfig, error) {
+	return parseUpstreams(v.value(envNameUpstreams))
+}
+
+// parseUpstreams parses a semicolon separated list of 'name=target' pairs.
+func parseUpstreams(value string) ([]upstreamConfig, error) {
+	var (
+		upstreams []upstreamConfig
+		seen      = make(map[string]bool)
+	)
+
+	for entry := range strings.SplitSeq(value, upstreamSep) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, target, found := strings.Cut(entry, upstreamNameSep)
+		name = strings.TrimSpace(name)
+		target = strings.TrimSpace(target)
+
+		if !found || name == "" || target == "" {
+			return nil, wrapError(errUpstreamFormat, "invalid upstream %q", entry)
+		}
+
+		if seen[name] {
+			return nil, wrapError(errUpstreamDup, "invalid upstream %q", entry)
+		}
+
+		seen[name] = true
+
+		upstreams = append(upstreams, upstreamConfig{Name: name, Target: target})
+	}
+
+	return upstreams, nil
+}
+
+// transport returns the client transport to connect to the upstream.
+func (u upstreamConfig) transport() mcp.Transport {
+	if strings.HasPrefix(u.Target, "http://") || strings.HasPrefix(u.Target, "https://") {
+		transport := new(mcp.StreamableClientTransport)
+		transport.Endpoint = u.Target
+
+		return transport
+	}
+
+	args := strings.Fields(u.Target)
+
+	//nolint:gosec // running the configured upstream command is the whole point
+	return &mcp.CommandTransport{Command: exec.Command(args[0], args[1:]...)}
+}
+
+// addUpstreams connects to all the upstreams and re-exposes their tools in the
+// tool set. The returned function closes all the upstream sessions.
+//
+// If any upstream fails to connect or has a tool whose name is taken, the
+// already connected ones are closed and the error is returned.
+func addUpstreams(ctx context.Context, tools *toolSet, upstreams []upstreamConfig) (func(), error) {
+	sessions := make([]*mcp.ClientSession, 0, len(upstreams))
+	closeAll := func() {
+		for _, session := range sessions {
+			_ = session.Close()
+		}
+	}
+
+	for _, upstream := range upstreams {
+		session, err := addUpstream(ctx, tools, upstream.Name, upstream.transport())
+		if err != nil {
+			closeAll()
+
+			return nil, wrapError(err, "failed to add upstream %q", upstream.Name)
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	return closeAll, nil
+}
+
+// addUpstream connects to a single upstream server via transport and registers
+// its tools in the tool set as '<name>_<tool>'. Calls to those tools are
+// proxied as is. A tool whose name is taken, such as 'mirror_batch' of an
+// upstream named 'mirror', is an error rather than replacing the other one.
+func addUpstream(
+	ctx context.Context,
+	tools *toolSet,
+	name string,
+	transport mcp.Transport,
+) (*mcp.ClientSession, error) {
+	client := mcp.NewClient(&mcp.Implementation{ //nolint:exhaustruct // minimal client info
+		Name:    serviceName + "-" + name,
+		Version: upstreamClientVer,
+	}, nil)
+
+	session, err := client.Connect(ctx, transport, nil)
+	if err != nil {
+		return nil, wrapError(err, "failed to connect")
+	}
+
+	for tool, err := range session.Tools(ctx, nil) {
+		if err != nil {
+			_ = session.Close()
+
+			return nil, wrapError(err, "failed to list tools")
+		}
+
+		if !isObjectSchema(tool.InputSchema) || (tool.OutputSchema != nil && !isObjectSchema(tool.OutputSchema)) {
+			warnLog(ctx, "upstream tool with non-object schema skipped", "upstream", name, logKeyTool, tool.Name)
+
+			continue
+		}
+
+		proxied := *tool
+		proxied.Name = name + upstreamToolSep + tool.Name
+
+		err = tools.registerExternal(&proxied, proxyToolHandler(session, tool.Name))
+		if err != nil {
+			_ = session.Close()
+
+			return nil, err
+		}
+
+		debugLog(ctx, "upstream tool added", logKeyTool, proxied.Name)
+	}
+
+	return session, nil
+}
+
+// proxyToolHandler returns a tool handler that forwards the call to the tool
+// named toolName on the upstream session.
+func proxyToolHandler(session *mcp.ClientSession, toolName string) mcp.ToolHandler {
+	return func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		params := new(mcp.CallToolParams)
+		params.Meta = req.Params.Meta
+		params.Name = toolName
+
+		if len(req.Params.Arguments) > 0 {
+			params.Arguments = req.Params.Arguments
+		}
+
+		res, err := session.CallTool(ctx, params)
+		if err != nil {
+			return nil, wrapError(err, "upstream tool %q failed", toolName)
+		}
+
+		return res, nil
+	}
+}
+
+// isObjectSchema reports whether the JSON schema has type "object", which the
+// MCP spec requires for tool input and output schemas.
+func isObjectSchema(schema any) bool {
+	raw, err := json.Marshal(schema)
+	if err != nil {
+		return false
+	}
+
+	var fields struct {
+		Type any `json:"type"`
+	}
+
+	return json.Unmarshal(raw, &fields) == nil && fields.Type == "object"
+}
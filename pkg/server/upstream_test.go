@@ -0,0 +1,265 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// =============================================================================
+//  Helpers for testing
+// =============================================================================
+
+// connectInMemory connects a test client to the server via in-memory transports
+// and returns the client session. The session is closed on test cleanup.
+func connectInMemory(t *testing.T, server *mcp.Server) *mcp.ClientSession {
+	t.Helper()
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.0"}, nil) //nolint:exhaustruct // minimal client
+
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = clientSession.Close()
+		_ = serverSession.Wait()
+	})
+
+	return clientSession
+}
+
+// callTool calls the tool with the arguments and returns the result.
+func callTool(t *testing.T, session *mcp.ClientSession, name string, args any) *mcp.CallToolResult {
+	t.Helper()
+
+	params := new(mcp.CallToolParams)
+	params.Name = name
+	params.Arguments = args
+
+	res, err := session.CallTool(context.Background(), params)
+	require.NoError(t, err)
+
+	return res
+}
+
+// =============================================================================
+//  Unit tests
+// =============================================================================
+
+// ----------------------------------------------------------------------------
+//  parseUpstreams
+// ----------------------------------------------------------------------------
+
+func Test_parseUpstreams(t *testing.T) {
+	t.Parallel()
+
+	for index, test := range []struct {
+		name    string
+		value   string
+		want    []upstreamConfig
+		wantErr error
+	}{
+		{"empty", "", nil, nil},
+		{"blank_entries", " ; ;", nil, nil},
+		{
+			"command_and_url",
+			"fs = mcp-fs --ro ; web=http://127.0.0.1:9000/mcp",
+			[]upstreamConfig{
+				{Name: "fs", Target: "mcp-fs --ro"},
+				{Name: "web", Target: "http://127.0.0.1:9000/mcp"},
+			},
+			nil,
+		},
+		{"missing_separator", "mcp-fs", nil, errUpstreamFormat},
+		{"missing_name", "=mcp-fs", nil, errUpstreamFormat},
+		{"missing_target", "fs=", nil, errUpstreamFormat},
+		{"duplicate_name", "fs=a;fs=b", nil, errUpstreamDup},
+	} {
+		t.Run(fmt.Sprintf("Test #%d: %s", index+1, test.name), func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseUpstreams(test.value)
+			if test.wantErr != nil {
+				require.ErrorIs(t, err, test.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.want, got)
+		})
+	}
+}
+
+func Test_GetUpstreams(t *testing.T) {
+	setEnv(t, envNameUpstreams, "fs=mcp-fs")
+
+	got, err := GetUpstreams()
+	require.NoError(t, err)
+	require.Equal(t, []upstreamConfig{{Name: "fs", Target: "mcp-fs"}}, got)
+}
+
+// ----------------------------------------------------------------------------
+//  upstreamConfig.transport
+// ----------------------------------------------------------------------------
+
+func Test_upstreamConfig_transport(t *testing.T) {
+	t.Parallel()
+
+	httpTransport := upstreamConfig{Name: "web", Target: "https://example.com/mcp"}.transport()
+	require.IsType(t, &mcp.StreamableClientTransport{}, httpTransport)
+
+	cmdTransport := upstreamConfig{Name: "fs", Target: "mcp-fs --ro"}.transport()
+	require.IsType(t, &mcp.CommandTransport{}, cmdTransport)
+	require.Equal(t, []string{"mcp-fs", "--ro"}, cmdTransport.(*mcp.CommandTransport).Command.Args) //nolint:forcetypeassert // checked above
+}
+
+// ----------------------------------------------------------------------------
+//  addUpstream
+// ----------------------------------------------------------------------------
+
+// newUpstream returns the client transport of an in-memory upstream server
+// whose tools of the given names append "!" to the text.
+func newUpstream(t *testing.T, toolNames ...string) mcp.Transport {
+	t.Helper()
+
+	upstream := mcp.NewServer(&mcp.Implementation{Name: "upstream", Version: "v0.0.0"}, nil) //nolint:exhaustruct // minimal server
+
+	for _, name := range toolNames {
+		tool := new(mcp.Tool)
+		tool.Name = name
+
+		mcp.AddTool(upstream, tool,
+			func(_ context.Context, _ *mcp.CallToolRequest, in MirrorInput) (*mcp.CallToolResult, MirrorOutput, error) {
+				return nil, MirrorOutput{Text: in.Text + "!"}, nil
+			})
+	}
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+
+	_, err := upstream.Connect(context.Background(), serverTransport, nil)
+	require.NoError(t, err)
+
+	return clientTransport
+}
+
+func Test_addUpstream(t *testing.T) {
+	t.Parallel()
+
+	// Aggregate the upstream with a single "shout" tool into text-mirror
+	state := newServerState()
+
+	upSession, err := addUpstream(context.Background(), state.tools, "up", newUpstream(t, "shout"))
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = upSession.Close() })
+
+	session := connectInMemory(t, state.server)
+
+	var names []string
+
+	for tool, err := range session.Tools(context.Background(), nil) {
+		require.NoError(t, err)
+
+		names = append(names, tool.Name)
+	}
+
+	require.ElementsMatch(t, append(defaultTools(), "up_shout"), names,
+		"upstream tools should be listed alongside mirror with the upstream name as prefix")
+
+	res := callTool(t, session, "up_shout", map[string]any{"text": "hey"})
+	require.False(t, res.IsError)
+	require.Equal(t, map[string]any{"text": "hey!"}, res.StructuredContent)
+
+	res = callTool(t, session, toolName, map[string]any{"text": "hey"})
+	require.Equal(t, map[string]any{"text": "yeh"}, res.StructuredContent)
+}
+
+func Test_addUpstream_name_taken(t *testing.T) {
+	t.Parallel()
+
+	state := newServerState()
+
+	_, err := addUpstream(context.Background(), state.tools, "mirror", newUpstream(t, "batch"))
+	require.ErrorIs(t, err, errToolExists, "upstream tools should not replace the built-in ones")
+
+	session := connectInMemory(t, state.server)
+
+	res := callTool(t, session, batchToolName, map[string]any{"texts": []any{"ab"}})
+	require.False(t, res.IsError, "the built-in tool should be kept")
+
+	upSession, err := addUpstream(context.Background(), state.tools, "up", newUpstream(t, "shout"))
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = upSession.Close() })
+
+	_, err = addUpstream(context.Background(), state.tools, "up", newUpstream(t, "shout"))
+	require.ErrorIs(t, err, errToolExists, "upstream tools should not replace each other")
+}
+
+//nolint:paralleltest // sets env vars
+func Test_addUpstream_tool_filter(t *testing.T) {
+	setEnv(t, envNameUpstreams, "up=up-server")
+	setEnv(t, envNameToolsDisabled, "up_secret")
+
+	state := newServerState()
+
+	upSession, err := addUpstream(context.Background(), state.tools, "up", newUpstream(t, "shout", "secret"))
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = upSession.Close() })
+
+	session := connectInMemory(t, state.server)
+
+	var names []string
+
+	for tool, err := range session.Tools(context.Background(), nil) {
+		require.NoError(t, err)
+
+		names = append(names, tool.Name)
+	}
+
+	require.Contains(t, names, "up_shout")
+	require.NotContains(t, names, "up_secret", "the denylist should apply to the upstream tools")
+}
+
+func Test_addUpstreams_failure(t *testing.T) {
+	t.Parallel()
+
+	upstreams := []upstreamConfig{{Name: "broken", Target: "/non-existent-dir-12345/mcp-server"}}
+
+	closeAll, err := addUpstreams(context.Background(), newServerState().tools, upstreams)
+	require.Error(t, err)
+	require.ErrorContains(t, err, `failed to add upstream "broken"`)
+	require.Nil(t, closeAll)
+}
+
+//nolint:paralleltest // sets env var
+func Test_run_invalid_upstreams(t *testing.T) {
+	setEnv(t, envNameUpstreams, "no-name")
+
+	err := newApp().serve(context.Background(), nil)
+	require.ErrorIs(t, err, errUpstreamFormat)
+}
+
+// ----------------------------------------------------------------------------
+//  isObjectSchema
+// ----------------------------------------------------------------------------
+
+func Test_isObjectSchema(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, isObjectSchema(map[string]any{"type": "object"}))
+	require.False(t, isObjectSchema(map[string]any{"type": "string"}))
+	require.False(t, isObjectSchema(nil))
+	require.False(t, isObjectSchema(func() {}), "unmarshalable schema should not be an object")
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rivo/uniseg"
+)
+
+// Sampling-based self-verification.
+const (
+	envNameVerify   = envPrefix + "VERIFY" // env var to ask the client's LLM to verify the mirrored tricky texts. e.g. true
+	verifyMetaKey   = "text-mirror/verification"
+	verifyMaxBytes  = 2048 // max text size to verify, to keep the prompt small
+	verifyMaxTokens = 64
+
+	verdictCorrect   = "correct"
+	verdictIncorrect = "incorrect"
+	verdictUnknown   = "unknown"
+
+	verifySystemPrompt = "You verify text reversal. Text is reversed by user-perceived characters " +
+		"(grapheme clusters): emoji with modifiers, flags, ZWJ sequences and letters with combining " +
+		"marks must stay intact. Answer YES or NO on the first line, then a short reason."
+)
+
+// Verification is the verdict of the client's LLM on the mirrored text, set
+// under the verifyMetaKey key of the _meta of the tool result.
+type Verification struct {
+	Verdict     string `json:"verdict"`               // verdictCorrect, verdictIncorrect or verdictUnknown
+	Model       string `json:"model,omitempty"`       // model which gave the verdict
+	Explanation string `json:"explanation,omitempty"` // reason given by the model, or the error
+}
+
+// GetVerifyEnabled returns whether the mirrored tricky texts are verified by the
+// client's LLM via MCP sampling, from 'MCP_TEXT_MIRROR_VERIFY' environment
+// variable. It is disabled by default.
+//
+// It is mostly a learning example of the sampling capability: the verdict is
+// informative only and never changes the result.
+func GetVerifyEnabled() (bool, error) {
+	return loadedSettings().verifyEnabled()
+}
+
+// verifyEnabled returns GetVerifyEnabled of the settings.
+func (v *settingValues) verifyEnabled() (bool, error) {
+	return v.envBool(envNameVerify)
+}
+
+// isTricky reports whether the text contains characters whose reversal is easy
+// to get wrong: multi-codepoint grapheme clusters or right-to-left scripts.
+func isTricky(text string) bool {
+	if uniseg.GraphemeClusterCount(text) != utf8.RuneCountInString(text) {
+		return true
+	}
+
+	return strings.IndexFunc(text, func(r rune) bool {
+		return unicode.In(r, unicode.Arabic, unicode.Hebrew, unicode.Syriac, unicode.Thaana, unicode.Nko)
+	}) >= 0
+}
+
+// verifyMirror asks the client's LLM via MCP sampling whether the output is the
+// correct reversal of the input, and returns the verdict.
+//
+// It returns nil if verification is disabled, if the client does not support
+// sampling or if the text is not tricky or too large. Sampling failures are
+// reported as verdictUnknown since the verdict is informative only.
+func verifyMirror(ctx context.Context, req *mcp.CallToolRequest, input, output string) *Verification {
+	// Invalid configurations are reported by loadSettings beforehand.
+	if enabled, _ := GetVerifyEnabled(); !enabled {
+		return nil
+	}
+
+	if req == nil || req.Session == nil || len(input) > verifyMaxBytes || !isTricky(input) {
+		return nil
+	}
+
+	initParams := req.Session.InitializeParams()
+	if initParams == nil || initParams.Capabilities == nil || initParams.Capabilities.Sampling == nil {
+		return nil
+	}
+
+	message := new(mcp.SamplingMessage)
+	message.Role = "user"
+	message.Content = &mcp.TextContent{ //nolint:exhaustruct // text only
+		Text: "Is the second text the correct reversal of the first one?\n" +
+			"First: " + input + "\nSecond: " + output,
+	}
+
+	params := new(mcp.CreateMessageParams)
+	params.SystemPrompt = verifySystemPrompt
+	params.Messages = []*mcp.SamplingMessage{message}
+	params.MaxTokens = verifyMaxTokens
+
+	verification := new(Verification)
+	verification.Verdict = verdictUnknown
+
+	res, err := req.Session.CreateMessage(ctx, params)
+	if err != nil {
+		verification.Explanation = err.Error()
+
+		return verification
+	}
+
+	verification.Model = res.Model
+
+	answer, ok := res.Content.(*mcp.TextContent)
+	if !ok {
+		verification.Explanation = "non-text answer"
+
+		return verification
+	}
+
+	verification.Explanation = strings.TrimSpace(answer.Text)
+	firstLine, _, _ := strings.Cut(verification.Explanation, "\n")
+
+	switch word := strings.ToUpper(strings.TrimSpace(firstLine)); {
+	case strings.HasPrefix(word, "YES"):
+		verification.Verdict = verdictCorrect
+	case strings.HasPrefix(word, "NO"):
+		verification.Verdict = verdictIncorrect
+	}
+
+	debugLog(ctx, "text verified", callLogAttrs(ctx, req, "verdict", verification.Verdict)...)
+
+	return verification
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// connectSampling connects a client answering sampling requests with the given
+// result or error to a new server. It returns the client session.
+func connectSampling(t *testing.T, result *mcp.CreateMessageResult, err error) *mcp.ClientSession {
+	t.Helper()
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+
+	serverSession, errConnect := newServer().Connect(ctx, serverTransport, nil)
+	require.NoError(t, errConnect)
+
+	options := new(mcp.ClientOptions)
+	options.CreateMessageHandler = func(context.Context, *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+		return result, err
+	}
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.0"}, options) //nolint:exhaustruct // minimal client
+
+	session, errConnect := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, errConnect)
+
+	t.Cleanup(func() {
+		_ = session.Close()
+		_ = serverSession.Wait()
+	})
+
+	return session
+}
+
+// verificationOf returns the verification in the _meta of the tool result, or
+// nil if none.
+func verificationOf(t *testing.T, res *mcp.CallToolResult) *Verification {
+	t.Helper()
+
+	value, ok := res.Meta[verifyMetaKey]
+	if !ok {
+		return nil
+	}
+
+	data, err := json.Marshal(value)
+	require.NoError(t, err)
+
+	verification := new(Verification)
+	require.NoError(t, json.Unmarshal(data, verification))
+
+	return verification
+}
+
+// ----------------------------------------------------------------------------
+//  isTricky
+// ----------------------------------------------------------------------------
+
+func Test_isTricky(t *testing.T) {
+	t.Parallel()
+
+	for index, test := range []struct {
+		input string
+		want  bool
+	}{
+		{"Hello, World!", false},
+		{"café", false}, // precomposed é
+		{"café", true},
+		{"👍🏽", true},
+		{"שלום", true},
+		{"مرحبا", true},
+		{"日本語", false},
+	} {
+		require.Equal(t, test.want, isTricky(test.input), fmt.Sprintf("Test #%d: %q", index+1, test.input))
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  verifyMirror
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_handleReverse_verify(t *testing.T) {
+	setEnv(t, envNameVerify, "true")
+
+	answer := func(text string) *mcp.CreateMessageResult {
+		return &mcp.CreateMessageResult{ //nolint:exhaustruct // minimal result
+			Content: &mcp.TextContent{Text: text}, //nolint:exhaustruct // text only
+			Model:   "test-model",
+			Role:    "assistant",
+		}
+	}
+
+	for index, test := range []struct {
+		name        string
+		result      *mcp.CreateMessageResult
+		err         error
+		input       string
+		wantVerdict string
+	}{
+		{"correct", answer("Yes.\nAll clusters are intact."), nil, "👍🏽a", verdictCorrect},
+		{"incorrect", answer("NO\nThe skin tone is detached."), nil, "👍🏽a", verdictIncorrect},
+		{"ambiguous", answer("Maybe"), nil, "👍🏽a", verdictUnknown},
+		{"non_text", &mcp.CreateMessageResult{Content: &mcp.ImageContent{MIMEType: "image/png"}, Model: "m", Role: "assistant"}, nil, "👍🏽a", verdictUnknown}, //nolint:exhaustruct,lll // minimal result
+		{"sampling_error", nil, errTest, "👍🏽a", verdictUnknown},
+		{"not_tricky", answer("YES"), nil, "abc", ""},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+		session := connectSampling(t, test.result, test.err)
+
+		res := callTool(t, session, toolName, map[string]any{"text": test.input})
+		require.False(t, res.IsError, name)
+
+		verification := verificationOf(t, res)
+		if test.wantVerdict == "" {
+			require.Nil(t, verification, name)
+
+			continue
+		}
+
+		require.NotNil(t, verification, name)
+		require.Equal(t, test.wantVerdict, verification.Verdict, name)
+		require.NotEmpty(t, verification.Explanation, name)
+	}
+}
+
+//nolint:paralleltest // sets env var
+func Test_handleReverse_verify_skipped(t *testing.T) {
+	setEnv(t, envNameVerify, "")
+
+	// Disabled
+	session := connectSampling(t, nil, errTest)
+	res := callTool(t, session, toolName, map[string]any{"text": "👍🏽a"})
+	require.Nil(t, verificationOf(t, res))
+
+	// Client without sampling capability
+	setEnv(t, envNameVerify, "true")
+
+	session = connectInMemory(t, newServer())
+	res = callTool(t, session, toolName, map[string]any{"text": "👍🏽a"})
+	require.Nil(t, verificationOf(t, res))
+}
+
+//nolint:paralleltest // sets env var
+func Test_run_invalid_verify(t *testing.T) {
+	setEnv(t, envNameVerify, "sometimes")
+
+	err := newApp().serve(context.Background(), nil)
+	require.ErrorIs(t, err, errInvalidBool)
+}
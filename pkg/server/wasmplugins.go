@@ -0,0 +1,116 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+// WebAssembly plugins: modules in the plugins directory, run in-process.
+const (
+	pluginWasmExt         = ".wasm" // extension of the WebAssembly plugins
+	pluginWasmMemoryPages = 4096    // max memory of a WebAssembly plugin call in 64 KiB pages, i.e. 256 MiB
+)
+
+// wasmPlugins runs the WebAssembly plugins with wazero, so that custom
+// transforms can be added without recompiling the server nor installing a
+// runtime.
+//
+// The plugins are WASI command modules speaking the protocol of the plugin
+// executables: each call instantiates the module with the request line on its
+// standard input, runs its _start function and reads the response line on its
+// standard output. The describe request declares the tools and their schemas.
+//
+// The modules are compiled once, on startup, and each call runs in a fresh
+// instance without any access to the file system, the network or the clock
+// beyond WASI's, with at most pluginWasmMemoryPages of memory. The instance is
+// closed once the context is done.
+type wasmPlugins struct {
+	runtime wazero.Runtime // started by the first load
+	mu      sync.Mutex
+}
+
+// isWasmPlugin reports whether the file is a WebAssembly plugin, by its
+// extension.
+func isWasmPlugin(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), pluginWasmExt)
+}
+
+// load compiles the module at path and returns its caller.
+func (w *wasmPlugins) load(ctx context.Context, path string) (pluginCaller, error) {
+	code, err := os.ReadFile(path)
+	if err != nil {
+		return nil, wrapError(err, "failed to read the WebAssembly module")
+	}
+
+	runtime := w.start(ctx)
+
+	module, err := runtime.CompileModule(ctx, code)
+	if err != nil {
+		return nil, wrapError(err, "failed to compile the WebAssembly module")
+	}
+
+	name := filepath.Base(path)
+
+	return func(ctx context.Context, req pluginRequest) (*pluginResponse, error) {
+		line, err := req.line()
+		if err != nil {
+			return nil, err
+		}
+
+		stdout := newPluginOutput(pluginStdoutMax, true)
+		stderr := newPluginOutput(pluginStderrMax, false)
+
+		// Anonymous, so that the calls can run concurrently.
+		config := wazero.NewModuleConfig().WithName("").WithArgs(name).
+			WithStdin(bytes.NewReader(line)).WithStdout(stdout).WithStderr(stderr)
+
+		instance, err := runtime.InstantiateModule(ctx, module, config)
+		if instance != nil {
+			_ = instance.Close(context.WithoutCancel(ctx))
+		}
+
+		switch {
+		case stdout.over:
+			return nil, stdout.overError()
+		case err != nil:
+			return nil, pluginFailure(err, stderr.Bytes())
+		}
+
+		return parsePluginResponse(stdout.Bytes())
+	}, nil
+}
+
+// start returns the runtime, starting it with WASI if not yet.
+func (w *wasmPlugins) start(ctx context.Context) wazero.Runtime {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.runtime == nil {
+		config := wazero.NewRuntimeConfig().
+			WithCloseOnContextDone(true).
+			WithMemoryLimitPages(pluginWasmMemoryPages)
+
+		w.runtime = wazero.NewRuntimeWithConfig(ctx, config)
+		wasi_snapshot_preview1.MustInstantiate(ctx, w.runtime)
+	}
+
+	return w.runtime
+}
+
+// close releases the runtime and the compiled modules, if started.
+func (w *wasmPlugins) close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.runtime != nil {
+		_ = w.runtime.Close(context.Background())
+		w.runtime = nil
+	}
+}
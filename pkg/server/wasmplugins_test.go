@@ -0,0 +1,220 @@
+package server
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// testWasmPlugin is the source of a WebAssembly plugin describing the rot13,
+// fail, crash and loop tools, built for WASI by buildWasmPlugin.
+const testWasmPlugin = `package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+
+	var req struct {
+		Method    string
+		Tool      string
+		Arguments struct{ Text string }
+	}
+	_ = json.Unmarshal([]byte(line), &req)
+
+	switch {
+	case req.Method == "describe":
+		fmt.Println(` + "`" + `{"tools":[{"name":"rot13","description":"Rotates the letters by 13","input_schema":{"type":"object","properties":{"text":{"type":"string"}},"required":["text"]},"output_schema":{"type":"object","properties":{"text":{"type":"string"}}}},{"name":"fail"},{"name":"crash"},{"name":"loop"}]}` + "`" + `)
+	case req.Tool == "rot13":
+		text := strings.Map(func(r rune) rune {
+			switch {
+			case r >= 'a' && r <= 'z':
+				return 'a' + (r-'a'+13)%26
+			case r >= 'A' && r <= 'Z':
+				return 'A' + (r-'A'+13)%26
+			}
+			return r
+		}, req.Arguments.Text)
+		out, _ := json.Marshal(map[string]any{"structured": map[string]string{"text": text}})
+		fmt.Println(string(out))
+	case req.Tool == "fail":
+		fmt.Println(` + "`" + `{"error":"failed as asked"}` + "`" + `)
+	case req.Tool == "loop":
+		for {
+		}
+	default:
+		fmt.Fprintln(os.Stderr, "crashed")
+		os.Exit(3)
+	}
+}
+`
+
+// buildWasmPlugin builds testWasmPlugin once, for all the tests, and returns
+// the module.
+//
+//nolint:gochecknoglobals // built once
+var buildWasmPlugin = sync.OnceValues(func() ([]byte, error) {
+	dir, err := os.MkdirTemp("", "text-mirror-wasm")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	source := filepath.Join(dir, "main.go")
+
+	err = os.WriteFile(source, []byte(testWasmPlugin), 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	output := filepath.Join(dir, "plugin.wasm")
+	cmd := exec.Command("go", "build", "-o", output, source) //nolint:gosec,noctx // test build
+	cmd.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm", "GOFLAGS=")
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, wrapError(err, "failed to build the test plugin: %s", out)
+	}
+
+	return os.ReadFile(output)
+})
+
+// writeWasmPlugin writes the test WebAssembly plugin to dir as name.
+func writeWasmPlugin(t *testing.T, dir, name string) {
+	t.Helper()
+
+	if testing.Short() {
+		t.Skip("builds a WebAssembly module")
+	}
+
+	module, err := buildWasmPlugin()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), module, 0o600))
+}
+
+// ----------------------------------------------------------------------------
+//  isWasmPlugin
+// ----------------------------------------------------------------------------
+
+func Test_isWasmPlugin(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, isWasmPlugin("rot13.wasm"))
+	require.True(t, isWasmPlugin("ROT13.WASM"))
+	require.False(t, isWasmPlugin("rot13"))
+	require.False(t, isWasmPlugin("rot13.wasm.sh"))
+}
+
+// ----------------------------------------------------------------------------
+//  wasmPlugins
+// ----------------------------------------------------------------------------
+
+func Test_addPlugins_wasm(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeWasmPlugin(t, dir, "text.wasm")
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v0.0.0"}, nil) //nolint:exhaustruct // minimal server
+	closePlugins, err := addPlugins(context.Background(), newToolSet(server), dir)
+	require.NoError(t, err)
+	t.Cleanup(closePlugins)
+
+	session := connectInMemory(t, server)
+
+	tools := make(map[string]*mcp.Tool)
+
+	for tool, err := range session.Tools(context.Background(), nil) {
+		require.NoError(t, err)
+
+		tools[tool.Name] = tool
+	}
+
+	require.Len(t, tools, 4)
+	require.Contains(t, tools, "text_rot13")
+	require.Equal(t, "Rotates the letters by 13", tools["text_rot13"].Description)
+	require.NotNil(t, tools["text_rot13"].OutputSchema, "the module should declare the schemas")
+
+	// Structured result, with concurrent instances
+	params := new(mcp.CallToolParams)
+	params.Name = "text_rot13"
+	params.Arguments = map[string]any{"text": "Hello"}
+
+	results := make([]*mcp.CallToolResult, 4)
+	errs := make([]error, len(results))
+
+	var wait sync.WaitGroup
+
+	for index := range results {
+		wait.Go(func() {
+			results[index], errs[index] = session.CallTool(context.Background(), params)
+		})
+	}
+
+	wait.Wait()
+
+	for index, res := range results {
+		require.NoError(t, errs[index])
+		require.False(t, res.IsError)
+		require.Equal(t, map[string]any{"text": "Uryyb"}, res.StructuredContent)
+	}
+
+	// Tool error
+	res := callTool(t, session, "text_fail", nil)
+	require.True(t, res.IsError)
+	require.Equal(t, "failed as asked", res.Content[0].(*mcp.TextContent).Text) //nolint:forcetypeassert // fails the test anyway
+
+	// Non-zero exit of the module
+	params = new(mcp.CallToolParams)
+	params.Name = "text_crash"
+
+	_, err = session.CallTool(context.Background(), params)
+	require.ErrorContains(t, err, "crashed")
+}
+
+func Test_wasmPlugins_canceled(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeWasmPlugin(t, dir, "text.wasm")
+
+	wasm := new(wasmPlugins)
+	t.Cleanup(wasm.close)
+
+	call, err := wasm.load(context.Background(), filepath.Join(dir, "text.wasm"))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	_, err = call(ctx, pluginRequest{Method: pluginMethodCall, Tool: "loop", Arguments: nil})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Less(t, time.Since(start), 5*time.Second, "the endless instance should be closed with the context")
+}
+
+func Test_addPlugins_wasm_invalid(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writePlugin(t, dir, "broken.wasm", "not a module", false)
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v0.0.0"}, nil) //nolint:exhaustruct // minimal server
+	_, err := addPlugins(context.Background(), newToolSet(server), dir)
+	require.ErrorContains(t, err, "failed to compile the WebAssembly module")
+	require.ErrorContains(t, err, `plugin "broken"`, "the plugin should be named in the error")
+}
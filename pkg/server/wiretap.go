@@ -0,0 +1,251 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Wire-tap of the JSON-RPC frames.
+//
+// E.g.: MCP_TEXT_MIRROR_WIRE_TAP=wire.log
+const (
+	envNameWireTap  = envPrefix + "WIRE_TAP" // env var of the trace file of the JSON-RPC frames
+	wireTapName     = "text-mirror-wire.log" // trace file of the dev profile, in the user's log directory
+	wireTapMaxFrame = 64 << 10               // bytes of a pretty-printed frame written at most
+	wireTapRecv     = "<-"                   // direction of the frames from the client
+	wireTapSend     = "->"                   // direction of the frames to the client
+	wireTapIndent   = "  "
+	wireTapSSEData  = "data: "         // prefix of the frames in the SSE events
+	headerSessionID = "Mcp-Session-Id" // header of the session ID of the streamable HTTP transport
+)
+
+// GetWireTapPath returns the path of the trace file to write every inbound
+// and outbound JSON-RPC frame to, from 'MCP_TEXT_MIRROR_WIRE_TAP' environment
+// variable, or empty if not set. Relative paths are in the user's log
+// directory, as the debug log.
+//
+// The frames are pretty-printed and cut at 64 KiB, to diagnose the
+// interoperability issues with the clients. They include the texts as sent,
+// so the file should be handled as the debug log is.
+func GetWireTapPath() string {
+	path := settingValue(envNameWireTap)
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(defaultLogDir(), path)
+}
+
+// wireTap writes the JSON-RPC frames to the trace file.
+type wireTap struct {
+	out io.Writer
+	now func() time.Time // replaceable in tests
+	mu  sync.Mutex
+}
+
+// openWireTap returns the wire-tap writing to the configured trace file, or
+// nil if not configured or the file can't be opened, which is logged to the
+// logger of ctx.
+func openWireTap(ctx context.Context) *wireTap {
+	path := GetWireTapPath()
+	if path == "" {
+		return nil
+	}
+
+	file := logOutput(true, path)
+	if file == os.Stderr {
+		warnLog(ctx, "failed to open the wire-tap file", "path", path)
+
+		return nil
+	}
+
+	return newWireTap(file)
+}
+
+// newWireTap returns the wire-tap writing to out.
+func newWireTap(out io.Writer) *wireTap {
+	tap := new(wireTap)
+	tap.out = out
+	tap.now = time.Now
+
+	return tap
+}
+
+// frame writes the frame with the time, the direction and the session. E.g.
+//
+//	--- 2025-01-02T03:04:05.678Z <- session=ABC 40 bytes
+//	{
+//	  "jsonrpc": "2.0",
+//	  "id": 1,
+//	  "method": "ping"
+//	}
+func (t *wireTap) frame(direction, session string, data []byte) {
+	pretty := buffers.get()
+	defer buffers.put(pretty)
+
+	if json.Indent(pretty, data, "", wireTapIndent) != nil {
+		pretty.Reset()
+		pretty.Write(data) // as is if not JSON
+	}
+
+	if pretty.Len() > wireTapMaxFrame {
+		cut := pretty.Len() - wireTapMaxFrame
+		pretty.Truncate(wireTapMaxFrame)
+		fmt.Fprintf(pretty, "\n... (%d bytes cut)", cut)
+	}
+
+	if session == "" {
+		session = "-"
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	_, _ = fmt.Fprintf(t.out, "--- %s %s session=%s %d bytes\n%s\n",
+		t.now().UTC().Format(time.RFC3339Nano), direction, session, len(data), pretty.Bytes())
+}
+
+// wireTapTransport is an mcp.Transport writing the frames of its connections
+// to the wire-tap, such as the stdio transport.
+type wireTapTransport struct {
+	mcp.Transport
+
+	tap *wireTap
+}
+
+// newWireTapTransport returns transport tapped by tap, or transport itself if
+// tap is nil.
+func newWireTapTransport(transport mcp.Transport, tap *wireTap) mcp.Transport {
+	if tap == nil {
+		return transport
+	}
+
+	return &wireTapTransport{Transport: transport, tap: tap}
+}
+
+// Connect returns the tapped connection. It is an implementation of
+// mcp.Transport.
+func (t *wireTapTransport) Connect(ctx context.Context) (mcp.Connection, error) {
+	conn, err := t.Transport.Connect(ctx)
+	if err != nil {
+		return nil, wrapError(err, "failed to connect")
+	}
+
+	return &wireTapConn{Connection: conn, tap: t.tap}, nil
+}
+
+// wireTapConn is the mcp.Connection of wireTapTransport.
+type wireTapConn struct {
+	mcp.Connection
+
+	tap *wireTap
+}
+
+// Read reads the frame from the client and writes it to the wire-tap.
+func (c *wireTapConn) Read(ctx context.Context) (jsonrpc.Message, error) {
+	msg, err := c.Connection.Read(ctx)
+	if err != nil {
+		return msg, err //nolint:wrapcheck // as is for the SDK to see io.EOF
+	}
+
+	if data, err := jsonrpc.EncodeMessage(msg); err == nil {
+		c.tap.frame(wireTapRecv, c.SessionID(), data)
+	}
+
+	return msg, nil
+}
+
+// Write writes the frame to the wire-tap and sends it to the client.
+func (c *wireTapConn) Write(ctx context.Context, msg jsonrpc.Message) error {
+	if data, err := jsonrpc.EncodeMessage(msg); err == nil {
+		c.tap.frame(wireTapSend, c.SessionID(), data)
+	}
+
+	return c.Connection.Write(ctx, msg) //nolint:wrapcheck // as is for the SDK
+}
+
+// withWireTap writes the JSON-RPC frames of the streamable HTTP transport to
+// the wire-tap: the bodies of the requests, and the responses either in JSON
+// or in the SSE events. It returns next itself if tap is nil.
+func withWireTap(tap *wireTap, next http.Handler) http.Handler {
+	if tap == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body != nil && r.Method == http.MethodPost {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, "failed to read the request body", http.StatusBadRequest)
+
+				return
+			}
+
+			_ = r.Body.Close()
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			tap.frame(wireTapRecv, r.Header.Get(headerSessionID), body)
+		}
+
+		tapped := new(wireTapResponse)
+		tapped.ResponseWriter = w
+		tapped.tap = tap
+
+		next.ServeHTTP(tapped, r)
+	})
+}
+
+// wireTapResponse is the http.ResponseWriter of withWireTap.
+type wireTapResponse struct {
+	http.ResponseWriter
+
+	tap *wireTap
+}
+
+// Write writes the frames of the body to the wire-tap and to the client. The
+// SSE events are written one at a time by the SDK.
+func (w *wireTapResponse) Write(data []byte) (int, error) {
+	session := w.Header().Get(headerSessionID)
+
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream") {
+		w.tap.frame(wireTapSend, session, data)
+
+		return w.ResponseWriter.Write(data) //nolint:wrapcheck // as is for the SDK
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+
+	for scanner.Scan() {
+		if frame, ok := strings.CutPrefix(scanner.Text(), wireTapSSEData); ok {
+			w.tap.frame(wireTapSend, session, []byte(frame))
+		}
+	}
+
+	return w.ResponseWriter.Write(data) //nolint:wrapcheck // as is for the SDK
+}
+
+// Flush flushes the SSE events to the client.
+func (w *wireTapResponse) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the original writer for http.ResponseController.
+func (w *wireTapResponse) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  GetWireTapPath
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func TestGetWireTapPath(t *testing.T) {
+	unsetEnv(t, envNameWireTap)
+	require.Empty(t, GetWireTapPath(), "not set should be empty")
+
+	abs := filepath.Join(t.TempDir(), "wire.log")
+	setEnv(t, envNameWireTap, abs)
+	require.Equal(t, abs, GetWireTapPath(), "absolute path should be as is")
+
+	setEnv(t, envNameWireTap, "wire.log")
+	require.Equal(t, filepath.Join(defaultLogDir(), "wire.log"), GetWireTapPath(),
+		"relative path should be in the log directory")
+}
+
+// ----------------------------------------------------------------------------
+//  wireTap.frame
+// ----------------------------------------------------------------------------
+
+func Test_wireTap_frame(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+
+	tap := newWireTap(&out)
+	tap.now = func() time.Time { return time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	tap.frame(wireTapRecv, "ABC", []byte(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+	require.Equal(t,
+		"--- 2025-01-02T03:04:05Z <- session=ABC 40 bytes\n"+
+			"{\n  \"jsonrpc\": \"2.0\",\n  \"id\": 1,\n  \"method\": \"ping\"\n}\n",
+		out.String(), "JSON frames should be pretty-printed")
+
+	out.Reset()
+	tap.frame(wireTapSend, "", []byte("not JSON"))
+	require.Equal(t, "--- 2025-01-02T03:04:05Z -> session=- 8 bytes\nnot JSON\n", out.String(),
+		"non-JSON frames should be as is without the session")
+
+	out.Reset()
+	tap.frame(wireTapSend, "ABC", []byte(`"`+strings.Repeat("x", wireTapMaxFrame)+`"`))
+	require.Contains(t, out.String(), "\n... (2 bytes cut)\n", "large frames should be cut")
+	require.Less(t, out.Len(), wireTapMaxFrame+100)
+}
+
+// ----------------------------------------------------------------------------
+//  newWireTapTransport
+// ----------------------------------------------------------------------------
+
+func Test_newWireTapTransport(t *testing.T) {
+	t.Parallel()
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	require.Same(t, serverTransport, newWireTapTransport(serverTransport, nil),
+		"nil tap should return the transport as is")
+
+	var out syncBuffer
+
+	ctx := context.Background()
+
+	serverSession, err := newServer().Connect(ctx, newWireTapTransport(serverTransport, newWireTap(&out)), nil)
+	require.NoError(t, err)
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.0"}, nil) //nolint:exhaustruct // minimal client
+
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+
+	res := callTool(t, clientSession, toolName, map[string]any{"text": "abc"})
+	require.False(t, res.IsError)
+
+	_ = clientSession.Close()
+	_ = serverSession.Wait()
+
+	trace := out.String()
+	require.Contains(t, trace, " <- session=- ", "frames from the client should be written")
+	require.Contains(t, trace, " -> session=- ", "frames to the client should be written")
+	require.Contains(t, trace, `"method": "tools/call"`)
+	require.Contains(t, trace, `"text": "cba"`)
+}
+
+// ----------------------------------------------------------------------------
+//  withWireTap
+// ----------------------------------------------------------------------------
+
+//nolint:paralleltest // sets env var
+func Test_withWireTap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wire.log")
+	setEnv(t, envNameWireTap, path)
+
+	server := httptest.NewServer(newHTTPHandler(context.Background(), newServer(), new(atomic.Bool)))
+	defer server.Close()
+
+	transport := new(mcp.StreamableClientTransport)
+	transport.Endpoint = server.URL + httpPathMCP
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.0"}, nil) //nolint:exhaustruct // minimal client
+
+	session, err := client.Connect(context.Background(), transport, nil)
+	require.NoError(t, err)
+
+	res := callTool(t, session, toolName, map[string]any{"text": "abc"})
+	require.False(t, res.IsError)
+	require.NoError(t, session.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	trace := string(data)
+	require.Contains(t, trace, " <- session=- ", "initialize request has no session yet")
+	require.Contains(t, trace, " <- session="+session.ID()+" ", "requests should have the session")
+	require.Contains(t, trace, " -> session="+session.ID()+" ", "responses should have the session")
+	require.Contains(t, trace, `"method": "initialize"`)
+	require.Contains(t, trace, `"text": "cba"`, "frames of the SSE events should be written")
+}
+
+// syncBuffer is a bytes.Buffer safe for the concurrent writes and reads.
+type syncBuffer struct {
+	buf bytes.Buffer
+	mu  sync.Mutex
+}
+
+func (b *syncBuffer) Write(data []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(data) //nolint:wrapcheck // as is as bytes.Buffer
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Reverse words tool.
+const (
+	wordsToolName        = "reverse_words"
+	wordsToolDescription = "Reverses the order of the words of the UTF-8 text, keeping the letters of each word " +
+		"in order. e.g. \"Hello, big world!\" -> \"world, big Hello!\""
+)
+
+// Values of the whitespace argument of the reverse_words tool.
+const (
+	whitespacePreserve = "preserve" // the whitespace stays as is (default)
+	whitespaceCollapse = "collapse" // the runs of whitespace become a single space, trimmed at both ends
+)
+
+// errInvalidWhitespace is returned for an unknown whitespace argument, which the
+// schema normally rejects beforehand.
+var errInvalidWhitespace = errors.New("whitespace must be preserve or collapse")
+
+// ReverseWordsInput is the input for the reverse_words tool.
+type ReverseWordsInput struct {
+	Text       string `json:"text"                 jsonschema:"The UTF-8 text whose words to reverse the order of."`
+	Whitespace string `json:"whitespace,omitempty" jsonschema:"How to treat the whitespace. preserve (default) keeps the spaces and line breaks as they are, collapse turns each run of them into a single space and trims both ends."`
+}
+
+// ReverseWordsOutput is the output from the reverse_words tool.
+type ReverseWordsOutput struct {
+	Text  string `json:"text"  jsonschema:"The text with its words in the reverse order. The whitespace and the punctuation between the words stay in place."`
+	Words int    `json:"words" jsonschema:"The number of words reversed."`
+}
+
+// wordsTool is the reverse_words tool, reversing the order of the words
+// instead of the characters.
+type wordsTool struct{}
+
+// Name returns the name of the tool.
+func (wordsTool) Name() string { return wordsToolName }
+
+// Description returns the description of the tool.
+func (wordsTool) Description() string { return wordsToolDescription }
+
+// Schema returns the schemas of the input and the output of the tool.
+func (wordsTool) Schema() (*jsonschema.Schema, *jsonschema.Schema) {
+	return reverseWordsInputSchema(), nil
+}
+
+// Annotations returns the hints of the tool.
+func (wordsTool) Annotations() *mcp.ToolAnnotations { return readOnlyAnnotations() }
+
+// Handler returns handleReverseWords.
+func (wordsTool) Handler(*serverState) ToolHandler { return TypedHandler(handleReverseWords) }
+
+// cacheable returns true, as the result depends on the text and the whitespace
+// argument only.
+func (wordsTool) cacheable(map[string]any) bool { return true }
+
+// reverseWordsInputSchema returns the JSON schema of ReverseWordsInput.
+func reverseWordsInputSchema() *jsonschema.Schema {
+	schema := mustInferSchema[ReverseWordsInput]()
+	schema.Title = "Reverse words input"
+	schema.Required = []string{"text"}
+
+	text := schema.Properties["text"]
+	text.Title = "Text"
+	text.MaxLength = jsonschema.Ptr(textMaxLength)
+	text.Examples = []any{"Hello, big world!"}
+
+	whitespace := schema.Properties["whitespace"]
+	whitespace.Title = "Whitespace"
+	whitespace.Enum = []any{whitespacePreserve, whitespaceCollapse}
+
+	return schema
+}
+
+// handleReverseWords returns the text with its words in the reverse order.
+func handleReverseWords(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input ReverseWordsInput,
+) (*mcp.CallToolResult, ReverseWordsOutput, error) {
+	err := ctx.Err()
+	if err != nil {
+		return nil, ReverseWordsOutput{}, wrapError(err, "request canceled")
+	}
+
+	err = checkTextLimit(req, input.Text)
+	if err != nil {
+		return nil, ReverseWordsOutput{}, err
+	}
+
+	text := input.Text
+
+	switch input.Whitespace {
+	case "", whitespacePreserve:
+	case whitespaceCollapse:
+		text = strings.Join(strings.Fields(text), " ")
+	default:
+		return nil, ReverseWordsOutput{}, fmt.Errorf("%w: %q given", errInvalidWhitespace, input.Whitespace)
+	}
+
+	start := time.Now()
+
+	reversed, words, err := reverseWords(ctx, text, true)
+	if err != nil {
+		return nil, ReverseWordsOutput{}, err
+	}
+
+	callLog(ctx, "words reversed", callLogAttrs(ctx, req, logKeyInputSize, len(input.Text), logKeyDuration, time.Since(start),
+		logKeyText, userText(input.Text), logKeyMirrored, userText(reversed))...)
+
+	return nil, ReverseWordsOutput{Text: reversed, Words: words}, nil
+}
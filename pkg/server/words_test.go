@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  handleReverseWords
+// ----------------------------------------------------------------------------
+
+func Test_handleReverseWords(t *testing.T) {
+	t.Parallel()
+
+	session := connectInMemory(t, newServer())
+
+	for index, test := range []struct {
+		name      string
+		args      map[string]any
+		wantText  string
+		wantWords float64
+	}{
+		{"punctuation", map[string]any{"text": "Hello, big world!"}, "world, big Hello!", 3},
+		{"preserve", map[string]any{"text": "  one\ttwo\n three ", "whitespace": whitespacePreserve}, "  three\ttwo\n one ", 3},
+		{"collapse", map[string]any{"text": "  one\ttwo\n three ", "whitespace": whitespaceCollapse}, "three two one", 3},
+		{"contraction", map[string]any{"text": "don't stop"}, "stop don't", 2},
+		{"emoji_and_accents", map[string]any{"text": "café 👍🏽 naïve"}, "naïve 👍🏽 café", 3},
+		{"numbers", map[string]any{"text": "1.5 and 2"}, "2 and 1.5", 3},
+		{"single_word", map[string]any{"text": "word"}, "word", 1},
+		{"no_word", map[string]any{"text": " ... "}, " ... ", 0},
+		{"empty", map[string]any{"text": ""}, "", 0},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		res := callTool(t, session, wordsToolName, test.args)
+		require.False(t, res.IsError, name)
+		require.Equal(t, map[string]any{"text": test.wantText, "words": test.wantWords}, res.StructuredContent, name)
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  reverseWords
+// ----------------------------------------------------------------------------
+
+func Test_reverseWords(t *testing.T) {
+	t.Parallel()
+
+	for index, test := range []struct {
+		name      string
+		text      string
+		keepPunct bool
+		want      string
+		wantWords int
+	}{
+		{"all_segments", "Hello, big world!", false, "!world big ,Hello", 3},
+		{"keep_punct", "Hello, big world!", true, "world, big Hello!", 3},
+		{"spaces", " one  two ", false, " two  one ", 2},
+		{"spaces_keep_punct", " one  two ", true, " two  one ", 2},
+		{"punct_only", "?!", true, "?!", 0},
+		{"empty", "", false, "", 0},
+	} {
+		name := fmt.Sprintf("Test #%d: %s", index+1, test.name)
+
+		got, words, err := reverseWords(context.Background(), test.text, test.keepPunct)
+		require.NoError(t, err, name)
+		require.Equal(t, test.want, got, name)
+		require.Equal(t, test.wantWords, words, name)
+	}
+}
+
+func Test_reverseWords_canceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := reverseWords(ctx, "a b", true)
+	require.ErrorIs(t, err, context.Canceled)
+}
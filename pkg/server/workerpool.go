@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Worker pool configuration.
+const (
+	envNameWorkers    = envPrefix + "WORKERS"     // env var of the max concurrent tool calls. defaults to GOMAXPROCS
+	envNameQueueDepth = envPrefix + "QUEUE_DEPTH" // env var of the max tool calls waiting for a worker
+	queueDepthDefault = 64                        // default max tool calls waiting for a worker
+)
+
+// errServerBusy is reported to the client if all workers are busy and the
+// queue is full.
+var errServerBusy = errors.New("server busy")
+
+// GetWorkerPool returns the max number of concurrent tool calls and the max
+// number of tool calls waiting for a free worker from 'MCP_TEXT_MIRROR_WORKERS'
+// and 'MCP_TEXT_MIRROR_QUEUE_DEPTH' environment variables.
+//
+// The number of workers defaults to GOMAXPROCS and a zero number of workers
+// disables the pool (unbounded). The queue depth defaults to queueDepthDefault.
+func GetWorkerPool() (int, int, error) {
+	return loadedSettings().workerPool()
+}
+
+// workerPool returns GetWorkerPool of the settings.
+func (v *settingValues) workerPool() (int, int, error) {
+	workers, err := v.envInt(envNameWorkers, runtime.GOMAXPROCS(0))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	queueDepth, err := v.envInt(envNameQueueDepth, queueDepthDefault)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return workers, queueDepth, nil
+}
+
+// workerPool bounds the number of tool calls running concurrently. Calls beyond
+// the limit wait in a bounded queue, and calls beyond the queue are rejected.
+type workerPool struct {
+	workers chan struct{} // a token per running call
+	queue   chan struct{} // a token per waiting call
+}
+
+// newWorkerPool returns a pool running up to workers tool calls at once with up
+// to queueDepth calls waiting.
+func newWorkerPool(workers, queueDepth int) *workerPool {
+	pool := new(workerPool)
+	pool.workers = make(chan struct{}, workers)
+	pool.queue = make(chan struct{}, queueDepth)
+
+	return pool
+}
+
+// acquire waits for a free worker. It fails immediately if the queue is full,
+// or once the context is done while waiting.
+func (p *workerPool) acquire(ctx context.Context) error {
+	// Fast path: a worker is free
+	select {
+	case p.workers <- struct{}{}:
+		return nil
+	default:
+	}
+
+	select {
+	case p.queue <- struct{}{}:
+	default:
+		return fmt.Errorf("%w: %d calls running and %d waiting, retry later",
+			errServerBusy, cap(p.workers), cap(p.queue))
+	}
+
+	defer func() { <-p.queue }()
+
+	select {
+	case p.workers <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return wrapError(ctx.Err(), "canceled while waiting for a worker")
+	}
+}
+
+// release frees the worker acquired by acquire.
+func (p *workerPool) release() {
+	<-p.workers
+}
+
+// middleware runs the tool calls within the bounds of the pool. Rejected calls
+// are reported as tool errors, marked as retryable if the server is busy.
+func (p *workerPool) middleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if method != methodCallTool {
+			return next(ctx, method, req)
+		}
+
+		err := p.acquire(ctx)
+		if err != nil {
+			warnLog(ctx, "tool call rejected", logKeyRequestID, requestID(ctx), logKeySession, sessionLog(req),
+				logKeyError, err)
+
+			if errors.Is(err, errServerBusy) {
+				return retryableErrorResult(err), nil
+			}
+
+			return toolErrorResult(err), nil
+		}
+		defer p.release()
+
+		return next(ctx, method, req)
+	}
+}
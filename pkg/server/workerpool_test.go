@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------------------
+//  GetWorkerPool
+// ----------------------------------------------------------------------------
+
+func Test_GetWorkerPool(t *testing.T) {
+	for index, test := range []struct {
+		name        string
+		workers     string
+		queueDepth  string
+		wantWorkers int
+		wantQueue   int
+		wantErr     bool
+	}{
+		{"defaults", "", "", runtime.GOMAXPROCS(0), queueDepthDefault, false},
+		{"custom", "2", "5", 2, 5, false},
+		{"unbounded", "0", "0", 0, 0, false},
+		{"invalid_workers", "two", "", 0, 0, true},
+		{"negative_queue", "2", "-1", 0, 0, true},
+	} {
+		t.Run(fmt.Sprintf("Test #%d: %s", index+1, test.name), func(t *testing.T) {
+			setEnv(t, envNameWorkers, test.workers)
+			setEnv(t, envNameQueueDepth, test.queueDepth)
+
+			workers, queueDepth, err := GetWorkerPool()
+			if test.wantErr {
+				require.ErrorIs(t, err, errInvalidNumber)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.wantWorkers, workers)
+			require.Equal(t, test.wantQueue, queueDepth)
+		})
+	}
+}
+
+//nolint:paralleltest // sets env var
+func Test_run_invalid_worker_pool(t *testing.T) {
+	setEnv(t, envNameWorkers, "many")
+
+	err := newApp().serve(context.Background(), nil)
+	require.ErrorIs(t, err, errInvalidNumber)
+}
+
+// ----------------------------------------------------------------------------
+//  workerPool
+// ----------------------------------------------------------------------------
+
+func Test_workerPool_acquire(t *testing.T) {
+	t.Parallel()
+
+	pool := newWorkerPool(1, 1)
+	ctx := context.Background()
+
+	require.NoError(t, pool.acquire(ctx), "first call should get the worker")
+
+	// Second call waits in the queue until the worker is released
+	acquired := make(chan error, 1)
+
+	go func() { acquired <- pool.acquire(ctx) }()
+
+	require.Eventually(t, func() bool { return len(pool.queue) == 1 }, timeoutEventually, tickEventually)
+
+	// Third call is rejected since the queue is full
+	require.ErrorIs(t, pool.acquire(ctx), errServerBusy)
+
+	pool.release()
+	require.NoError(t, <-acquired, "queued call should get the released worker")
+	require.Empty(t, pool.queue, "queue should be empty once the call is running")
+
+	// Waiting call is canceled with the context
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	require.ErrorIs(t, pool.acquire(canceledCtx), context.Canceled)
+	require.Empty(t, pool.queue)
+
+	pool.release()
+	require.Empty(t, pool.workers, "all workers should be free")
+}
+
+func Test_workerPool_middleware(t *testing.T) {
+	t.Parallel()
+
+	pool := newWorkerPool(1, 0)
+	server := newServer()
+	server.AddReceivingMiddleware(pool.middleware)
+
+	session := connectInMemory(t, server)
+	args := map[string]any{"text": "abc"}
+
+	require.False(t, callTool(t, session, toolName, args).IsError)
+	require.Empty(t, pool.workers, "worker should be released after the call")
+
+	// Occupy the only worker, so that the next call is rejected (no queue)
+	require.NoError(t, pool.acquire(context.Background()))
+	defer pool.release()
+
+	res := callTool(t, session, toolName, args)
+	require.True(t, res.IsError)
+	require.Contains(t, res.Content[0].(*mcp.TextContent).Text, errServerBusy.Error()) //nolint:forcetypeassert // text content
+	require.Equal(t, true, res.Meta[metaKeyRetryable], "busy server should be worth retrying")
+
+	// Other methods are not bounded
+	_, err := session.ListTools(context.Background(), nil)
+	require.NoError(t, err)
+}